@@ -0,0 +1,162 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+// ErrConnAffinityNotSupported is returned from WithConn if the client is not created by the NewClient, such as a mock or a wrapped client.
+var ErrConnAffinityNotSupported = errors.New("WithConn is not supported by the client")
+
+// ErrConnPinnedWithoutSlot is returned in cluster mode if a command with keys is sent by the CoreClient of WithConn
+// after commands without keys, such as the PING, have already picked a connection which may not serve the slot of the keys.
+var ErrConnPinnedWithoutSlot = errors.New("the connection of WithConn is picked by commands without keys before a command with keys")
+
+const (
+	pinnedClientUsedAfterReleased = "CoreClient of WithConn should not be used after the fn returns"
+	panicMsgCxSlotPinned          = "cross slot command in WithConn is prohibited"
+)
+
+type connPinner interface {
+	withConn(fn func(c CoreClient) error) error
+}
+
+// WithConn calls the fn with a CoreClient that sends all its commands on the same connection, which is lighter than
+// the Dedicated because the connection is one of the auto pipelining connections and keeps serving other traffic.
+// The commands are written to the connection in the order they are issued, so that connection scoped behaviors,
+// such as the WAIT for the writes of the same connection, apply to all of them across multiple Do and DoMulti calls.
+// Unlike the DoMulti, which writes its commands back to back, commands of other traffic may be interleaved between
+// the calls, and neither of them is atomic. Use the Dedicated with MULTI EXEC for that.
+// Commands changing the connection state, such as SELECT and CLIENT REPLY, and blocking commands must not be used,
+// because they affect other traffic on the same connection. Commands are not retried, and if the connection is broken,
+// the rest of them fail instead of being sent on another connection.
+// In cluster mode, the connection is picked by the slot of the first command, so all keys of the commands must be in the same slot,
+// for example, by sharing a hash tag like {user}:k1 and {user}:k2. Like the Dedicated, a command of another slot panics.
+// Commands without keys don't decide the slot, but if they are the first ones, they pick a connection to any node,
+// and a later command with keys returns the ErrConnPinnedWithoutSlot.
+// It returns the ctx.Err() without calling the fn if the ctx is done, or ErrConnAffinityNotSupported if the client is not
+// created by the NewClient.
+func WithConn(client Client, ctx context.Context, fn func(c CoreClient) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c, ok := client.(connPinner); ok {
+		return c.withConn(fn)
+	}
+	return ErrConnAffinityNotSupported
+}
+
+func withPinned(builder Builder, pick func(ctx context.Context, slot uint16) (conn, error), fn func(c CoreClient) error) error {
+	pc := &pinnedClient{cmd: builder, pick: pick, slot: cmds.NoSlot}
+	err := fn(pc)
+	pc.release()
+	return err
+}
+
+// pinnedClient sends its commands on one of the auto pipelining wires of the conn picked by the slot of the first command.
+type pinnedClient struct {
+	pick func(ctx context.Context, slot uint16) (conn, error)
+	wire wire
+	cmd  Builder
+	mu   sync.Mutex
+	slot uint16
+	mark bool
+}
+
+func (c *pinnedClient) acquire(ctx context.Context, slots ...uint16) (w wire, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mark {
+		panic(pinnedClientUsedAfterReleased)
+	}
+	for _, slot := range slots {
+		if slot == cmds.NoSlot || slot == cmds.InitSlot { // commands without keys can be sent to any node.
+			continue
+		}
+		if c.slot == cmds.NoSlot {
+			if c.wire != nil {
+				return nil, ErrConnPinnedWithoutSlot
+			}
+			c.slot = slot
+		} else if c.slot != slot {
+			panic(panicMsgCxSlotPinned)
+		}
+	}
+	if c.wire == nil {
+		slot := c.slot
+		if slot == cmds.NoSlot {
+			slot = slots[0]
+		}
+		var cc conn
+		if cc, err = c.pick(ctx, slot); err != nil {
+			return nil, err
+		}
+		c.wire = cc.Pin()
+	}
+	return c.wire, nil
+}
+
+func (c *pinnedClient) release() {
+	c.mu.Lock()
+	c.mark = true
+	c.mu.Unlock()
+}
+
+func (c *pinnedClient) B() Builder {
+	return c.cmd
+}
+
+func (c *pinnedClient) Do(ctx context.Context, cmd Completed) (resp RedisResult) {
+	w, err := c.acquire(ctx, cmd.Slot())
+	if err != nil {
+		return newErrResult(err)
+	}
+	if resp = w.Do(ctx, cmd); resp.NonRedisError() == nil {
+		cmds.PutCompleted(cmd)
+	}
+	return resp
+}
+
+func (c *pinnedClient) DoMulti(ctx context.Context, multi ...Completed) (resp []RedisResult) {
+	if len(multi) == 0 {
+		return nil
+	}
+	slots := make([]uint16, len(multi))
+	for i, cmd := range multi {
+		slots[i] = cmd.Slot()
+	}
+	w, err := c.acquire(ctx, slots...)
+	if err != nil {
+		resp = make([]RedisResult, len(multi))
+		for i := range resp {
+			resp[i] = newErrResult(err)
+		}
+		return resp
+	}
+	resp = w.DoMulti(ctx, multi...).s
+	for i, cmd := range multi {
+		if resp[i].NonRedisError() == nil {
+			cmds.PutCompleted(cmd)
+		}
+	}
+	return resp
+}
+
+func (c *pinnedClient) Receive(ctx context.Context, subscribe Completed, fn func(msg PubSubMessage)) (err error) {
+	w, err := c.acquire(ctx, subscribe.Slot())
+	if err != nil {
+		return err
+	}
+	if err = w.Receive(ctx, subscribe, fn); err == nil {
+		cmds.PutCompleted(subscribe)
+	}
+	return err
+}
+
+// Close only makes further calls panic. The shared connection is not closed.
+func (c *pinnedClient) Close() {
+	c.release()
+}
@@ -0,0 +1,227 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWithConn(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var sent [][]string
+	pins := 0
+	w := &mockWire{
+		DoFn: func(cmd Completed) RedisResult {
+			sent = append(sent, append([]string(nil), cmd.Commands()...))
+			return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+		},
+		DoMultiFn: func(multi ...Completed) *redisresults {
+			resps := make([]RedisResult, 0, len(multi))
+			for _, cmd := range multi {
+				sent = append(sent, append([]string(nil), cmd.Commands()...))
+				resps = append(resps, newResult(RedisMessage{typ: '+', string: "OK"}, nil))
+			}
+			return &redisresults{s: resps}
+		},
+		ReceiveFn: func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
+			sent = append(sent, append([]string(nil), subscribe.Commands()...))
+			return nil
+		},
+	}
+	m := &mockConn{
+		PinFn: func() wire {
+			pins++
+			return w
+		},
+		DoFn: func(cmd Completed) RedisResult {
+			t.Fatalf("unexpected command on the mux %v", cmd.Commands())
+			return RedisResult{}
+		},
+	}
+	sc, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn { return m })
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	ctx := context.Background()
+
+	var pinned CoreClient
+	if err := WithConn(sc, ctx, func(c CoreClient) error {
+		pinned = c
+		if err := c.Do(ctx, c.B().Set().Key("k").Value("v").Build()).Error(); err != nil {
+			return err
+		}
+		for _, resp := range c.DoMulti(ctx, c.B().Incr().Key("k").Build(), c.B().Wait().Numreplicas(1).Timeout(0).Build()) {
+			if err := resp.Error(); err != nil {
+				return err
+			}
+		}
+		if len(c.DoMulti(ctx)) != 0 {
+			t.Fatal("DoMulti without commands should return nothing")
+		}
+		return c.Receive(ctx, c.B().Subscribe().Channel("ch").Build(), func(msg PubSubMessage) {})
+	}); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if pins != 1 {
+		t.Fatalf("unexpected pins %v", pins)
+	}
+	if !reflect.DeepEqual(sent, [][]string{{"SET", "k", "v"}, {"INCR", "k"}, {"WAIT", "1", "0"}, {"SUBSCRIBE", "ch"}}) {
+		t.Fatalf("unexpected commands %v", sent)
+	}
+
+	t.Run("Used After Released", func(t *testing.T) {
+		defer func() {
+			if msg := recover(); msg != pinnedClientUsedAfterReleased {
+				t.Fatalf("unexpected panic %v", msg)
+			}
+		}()
+		pinned.Do(ctx, pinned.B().Get().Key("k").Build())
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		defer func() {
+			if msg := recover(); msg != pinnedClientUsedAfterReleased {
+				t.Fatalf("unexpected panic %v", msg)
+			}
+		}()
+		_ = WithConn(sc, ctx, func(c CoreClient) error {
+			c.Close()
+			c.Do(ctx, c.B().Get().Key("k").Build())
+			return nil
+		})
+	})
+
+	t.Run("Context Done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := WithConn(sc, ctx, func(c CoreClient) error {
+			t.Fatal("fn should not be called")
+			return nil
+		}); err != context.Canceled {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Not Supported", func(t *testing.T) {
+		if err := WithConn(&client{}, ctx, func(c CoreClient) error {
+			t.Fatal("fn should not be called")
+			return nil
+		}); err != ErrConnAffinityNotSupported {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Error From Fn", func(t *testing.T) {
+		e := errors.New("fn")
+		if err := WithConn(sc, ctx, func(c CoreClient) error { return e }); err != e {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
+
+func TestWithConnCluster(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var mu sync.Mutex
+	sent := map[string][][]string{}
+	client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+		return &mockConn{
+			DoFn: func(cmd Completed) RedisResult {
+				if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+					return slotsMultiResp
+				}
+				t.Fatalf("unexpected command on the mux %v", cmd.Commands())
+				return RedisResult{}
+			},
+			PinFn: func() wire {
+				return &mockWire{
+					DoFn: func(cmd Completed) RedisResult {
+						mu.Lock()
+						sent[dst] = append(sent[dst], append([]string(nil), cmd.Commands()...))
+						mu.Unlock()
+						return newResult(RedisMessage{typ: '+', string: dst}, nil)
+					},
+					DoMultiFn: func(multi ...Completed) *redisresults {
+						resps := make([]RedisResult, len(multi))
+						for i := range resps {
+							resps[i] = newResult(RedisMessage{typ: '+', string: dst}, nil)
+						}
+						return &redisresults{s: resps}
+					},
+				}
+			},
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	// the slot of "a" is 15495, which is served by the master 127.0.2.1:0.
+	if err := WithConn(client, ctx, func(c CoreClient) error {
+		for _, cmd := range []Completed{c.B().Get().Key("a").Build(), c.B().Ping().Build(), c.B().Set().Key("a").Value("v").Build()} {
+			if v, err := c.Do(ctx, cmd).ToString(); err != nil || v != "127.0.2.1:0" {
+				t.Fatalf("unexpected response %v %v", v, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if !reflect.DeepEqual(sent["127.0.2.1:0"], [][]string{{"GET", "a"}, {"PING"}, {"SET", "a", "v"}}) {
+		t.Fatalf("unexpected commands %v", sent)
+	}
+
+	t.Run("Ping Then Set", func(t *testing.T) {
+		if err := WithConn(client, ctx, func(c CoreClient) error {
+			if err := c.Do(ctx, c.B().Ping().Build()).Error(); err != nil {
+				t.Fatalf("unexpected err %v", err)
+			}
+			return c.Do(ctx, c.B().Set().Key("a").Value("v").Build()).Error()
+		}); err != ErrConnPinnedWithoutSlot {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Ping Then Set In DoMulti", func(t *testing.T) {
+		if err := WithConn(client, ctx, func(c CoreClient) error {
+			for _, resp := range c.DoMulti(ctx, c.B().Ping().Build(), c.B().Set().Key("a").Value("v").Build()) {
+				if v, err := resp.ToString(); err != nil || v != "127.0.2.1:0" {
+					t.Fatalf("unexpected response %v %v", v, err)
+				}
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Cross Slot", func(t *testing.T) {
+		defer func() {
+			if msg := recover(); msg != panicMsgCxSlotPinned {
+				t.Fatalf("unexpected panic %v", msg)
+			}
+		}()
+		_ = WithConn(client, ctx, func(c CoreClient) error {
+			c.DoMulti(ctx, c.B().Get().Key("a").Build(), c.B().Get().Key("b").Build())
+			return nil
+		})
+	})
+}
+
+func TestMuxPin(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	wires := []*mockWire{{}, {}}
+	m, checkClean := setupMuxWithOption(wires, &ClientOption{PipelineMultiplex: 1})
+	defer checkClean(t)
+	seen := map[wire]bool{}
+	for i := 0; i < 100; i++ {
+		seen[m.Pin()] = true
+	}
+	if len(seen) != 2 || !seen[wires[0]] || !seen[wires[1]] {
+		t.Fatalf("unexpected pinned wires %v", seen)
+	}
+}
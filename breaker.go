@@ -0,0 +1,115 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned from a cluster client if the circuit of the node serving the command is open,
+// and the command can't be sent to a replica instead. See ClientOption.CircuitBreaker.
+var ErrCircuitOpen = errors.New("the circuit of the redis node is open")
+
+// CircuitBreaker configures the per node circuit breaker of the cluster client.
+// The circuit of a node is opened after the Threshold errors within the Window. While it is open, read-only commands
+// of the slots served by the node are sent to their replicas, with the READONLY first in the default mode, and other commands
+// fail fast with the ErrCircuitOpen. In the default mode, the replies of cacheable commands sent to the replicas are not cached.
+// After the Cooldown, one command is let through to probe the node, which is half-open,
+// and the circuit is closed if the probe succeeds, or opened again otherwise.
+// Only the network errors, such as timeouts and connection errors, are counted. Redis error replies are not.
+type CircuitBreaker struct {
+	// Threshold is how many errors within the Window open the circuit. Default value is 5.
+	Threshold int
+	// Window is the duration in which the errors are counted. Default value is 10s.
+	Window time.Duration
+	// Cooldown is how long the circuit stays open before the probe. Default value is 5s.
+	Cooldown time.Duration
+}
+
+type breakers struct {
+	nodes sync.Map // map[conn]*breaker
+	opt   CircuitBreaker
+	now   func() time.Time
+}
+
+func newBreakers(opt *CircuitBreaker) *breakers {
+	if opt == nil {
+		return nil
+	}
+	b := &breakers{opt: *opt, now: time.Now}
+	if b.opt.Threshold <= 0 {
+		b.opt.Threshold = 5
+	}
+	if b.opt.Window <= 0 {
+		b.opt.Window = 10 * time.Second
+	}
+	if b.opt.Cooldown <= 0 {
+		b.opt.Cooldown = 5 * time.Second
+	}
+	return b
+}
+
+func (b *breakers) of(cc conn) *breaker {
+	if v, ok := b.nodes.Load(cc); ok {
+		return v.(*breaker)
+	}
+	v, _ := b.nodes.LoadOrStore(cc, &breaker{})
+	return v.(*breaker)
+}
+
+// allow reports whether a command can be sent to the cc. If the circuit is open and its cooldown is over,
+// only the first caller is allowed as the probe until it is recorded.
+func (b *breakers) allow(cc conn) bool {
+	n := b.of(cc)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.opened.IsZero() {
+		return true
+	}
+	if !n.probing && b.now().Sub(n.opened) >= b.opt.Cooldown {
+		n.probing = true
+		return true
+	}
+	return false
+}
+
+// record counts the err of a command sent to the cc. The errors caused by the ctx of the caller are ignored.
+func (b *breakers) record(ctx context.Context, cc conn, err error) {
+	n := b.of(cc)
+	now := b.now()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err != nil && ctx.Err() != nil {
+		n.probing = false // let another command probe the node.
+		return
+	}
+	if err == nil {
+		if n.probing {
+			n.opened, n.probing, n.errs = time.Time{}, false, 0
+		}
+		return
+	}
+	if n.probing {
+		n.opened, n.probing = now, false
+		return
+	}
+	if now.Sub(n.start) > b.opt.Window {
+		n.start, n.errs = now, 0
+	}
+	if n.errs++; n.errs >= b.opt.Threshold && n.opened.IsZero() {
+		n.opened = now
+	}
+}
+
+func (b *breakers) remove(cc conn) {
+	b.nodes.Delete(cc)
+}
+
+type breaker struct {
+	start   time.Time // the start of the current window
+	opened  time.Time // zero if the circuit is closed
+	mu      sync.Mutex
+	errs    int
+	probing bool
+}
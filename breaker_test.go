@@ -0,0 +1,278 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreakers(t *testing.T) {
+	if newBreakers(nil) != nil {
+		t.Fatal("breakers should be disabled without the option")
+	}
+	b := newBreakers(&CircuitBreaker{})
+	if b.opt.Threshold != 5 || b.opt.Window != 10*time.Second || b.opt.Cooldown != 5*time.Second {
+		t.Fatalf("unexpected defaults %v", b.opt)
+	}
+
+	now := time.Now()
+	b = newBreakers(&CircuitBreaker{Threshold: 2, Window: time.Second, Cooldown: time.Second})
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+	cc := &mockConn{}
+	fail := errors.New("fail")
+
+	b.record(ctx, cc, fail)
+	now = now.Add(2 * time.Second) // the first error is out of the window
+	b.record(ctx, cc, fail)
+	if !b.allow(cc) {
+		t.Fatal("circuit should be closed if the errors are not within the window")
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	b.record(canceled, cc, fail)
+	if !b.allow(cc) {
+		t.Fatal("errors of canceled ctx should not be counted")
+	}
+	b.record(ctx, cc, nil)
+	b.record(ctx, cc, fail)
+	if b.allow(cc) {
+		t.Fatal("circuit should be open after the threshold")
+	}
+
+	now = now.Add(time.Second)
+	if !b.allow(cc) {
+		t.Fatal("a probe should be allowed after the cooldown")
+	}
+	if b.allow(cc) {
+		t.Fatal("only one probe should be allowed")
+	}
+	b.record(ctx, cc, fail)
+	if b.allow(cc) {
+		t.Fatal("circuit should be opened again if the probe fails")
+	}
+
+	now = now.Add(time.Second)
+	if !b.allow(cc) {
+		t.Fatal("a probe should be allowed after the cooldown")
+	}
+	b.record(canceled, cc, fail)
+	if !b.allow(cc) {
+		t.Fatal("another probe should be allowed if the previous one is canceled")
+	}
+	b.record(ctx, cc, nil)
+	if !b.allow(cc) || !b.allow(cc) {
+		t.Fatal("circuit should be closed if the probe succeeds")
+	}
+	b.record(ctx, cc, fail)
+	if !b.allow(cc) {
+		t.Fatal("errors should be counted from zero after the circuit is closed")
+	}
+
+	b.remove(cc)
+	if _, ok := b.nodes.Load(cc); ok {
+		t.Fatal("breaker should be removed")
+	}
+}
+
+func TestClusterClientCircuitBreaker(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var mu sync.Mutex
+	sent := map[string][]string{}
+	var failing int32 = 1
+	setup := func(t *testing.T, slots RedisResult, failed string, sendToReplicas func(cmd Completed) bool) *clusterClient {
+		sent = map[string][]string{}
+		atomic.StoreInt32(&failing, 1)
+		client, err := newClusterClient(&ClientOption{
+			InitAddress:    []string{"127.0.0.1:0"},
+			DisableRetry:   true,
+			SendToReplicas: sendToReplicas,
+			CircuitBreaker: &CircuitBreaker{Threshold: 2, Cooldown: time.Hour},
+		}, func(dst string, opt *ClientOption) conn {
+			do := func(cmd Completed) RedisResult {
+				if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+					return slots
+				}
+				mu.Lock()
+				sent[dst] = append(sent[dst], cmd.Commands()[0])
+				mu.Unlock()
+				if dst == failed && atomic.LoadInt32(&failing) == 1 {
+					return newErrResult(errors.New("timeout"))
+				}
+				return newResult(RedisMessage{typ: '+', string: dst}, nil)
+			}
+			return &mockConn{
+				DoFn: do,
+				DoCacheFn: func(cmd Cacheable, ttl time.Duration) RedisResult {
+					return do(Completed(cmd))
+				},
+				DoMultiFn: func(multi ...Completed) *redisresults {
+					resps := make([]RedisResult, len(multi))
+					for i, cmd := range multi {
+						resps[i] = do(cmd)
+					}
+					return &redisresults{s: resps}
+				},
+				DoMultiCacheFn: func(multi ...CacheableTTL) *redisresults {
+					resps := make([]RedisResult, len(multi))
+					for i, cmd := range multi {
+						resps[i] = do(Completed(cmd.Cmd))
+					}
+					return &redisresults{s: resps}
+				},
+				ReceiveFn: func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
+					return do(subscribe).Error()
+				},
+			}
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client
+	}
+	sentTo := func(dst string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), sent[dst]...)
+	}
+
+	// the slot of "a" is 15495, which is served by the master 127.0.2.1:0 and the replica 127.0.3.1:1 in the slotsMultiResp,
+	// and by the master 127.0.1.1:0 in the slotsMultiRespWithoutReplicas.
+	for _, c := range []struct {
+		name           string
+		sendToReplicas func(cmd Completed) bool
+		readonly       []string
+	}{
+		{name: "Default", readonly: []string{"READONLY"}},
+		{name: "SendToReplicas", sendToReplicas: func(cmd Completed) bool { return false }},
+	} {
+		c := c
+		t.Run("Fallback To Replicas "+c.name, func(t *testing.T) {
+			client := setup(t, slotsMultiResp, "127.0.2.1:0", c.sendToReplicas)
+			defer client.Close()
+			for i := 0; i < 2; i++ {
+				if err := client.Do(context.Background(), client.B().Get().Key("a").Build()).Error(); err == nil || err.Error() != "timeout" {
+					t.Fatalf("unexpected err %v", err)
+				}
+			}
+			if v, err := client.Do(context.Background(), client.B().Get().Key("a").Build()).ToString(); err != nil || v != "127.0.3.1:1" {
+				t.Fatalf("read-only commands should be sent to the replica %v %v", v, err)
+			}
+			if v, err := client.DoCache(context.Background(), client.B().Get().Key("a").Cache(), time.Second).ToString(); err != nil || v != "127.0.3.1:1" {
+				t.Fatalf("read-only commands should be sent to the replica %v %v", v, err)
+			}
+			if err := client.Do(context.Background(), client.B().Set().Key("a").Value("v").Build()).Error(); err != ErrCircuitOpen {
+				t.Fatalf("write commands should fail fast %v", err)
+			}
+			if v, err := client.Do(context.Background(), client.B().Get().Key("b").Build()).ToString(); err != nil || v != "127.0.0.1:0" {
+				t.Fatalf("other nodes should not be affected %v %v", v, err)
+			}
+			if n := len(sentTo("127.0.2.1:0")); n != 2 {
+				t.Fatalf("unexpected commands sent to the open node %v", sentTo("127.0.2.1:0"))
+			}
+			want := append(append([]string{}, c.readonly...), "GET")
+			want = append(want, want...)
+			if got := sentTo("127.0.3.1:1"); strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Fatalf("unexpected commands sent to the replica %v", got)
+			}
+		})
+	}
+
+	t.Run("DoMulti", func(t *testing.T) {
+		client := setup(t, slotsMultiResp, "127.0.2.1:0", nil)
+		defer client.Close()
+		for i := 0; i < 2; i++ { // each DoMulti to a node counts as one error
+			for _, resp := range client.DoMulti(context.Background(), client.B().Get().Key("a").Build(), client.B().Get().Key("a").Build()) {
+				if err := resp.Error(); err == nil || err.Error() != "timeout" {
+					t.Fatalf("unexpected err %v", err)
+				}
+			}
+		}
+		if err := client.Do(context.Background(), client.B().Set().Key("a").Value("v").Build()).Error(); err != ErrCircuitOpen {
+			t.Fatalf("the circuit should be opened by the DoMulti %v", err)
+		}
+		resps := client.DoMulti(context.Background(), client.B().Get().Key("a").Build())
+		if v, err := resps[0].ToString(); err != nil || v != "127.0.3.1:1" {
+			t.Fatalf("read-only commands should be sent to the replica %v %v", v, err)
+		}
+		resps = client.DoMulti(context.Background(),
+			client.B().Get().Key("a").Build(),
+			client.B().Get().Key("b").Build(),
+			client.B().Set().Key("a").Value("v").Build(),
+		)
+		if v, err := resps[0].ToString(); err != nil || v != "127.0.3.1:1" {
+			t.Fatalf("read-only commands should be sent to the replica %v %v", v, err)
+		}
+		if v, err := resps[1].ToString(); err != nil || v != "127.0.0.1:0" {
+			t.Fatalf("other nodes should not be affected %v %v", v, err)
+		}
+		if err := resps[2].Error(); err != ErrCircuitOpen {
+			t.Fatalf("write commands should fail fast %v", err)
+		}
+		if n := len(sentTo("127.0.2.1:0")); n != 4 {
+			t.Fatalf("unexpected commands sent to the open node %v", sentTo("127.0.2.1:0"))
+		}
+		if got := strings.Join(sentTo("127.0.3.1:1"), " "); got != "READONLY GET READONLY GET" {
+			t.Fatalf("unexpected commands sent to the replica %v", got)
+		}
+	})
+
+	t.Run("DoMultiCache", func(t *testing.T) {
+		client := setup(t, slotsMultiResp, "127.0.2.1:0", nil)
+		defer client.Close()
+		for i := 0; i < 2; i++ {
+			client.Do(context.Background(), client.B().Get().Key("a").Build())
+		}
+		resps := client.DoMultiCache(context.Background(),
+			CT(client.B().Get().Key("a").Cache(), time.Second),
+			CT(client.B().Get().Key("b").Cache(), time.Second),
+		)
+		if v, err := resps[0].ToString(); err != nil || v != "127.0.3.1:1" {
+			t.Fatalf("read-only commands should be sent to the replica %v %v", v, err)
+		}
+		if v, err := resps[1].ToString(); err != nil || v != "127.0.0.1:0" {
+			t.Fatalf("other nodes should not be affected %v %v", v, err)
+		}
+		if got := strings.Join(sentTo("127.0.3.1:1"), " "); got != "READONLY GET" {
+			t.Fatalf("unexpected commands sent to the replica %v", got)
+		}
+	})
+
+	t.Run("Receive", func(t *testing.T) {
+		client := setup(t, slotsMultiResp, "127.0.2.1:0", nil)
+		defer client.Close()
+		for i := 0; i < 2; i++ {
+			if err := client.Receive(context.Background(), client.B().Ssubscribe().Channel("a").Build(), func(msg PubSubMessage) {}); err == nil || err.Error() != "timeout" {
+				t.Fatalf("unexpected err %v", err)
+			}
+		}
+		if err := client.Receive(context.Background(), client.B().Ssubscribe().Channel("a").Build(), func(msg PubSubMessage) {}); err != ErrCircuitOpen {
+			t.Fatalf("subscriptions should fail fast %v", err)
+		}
+	})
+
+	t.Run("Half Open", func(t *testing.T) {
+		client := setup(t, slotsMultiRespWithoutReplicas, "127.0.1.1:0", nil)
+		defer client.Close()
+		now := time.Now()
+		client.breakers.now = func() time.Time { return now }
+		for i := 0; i < 2; i++ {
+			client.Do(context.Background(), client.B().Get().Key("a").Build())
+		}
+		if err := client.Do(context.Background(), client.B().Get().Key("a").Build()).Error(); err != ErrCircuitOpen {
+			t.Fatalf("read-only commands should fail fast without replicas %v", err)
+		}
+		atomic.StoreInt32(&failing, 0)
+		now = now.Add(time.Hour)
+		if v, err := client.Do(context.Background(), client.B().Get().Key("a").Build()).ToString(); err != nil || v != "127.0.1.1:0" {
+			t.Fatalf("the probe should be sent to the node %v %v", v, err)
+		}
+		if v, err := client.Do(context.Background(), client.B().Set().Key("a").Value("v").Build()).ToString(); err != nil || v != "127.0.1.1:0" {
+			t.Fatalf("circuit should be closed after the probe %v %v", v, err)
+		}
+	})
+}
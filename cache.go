@@ -178,3 +178,23 @@ func (a *adapterEntry) Wait(ctx context.Context) (RedisMessage, error) {
 		return a.val, a.err
 	}
 }
+
+type cacheFlusher interface {
+	flushCache(keys []string)
+}
+
+// FlushCache deletes all the client side cached responses of the client on every connection without reconnecting,
+// for example, after the data are changed out-of-band. Pending DoCache requests are not affected.
+// It does nothing if the client is not created by the NewClient, such as a mock or a wrapped client.
+func FlushCache(client Client) {
+	if c, ok := client.(cacheFlusher); ok {
+		c.flushCache(nil)
+	}
+}
+
+// FlushCacheKeys is like FlushCache, but only deletes the client side cached responses of the keys.
+func FlushCacheKeys(client Client, keys ...string) {
+	if c, ok := client.(cacheFlusher); ok && len(keys) != 0 {
+		c.flushCache(keys)
+	}
+}
@@ -204,3 +204,22 @@ func (s *simple) Del(key string) {
 func (s *simple) Flush() {
 	s.store = nil
 }
+
+func TestFlushCache(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var flushed [][]string
+	m := &mockConn{FlushCacheFn: func(keys []string) { flushed = append(flushed, keys) }}
+	client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+		return m
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	FlushCache(client)
+	FlushCacheKeys(client, "a", "b")
+	FlushCacheKeys(client)
+	FlushCache(struct{ Client }{client})
+	if len(flushed) != 2 || flushed[0] != nil || len(flushed[1]) != 2 || flushed[1][0] != "a" || flushed[1][1] != "b" {
+		t.Fatalf("unexpected flushed %v", flushed)
+	}
+}
@@ -3,6 +3,7 @@ package rueidis
 import (
 	"context"
 	"io"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -10,10 +11,12 @@ import (
 )
 
 type singleClient struct {
-	conn  conn
-	stop  uint32
-	cmd   Builder
-	retry bool
+	conn   conn
+	policy RetryPolicy
+	stop   uint32
+	cmd    Builder
+	retry  bool
+	lazy   bool
 }
 
 func newSingleClient(opt *ClientOption, prev conn, connFn connFn) (*singleClient, error) {
@@ -30,11 +33,12 @@ func newSingleClient(opt *ClientOption, prev conn, connFn connFn) (*singleClient
 	if err := conn.Dial(); err != nil {
 		return nil, err
 	}
-	return newSingleClientWithConn(conn, cmds.NewBuilder(cmds.NoSlot), !opt.DisableRetry), nil
+	return newSingleClientWithConn(conn, cmds.NewBuilder(cmds.NoSlot), !opt.DisableRetry, opt.RetryPolicy, opt.MaxConns > 0), nil
 }
 
-func newSingleClientWithConn(conn conn, builder Builder, retry bool) *singleClient {
-	return &singleClient{cmd: builder, conn: conn, retry: retry}
+// newSingleClientWithConn makes a singleClient of the conn, whose dedicated clients take their wires lazily if the lazy is set for the MaxConns.
+func newSingleClientWithConn(conn conn, builder Builder, retry bool, policy RetryPolicy, lazy bool) *singleClient {
+	return &singleClient{cmd: builder, conn: conn, retry: retry, policy: policy, lazy: lazy}
 }
 
 func (c *singleClient) B() Builder {
@@ -42,9 +46,11 @@ func (c *singleClient) B() Builder {
 }
 
 func (c *singleClient) Do(ctx context.Context, cmd Completed) (resp RedisResult) {
+	attempts := 1
 retry:
 	resp = c.conn.Do(ctx, cmd)
-	if c.retry && cmd.IsReadOnly() && c.isRetryable(resp.NonRedisError(), ctx) {
+	if c.retry && c.isRetryable(resp.NonRedisError(), ctx) && retryable(c.policy, ctx, cmd, attempts, resp.NonRedisError()) {
+		attempts++
 		goto retry
 	}
 	if resp.NonRedisError() == nil { // not recycle cmds if error, since cmds may be used later in pipe. consider recycle them by pipe
@@ -74,12 +80,17 @@ func (c *singleClient) DoMulti(ctx context.Context, multi ...Completed) (resps [
 	if len(multi) == 0 {
 		return nil
 	}
+	attempts := 1
 retry:
 	resps = c.conn.DoMulti(ctx, multi...).s
-	if c.retry && allReadOnly(multi) {
+	if c.retry {
 		for _, resp := range resps {
-			if c.isRetryable(resp.NonRedisError(), ctx) {
-				goto retry
+			if err := resp.NonRedisError(); c.isRetryable(err, ctx) {
+				if retryableMulti(c.policy, ctx, multi, attempts, err) {
+					attempts++
+					goto retry
+				}
+				break
 			}
 		}
 	}
@@ -95,12 +106,17 @@ func (c *singleClient) DoMultiCache(ctx context.Context, multi ...CacheableTTL)
 	if len(multi) == 0 {
 		return nil
 	}
+	attempts := 1
 retry:
 	resps = c.conn.DoMultiCache(ctx, multi...).s
 	if c.retry {
 		for _, resp := range resps {
-			if c.isRetryable(resp.NonRedisError(), ctx) {
-				goto retry
+			if err := resp.NonRedisError(); c.isRetryable(err, ctx) {
+				if retryableMultiCache(c.policy, ctx, multi, attempts, err) {
+					attempts++
+					goto retry
+				}
+				break
 			}
 		}
 	}
@@ -113,9 +129,11 @@ retry:
 }
 
 func (c *singleClient) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) (resp RedisResult) {
+	attempts := 1
 retry:
 	resp = c.conn.DoCache(ctx, cmd, ttl)
-	if c.retry && c.isRetryable(resp.NonRedisError(), ctx) {
+	if c.retry && c.isRetryable(resp.NonRedisError(), ctx) && retryableCache(c.policy, ctx, cmd, attempts, resp.NonRedisError()) {
+		attempts++
 		goto retry
 	}
 	if err := resp.NonRedisError(); err == nil || err == ErrDoCacheAborted {
@@ -139,46 +157,106 @@ retry:
 }
 
 func (c *singleClient) Dedicated(fn func(DedicatedClient) error) (err error) {
-	wire := c.conn.Acquire()
-	dsc := &dedicatedSingleClient{cmd: c.cmd, conn: c.conn, wire: wire, retry: c.retry}
+	dsc := newDedicatedSingleClient(c.cmd, c.conn, c.retry, c.lazy)
 	err = fn(dsc)
 	dsc.release()
 	return err
 }
 
 func (c *singleClient) Dedicate() (DedicatedClient, func()) {
-	wire := c.conn.Acquire()
-	dsc := &dedicatedSingleClient{cmd: c.cmd, conn: c.conn, wire: wire, retry: c.retry}
+	dsc := newDedicatedSingleClient(c.cmd, c.conn, c.retry, c.lazy)
 	return dsc, dsc.release
 }
 
+func (c *singleClient) withConn(fn func(c CoreClient) error) error {
+	return withPinned(c.cmd, func(ctx context.Context, slot uint16) (conn, error) { return c.conn, nil }, fn)
+}
+
 func (c *singleClient) Nodes() map[string]Client {
 	return map[string]Client{c.conn.Addr(): c}
 }
 
+func (c *singleClient) connStats() []ConnStat {
+	return []ConnStat{c.conn.Stats()}
+}
+
+func (c *singleClient) serverVersion() string {
+	return c.conn.Info()["version"].string
+}
+
+func (c *singleClient) flushCache(keys []string) {
+	c.conn.FlushCache(keys)
+}
+
 func (c *singleClient) Close() {
 	atomic.StoreUint32(&c.stop, 1)
 	c.conn.Close()
 }
 
 type dedicatedSingleClient struct {
-	conn conn
-	wire wire
-	mark uint32
-	cmd  Builder
+	conn  conn
+	wire  wire
+	pshks *pshks
 
+	mu    sync.Mutex
+	cmd   Builder
+	mark  bool
 	retry bool
 }
 
+// newDedicatedSingleClient takes the wire of the conn eagerly, unless the lazy is set for the MaxConns.
+// In that case, the wire is taken by the first command instead, so that it waits for the MaxConns until the ctx of the command is done.
+func newDedicatedSingleClient(cmd Builder, conn conn, retry, lazy bool) *dedicatedSingleClient {
+	dsc := &dedicatedSingleClient{cmd: cmd, conn: conn, retry: retry}
+	if !lazy {
+		dsc.wire, _ = conn.Acquire(context.Background())
+	}
+	return dsc
+}
+
+func (c *dedicatedSingleClient) acquire(ctx context.Context) (wire wire, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mark {
+		panic(dedicatedClientUsedAfterReleased)
+	}
+	if c.wire != nil {
+		return c.wire, nil
+	}
+	if wire, err = c.conn.Acquire(ctx); err != nil {
+		if p := c.pshks; p != nil {
+			c.pshks = nil
+			p.close <- err
+			close(p.close)
+		}
+		return nil, err
+	}
+	c.wire = wire
+	if p := c.pshks; p != nil {
+		c.pshks = nil
+		ch := c.wire.SetPubSubHooks(p.hooks)
+		go func(ch <-chan error) {
+			for e := range ch {
+				p.close <- e
+			}
+			close(p.close)
+		}(ch)
+	}
+	return c.wire, nil
+}
+
 func (c *dedicatedSingleClient) B() Builder {
 	return c.cmd
 }
 
 func (c *dedicatedSingleClient) Do(ctx context.Context, cmd Completed) (resp RedisResult) {
 retry:
-	c.check()
-	resp = c.wire.Do(ctx, cmd)
-	if c.retry && cmd.IsReadOnly() && isRetryable(resp.NonRedisError(), c.wire, ctx) {
+	w, err := c.acquire(ctx)
+	if err != nil {
+		return newErrResult(err)
+	}
+	resp = w.Do(ctx, cmd)
+	if c.retry && cmd.IsReadOnly() && isRetryable(resp.NonRedisError(), w, ctx) {
 		goto retry
 	}
 	if resp.NonRedisError() == nil {
@@ -196,9 +274,16 @@ func (c *dedicatedSingleClient) DoMulti(ctx context.Context, multi ...Completed)
 		retryable = allReadOnly(multi)
 	}
 retry:
-	c.check()
-	resp = c.wire.DoMulti(ctx, multi...).s
-	if retryable && anyRetryable(resp, c.wire, ctx) {
+	w, err := c.acquire(ctx)
+	if err != nil {
+		resp = make([]RedisResult, len(multi))
+		for i := range resp {
+			resp[i] = newErrResult(err)
+		}
+		return resp
+	}
+	resp = w.DoMulti(ctx, multi...).s
+	if retryable && anyRetryable(resp, w, ctx) {
 		goto retry
 	}
 	for i, cmd := range multi {
@@ -211,10 +296,13 @@ retry:
 
 func (c *dedicatedSingleClient) Receive(ctx context.Context, subscribe Completed, fn func(msg PubSubMessage)) (err error) {
 retry:
-	c.check()
-	err = c.wire.Receive(ctx, subscribe, fn)
+	w, err := c.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	err = w.Receive(ctx, subscribe, fn)
 	if c.retry {
-		if _, ok := err.(*RedisError); !ok && isRetryable(err, c.wire, ctx) {
+		if _, ok := err.(*RedisError); !ok && isRetryable(err, w, ctx) {
 			goto retry
 		}
 	}
@@ -225,25 +313,53 @@ retry:
 }
 
 func (c *dedicatedSingleClient) SetPubSubHooks(hooks PubSubHooks) <-chan error {
-	c.check()
-	return c.wire.SetPubSubHooks(hooks)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mark {
+		panic(dedicatedClientUsedAfterReleased)
+	}
+	if p := c.pshks; p != nil {
+		c.pshks = nil
+		close(p.close)
+	}
+	if c.wire != nil {
+		return c.wire.SetPubSubHooks(hooks)
+	}
+	if hooks.isZero() {
+		return nil
+	}
+	ch := make(chan error, 1)
+	c.pshks = &pshks{hooks: hooks, close: ch}
+	return ch
 }
 
 func (c *dedicatedSingleClient) Close() {
-	c.wire.Close()
-	c.release()
-}
-
-func (c *dedicatedSingleClient) check() {
-	if atomic.LoadUint32(&c.mark) != 0 {
-		panic(dedicatedClientUsedAfterReleased)
+	c.mu.Lock()
+	if p := c.pshks; p != nil {
+		c.pshks = nil
+		p.close <- ErrClosing
+		close(p.close)
+	}
+	if c.wire != nil {
+		c.wire.Close()
 	}
+	c.mu.Unlock()
+	c.release()
 }
 
 func (c *dedicatedSingleClient) release() {
-	if atomic.CompareAndSwapUint32(&c.mark, 0, 1) {
-		c.conn.Store(c.wire)
+	c.mu.Lock()
+	if !c.mark {
+		if p := c.pshks; p != nil {
+			c.pshks = nil
+			close(p.close)
+		}
+		if c.wire != nil {
+			c.conn.Store(c.wire)
+		}
 	}
+	c.mark = true
+	c.mu.Unlock()
 }
 
 func (c *singleClient) isRetryable(err error, ctx context.Context) bool {
@@ -27,8 +27,11 @@ type mockConn struct {
 	DialFn          func() error
 	AcquireFn       func() wire
 	StoreFn         func(w wire)
+	PinFn           func() wire
 	OverrideFn      func(c conn)
 	AddrFn          func() string
+	StatsFn         func() ConnStat
+	FlushCacheFn    func(keys []string)
 
 	DoOverride      map[string]func(cmd Completed) RedisResult
 	DoCacheOverride map[string]func(cmd Cacheable, ttl time.Duration) RedisResult
@@ -48,9 +51,16 @@ func (m *mockConn) Dial() error {
 	return nil
 }
 
-func (m *mockConn) Acquire() wire {
+func (m *mockConn) Acquire(ctx context.Context) (wire, error) {
 	if m.AcquireFn != nil {
-		return m.AcquireFn()
+		return m.AcquireFn(), nil
+	}
+	return nil, nil
+}
+
+func (m *mockConn) Pin() wire {
+	if m.PinFn != nil {
+		return m.PinFn()
 	}
 	return nil
 }
@@ -183,6 +193,19 @@ func (m *mockConn) Addr() string {
 	return ""
 }
 
+func (m *mockConn) Stats() ConnStat {
+	if m.StatsFn != nil {
+		return m.StatsFn()
+	}
+	return ConnStat{}
+}
+
+func (m *mockConn) FlushCache(keys []string) {
+	if m.FlushCacheFn != nil {
+		m.FlushCacheFn(keys)
+	}
+}
+
 func TestNewSingleClientNoNode(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	if _, err := newSingleClient(&ClientOption{}, nil, func(dst string, opt *ClientOption) conn {
@@ -548,6 +571,22 @@ func TestSingleClient(t *testing.T) {
 		}
 	})
 
+	t.Run("Dedicate Lazy With MaxConns", func(t *testing.T) {
+		acquired := 0
+		w := &mockWire{}
+		m.AcquireFn = func() wire { acquired++; return w }
+		c, cancel := newSingleClientWithConn(m, client.cmd, true, nil, true).Dedicate()
+		if acquired != 0 {
+			t.Fatalf("unexpected acquired count %v", acquired)
+		}
+		c.Do(context.Background(), c.B().Get().Key("a").Build())
+		c.Do(context.Background(), c.B().Get().Key("a").Build())
+		cancel()
+		if acquired != 1 {
+			t.Fatalf("unexpected acquired count %v", acquired)
+		}
+	})
+
 	t.Run("Dedicate panic after released", func(t *testing.T) {
 		m.AcquireFn = func() wire { return &mockWire{} }
 		check := func() {
@@ -589,6 +628,19 @@ func TestSingleClient(t *testing.T) {
 	})
 }
 
+func TestSingleClientRetryPolicy(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	SetupClientRetryPolicy(t, func(m *mockConn, policy RetryPolicy) Client {
+		c, err := newSingleClient(&ClientOption{InitAddress: []string{""}, RetryPolicy: policy}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return c
+	})
+}
+
 func TestSingleClientRetry(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	SetupClientRetry(t, func(m *mockConn) Client {
@@ -602,6 +654,120 @@ func TestSingleClientRetry(t *testing.T) {
 	})
 }
 
+//gocyclo:ignore
+func SetupClientRetryPolicy(t *testing.T, fn func(mock *mockConn, policy RetryPolicy) Client) {
+	var attempts []int
+	setup := func(delay time.Duration, ok bool) (Client, *mockConn) {
+		attempts = nil
+		m := &mockConn{}
+		return fn(m, func(cmd Completed, n int, err error) (time.Duration, bool) {
+			if err != ErrClosing {
+				t.Fatalf("unexpected err %v", err)
+			}
+			attempts = append(attempts, n)
+			return delay, ok
+		}), m
+	}
+	errThenOK := func() func() RedisResult {
+		count := 0
+		return func() RedisResult {
+			if count++; count == 1 {
+				return newErrResult(ErrClosing)
+			}
+			return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+		}
+	}
+
+	t.Run("Do Write Retry", func(t *testing.T) {
+		c, m := setup(time.Millisecond, true)
+		next := errThenOK()
+		m.DoFn = func(cmd Completed) RedisResult { return next() }
+		if v, err := c.Do(context.Background(), c.B().Set().Key("Do").Value("V").Build()).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if len(attempts) != 1 || attempts[0] != 1 {
+			t.Fatalf("unexpected attempts %v", attempts)
+		}
+	})
+
+	t.Run("Do ReadOnly NoRetry", func(t *testing.T) {
+		c, m := setup(0, false)
+		next := errThenOK()
+		m.DoFn = func(cmd Completed) RedisResult { return next() }
+		if v, err := c.Do(context.Background(), c.B().Get().Key("Do").Build()).ToString(); err != ErrClosing {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("Do NoRetry - ctx done during delay", func(t *testing.T) {
+		c, m := setup(time.Second, true)
+		next := errThenOK()
+		m.DoFn = func(cmd Completed) RedisResult { return next() }
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		if v, err := c.Do(ctx, c.B().Get().Key("Do").Build()).ToString(); err != ErrClosing {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("DoMulti Write Retry", func(t *testing.T) {
+		c, m := setup(time.Millisecond, true)
+		next := errThenOK()
+		m.DoMultiFn = func(multi ...Completed) *redisresults { return &redisresults{s: []RedisResult{next()}} }
+		if v, err := c.DoMulti(context.Background(), c.B().Set().Key("Do").Value("V").Build())[0].ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if len(attempts) != 1 || attempts[0] != 1 {
+			t.Fatalf("unexpected attempts %v", attempts)
+		}
+	})
+
+	t.Run("DoMulti ReadOnly NoRetry", func(t *testing.T) {
+		c, m := setup(0, false)
+		next := errThenOK()
+		m.DoMultiFn = func(multi ...Completed) *redisresults { return &redisresults{s: []RedisResult{next()}} }
+		if v, err := c.DoMulti(context.Background(), c.B().Get().Key("Do").Build())[0].ToString(); err != ErrClosing {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("DoCache Retry", func(t *testing.T) {
+		c, m := setup(time.Millisecond, true)
+		next := errThenOK()
+		m.DoCacheFn = func(cmd Cacheable, ttl time.Duration) RedisResult { return next() }
+		if v, err := c.DoCache(context.Background(), c.B().Get().Key("Do").Cache(), 0).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("DoCache NoRetry", func(t *testing.T) {
+		c, m := setup(0, false)
+		next := errThenOK()
+		m.DoCacheFn = func(cmd Cacheable, ttl time.Duration) RedisResult { return next() }
+		if v, err := c.DoCache(context.Background(), c.B().Get().Key("Do").Cache(), 0).ToString(); err != ErrClosing {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("DoMultiCache Retry", func(t *testing.T) {
+		c, m := setup(time.Millisecond, true)
+		next := errThenOK()
+		m.DoMultiCacheFn = func(multi ...CacheableTTL) *redisresults { return &redisresults{s: []RedisResult{next()}} }
+		if v, err := c.DoMultiCache(context.Background(), CT(c.B().Get().Key("Do").Cache(), 0))[0].ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+
+	t.Run("DoMultiCache NoRetry", func(t *testing.T) {
+		c, m := setup(0, false)
+		next := errThenOK()
+		m.DoMultiCacheFn = func(multi ...CacheableTTL) *redisresults { return &redisresults{s: []RedisResult{next()}} }
+		if v, err := c.DoMultiCache(context.Background(), CT(c.B().Get().Key("Do").Cache(), 0))[0].ToString(); err != ErrClosing {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+}
+
 //gocyclo:ignore
 func SetupClientRetry(t *testing.T, fn func(mock *mockConn) Client) {
 	setup := func() (Client, *mockConn) {
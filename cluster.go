@@ -71,18 +71,21 @@ var retrycachep = util.NewPool(func(capacity int) *retrycache {
 })
 
 type clusterClient struct {
-	pslots [16384]conn
-	rslots []conn
-	opt    *ClientOption
-	rOpt   *ClientOption
-	conns  map[string]connrole
-	connFn connFn
-	sc     call
-	mu     sync.RWMutex
-	stop   uint32
-	cmd    Builder
-	retry  bool
-	aws    bool
+	pslots   [16384]conn
+	rslots   []conn
+	opt      *ClientOption
+	rOpt     *ClientOption
+	conns    map[string]connrole
+	shards   map[conn][]conn
+	replicas map[conn][]conn
+	breakers *breakers
+	connFn   connFn
+	sc       call
+	mu       sync.RWMutex
+	stop     uint32
+	cmd      Builder
+	retry    bool
+	aws      bool
 }
 
 // NOTE: connrole and conn must be initialized at the same time
@@ -93,12 +96,13 @@ type connrole struct {
 
 func newClusterClient(opt *ClientOption, connFn connFn) (*clusterClient, error) {
 	client := &clusterClient{
-		cmd:    cmds.NewBuilder(cmds.InitSlot),
-		connFn: connFn,
-		opt:    opt,
-		conns:  make(map[string]connrole),
-		retry:  !opt.DisableRetry,
-		aws:    len(opt.InitAddress) == 1 && strings.Contains(opt.InitAddress[0], "amazonaws.com"),
+		cmd:      cmds.NewBuilder(cmds.InitSlot),
+		connFn:   connFn,
+		opt:      opt,
+		conns:    make(map[string]connrole),
+		breakers: newBreakers(opt.CircuitBreaker),
+		retry:    !opt.DisableRetry,
+		aws:      len(opt.InitAddress) == 1 && strings.Contains(opt.InitAddress[0], "amazonaws.com"),
 	}
 
 	if opt.ReplicaOnly && opt.SendToReplicas != nil {
@@ -264,6 +268,31 @@ func (c *clusterClient) _refresh() (err error) {
 	}
 	c.mu.RUnlock()
 
+	var shards map[conn][]conn
+	if c.opt.ReplicaOnly || c.rOpt != nil { // replicas are READONLY only in these modes
+		shards = make(map[conn][]conn, len(conns))
+		for _, g := range groups {
+			shard := make([]conn, 0, len(g.nodes))
+			for _, addr := range g.nodes {
+				shard = append(shard, conns[addr].conn)
+			}
+			for _, cc := range shard {
+				shards[cc] = shard
+			}
+		}
+	}
+
+	replicas := make(map[conn][]conn, len(conns))
+	for _, g := range groups {
+		nodes := make([]conn, 0, len(g.nodes))
+		for _, addr := range g.nodes[1:] {
+			nodes = append(nodes, conns[addr].conn)
+		}
+		for _, addr := range g.nodes {
+			replicas[conns[addr].conn] = nodes
+		}
+	}
+
 	pslots := [16384]conn{}
 	var rslots []conn
 	for master, g := range groups {
@@ -307,6 +336,8 @@ func (c *clusterClient) _refresh() (err error) {
 	c.pslots = pslots
 	c.rslots = rslots
 	c.conns = conns
+	c.shards = shards
+	c.replicas = replicas
 	c.mu.Unlock()
 
 	if len(removes) > 0 {
@@ -316,6 +347,11 @@ func (c *clusterClient) _refresh() (err error) {
 				cc.Close()
 			}
 		}(removes)
+		if c.breakers != nil {
+			for _, cc := range removes {
+				c.breakers.remove(cc)
+			}
+		}
 	}
 
 	return nil
@@ -401,6 +437,8 @@ func parseShards(shards RedisMessage, defaultAddr string, tls bool) map[string]g
 			if dst := parseEndpoint(defaultAddr, dict["endpoint"].string, port); dst != "" {
 				if dict["role"].string == "master" {
 					m = len(g.nodes)
+				} else if health := dict["health"].string; health != "" && health != "online" {
+					continue // skip failed or loading replicas, so that replica reads fall back to the master if none is healthy.
 				}
 				g.nodes = append(g.nodes, dst)
 			}
@@ -483,12 +521,25 @@ func (c *clusterClient) Do(ctx context.Context, cmd Completed) (resp RedisResult
 }
 
 func (c *clusterClient) do(ctx context.Context, cmd Completed) (resp RedisResult) {
+	attempts := 1
 retry:
 	cc, err := c.pick(ctx, cmd.Slot(), c.toReplica(cmd))
 	if err != nil {
 		return newErrResult(err)
 	}
-	resp = cc.Do(ctx, cmd)
+	if c.breakers != nil {
+		if cc, err = c.circuit(cc, cmd.Slot(), cmd.IsReadOnly()); err != nil {
+			return newErrResult(err)
+		}
+		if c.needsReadOnly(cc) {
+			resp = c.doReadOnly(ctx, cc, cmd)
+		} else {
+			resp = cc.Do(ctx, cmd)
+		}
+		c.breakers.record(ctx, cc, resp.NonRedisError())
+	} else {
+		resp = cc.Do(ctx, cmd)
+	}
 process:
 	switch addr, mode := c.shouldRefreshRetry(resp.Error(), ctx); mode {
 	case RedirectMove:
@@ -500,7 +551,8 @@ process:
 		resultsp.Put(results)
 		goto process
 	case RedirectRetry:
-		if c.retry && cmd.IsReadOnly() {
+		if c.retry && retryable(c.opt.RetryPolicy, ctx, cmd, attempts, resp.Error()) {
+			attempts++
 			runtime.Gosched()
 			goto retry
 		}
@@ -508,6 +560,152 @@ process:
 	return resp
 }
 
+// hedge sends the cmd to a node serving the slot of the cmd other than the one picked by do, if there is any.
+// In the default mode, it is a replica of the master picked by do, which needs the READONLY first.
+func (c *clusterClient) hedge(ctx context.Context, cmd Completed) (resp RedisResult) {
+	cc, err := c.pick(ctx, cmd.Slot(), c.toReplica(cmd))
+	if err != nil {
+		return newErrResult(err)
+	}
+	readonly := false
+	c.mu.RLock()
+	if shard := c.shards[cc]; len(shard) > 1 {
+		for {
+			if n := shard[util.FastRand(len(shard))]; n != cc {
+				cc = n
+				break
+			}
+		}
+	} else if replicas := c.replicas[cc]; len(replicas) > 0 { // the default mode, where cc is the master
+		cc, readonly = replicas[util.FastRand(len(replicas))], true
+	}
+	c.mu.RUnlock()
+	if readonly {
+		resp = c.doReadOnly(ctx, cc, cmd)
+	} else {
+		resp = cc.Do(ctx, cmd)
+	}
+	if resp.NonRedisError() == nil {
+		if _, mode := c.shouldRefreshRetry(resp.Error(), ctx); mode != RedirectNone {
+			return c.do(ctx, cmd)
+		}
+	}
+	return resp
+}
+
+// doReplica sends the cmd to the replica of the given index of the shard serving the slot of the cmd.
+func (c *clusterClient) doReplica(ctx context.Context, cmd Completed, replica int) RedisResult {
+	cc, err := c.pick(ctx, cmd.Slot(), false)
+	if err != nil {
+		return newErrResult(err)
+	}
+	c.mu.RLock()
+	replicas := c.replicas[cc]
+	c.mu.RUnlock()
+	if replica < 0 || replica >= len(replicas) {
+		return newErrResult(ErrNoReplica)
+	}
+	return c.doReadOnly(ctx, replicas[replica], cmd)
+}
+
+// doReadOnly sends the cmd to the replica, with the READONLY first unless the replica is READONLY already.
+func (c *clusterClient) doReadOnly(ctx context.Context, replica conn, cmd Completed) RedisResult {
+	if c.opt.ReplicaOnly || c.rOpt != nil { // replicas are READONLY already in these modes
+		return replica.Do(ctx, cmd)
+	}
+	results := replica.DoMulti(ctx, cmds.ReadOnlyCmd, cmd)
+	resp := results.s[1]
+	resultsp.Put(results)
+	return resp
+}
+
+// circuit returns the cc if its circuit is not open. Otherwise, it returns another node serving the slot
+// for read-only commands, or the ErrCircuitOpen if there is none.
+func (c *clusterClient) circuit(cc conn, slot uint16, readonly bool) (conn, error) {
+	if c.breakers.allow(cc) {
+		return cc, nil
+	}
+	if alt := c.alternative(cc, slot, readonly); alt != nil {
+		return alt, nil
+	}
+	return nil, ErrCircuitOpen
+}
+
+// alternative returns a node other than the cc serving the slot whose circuit is not open for read-only commands,
+// trying the replica of the SendToReplicas, the replicas of the shard, and then the node of the pslots. It returns nil if there is none.
+func (c *clusterClient) alternative(cc conn, slot uint16, readonly bool) conn {
+	if !readonly || slot == cmds.InitSlot {
+		return nil
+	}
+	c.mu.RLock()
+	p := c.pslots[slot]
+	alts := make([]conn, 0, len(c.replicas[p])+2)
+	if c.rslots != nil {
+		alts = append(alts, c.rslots[slot])
+	}
+	alts = append(alts, c.replicas[p]...)
+	alts = append(alts, p)
+	c.mu.RUnlock()
+	for _, alt := range alts {
+		if alt != nil && alt != cc && c.breakers.allow(alt) {
+			return alt
+		}
+	}
+	return nil
+}
+
+// needsReadOnly reports whether the cc is a replica that is not READONLY, which is only in the default mode.
+func (c *clusterClient) needsReadOnly(cc conn) bool {
+	if c.opt.ReplicaOnly || c.rOpt != nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range c.replicas[cc] {
+		if r == cc {
+			return true
+		}
+	}
+	return false
+}
+
+// domulti sends the multi to the cc like cc.DoMulti. With the CircuitBreaker, the READONLY is sent first
+// if the cc is a replica as the fallback, and the result is recorded to the breaker of the cc.
+func (c *clusterClient) domulti(ctx context.Context, cc conn, multi []Completed) (resps *redisresults) {
+	if c.breakers == nil {
+		return cc.DoMulti(ctx, multi...)
+	}
+	if c.needsReadOnly(cc) {
+		resps = readOnlyMulti(cc, ctx, multi)
+	} else {
+		resps = cc.DoMulti(ctx, multi...)
+	}
+	c.breakers.record(ctx, cc, nonRedisError(resps.s))
+	return resps
+}
+
+// divert queues the read-only commands of the cc with an open circuit to other nodes serving their slots
+// for the next attempt, and fails the others with the ErrCircuitOpen.
+func (c *clusterClient) divert(results *redisresults, retries *connretry, mu *sync.Mutex, cc conn, cIndexes []int, commands []Completed) {
+	for i, cm := range commands {
+		ii := cIndexes[i]
+		results.s[ii] = newErrResult(ErrCircuitOpen)
+		nc := c.alternative(cc, cm.Slot(), cm.IsReadOnly())
+		if nc == nil {
+			continue
+		}
+		mu.Lock()
+		nr := retries.m[nc]
+		if nr == nil {
+			nr = retryp.Get(0, len(commands))
+			retries.m[nc] = nr
+		}
+		nr.cIndexes = append(nr.cIndexes, ii)
+		nr.commands = append(nr.commands, cm)
+		mu.Unlock()
+	}
+}
+
 func (c *clusterClient) toReplica(cmd Completed) bool {
 	if c.opt.SendToReplicas != nil {
 		return c.opt.SendToReplicas(cmd)
@@ -624,8 +822,17 @@ func (c *clusterClient) doresultfn(ctx context.Context, results *redisresults, r
 		if mode != RedirectNone {
 			nc := cc
 			if mode == RedirectRetry {
-				if !c.retry || !cm.IsReadOnly() {
+				if !c.retry {
+					continue
+				}
+				if policy := c.opt.RetryPolicy; policy == nil {
+					if !cm.IsReadOnly() {
+						continue
+					}
+				} else if delay, ok := policy(cm, retries.attempts, resp.Error()); !ok {
 					continue
+				} else {
+					retries.delay(mu, delay)
 				}
 			} else {
 				nc = c.redirectOrNew(addr, cc)
@@ -650,9 +857,13 @@ func (c *clusterClient) doresultfn(ctx context.Context, results *redisresults, r
 
 func (c *clusterClient) doretry(ctx context.Context, cc conn, results *redisresults, retries *connretry, re *retry, mu *sync.Mutex, wg *sync.WaitGroup) {
 	if len(re.commands) != 0 {
-		resps := cc.DoMulti(ctx, re.commands...)
-		c.doresultfn(ctx, results, retries, mu, cc, re.cIndexes, re.commands, resps.s)
-		resultsp.Put(resps)
+		if c.breakers != nil && !c.breakers.allow(cc) {
+			c.divert(results, retries, mu, cc, re.cIndexes, re.commands)
+		} else {
+			resps := c.domulti(ctx, cc, re.commands)
+			c.doresultfn(ctx, results, retries, mu, cc, re.cIndexes, re.commands, resps.s)
+			resultsp.Put(resps)
+		}
 	}
 	if len(re.cAskings) != 0 {
 		resps := askingMulti(cc, ctx, re.cAskings)
@@ -699,7 +910,12 @@ retry:
 	wg.Wait()
 
 	if len(retries.m) != 0 {
-		goto retry
+		if retries.wait(ctx) {
+			goto retry
+		}
+		for _, re := range retries.m {
+			retryp.Put(re)
+		}
 	}
 
 	for i, cmd := range multi {
@@ -710,6 +926,16 @@ retry:
 	return results.s
 }
 
+// nonRedisError returns the first non redis error of the resps, such as timeouts and connection errors.
+func nonRedisError(resps []RedisResult) error {
+	for _, resp := range resps {
+		if err := resp.NonRedisError(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func fillErrs(n int, err error) (results []RedisResult) {
 	results = resultsp.Get(n, n).s
 	for i := range results {
@@ -719,12 +945,18 @@ func fillErrs(n int, err error) (results []RedisResult) {
 }
 
 func (c *clusterClient) doMulti(ctx context.Context, slot uint16, multi []Completed, toReplica bool) []RedisResult {
+	attempts := 1
 retry:
 	cc, err := c.pick(ctx, slot, toReplica)
 	if err != nil {
 		return fillErrs(len(multi), err)
 	}
-	resps := cc.DoMulti(ctx, multi...)
+	if c.breakers != nil {
+		if cc, err = c.circuit(cc, slot, allReadOnly(multi)); err != nil {
+			return fillErrs(len(multi), err)
+		}
+	}
+	resps := c.domulti(ctx, cc, multi)
 process:
 	for _, resp := range resps.s {
 		switch addr, mode := c.shouldRefreshRetry(resp.Error(), ctx); mode {
@@ -741,8 +973,9 @@ process:
 				goto process
 			}
 		case RedirectRetry:
-			if c.retry && allReadOnly(multi) {
+			if c.retry && retryableMulti(c.opt.RetryPolicy, ctx, multi, attempts, resp.Error()) {
 				resultsp.Put(resps)
+				attempts++
 				runtime.Gosched()
 				goto retry
 			}
@@ -752,12 +985,25 @@ process:
 }
 
 func (c *clusterClient) doCache(ctx context.Context, cmd Cacheable, ttl time.Duration) (resp RedisResult) {
+	attempts := 1
 retry:
 	cc, err := c.pick(ctx, cmd.Slot(), c.toReplica(Completed(cmd)))
 	if err != nil {
 		return newErrResult(err)
 	}
-	resp = cc.DoCache(ctx, cmd, ttl)
+	if c.breakers != nil {
+		if cc, err = c.circuit(cc, cmd.Slot(), true); err != nil {
+			return newErrResult(err)
+		}
+		if c.needsReadOnly(cc) { // not cached, since the replica is only a fallback in the default mode
+			resp = c.doReadOnly(ctx, cc, Completed(cmd))
+		} else {
+			resp = cc.DoCache(ctx, cmd, ttl)
+		}
+		c.breakers.record(ctx, cc, resp.NonRedisError())
+	} else {
+		resp = cc.DoCache(ctx, cmd, ttl)
+	}
 process:
 	switch addr, mode := c.shouldRefreshRetry(resp.Error(), ctx); mode {
 	case RedirectMove:
@@ -769,7 +1015,8 @@ process:
 		resultsp.Put(results)
 		goto process
 	case RedirectRetry:
-		if c.retry {
+		if c.retry && retryableCache(c.opt.RetryPolicy, ctx, cmd, attempts, resp.Error()) {
+			attempts++
 			runtime.Gosched()
 			goto retry
 		}
@@ -785,6 +1032,17 @@ func (c *clusterClient) DoCache(ctx context.Context, cmd Cacheable, ttl time.Dur
 	return resp
 }
 
+func readOnlyMulti(cc conn, ctx context.Context, multi []Completed) *redisresults {
+	commands := make([]Completed, 0, len(multi)+1)
+	commands = append(commands, cmds.ReadOnlyCmd)
+	commands = append(commands, multi...)
+	results := resultsp.Get(0, len(multi))
+	resps := cc.DoMulti(ctx, commands...)
+	results.s = append(results.s, resps.s[1:]...)
+	resultsp.Put(resps)
+	return results
+}
+
 func askingMulti(cc conn, ctx context.Context, multi []Completed) *redisresults {
 	commands := make([]Completed, 0, len(multi)*2)
 	for _, cmd := range multi {
@@ -907,6 +1165,13 @@ func (c *clusterClient) resultcachefn(ctx context.Context, results *redisresults
 				if !c.retry {
 					continue
 				}
+				if policy := c.opt.RetryPolicy; policy != nil {
+					delay, ok := policy(Completed(cm.Cmd), retries.attempts, resp.Error())
+					if !ok {
+						continue
+					}
+					retries.delay(mu, delay)
+				}
 			} else {
 				nc = c.redirectOrNew(addr, cc)
 			}
@@ -928,11 +1193,55 @@ func (c *clusterClient) resultcachefn(ctx context.Context, results *redisresults
 	}
 }
 
+// domulticache sends the multi to the cc like cc.DoMultiCache. With the CircuitBreaker, the multi is sent without
+// caching after the READONLY if the cc is a replica as the fallback, and the result is recorded to the breaker of the cc.
+func (c *clusterClient) domulticache(ctx context.Context, cc conn, multi []CacheableTTL) (resps *redisresults) {
+	if c.breakers == nil {
+		return cc.DoMultiCache(ctx, multi...)
+	}
+	if c.needsReadOnly(cc) {
+		commands := make([]Completed, len(multi))
+		for i, cmd := range multi {
+			commands[i] = Completed(cmd.Cmd)
+		}
+		resps = readOnlyMulti(cc, ctx, commands)
+	} else {
+		resps = cc.DoMultiCache(ctx, multi...)
+	}
+	c.breakers.record(ctx, cc, nonRedisError(resps.s))
+	return resps
+}
+
+// divertcache queues the commands of the cc with an open circuit to other nodes serving their slots for the next attempt.
+func (c *clusterClient) divertcache(results *redisresults, retries *connretrycache, mu *sync.Mutex, cc conn, cIndexes []int, commands []CacheableTTL) {
+	for i, cm := range commands {
+		ii := cIndexes[i]
+		results.s[ii] = newErrResult(ErrCircuitOpen)
+		nc := c.alternative(cc, cm.Cmd.Slot(), true)
+		if nc == nil {
+			continue
+		}
+		mu.Lock()
+		nr := retries.m[nc]
+		if nr == nil {
+			nr = retrycachep.Get(0, len(commands))
+			retries.m[nc] = nr
+		}
+		nr.cIndexes = append(nr.cIndexes, ii)
+		nr.commands = append(nr.commands, cm)
+		mu.Unlock()
+	}
+}
+
 func (c *clusterClient) doretrycache(ctx context.Context, cc conn, results *redisresults, retries *connretrycache, re *retrycache, mu *sync.Mutex, wg *sync.WaitGroup) {
 	if len(re.commands) != 0 {
-		resps := cc.DoMultiCache(ctx, re.commands...)
-		c.resultcachefn(ctx, results, retries, mu, cc, re.cIndexes, re.commands, resps.s)
-		resultsp.Put(resps)
+		if c.breakers != nil && !c.breakers.allow(cc) {
+			c.divertcache(results, retries, mu, cc, re.cIndexes, re.commands)
+		} else {
+			resps := c.domulticache(ctx, cc, re.commands)
+			c.resultcachefn(ctx, results, retries, mu, cc, re.cIndexes, re.commands, resps.s)
+			resultsp.Put(resps)
+		}
 	}
 	if len(re.cAskings) != 0 {
 		resps := askingMultiCache(cc, ctx, re.cAskings)
@@ -972,7 +1281,12 @@ retry:
 	wg.Wait()
 
 	if len(retries.m) != 0 {
-		goto retry
+		if retries.wait(ctx) {
+			goto retry
+		}
+		for _, re := range retries.m {
+			retrycachep.Put(re)
+		}
 	}
 
 	for i, cmd := range multi {
@@ -989,7 +1303,19 @@ retry:
 	if err != nil {
 		goto ret
 	}
+	if c.breakers != nil {
+		if cc, err = c.circuit(cc, subscribe.Slot(), false); err != nil {
+			goto ret
+		}
+	}
 	err = cc.Receive(ctx, subscribe, fn)
+	if c.breakers != nil {
+		if _, ok := err.(*RedisError); ok {
+			c.breakers.record(ctx, cc, nil)
+		} else {
+			c.breakers.record(ctx, cc, err)
+		}
+	}
 	if _, mode := c.shouldRefreshRetry(err, ctx); c.retry && mode != RedirectNone {
 		runtime.Gosched()
 		goto retry
@@ -1050,16 +1376,65 @@ func (c *clusterClient) Dedicate() (DedicatedClient, func()) {
 	return dcc, dcc.release
 }
 
+func (c *clusterClient) withConn(fn func(c CoreClient) error) error {
+	return withPinned(c.cmd, func(ctx context.Context, slot uint16) (conn, error) { return c.pick(ctx, slot, false) }, fn)
+}
+
 func (c *clusterClient) Nodes() map[string]Client {
 	c.mu.RLock()
 	nodes := make(map[string]Client, len(c.conns))
 	for addr, cc := range c.conns {
-		nodes[addr] = newSingleClientWithConn(cc.conn, c.cmd, c.retry)
+		nodes[addr] = newSingleClientWithConn(cc.conn, c.cmd, c.retry, c.opt.RetryPolicy, c.opt.MaxConns > 0)
 	}
 	c.mu.RUnlock()
 	return nodes
 }
 
+// masters returns clients of the nodes serving slots, excluding replicas and the InitAddress not in the topology.
+func (c *clusterClient) masters() []Client {
+	c.mu.RLock()
+	owners := make(map[conn]struct{}, len(c.conns))
+	for _, cc := range c.pslots {
+		owners[cc] = struct{}{}
+	}
+	masters := make([]Client, 0, len(c.conns))
+	for _, cc := range c.conns {
+		if _, ok := owners[cc.conn]; !cc.replica && (ok || c.opt.ReplicaOnly) {
+			masters = append(masters, newSingleClientWithConn(cc.conn, c.cmd, c.retry, c.opt.RetryPolicy, c.opt.MaxConns > 0))
+		}
+	}
+	c.mu.RUnlock()
+	return masters
+}
+
+func (c *clusterClient) connStats() []ConnStat {
+	c.mu.RLock()
+	stats := make([]ConnStat, 0, len(c.conns))
+	for _, cc := range c.conns {
+		stats = append(stats, cc.conn.Stats())
+	}
+	c.mu.RUnlock()
+	return stats
+}
+
+func (c *clusterClient) serverVersion() string {
+	c.mu.RLock()
+	versions := make([]string, 0, len(c.conns))
+	for _, cc := range c.conns {
+		versions = append(versions, cc.conn.Info()["version"].string)
+	}
+	c.mu.RUnlock()
+	return lowestVersion(versions...)
+}
+
+func (c *clusterClient) flushCache(keys []string) {
+	c.mu.RLock()
+	for _, cc := range c.conns {
+		cc.conn.FlushCache(keys)
+	}
+	c.mu.RUnlock()
+}
+
 func (c *clusterClient) Close() {
 	atomic.StoreUint32(&c.stop, 1)
 	c.mu.RLock()
@@ -1116,7 +1491,10 @@ func (c *dedicatedClusterClient) acquire(ctx context.Context, slot uint16) (wire
 	if c.wire != nil {
 		return c.wire, nil
 	}
-	if c.conn, err = c.client.pick(ctx, c.slot, false); err != nil {
+	if c.conn, err = c.client.pick(ctx, c.slot, false); err == nil {
+		c.wire, err = c.conn.Acquire(ctx)
+	}
+	if err != nil {
 		if p := c.pshks; p != nil {
 			c.pshks = nil
 			p.close <- err
@@ -1124,7 +1502,6 @@ func (c *dedicatedClusterClient) acquire(ctx context.Context, slot uint16) (wire
 		}
 		return nil, err
 	}
-	c.wire = c.conn.Acquire()
 	if p := c.pshks; p != nil {
 		c.pshks = nil
 		ch := c.wire.SetPubSubHooks(p.hooks)
@@ -1301,6 +1678,7 @@ var conncountp = util.NewPool(func(capacity int) *conncount {
 type connretry struct {
 	m map[conn]*retry
 	n int
+	retrydelay
 }
 
 func (r *connretry) Capacity() int {
@@ -1311,6 +1689,7 @@ func (r *connretry) ResetLen(n int) {
 	for k := range r.m {
 		delete(r.m, k)
 	}
+	r.retrydelay = retrydelay{attempts: 1}
 }
 
 var connretryp = util.NewPool(func(capacity int) *connretry {
@@ -1320,6 +1699,7 @@ var connretryp = util.NewPool(func(capacity int) *connretry {
 type connretrycache struct {
 	m map[conn]*retrycache
 	n int
+	retrydelay
 }
 
 func (r *connretrycache) Capacity() int {
@@ -1330,6 +1710,29 @@ func (r *connretrycache) ResetLen(n int) {
 	for k := range r.m {
 		delete(r.m, k)
 	}
+	r.retrydelay = retrydelay{attempts: 1}
+}
+
+// retrydelay tracks the attempts of a multi-node DoMulti and the longest delay requested by the RetryPolicy in the current attempt.
+type retrydelay struct {
+	attempts int
+	maxdelay time.Duration
+}
+
+func (r *retrydelay) delay(mu *sync.Mutex, delay time.Duration) {
+	mu.Lock()
+	if delay > r.maxdelay {
+		r.maxdelay = delay
+	}
+	mu.Unlock()
+}
+
+// wait waits for the longest delay before the next attempt. It returns false if the ctx is done during waiting.
+func (r *retrydelay) wait(ctx context.Context) bool {
+	delay := r.maxdelay
+	r.attempts++
+	r.maxdelay = 0
+	return waitRetry(ctx, delay)
 }
 
 var connretrycachep = util.NewPool(func(capacity int) *connretrycache {
@@ -4142,6 +4142,22 @@ func TestClusterClientErr(t *testing.T) {
 	})
 }
 
+func TestClusterClientRetryPolicy(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	SetupClientRetryPolicy(t, func(m *mockConn, policy RetryPolicy) Client {
+		m.DoOverride = map[string]func(cmd Completed) RedisResult{
+			"CLUSTER SLOTS": func(cmd Completed) RedisResult { return slotsMultiResp },
+		}
+		c, err := newClusterClient(&ClientOption{InitAddress: []string{":0"}, RetryPolicy: policy}, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return c
+	})
+}
+
 func TestClusterClientRetry(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	SetupClientRetry(t, func(m *mockConn) Client {
@@ -4329,6 +4345,41 @@ func TestClusterShardsParsing(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("unhealthy replicas", func(t *testing.T) {
+		node := func(ip, role, health string) RedisMessage {
+			return RedisMessage{typ: typeMap, values: []RedisMessage{
+				{typ: typeBlobString, string: "port"},
+				{typ: typeInteger, integer: 1},
+				{typ: typeBlobString, string: "endpoint"},
+				{typ: typeBlobString, string: ip},
+				{typ: typeBlobString, string: "role"},
+				{typ: typeBlobString, string: role},
+				{typ: typeBlobString, string: "health"},
+				{typ: typeBlobString, string: health},
+			}}
+		}
+		shards := RedisMessage{typ: typeArray, values: []RedisMessage{
+			{typ: typeMap, values: []RedisMessage{
+				{typ: typeBlobString, string: "slots"},
+				{typ: typeArray, values: []RedisMessage{
+					{typ: typeBlobString, string: "0"},
+					{typ: typeBlobString, string: "16383"},
+				}},
+				{typ: typeBlobString, string: "nodes"},
+				{typ: typeArray, values: []RedisMessage{
+					node("127.0.1.1", "replica", "failed"),
+					node("127.0.0.1", "master", "online"),
+					node("127.0.2.1", "replica", "loading"),
+					node("127.0.3.1", "replica", "online"),
+				}},
+			}},
+		}}
+		result := parseShards(shards, "127.0.0.1:5", false)
+		if g, ok := result["127.0.0.1:1"]; !ok || len(g.nodes) != 2 || g.nodes[1] != "127.0.3.1:1" {
+			t.Fatalf("unexpected result %v", result)
+		}
+	})
 }
 
 // https://github.com/redis/rueidis/issues/543
@@ -4351,3 +4402,67 @@ func TestConnectToNonAvailableCluster(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestClusterClientDoMultiBatching(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var mu sync.Mutex
+	calls := map[string][][]string{}
+	moved := ""
+	client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+		return &mockConn{
+			DoFn: func(cmd Completed) RedisResult {
+				return slotsMultiResp
+			},
+			DoMultiFn: func(multi ...Completed) *redisresults {
+				mu.Lock()
+				defer mu.Unlock()
+				keys := make([]string, len(multi))
+				ret := make([]RedisResult, len(multi))
+				for i, cmd := range multi {
+					keys[i] = cmd.Commands()[1]
+					if keys[i] == moved && dst == "127.0.0.1:0" {
+						ret[i] = newResult(RedisMessage{typ: '-', string: fmt.Sprintf("MOVED %d 127.0.2.1:0", cmd.Slot())}, nil)
+					} else {
+						ret[i] = newResult(RedisMessage{typ: '+', string: keys[i]}, nil)
+					}
+				}
+				calls[dst] = append(calls[dst], keys)
+				return &redisresults{s: ret}
+			},
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	multi := make([]Completed, 1000)
+	owners := map[string][]string{}
+	for i := range multi {
+		key := "k" + strconv.Itoa(i)
+		multi[i] = client.B().Get().Key(key).Build()
+		owner := "127.0.2.1:0"
+		if multi[i].Slot() <= 8192 {
+			owner = "127.0.0.1:0"
+			if moved == "" {
+				moved = key
+			}
+		}
+		owners[owner] = append(owners[owner], key)
+	}
+	if len(owners) != 2 || moved == "" {
+		t.Fatalf("keys should be spread across both nodes %v", len(owners))
+	}
+
+	for i, resp := range client.DoMulti(context.Background(), multi...) {
+		if v, err := resp.ToString(); err != nil || v != "k"+strconv.Itoa(i) {
+			t.Fatalf("unexpected resp %d %v %v", i, v, err)
+		}
+	}
+	// each node receives its commands in one pipeline in their original order, and only the moved command is redirected.
+	if len(calls["127.0.0.1:0"]) != 1 || !reflect.DeepEqual(calls["127.0.0.1:0"][0], owners["127.0.0.1:0"]) {
+		t.Fatalf("unexpected calls to 127.0.0.1:0 %v", len(calls["127.0.0.1:0"]))
+	}
+	if len(calls["127.0.2.1:0"]) != 2 || !reflect.DeepEqual(calls["127.0.2.1:0"][0], owners["127.0.2.1:0"]) || !reflect.DeepEqual(calls["127.0.2.1:0"][1], []string{moved}) {
+		t.Fatalf("unexpected calls to 127.0.2.1:0 %v", len(calls["127.0.2.1:0"]))
+	}
+}
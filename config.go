@@ -0,0 +1,62 @@
+package rueidis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConfigMap is the response of the CONFIG GET, which maps the config parameters to their values.
+type ConfigMap map[string]string
+
+// ConfigGet returns the values of the params by the CONFIG GET, where the params can be glob-style patterns,
+// and all the config parameters are returned if no params is given. Multiple params need redis 7 and above.
+// In cluster mode, the CONFIG GET is sent to an arbitrary node.
+func ConfigGet(client Client, ctx context.Context, params ...string) (ConfigMap, error) {
+	if len(params) == 0 {
+		params = []string{"*"}
+	}
+	return client.Do(ctx, client.B().ConfigGet().Parameter(params...).Build()).AsConfigMap()
+}
+
+// Get returns the value of the param, which is case-insensitive like the CONFIG GET.
+// It returns a redis nil error, which can be checked by the IsRedisNil, if the param is not in the map.
+func (c ConfigMap) Get(param string) (string, error) {
+	if v, ok := c[param]; ok {
+		return v, nil
+	}
+	if v, ok := c[strings.ToLower(param)]; ok {
+		return v, nil
+	}
+	return "", Nil
+}
+
+// Int64 returns the value of a numeric param, such as the maxmemory and the timeout.
+// Memory sizes are returned in bytes by the CONFIG GET even if they are configured with units.
+func (c ConfigMap) Int64(param string) (int64, error) {
+	v, err := c.Get(param)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: config %s is not an integer: %q", errParse, param, v)
+	}
+	return n, nil
+}
+
+// Bool returns the value of a yes/no param, such as the appendonly and the cluster-enabled.
+func (c ConfigMap) Bool(param string) (bool, error) {
+	v, err := c.Get(param)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(v) {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: config %s is not a yes/no: %q", errParse, param, v)
+}
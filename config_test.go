@@ -0,0 +1,78 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigGet(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	m := &mockConn{}
+	client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+		return m
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	m.DoFn = func(cmd Completed) RedisResult {
+		switch strings.Join(cmd.Commands(), " ") {
+		case "CONFIG GET *":
+			return newResult(RedisMessage{typ: '*', values: []RedisMessage{
+				{typ: '$', string: "maxmemory"}, {typ: '$', string: "104857600"},
+				{typ: '$', string: "appendonly"}, {typ: '$', string: "no"},
+				{typ: '$', string: "cluster-enabled"}, {typ: '$', string: "yes"},
+				{typ: '$', string: "save"}, {typ: '$', string: "3600 1"},
+			}}, nil)
+		case "CONFIG GET maxmemory timeout":
+			return newResult(RedisMessage{typ: '%', values: []RedisMessage{
+				{typ: '$', string: "maxmemory"}, {typ: '$', string: "0"},
+				{typ: '$', string: "timeout"}, {typ: '$', string: "300"},
+			}}, nil)
+		case "CONFIG GET unknown":
+			return newResult(RedisMessage{typ: '-', string: "ERR unknown"}, nil)
+		}
+		return newErrResult(errors.New("unexpected command"))
+	}
+	ctx := context.Background()
+
+	config, err := ConfigGet(client, ctx)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if n, err := config.Int64("MaxMemory"); err != nil || n != 104857600 {
+		t.Fatalf("unexpected maxmemory %v %v", n, err)
+	}
+	if v, err := config.Bool("appendonly"); err != nil || v {
+		t.Fatalf("unexpected appendonly %v %v", v, err)
+	}
+	if v, err := config.Bool("cluster-enabled"); err != nil || !v {
+		t.Fatalf("unexpected cluster-enabled %v %v", v, err)
+	}
+	if v, err := config.Get("save"); err != nil || v != "3600 1" {
+		t.Fatalf("unexpected save %v %v", v, err)
+	}
+	if _, err := config.Int64("save"); err == nil || !strings.Contains(err.Error(), "save") {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if _, err := config.Bool("maxmemory"); err == nil || !strings.Contains(err.Error(), "maxmemory") {
+		t.Fatalf("unexpected err %v", err)
+	}
+	for _, param := range []string{"missing", "Missing"} {
+		if _, err := config.Int64(param); !IsRedisNil(err) {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if _, err := config.Bool(param); !IsRedisNil(err) {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+
+	if config, err := ConfigGet(client, ctx, "maxmemory", "timeout"); err != nil || !reflect.DeepEqual(config, ConfigMap{"maxmemory": "0", "timeout": "300"}) {
+		t.Fatalf("unexpected response %v %v", config, err)
+	}
+	if _, err := ConfigGet(client, ctx, "unknown"); err == nil {
+		t.Fatal("unexpected nil err")
+	}
+}
@@ -0,0 +1,183 @@
+package rueidis
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	messageType = reflect.TypeOf(RedisMessage{})
+	fieldsCache sync.Map // map[reflect.Type]map[string]int
+)
+
+// structFields maps the keys of a redis map to the field indexes of the struct type t.
+// A field is keyed by the name of its `redis` tag, or its name in lower case if it has no tag. Fields tagged with `redis:"-"` are skipped.
+func structFields(t reflect.Type) map[string]int {
+	if fields, ok := fieldsCache.Load(t); ok {
+		return fields.(map[string]int)
+	}
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("redis"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = i
+	}
+	fieldsCache.Store(t, fields)
+	return fields
+}
+
+func decodeStruct(m *RedisMessage, v reflect.Value) error {
+	kvs, err := m.AsMap()
+	if err != nil {
+		return err
+	}
+	fields := structFields(v.Type())
+	for k, e := range kvs {
+		i, ok := fields[k]
+		if !ok {
+			if i, ok = fields[strings.ToLower(k)]; !ok {
+				continue
+			}
+		}
+		if err := decodeValue(&e, v.Field(i)); err != nil {
+			return fmt.Errorf("%w: decode %q into the field %s: %v", errParse, k, v.Type().Field(i).Name, err)
+		}
+	}
+	return nil
+}
+
+func decodeValue(m *RedisMessage, v reflect.Value) (err error) {
+	if m.IsNil() {
+		return nil
+	}
+	if err = m.Error(); err != nil {
+		return err
+	}
+	if v.Type() == messageType {
+		v.Set(reflect.ValueOf(*m))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(m, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			break
+		}
+		var a any
+		if a, err = m.ToAny(); err == nil {
+			v.Set(reflect.ValueOf(a))
+		}
+		return err
+	case reflect.String:
+		var s string
+		if m.IsInt64() {
+			s = strconv.FormatInt(m.integer, 10)
+		} else if s, err = m.ToString(); err != nil {
+			return err
+		}
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		var b bool
+		if b, err = decodeBool(m); err == nil {
+			v.SetBool(b)
+		}
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = m.AsInt64(); err == nil {
+			if v.OverflowInt(n) {
+				return fmt.Errorf("%d overflows %s", n, v.Type())
+			}
+			v.SetInt(n)
+		}
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = m.AsUint64(); err == nil {
+			if v.OverflowUint(n) {
+				return fmt.Errorf("%d overflows %s", n, v.Type())
+			}
+			v.SetUint(n)
+		}
+		return err
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if m.IsInt64() {
+			f = float64(m.integer)
+		} else if f, err = m.AsFloat64(); err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	case reflect.Struct:
+		return decodeStruct(m, v)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		var kvs map[string]RedisMessage
+		if kvs, err = m.AsMap(); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(kvs)))
+		}
+		for k, e := range kvs {
+			ev := reflect.New(v.Type().Elem()).Elem()
+			if err = decodeValue(&e, ev); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), ev)
+		}
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && m.IsString() {
+			v.SetBytes([]byte(m.string))
+			return nil
+		}
+		var values []RedisMessage
+		if values, err = m.ToArray(); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i := range values {
+			if err = decodeValue(&values[i], s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	return fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// decodeBool parses the integers, the RESP3 booleans, and the strings like "yes", "no", "true" and "1" as bool.
+func decodeBool(m *RedisMessage) (bool, error) {
+	if !m.IsString() {
+		return m.AsBool()
+	}
+	switch strings.ToLower(m.string) {
+	case "yes", "on", "ok":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(m.string)
+}
@@ -0,0 +1,134 @@
+package rueidis
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func strmsg(s string) RedisMessage {
+	return RedisMessage{typ: typeBlobString, string: s}
+}
+
+func intmsg(i int64) RedisMessage {
+	return RedisMessage{typ: typeInteger, integer: i}
+}
+
+func TestDecodeStruct(t *testing.T) {
+	type Entry struct {
+		ID     string
+		Fields []string
+	}
+	type Stream struct {
+		Length          int64             `redis:"length"`
+		RadixTreeKeys   uint32            `redis:"radix-tree-keys"`
+		LastGeneratedID string            `redis:"last-generated-id"`
+		FirstEntry      *Entry            `redis:"first-entry"`
+		Groups          int               // matched by the lower case name
+		Ratio           float64           `redis:"ratio"`
+		Ok              bool              `redis:"ok"`
+		Meta            map[string]string `redis:"meta"`
+		Raw             RedisMessage      `redis:"raw"`
+		Any             any               `redis:"any"`
+		Skipped         string            `redis:"-"`
+		Missing         string            `redis:"missing"`
+		Bytes           []byte            `redis:"bytes"`
+		Nested          struct {
+			Name string `redis:"name"`
+		} `redis:"nested"`
+		unexported string
+	}
+	msg := RedisMessage{typ: typeMap, values: []RedisMessage{
+		strmsg("length"), intmsg(2),
+		strmsg("radix-tree-keys"), strmsg("1"),
+		strmsg("last-generated-id"), strmsg("1-0"),
+		strmsg("first-entry"), {typ: typeMap, values: []RedisMessage{
+			strmsg("id"), strmsg("0-1"),
+			strmsg("fields"), {typ: typeArray, values: []RedisMessage{strmsg("f"), strmsg("v")}},
+		}},
+		strmsg("Groups"), intmsg(3),
+		strmsg("ratio"), {typ: typeFloat, string: "0.5"},
+		strmsg("ok"), strmsg("yes"),
+		strmsg("meta"), {typ: typeMap, values: []RedisMessage{strmsg("k"), strmsg("v")}},
+		strmsg("raw"), intmsg(7),
+		strmsg("any"), {typ: typeArray, values: []RedisMessage{intmsg(1), strmsg("a")}},
+		strmsg("-"), strmsg("skipped"),
+		strmsg("missing"), {typ: typeNull},
+		strmsg("bytes"), strmsg("b"),
+		strmsg("nested"), {typ: typeMap, values: []RedisMessage{strmsg("name"), strmsg("n")}},
+		strmsg("unexported"), strmsg("x"),
+		strmsg("unknown"), strmsg("u"),
+	}}
+
+	var s Stream
+	if err := (RedisResult{val: msg}).DecodeStruct(&s); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	expected := Stream{
+		Length:          2,
+		RadixTreeKeys:   1,
+		LastGeneratedID: "1-0",
+		FirstEntry:      &Entry{ID: "0-1", Fields: []string{"f", "v"}},
+		Groups:          3,
+		Ratio:           0.5,
+		Ok:              true,
+		Meta:            map[string]string{"k": "v"},
+		Raw:             intmsg(7),
+		Any:             []any{int64(1), "a"},
+		Bytes:           []byte("b"),
+	}
+	expected.Nested.Name = "n"
+	if !reflect.DeepEqual(s, expected) {
+		t.Fatalf("unexpected struct %+v", s)
+	}
+
+	t.Run("RESP2", func(t *testing.T) {
+		var c struct {
+			MaxMemory  int64 `redis:"maxmemory"`
+			AppendOnly bool  `redis:"appendonly"`
+		}
+		resp2 := RedisMessage{typ: typeArray, values: []RedisMessage{strmsg("maxmemory"), strmsg("1024"), strmsg("appendonly"), strmsg("no")}}
+		if err := resp2.DecodeStruct(&c); err != nil || c.MaxMemory != 1024 || c.AppendOnly {
+			t.Fatalf("unexpected struct %+v %v", c, err)
+		}
+	})
+
+	t.Run("Invalid Target", func(t *testing.T) {
+		var n int
+		for _, v := range []any{nil, s, &n, (*Stream)(nil)} {
+			if err := msg.DecodeStruct(v); !errors.Is(err, errParse) {
+				t.Fatalf("unexpected err %v", err)
+			}
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		if err := (RedisResult{err: errors.New("other")}).DecodeStruct(&s); err == nil || err.Error() != "other" {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if err := (&RedisMessage{typ: typeSimpleErr, string: "ERR"}).DecodeStruct(&s); err == nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if err := (&RedisMessage{typ: typeArray, values: []RedisMessage{strmsg("k")}}).DecodeStruct(&s); !errors.Is(err, errParse) {
+			t.Fatalf("unexpected err %v", err)
+		}
+		bad := RedisMessage{typ: typeMap, values: []RedisMessage{strmsg("length"), strmsg("x")}}
+		if err := bad.DecodeStruct(&s); !errors.Is(err, errParse) || !strings.Contains(err.Error(), "Length") {
+			t.Fatalf("unexpected err %v", err)
+		}
+		var small struct {
+			N int8 `redis:"n"`
+		}
+		overflow := RedisMessage{typ: typeMap, values: []RedisMessage{strmsg("n"), intmsg(1000)}}
+		if err := overflow.DecodeStruct(&small); !errors.Is(err, errParse) {
+			t.Fatalf("unexpected err %v", err)
+		}
+		var unsupported struct {
+			C chan int `redis:"c"`
+		}
+		if err := (&RedisMessage{typ: typeMap, values: []RedisMessage{strmsg("c"), intmsg(1)}}).DecodeStruct(&unsupported); !errors.Is(err, errParse) {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDedicatedTimeout is returned from DedicatedWithTimeout and from the commands issued by its callback
+// after the callback has held the dedicated connection for longer than the given duration.
+var ErrDedicatedTimeout = errors.New("dedicated connection is released forcibly because the callback exceeds the timeout")
+
+// DedicatedWithTimeout is similar to Client.Dedicated, but it bounds how long the fn can hold the dedicated connection.
+// The ctx passed to the fn will be done after the max duration, and every command issued through the DedicatedClient
+// will also be bounded by the same deadline. If the fn does not return in time, DedicatedWithTimeout returns ErrDedicatedTimeout
+// without waiting for the fn, and further commands issued by the fn will return ErrDedicatedTimeout.
+// In that case, the connection will be closed instead of being put back to the pool, because it may be left in
+// the middle of MULTI or WATCH.
+func DedicatedWithTimeout(client Client, ctx context.Context, fn func(ctx context.Context, c DedicatedClient) error, max time.Duration) error {
+	lctx, cancel := context.WithTimeout(ctx, max)
+	defer cancel()
+
+	dc, release := client.Dedicate()
+	lc := &leasedClient{client: dc, ctx: lctx}
+
+	done := make(chan error, 1)
+	go func() { done <- fn(lctx, lc) }()
+
+	select {
+	case err := <-done:
+		if dl, _ := lctx.Deadline(); ctx.Err() != nil || (lctx.Err() == nil && time.Now().Before(dl)) {
+			lc.expire()
+			release()
+			return err
+		}
+		// the fn returned because of the lease deadline, which may be reached by its commands slightly before the lctx.
+	case <-lctx.Done():
+	}
+	lc.expire()
+	dc.Close() // the connection may be in the middle of MULTI, close it instead of recycling it.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrDedicatedTimeout
+}
+
+type leasedClient struct {
+	client DedicatedClient
+	ctx    context.Context
+	idle   chan struct{} // closed once the in-flight commands are done after the expire
+	mu     sync.Mutex
+	flying int
+	mark   bool
+}
+
+// enter counts the command in flight instead of holding a lock for it, so that commands nested in a Receive callback
+// are rejected after the expire instead of being blocked by it.
+func (c *leasedClient) enter(ctx context.Context) (context.Context, context.CancelFunc, bool) {
+	c.mu.Lock()
+	if c.mark {
+		c.mu.Unlock()
+		return nil, nil, false
+	}
+	c.flying++
+	c.mu.Unlock()
+	dl, _ := c.ctx.Deadline()
+	ctx, cancel := context.WithDeadline(ctx, dl)
+	return ctx, cancel, true
+}
+
+func (c *leasedClient) leave(cancel context.CancelFunc) {
+	cancel()
+	c.mu.Lock()
+	if c.flying--; c.flying == 0 && c.idle != nil {
+		close(c.idle)
+		c.idle = nil
+	}
+	c.mu.Unlock()
+}
+
+// expire rejects further commands and waits for in-flight commands, which are bounded by the lease deadline.
+func (c *leasedClient) expire() {
+	c.mu.Lock()
+	c.mark = true
+	if c.flying == 0 {
+		c.mu.Unlock()
+		return
+	}
+	idle := make(chan struct{})
+	c.idle = idle
+	c.mu.Unlock()
+	<-idle
+}
+
+func (c *leasedClient) B() Builder {
+	return c.client.B()
+}
+
+func (c *leasedClient) Do(ctx context.Context, cmd Completed) (resp RedisResult) {
+	ctx, cancel, ok := c.enter(ctx)
+	if !ok {
+		return newErrResult(ErrDedicatedTimeout)
+	}
+	resp = c.client.Do(ctx, cmd)
+	c.leave(cancel)
+	return resp
+}
+
+func (c *leasedClient) DoMulti(ctx context.Context, multi ...Completed) (resp []RedisResult) {
+	ctx, cancel, ok := c.enter(ctx)
+	if !ok {
+		return fillErrs(len(multi), ErrDedicatedTimeout)
+	}
+	resp = c.client.DoMulti(ctx, multi...)
+	c.leave(cancel)
+	return resp
+}
+
+func (c *leasedClient) Receive(ctx context.Context, subscribe Completed, fn func(msg PubSubMessage)) (err error) {
+	ctx, cancel, ok := c.enter(ctx)
+	if !ok {
+		return ErrDedicatedTimeout
+	}
+	err = c.client.Receive(ctx, subscribe, fn)
+	c.leave(cancel)
+	return err
+}
+
+func (c *leasedClient) SetPubSubHooks(hooks PubSubHooks) <-chan error {
+	_, cancel, ok := c.enter(context.Background())
+	if !ok {
+		ch := make(chan error, 1)
+		ch <- ErrDedicatedTimeout
+		close(ch)
+		return ch
+	}
+	ch := c.client.SetPubSubHooks(hooks)
+	c.leave(cancel)
+	return ch
+}
+
+func (c *leasedClient) Close() {
+	_, cancel, ok := c.enter(context.Background())
+	if !ok {
+		return
+	}
+	c.client.Close()
+	c.leave(cancel)
+}
@@ -0,0 +1,130 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDedicatedWithTimeout(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+
+	setup := func(t *testing.T) (*singleClient, *mockWire, *bool, *bool) {
+		stored, closed := false, false
+		w := &mockWire{
+			DoFn: func(cmd Completed) RedisResult {
+				return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+			},
+			ReceiveFn: func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			CloseFn: func() { closed = true },
+		}
+		m := &mockConn{
+			AcquireFn: func() wire { return w },
+			StoreFn: func(ww wire) {
+				if ww != w {
+					t.Fatalf("received unexpected wire %v", ww)
+				}
+				stored = true
+			},
+		}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client, w, &stored, &closed
+	}
+
+	t.Run("Return In Time", func(t *testing.T) {
+		client, _, stored, closed := setup(t)
+		v := errors.New("fn err")
+		err := DedicatedWithTimeout(client, context.Background(), func(ctx context.Context, c DedicatedClient) error {
+			if s, err := c.Do(ctx, c.B().Get().Key("a").Build()).ToString(); err != nil || s != "OK" {
+				t.Fatalf("unexpected response %v %v", s, err)
+			}
+			return v
+		}, time.Second)
+		if err != v {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if !*stored || *closed {
+			t.Fatalf("connection should be put back without closing")
+		}
+	})
+
+	t.Run("Exceed Timeout", func(t *testing.T) {
+		client, _, stored, closed := setup(t)
+		leased := make(chan DedicatedClient, 1)
+		err := DedicatedWithTimeout(client, context.Background(), func(ctx context.Context, c DedicatedClient) error {
+			leased <- c
+			return c.Receive(context.Background(), c.B().Subscribe().Channel("ch").Build(), func(msg PubSubMessage) {})
+		}, 50*time.Millisecond)
+		if err != ErrDedicatedTimeout {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if !*stored || !*closed {
+			t.Fatalf("connection should be closed before put back")
+		}
+		c := <-leased
+		if err := c.Do(context.Background(), c.B().Get().Key("a").Build()).Error(); err != ErrDedicatedTimeout {
+			t.Fatalf("unexpected err %v", err)
+		}
+		for _, resp := range c.DoMulti(context.Background(), c.B().Get().Key("a").Build()) {
+			if err := resp.Error(); err != ErrDedicatedTimeout {
+				t.Fatalf("unexpected err %v", err)
+			}
+		}
+		if err := <-c.SetPubSubHooks(PubSubHooks{}); err != ErrDedicatedTimeout {
+			t.Fatalf("unexpected err %v", err)
+		}
+		c.Close()
+	})
+
+	t.Run("Nested In Receive", func(t *testing.T) {
+		client, w, stored, closed := setup(t)
+		w.ReceiveFn = func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond) // let the lease be expired before the callback
+			fn(PubSubMessage{Channel: "ch"})
+			return ctx.Err()
+		}
+		nested := make(chan error, 1)
+		err := DedicatedWithTimeout(client, context.Background(), func(ctx context.Context, c DedicatedClient) error {
+			return c.Receive(ctx, c.B().Subscribe().Channel("ch").Build(), func(msg PubSubMessage) {
+				nested <- c.Do(context.Background(), c.B().Get().Key("a").Build()).Error()
+			})
+		}, 50*time.Millisecond)
+		if err != ErrDedicatedTimeout {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if err := <-nested; err != ErrDedicatedTimeout {
+			t.Fatalf("unexpected nested err %v", err)
+		}
+		if !*stored || !*closed {
+			t.Fatalf("connection should be closed before put back")
+		}
+	})
+
+	t.Run("Parent Context Canceled", func(t *testing.T) {
+		client, _, stored, closed := setup(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		block := make(chan struct{})
+		defer close(block)
+		err := DedicatedWithTimeout(client, ctx, func(ctx context.Context, c DedicatedClient) error {
+			<-block
+			return nil
+		}, time.Second)
+		if err != context.Canceled {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if !*stored || !*closed {
+			t.Fatalf("connection should be closed before put back")
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package rueidis
+
+import (
+	"context"
+	"strings"
+)
+
+// NewFunctionLibrary creates a FunctionLibrary of the library code, which must start with the shebang line
+// like "#!lua name=mylib", for the Redis Functions of redis >= 7.0.
+func NewFunctionLibrary(code string) *FunctionLibrary {
+	return &FunctionLibrary{code: code}
+}
+
+// FunctionLibrary represents a redis function library. It should be created from the NewFunctionLibrary().
+type FunctionLibrary struct {
+	code string
+}
+
+// Load the library to the given Client by the FUNCTION LOAD, with the REPLACE option if replace is true.
+// Since functions are not propagated across shards, the library is loaded to every master node if the Client is a cluster client.
+func (l *FunctionLibrary) Load(ctx context.Context, c Client, replace bool) error {
+	if cc, ok := c.(*clusterClient); ok {
+		for _, node := range cc.masters() {
+			if err := l.load(ctx, node, replace); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return l.load(ctx, c, replace)
+}
+
+func (l *FunctionLibrary) load(ctx context.Context, c Client, replace bool) error {
+	if replace {
+		return c.Do(ctx, c.B().FunctionLoad().Replace().FunctionCode(l.code).Build()).Error()
+	}
+	return c.Do(ctx, c.B().FunctionLoad().FunctionCode(l.code).Build()).Error()
+}
+
+// FCall calls the function fn of the library by the FCALL, which is routed by the slot of the keys if the Client is a cluster client.
+// If the function is not found, for example, because the node is restarted or failed over without the library persisted,
+// it will load the library by the Load with the REPLACE option and then try again once.
+// Cross slot keys are prohibited if the Client is a cluster client.
+func (l *FunctionLibrary) FCall(ctx context.Context, c Client, fn string, keys, args []string) (resp RedisResult) {
+	resp = c.Do(ctx, c.B().Fcall().Function(fn).Numkeys(int64(len(keys))).Key(keys...).Arg(args...).Build())
+	if l.reload(ctx, c, resp) {
+		resp = c.Do(ctx, c.B().Fcall().Function(fn).Numkeys(int64(len(keys))).Key(keys...).Arg(args...).Build())
+	}
+	return resp
+}
+
+// FCallRO is the same as the FCall but uses the FCALL_RO, which can be served by replicas, for functions with the no-writes flag.
+func (l *FunctionLibrary) FCallRO(ctx context.Context, c Client, fn string, keys, args []string) (resp RedisResult) {
+	resp = c.Do(ctx, c.B().FcallRo().Function(fn).Numkeys(int64(len(keys))).Key(keys...).Arg(args...).Build())
+	if l.reload(ctx, c, resp) {
+		resp = c.Do(ctx, c.B().FcallRo().Function(fn).Numkeys(int64(len(keys))).Key(keys...).Arg(args...).Build())
+	}
+	return resp
+}
+
+// reload loads the library again if the resp is the error of a missing function, and reports whether it is loaded successfully.
+func (l *FunctionLibrary) reload(ctx context.Context, c Client, resp RedisResult) bool {
+	if err, ok := IsRedisErr(resp.Error()); ok && strings.Contains(err.string, "Function not found") {
+		return l.Load(ctx, c, true) == nil
+	}
+	return false
+}
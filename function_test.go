@@ -0,0 +1,150 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+const testLibrary = "#!lua name=mylib\nredis.register_function('myfn', function(keys, args) return args[1] end)"
+
+func TestFunctionLibraryLoad(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var sent [][]string
+	c := &client{
+		BFn: func() Builder {
+			return cmds.NewBuilder(cmds.NoSlot)
+		},
+		DoFn: func(ctx context.Context, cmd Completed) (resp RedisResult) {
+			sent = append(sent, cmd.Commands())
+			return newResult(RedisMessage{typ: '+', string: "mylib"}, nil)
+		},
+	}
+	lib := NewFunctionLibrary(testLibrary)
+	if err := lib.Load(context.Background(), c, false); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := lib.Load(context.Background(), c, true); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if !reflect.DeepEqual(sent, [][]string{{"FUNCTION", "LOAD", testLibrary}, {"FUNCTION", "LOAD", "REPLACE", testLibrary}}) {
+		t.Fatalf("unexpected commands %v", sent)
+	}
+}
+
+func TestFunctionLibraryLoadCluster(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var mu sync.Mutex
+	loaded := map[string]int{}
+	client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+		return &mockConn{
+			DoFn: func(cmd Completed) RedisResult {
+				if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+					return slotsMultiResp
+				}
+				mu.Lock()
+				loaded[dst]++
+				mu.Unlock()
+				if dst == "127.0.2.1:0" {
+					return newResult(RedisMessage{typ: '-', string: "ERR Library 'mylib' already exists"}, nil)
+				}
+				return newResult(RedisMessage{typ: '+', string: "mylib"}, nil)
+			},
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+	lib := NewFunctionLibrary(testLibrary)
+	if err := lib.Load(context.Background(), client, true); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := lib.Load(context.Background(), client, true); err == nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if loaded["127.0.2.1:0"] != 2 || loaded["127.0.0.1:0"] > 2 {
+		t.Fatalf("unexpected loads %v", loaded)
+	}
+}
+
+func TestFunctionLibraryFCall(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	for _, ro := range []bool{false, true} {
+		command := "FCALL"
+		if ro {
+			command = "FCALL_RO"
+		}
+		loaded := false
+		var sent [][]string
+		c := &client{
+			BFn: func() Builder {
+				return cmds.NewBuilder(cmds.NoSlot)
+			},
+			DoFn: func(ctx context.Context, cmd Completed) (resp RedisResult) {
+				sent = append(sent, cmd.Commands())
+				switch cmd.Commands()[0] {
+				case "FUNCTION":
+					loaded = true
+					return newResult(RedisMessage{typ: '+', string: "mylib"}, nil)
+				case command:
+					if !loaded {
+						return newResult(RedisMessage{typ: '-', string: "ERR Function not found"}, nil)
+					}
+					return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+				}
+				return newErrResult(errors.New("unexpected"))
+			},
+		}
+		lib := NewFunctionLibrary(testLibrary)
+		call := lib.FCall
+		if ro {
+			call = lib.FCallRO
+		}
+		if v, err := call(context.Background(), c, "myfn", []string{"k"}, []string{"a"}).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if v, err := call(context.Background(), c, "myfn", []string{"k"}, []string{"a"}).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if !reflect.DeepEqual(sent, [][]string{
+			{command, "myfn", "1", "k", "a"},
+			{"FUNCTION", "LOAD", "REPLACE", testLibrary},
+			{command, "myfn", "1", "k", "a"},
+			{command, "myfn", "1", "k", "a"},
+		}) {
+			t.Fatalf("unexpected commands %v", sent)
+		}
+	}
+}
+
+func TestFunctionLibraryFCallReloadFailure(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	calls := 0
+	c := &client{
+		BFn: func() Builder {
+			return cmds.NewBuilder(cmds.NoSlot)
+		},
+		DoFn: func(ctx context.Context, cmd Completed) (resp RedisResult) {
+			if cmd.Commands()[0] == "FUNCTION" {
+				return newResult(RedisMessage{typ: '-', string: "ERR Error compiling function"}, nil)
+			}
+			calls++
+			return newResult(RedisMessage{typ: '-', string: "ERR Function not found"}, nil)
+		},
+	}
+	lib := NewFunctionLibrary(testLibrary)
+	if err := lib.FCall(context.Background(), c, "myfn", nil, nil).Error(); err == nil || !strings.Contains(err.Error(), "Function not found") {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("the call should not be retried if the reload fails %v", calls)
+	}
+}
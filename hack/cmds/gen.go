@@ -43,9 +43,11 @@ type parameter struct {
 }
 
 type command struct {
-	Group     string     `json:"group"`
-	Since     string     `json:"since"`
-	Arguments []argument `json:"arguments"`
+	Group      string     `json:"group"`
+	Since      string     `json:"since"`
+	Deprecated string     `json:"deprecated_since"`
+	ReplacedBy string     `json:"replaced_by"`
+	Arguments  []argument `json:"arguments"`
 }
 
 type argument struct {
@@ -61,6 +63,9 @@ type argument struct {
 	Variadic  bool       `json:"variadic"`
 
 	MultipleToken bool `json:"multiple_token"`
+
+	// Excludes lists, for each enum value, the names of the following arguments which can't be used with it.
+	Excludes map[string][]string `json:"excludes"`
 }
 
 type node struct {
@@ -129,7 +134,7 @@ func (n *node) GoStructs() (out []goStruct) {
 				},
 				Variadic:      n.Variadic() && !n.MultipleToken(),
 				MultipleToken: n.MultipleToken(),
-				NextNodes:     n.NextNodes(),
+				NextNodes:     n.NextNodesOf(e),
 			}
 			if len(n.Arg.Command) != 0 {
 				if !strings.HasSuffix(s.FullName, name(n.Arg.Command)) {
@@ -331,6 +336,21 @@ func (n *node) NextNodes() (nodes []*node) {
 	return nodes
 }
 
+// NextNodesOf returns the NextNodes without the arguments excluded by the enum value e.
+func (n *node) NextNodesOf(e string) (nodes []*node) {
+	excludes := n.Arg.Excludes[e]
+next:
+	for _, nn := range n.NextNodes() {
+		for _, ex := range excludes {
+			if nn.Arg.Name == ex {
+				continue next
+			}
+		}
+		nodes = append(nodes, nn)
+	}
+	return nodes
+}
+
 func (n *node) Walk(fn func(node *node)) {
 	next := n
 	for next != nil {
@@ -519,7 +539,7 @@ var blockmark = map[*node]bool{}
 
 func makePath(s goStruct, path []goStruct, pathes [][]goStruct) [][]goStruct {
 	path = append(path, s)
-	nexts := s.Node.NextNodes()
+	nexts := s.NextNodes
 	if pathmark[s.FullName] && allOptional(s.Node, nexts) {
 		pathes = append(pathes, path)
 		return pathes
@@ -698,6 +718,9 @@ func toGoName(paramName string) string {
 }
 
 func printRootBuilder(w io.Writer, root goStruct) {
+	if cmd := root.Node.Cmd; cmd.ReplacedBy != "" {
+		fmt.Fprintf(w, "// Deprecated: since redis %s, use %s instead.\n", cmd.Deprecated, cmd.ReplacedBy)
+	}
 	fmt.Fprintf(w, "func (b Builder) %s() (c %s) {\n", root.FullName, root.FullName)
 
 	var appends []string
@@ -1072,6 +1095,10 @@ var cacheableCMDs = map[string]bool{
 	"getbit":              false,
 	"getrange":            false,
 	"hexists":             false,
+	"hexpiretime":         false,
+	"hpexpiretime":        false,
+	"hpttl":               false,
+	"httl":                false,
 	"hget":                false,
 	"hgetall":             false,
 	"hkeys":               false,
@@ -1153,6 +1180,10 @@ var readOnlyCMDs = map[string]bool{
 	"getbit":              false,
 	"getrange":            false,
 	"hexists":             false,
+	"hexpiretime":         false,
+	"hpexpiretime":        false,
+	"hpttl":               false,
+	"httl":                false,
 	"hget":                false,
 	"hgetall":             false,
 	"hkeys":               false,
@@ -1233,6 +1264,9 @@ var readOnlyCMDs = map[string]bool{
 	"zscore":              false,
 	"zunion":              false,
 	"zintercard":          false,
+	"vcard":               false,
+	"vdim":                false,
+	"vsim":                false,
 	"jsonget":             false,
 	"jsonstrlen":          false,
 	"jsonarrindex":        false,
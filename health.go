@@ -0,0 +1,99 @@
+package rueidis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Ping sends a PING with the client and returns its error. In cluster mode, the PING is sent to a random node.
+// Use the HealthCheck to check every node.
+func Ping(client Client, ctx context.Context) error {
+	return client.Do(ctx, client.B().Ping().Build()).Error()
+}
+
+// NodeHealth is the health of a redis node reported by the HealthCheck.
+type NodeHealth struct {
+	// Err is the error of the ROLE command sent to the node, or the error of parsing its reply. The node is unreachable if it is not nil.
+	Err error
+	// Addr is the address of the node.
+	Addr string
+	// Role is "master", "replica" or "sentinel". It may be empty if the Err is not nil.
+	Role string
+	// Latency is the round-trip time of the ROLE command.
+	Latency time.Duration
+	// Linked reports whether the replication link of a replica to its master is connected. It is always true for other roles.
+	Linked bool
+}
+
+// HealthReport is the result of the HealthCheck.
+type HealthReport struct {
+	// Nodes are the health of each node sorted by their addresses.
+	Nodes []NodeHealth
+}
+
+// Healthy reports whether all the nodes are reachable and all the replicas are linked to their masters.
+// It returns false if there is no node.
+func (r HealthReport) Healthy() bool {
+	for _, n := range r.Nodes {
+		if n.Err != nil || !n.Linked {
+			return false
+		}
+	}
+	return len(r.Nodes) != 0
+}
+
+// HealthCheck sends a ROLE command to every node of the client concurrently and reports their reachability, roles
+// and whether the replicas are linked to their masters. It is suitable for the readiness and liveness probes.
+// It returns after all the nodes reply or the ctx is done, and the nodes failing to reply in time have the ctx error,
+// so the ctx should have a deadline to bound the check.
+func HealthCheck(client Client, ctx context.Context) HealthReport {
+	nodes := client.Nodes()
+	report := HealthReport{Nodes: make([]NodeHealth, 0, len(nodes))}
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	wg.Add(len(nodes))
+	for addr, node := range nodes {
+		go func(addr string, node Client) {
+			defer wg.Done()
+			health := checkNode(ctx, node)
+			health.Addr = addr
+			mu.Lock()
+			report.Nodes = append(report.Nodes, health)
+			mu.Unlock()
+		}(addr, node)
+	}
+	wg.Wait()
+	sort.Slice(report.Nodes, func(i, j int) bool { return report.Nodes[i].Addr < report.Nodes[j].Addr })
+	return report
+}
+
+func checkNode(ctx context.Context, node Client) (health NodeHealth) {
+	start := time.Now()
+	values, err := node.Do(ctx, node.B().Role().Build()).ToArray()
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Err = err
+		return health
+	}
+	if len(values) == 0 {
+		health.Err = fmt.Errorf("%w: empty ROLE reply", errParse)
+		return health
+	}
+	if health.Role, health.Err = values[0].ToString(); health.Err != nil {
+		return health
+	}
+	health.Linked = true
+	if health.Role == "slave" {
+		health.Role = "replica"
+		if len(values) < 4 {
+			health.Err = fmt.Errorf("%w: unexpected ROLE reply of a replica", errParse)
+			return health
+		}
+		state, _ := values[3].ToString()
+		health.Linked = state == "connected"
+	}
+	return health
+}
@@ -0,0 +1,139 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+func TestPing(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var sent []string
+	c := &client{
+		BFn: func() Builder {
+			return cmds.NewBuilder(cmds.NoSlot)
+		},
+		DoFn: func(ctx context.Context, cmd Completed) (resp RedisResult) {
+			sent = cmd.Commands()
+			return newResult(RedisMessage{typ: '+', string: "PONG"}, nil)
+		},
+	}
+	if err := Ping(c, context.Background()); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if !reflect.DeepEqual(sent, []string{"PING"}) {
+		t.Fatalf("unexpected command %v", sent)
+	}
+	c.DoFn = func(ctx context.Context, cmd Completed) (resp RedisResult) {
+		return newErrResult(context.DeadlineExceeded)
+	}
+	if err := Ping(c, context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	master := RedisMessage{typ: '*', values: []RedisMessage{
+		{typ: '+', string: "master"}, {typ: ':', integer: 10}, {typ: '*'},
+	}}
+	replica := func(state string) RedisMessage {
+		return RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '+', string: "slave"}, {typ: '+', string: "127.0.0.1"}, {typ: ':', integer: 6379}, {typ: '+', string: state}, {typ: ':', integer: 10},
+		}}
+	}
+	node := func(report HealthReport, addr string) NodeHealth {
+		for _, n := range report.Nodes {
+			if n.Addr == addr {
+				return n
+			}
+		}
+		t.Fatalf("node %s not found in %v", addr, report)
+		return NodeHealth{}
+	}
+	setup := func(t *testing.T, other RedisResult) Client {
+		client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				DoFn: func(cmd Completed) RedisResult {
+					switch strings.Join(cmd.Commands(), " ") {
+					case "CLUSTER SLOTS":
+						return slotsMultiResp
+					case "ROLE":
+						if dst == "127.0.1.1:1" {
+							return other
+						}
+						return newResult(master, nil)
+					}
+					return newErrResult(errors.New("unexpected call"))
+				},
+			}
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client
+	}
+
+	t.Run("Healthy", func(t *testing.T) {
+		client := setup(t, newResult(replica("connected"), nil))
+		defer client.Close()
+		report := HealthCheck(client, context.Background())
+		if !report.Healthy() || len(report.Nodes) != 4 {
+			t.Fatalf("unexpected report %v", report)
+		}
+		for i := 1; i < len(report.Nodes); i++ {
+			if report.Nodes[i-1].Addr >= report.Nodes[i].Addr {
+				t.Fatalf("nodes should be sorted by addresses %v", report)
+			}
+		}
+		if n := node(report, "127.0.0.1:0"); n.Role != "master" || !n.Linked || n.Err != nil {
+			t.Fatalf("unexpected node %v", n)
+		}
+		if n := node(report, "127.0.1.1:1"); n.Role != "replica" || !n.Linked || n.Err != nil {
+			t.Fatalf("unexpected node %v", n)
+		}
+	})
+
+	t.Run("Replication Not Linked", func(t *testing.T) {
+		client := setup(t, newResult(replica("connecting"), nil))
+		defer client.Close()
+		report := HealthCheck(client, context.Background())
+		if report.Healthy() {
+			t.Fatalf("unexpected report %v", report)
+		}
+		if n := node(report, "127.0.1.1:1"); n.Role != "replica" || n.Linked || n.Err != nil {
+			t.Fatalf("unexpected node %v", n)
+		}
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		client := setup(t, newResult(RedisMessage{typ: '-', string: "LOADING Redis is loading the dataset in memory"}, nil))
+		defer client.Close()
+		report := HealthCheck(client, context.Background())
+		if report.Healthy() {
+			t.Fatalf("unexpected report %v", report)
+		}
+		if n := node(report, "127.0.0.1:0"); n.Err != nil || n.Role != "master" {
+			t.Fatalf("the other nodes should be reported %v", n)
+		}
+		if n := node(report, "127.0.1.1:1"); n.Err == nil || n.Role != "" || n.Linked {
+			t.Fatalf("unexpected node %v", n)
+		}
+	})
+
+	t.Run("Unexpected Reply", func(t *testing.T) {
+		client := setup(t, newResult(RedisMessage{typ: '*', values: []RedisMessage{{typ: '+', string: "slave"}}}, nil))
+		defer client.Close()
+		if n := node(HealthCheck(client, context.Background()), "127.0.1.1:1"); !errors.Is(n.Err, errParse) {
+			t.Fatalf("unexpected node %v", n)
+		}
+	})
+
+	if (HealthReport{}).Healthy() {
+		t.Fatal("an empty report should not be healthy")
+	}
+}
@@ -0,0 +1,65 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrHedgeNotReadOnly is returned from DoHedged if the cmd is not a read-only command.
+var ErrHedgeNotReadOnly = errors.New("DoHedged only accepts read-only commands")
+
+type hedger interface {
+	hedge(ctx context.Context, cmd Completed) RedisResult
+}
+
+// DoHedged sends the cmd like client.Do, but if the cmd hasn't returned within the after duration,
+// it sends a duplicate and returns whichever reply comes first, and then the other one is canceled.
+// For cluster clients, the duplicate is sent to another node serving the slot of the cmd, which is a replica in the default mode.
+// For other clients, the duplicate is sent through the same client, which may use another pipeline connection.
+// Since the cmd may be sent twice, DoHedged only accepts read-only commands and returns ErrHedgeNotReadOnly for others.
+// The cmd will be pinned and will not be recycled.
+func DoHedged(client Client, ctx context.Context, cmd Completed, after time.Duration) RedisResult {
+	if !cmd.IsReadOnly() {
+		return newErrResult(ErrHedgeNotReadOnly)
+	}
+	cmd = cmd.Pin() // the cmd can't be recycled by the first reply while the duplicate is still in flight.
+
+	ctx1, cancel1 := context.WithCancel(ctx)
+	defer cancel1()
+	first := make(chan RedisResult, 1)
+	go func() { first <- client.Do(ctx1, cmd) }()
+
+	timer := time.NewTimer(after)
+	defer timer.Stop()
+	select {
+	case resp := <-first:
+		return resp
+	case <-timer.C:
+	}
+
+	ctx2, cancel2 := context.WithCancel(ctx)
+	defer cancel2()
+	second := make(chan RedisResult, 1)
+	go func() {
+		if h, ok := client.(hedger); ok {
+			second <- h.hedge(ctx2, cmd)
+		} else {
+			second <- client.Do(ctx2, cmd)
+		}
+	}()
+
+	var resp RedisResult
+	for i := 0; i < 2; i++ {
+		select {
+		case resp = <-first:
+			first = nil
+		case resp = <-second:
+			second = nil
+		}
+		if resp.NonRedisError() == nil {
+			break
+		}
+	}
+	return resp
+}
@@ -0,0 +1,140 @@
+package rueidis
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoHedged(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+
+	setup := func(t *testing.T, fn func(cmd Completed) RedisResult) *singleClient {
+		m := &mockConn{DoFn: fn}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}, DisableRetry: true}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client
+	}
+
+	t.Run("Not ReadOnly", func(t *testing.T) {
+		client := setup(t, func(cmd Completed) RedisResult {
+			t.Fatalf("unexpected call %v", cmd.Commands())
+			return RedisResult{}
+		})
+		if err := DoHedged(client, context.Background(), client.B().Set().Key("a").Value("b").Build(), time.Millisecond).Error(); err != ErrHedgeNotReadOnly {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Return Before Hedge", func(t *testing.T) {
+		var calls int32
+		client := setup(t, func(cmd Completed) RedisResult {
+			atomic.AddInt32(&calls, 1)
+			return newResult(RedisMessage{typ: '+', string: "1"}, nil)
+		})
+		if v, err := DoHedged(client, context.Background(), client.B().Get().Key("a").Build(), time.Second).ToString(); err != nil || v != "1" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if atomic.LoadInt32(&calls) != 1 {
+			t.Fatalf("unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("Hedge Wins", func(t *testing.T) {
+		var calls int32
+		release := make(chan struct{})
+		client := setup(t, func(cmd Completed) RedisResult {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-release
+				return newResult(RedisMessage{typ: '+', string: "1"}, nil)
+			}
+			return newResult(RedisMessage{typ: '+', string: "2"}, nil)
+		})
+		if v, err := DoHedged(client, context.Background(), client.B().Get().Key("a").Build(), 10*time.Millisecond).ToString(); err != nil || v != "2" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		close(release)
+	})
+
+	t.Run("Hedge Fails", func(t *testing.T) {
+		var calls int32
+		release := make(chan struct{})
+		client := setup(t, func(cmd Completed) RedisResult {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-release
+				return newResult(RedisMessage{typ: '+', string: "1"}, nil)
+			}
+			close(release)
+			return newErrResult(ErrClosing)
+		})
+		if v, err := DoHedged(client, context.Background(), client.B().Get().Key("a").Build(), 10*time.Millisecond).ToString(); err != nil || v != "1" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+	})
+}
+
+func TestClusterClientHedge(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	for _, opt := range []ClientOption{
+		{InitAddress: []string{"127.0.0.1:0"}, ReplicaOnly: true},
+		{InitAddress: []string{"127.0.0.1:0"}, SendToReplicas: func(cmd Completed) bool { return cmd.IsReadOnly() }},
+	} {
+		opt := opt
+		client, err := newClusterClient(&opt, func(dst string, opt *ClientOption) conn {
+			return &mockConn{DoFn: func(cmd Completed) RedisResult {
+				if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+					return slotsMultiResp
+				}
+				return newResult(RedisMessage{typ: '+', string: dst}, nil)
+			}}
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		cmd := client.B().Get().Key("a").Build() // slot 15495
+		if v, err := client.Do(context.Background(), cmd.Pin()).ToString(); err != nil || v != "127.0.3.1:1" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if v, err := client.hedge(context.Background(), cmd).ToString(); err != nil || v != "127.0.2.1:0" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		client.Close()
+	}
+}
+
+func TestClusterClientHedgeDefaultMode(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+		return &mockConn{
+			DoFn: func(cmd Completed) RedisResult {
+				if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+					return slotsMultiResp
+				}
+				return newResult(RedisMessage{typ: '+', string: dst}, nil)
+			},
+			DoMultiFn: func(multi ...Completed) *redisresults {
+				if len(multi) != 2 || multi[0].Commands()[0] != "READONLY" {
+					t.Errorf("the duplicate should be sent with the READONLY first %v", multi)
+				}
+				return &redisresults{s: []RedisResult{newResult(RedisMessage{typ: '+', string: "OK"}, nil), newResult(RedisMessage{typ: '+', string: dst}, nil)}}
+			},
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+	cmd := client.B().Get().Key("a").Build() // slot 15495
+	if v, err := client.Do(context.Background(), cmd.Pin()).ToString(); err != nil || v != "127.0.2.1:0" {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	if v, err := client.hedge(context.Background(), cmd).ToString(); err != nil || v != "127.0.3.1:1" {
+		t.Fatalf("the duplicate should be sent to the replica %v %v", v, err)
+	}
+}
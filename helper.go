@@ -181,6 +181,83 @@ func JsonMSet(client Client, ctx context.Context, kvs map[string]string, path st
 	return doMultiSet(client, ctx, cmds.s)
 }
 
+// DeleteByPattern is a helper that deletes all keys matching the pattern by SCAN with the MATCH and the COUNT option
+// and UNLINK of the scanned keys, or DEL if the UNLINK is not supported by the server, instead of the blocking KEYS command. The count bounds the number of keys
+// scanned per iteration and defaults to 100 if it is not positive. In cluster mode, every master node is scanned.
+// It returns the number of deleted keys, including the ones deleted before an error is encountered.
+func DeleteByPattern(client Client, ctx context.Context, match string, count int64) (deleted int64, err error) {
+	if count <= 0 {
+		count = 100
+	}
+	unlink := supports(client, "UNLINK")
+	if c, ok := client.(*clusterClient); ok {
+		for _, node := range c.masters() {
+			if deleted, err = deleteByPattern(node, ctx, match, count, deleted, true, unlink); err != nil {
+				return deleted, err
+			}
+		}
+		return deleted, nil
+	}
+	return deleteByPattern(client, ctx, match, count, deleted, false, unlink)
+}
+
+func deleteByPattern(client Client, ctx context.Context, match string, count int64, deleted int64, perKey, unlink bool) (int64, error) {
+	var cursor uint64
+	for {
+		entry, err := client.Do(ctx, client.B().Scan().Cursor(cursor).Match(match).Count(count).Build()).AsScanEntry()
+		if err != nil {
+			return deleted, err
+		}
+		if len(entry.Elements) != 0 {
+			var cmds Commands
+			if perKey { // keys scanned from a cluster node may belong to different slots
+				cmds = make(Commands, len(entry.Elements))
+				for i, k := range entry.Elements {
+					cmds[i] = deleteCmd(client, unlink, k)
+				}
+			} else {
+				cmds = Commands{deleteCmd(client, unlink, entry.Elements...)}
+			}
+			for _, resp := range client.DoMulti(ctx, cmds...) {
+				n, err := resp.AsInt64()
+				if err != nil {
+					return deleted, err
+				}
+				deleted += n
+			}
+		}
+		if cursor = entry.Cursor; cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+// DoWithTimeout is like client.Do, but the cmd is bounded by the timeout as if the ctx is wrapped by context.WithTimeout,
+// including returning context.DeadlineExceeded when the timeout is reached. The wrapping context is not allocated
+// if the ctx already has an earlier deadline or the timeout is not positive.
+func DoWithTimeout(client Client, ctx context.Context, cmd Completed, timeout time.Duration) RedisResult {
+	if timeout <= 0 {
+		if err := ctx.Err(); err != nil {
+			return newErrResult(err)
+		}
+		return newErrResult(context.DeadlineExceeded)
+	}
+	if dl, ok := ctx.Deadline(); ok && !dl.After(time.Now().Add(timeout)) {
+		return client.Do(ctx, cmd)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	resp := client.Do(ctx, cmd)
+	cancel()
+	return resp
+}
+
+func deleteCmd(client Client, unlink bool, keys ...string) Completed {
+	if unlink {
+		return client.B().Unlink().Key(keys...).Build()
+	}
+	return client.B().Del().Key(keys...).Build()
+}
+
 // DecodeSliceOfJSON is a helper that struct-scans each RedisMessage into dest, which must be a slice of pointer.
 func DecodeSliceOfJSON[T any](result RedisResult, dest *[]T) error {
 	values, err := result.ToArray()
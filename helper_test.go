@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 )
 
 //gocyclo:ignore
@@ -295,6 +296,190 @@ func TestMDel(t *testing.T) {
 	})
 }
 
+//gocyclo:ignore
+func TestDeleteByPattern(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	scan := func(cmd Completed, cursor string, next int64, keys ...string) RedisResult {
+		if !reflect.DeepEqual(cmd.Commands(), []string{"SCAN", cursor, "MATCH", "k*", "COUNT", "100"}) {
+			t.Fatalf("unexpected command %v", cmd)
+		}
+		elements := make([]RedisMessage, len(keys))
+		for i, k := range keys {
+			elements[i] = RedisMessage{typ: '+', string: k}
+		}
+		return newResult(RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '+', string: strconv.FormatInt(next, 10)},
+			{typ: '*', values: elements},
+		}}, nil)
+	}
+	t.Run("single client", func(t *testing.T) {
+		m := &mockConn{}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		t.Run("Delegate Do", func(t *testing.T) {
+			m.DoFn = func(cmd Completed) RedisResult {
+				if cmd.Commands()[1] == "0" {
+					return scan(cmd, "0", 1, "k1", "k2")
+				}
+				return scan(cmd, "1", 0)
+			}
+			m.DoMultiFn = func(multi ...Completed) *redisresults {
+				if len(multi) != 1 || !reflect.DeepEqual(multi[0].Commands(), []string{"UNLINK", "k1", "k2"}) {
+					t.Fatalf("unexpected command %v", multi)
+				}
+				return &redisresults{s: []RedisResult{newResult(RedisMessage{typ: ':', integer: 2}, nil)}}
+			}
+			if n, err := DeleteByPattern(client, context.Background(), "k*", 0); err != nil || n != 2 {
+				t.Fatalf("unexpected response %v %v", n, err)
+			}
+		})
+		t.Run("Delegate Do DEL", func(t *testing.T) {
+			m.InfoFn = func() map[string]RedisMessage {
+				return map[string]RedisMessage{"version": {typ: '+', string: "3.2.0"}}
+			}
+			defer func() { m.InfoFn = nil }()
+			m.DoFn = func(cmd Completed) RedisResult {
+				return scan(cmd, "0", 0, "k1")
+			}
+			m.DoMultiFn = func(multi ...Completed) *redisresults {
+				if len(multi) != 1 || !reflect.DeepEqual(multi[0].Commands(), []string{"DEL", "k1"}) {
+					t.Fatalf("unexpected command %v", multi)
+				}
+				return &redisresults{s: []RedisResult{newResult(RedisMessage{typ: ':', integer: 1}, nil)}}
+			}
+			if n, err := DeleteByPattern(client, context.Background(), "k*", 0); err != nil || n != 1 {
+				t.Fatalf("unexpected response %v %v", n, err)
+			}
+		})
+		t.Run("Delegate Do Err", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			m.DoFn = func(cmd Completed) RedisResult {
+				return scan(cmd, "0", 1, "k1")
+			}
+			m.DoMultiFn = func(multi ...Completed) *redisresults {
+				return &redisresults{s: []RedisResult{newErrResult(context.Canceled)}}
+			}
+			if n, err := DeleteByPattern(client, ctx, "k*", 100); err != context.Canceled || n != 0 {
+				t.Fatalf("unexpected response %v %v", n, err)
+			}
+		})
+	})
+	t.Run("cluster client", func(t *testing.T) {
+		nodes := make(map[string]*mockConn)
+		client, err := newClusterClient(&ClientOption{InitAddress: []string{":0"}}, func(dst string, opt *ClientOption) conn {
+			nodes[dst] = &mockConn{
+				DoFn: func(cmd Completed) RedisResult {
+					return slotsResp
+				},
+			}
+			return nodes[dst]
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		for addr, m := range nodes {
+			if addr != "127.0.0.1:0" {
+				m.DoFn = func(cmd Completed) RedisResult {
+					t.Fatalf("unexpected command %v on %v", cmd, addr)
+					return RedisResult{}
+				}
+			}
+		}
+		m := nodes["127.0.0.1:0"]
+		t.Run("Delegate Do", func(t *testing.T) {
+			m.DoFn = func(cmd Completed) RedisResult {
+				return scan(cmd, "0", 0, "k1", "k2")
+			}
+			m.DoMultiFn = func(multi ...Completed) *redisresults {
+				if len(multi) != 2 ||
+					!reflect.DeepEqual(multi[0].Commands(), []string{"UNLINK", "k1"}) ||
+					!reflect.DeepEqual(multi[1].Commands(), []string{"UNLINK", "k2"}) {
+					t.Fatalf("unexpected command %v", multi)
+				}
+				return &redisresults{s: []RedisResult{
+					newResult(RedisMessage{typ: ':', integer: 1}, nil),
+					newResult(RedisMessage{typ: ':', integer: 0}, nil),
+				}}
+			}
+			if n, err := DeleteByPattern(client, context.Background(), "k*", 100); err != nil || n != 1 {
+				t.Fatalf("unexpected response %v %v", n, err)
+			}
+		})
+		t.Run("Delegate Do Err", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			m.DoFn = func(cmd Completed) RedisResult {
+				return newErrResult(context.Canceled)
+			}
+			if n, err := DeleteByPattern(client, ctx, "k*", 100); err != context.Canceled || n != 0 {
+				t.Fatalf("unexpected response %v %v", n, err)
+			}
+		})
+	})
+}
+
+type deadlineClient struct {
+	Client
+	deadlines []time.Time
+}
+
+func (c *deadlineClient) Do(ctx context.Context, cmd Completed) RedisResult {
+	dl, _ := ctx.Deadline()
+	c.deadlines = append(c.deadlines, dl)
+	return c.Client.Do(ctx, cmd)
+}
+
+func TestDoWithTimeout(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	m := &mockConn{
+		DoFn: func(cmd Completed) RedisResult {
+			return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+		},
+	}
+	c, err := newSingleClient(&ClientOption{InitAddress: []string{""}, DisableRetry: true}, m, func(dst string, opt *ClientOption) conn {
+		return m
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	client := &deadlineClient{Client: c}
+	t.Run("Timeout", func(t *testing.T) {
+		start := time.Now()
+		if v, err := DoWithTimeout(client, context.Background(), client.B().Get().Key("a").Build(), time.Second).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if dl := client.deadlines[len(client.deadlines)-1]; dl.Before(start.Add(time.Second)) || dl.After(time.Now().Add(time.Second)) {
+			t.Fatalf("unexpected deadline %v", dl)
+		}
+	})
+	t.Run("Earlier Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		expected, _ := ctx.Deadline()
+		if v, err := DoWithTimeout(client, ctx, client.B().Get().Key("a").Build(), time.Second).ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if dl := client.deadlines[len(client.deadlines)-1]; !dl.Equal(expected) {
+			t.Fatalf("unexpected deadline %v", dl)
+		}
+	})
+	t.Run("Non-Positive Timeout", func(t *testing.T) {
+		if err := DoWithTimeout(client, context.Background(), client.B().Get().Key("a").Build(), 0).Error(); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected err %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := DoWithTimeout(client, ctx, client.B().Get().Key("a").Build(), 0).Error(); err != context.Canceled {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
+
 func TestMSet(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	t.Run("single client", func(t *testing.T) {
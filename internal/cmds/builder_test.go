@@ -156,3 +156,27 @@ func TestVerify(t *testing.T) {
 	}()
 	cmd1.cs.Verify()
 }
+
+func BenchmarkBuilder(b *testing.B) {
+	builder := NewBuilder(NoSlot)
+	b.Run("Recycled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			PutCompleted(builder.Set().Key("k").Value("v").Build())
+		}
+	})
+	b.Run("NotRecycled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder.Set().Key("k").Value("v").Build().Pin()
+		}
+	})
+	b.Run("Pinned", func(b *testing.B) {
+		b.ReportAllocs()
+		cmd := builder.Set().Key("k").Value("v").Build().Pin()
+		for i := 0; i < b.N; i++ {
+			PutCompleted(cmd)
+		}
+		PutCompleted(cmd.Unpin())
+	})
+}
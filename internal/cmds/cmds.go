@@ -66,6 +66,10 @@ var (
 	AskingCmd = Completed{
 		cs: newCommandSlice([]string{"ASKING"}),
 	}
+	// ReadOnlyCmd is predefined READONLY
+	ReadOnlyCmd = Completed{
+		cs: newCommandSlice([]string{"READONLY"}),
+	}
 	// SentinelSubscribe is predefined SUBSCRIBE ASKING
 	SentinelSubscribe = Completed{
 		cs: newCommandSlice([]string{"SUBSCRIBE", "+sentinel", "+slave", "-sdown", "+sdown", "+switch-master", "+reboot"}),
@@ -103,6 +107,13 @@ func (c Completed) Pin() Completed {
 	return c
 }
 
+// Unpin allows a pinned Completed to be recycled again by the next call it is passed to.
+// It must be called only when the Completed is not in flight, and the Completed must not be used after the next call.
+func (c Completed) Unpin() Completed {
+	c.cs.r = 0
+	return c
+}
+
 // IsEmpty checks if it is an empty command.
 func (c *Completed) IsEmpty() bool {
 	return c.cs == nil || len(c.cs.s) == 0
@@ -165,6 +176,13 @@ func (c Cacheable) Pin() Cacheable {
 	return c
 }
 
+// Unpin allows a pinned Cacheable to be recycled again by the next call it is passed to.
+// It must be called only when the Cacheable is not in flight, and the Cacheable must not be used after the next call.
+func (c Cacheable) Unpin() Cacheable {
+	c.cs.r = 0
+	return c
+}
+
 // Slot returns the command key slot
 func (c *Cacheable) Slot() uint16 {
 	return c.ks
@@ -314,6 +314,17 @@ func TestCmdPin(t *testing.T) {
 	}
 }
 
+func TestCmdUnpin(t *testing.T) {
+	c1 := NewMGetCompleted([]string{"MGET", "K"}).Pin()
+	if c1.Unpin(); c1.cs.r != 0 {
+		t.Fail()
+	}
+	cc := Cacheable(NewMGetCompleted([]string{"MGET", "K"})).Pin()
+	if cc.Unpin(); cc.cs.r != 0 {
+		t.Fail()
+	}
+}
+
 func TestCompletedCS(t *testing.T) {
 	if c1 := NewMGetCompleted([]string{"MGET", "K"}); CompletedCS(c1) != c1.cs {
 		t.Fail()
@@ -325,3 +336,191 @@ func TestCacheableCS(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestCopyAndObjectArgs(t *testing.T) {
+	b := NewBuilder(NoSlot)
+	for _, c := range []struct {
+		cmd  Completed
+		args []string
+	}{
+		{cmd: b.Copy().Source("s").Destination("d").Build(), args: []string{"COPY", "s", "d"}},
+		{cmd: b.Copy().Source("s").Destination("d").Db(1).Build(), args: []string{"COPY", "s", "d", "DB", "1"}},
+		{cmd: b.Copy().Source("s").Destination("d").Replace().Build(), args: []string{"COPY", "s", "d", "REPLACE"}},
+		{cmd: b.Copy().Source("s").Destination("d").Db(1).Replace().Build(), args: []string{"COPY", "s", "d", "DB", "1", "REPLACE"}},
+		{cmd: b.ObjectEncoding().Key("k").Build(), args: []string{"OBJECT", "ENCODING", "k"}},
+		{cmd: b.ObjectFreq().Key("k").Build(), args: []string{"OBJECT", "FREQ", "k"}},
+		{cmd: b.ObjectIdletime().Key("k").Build(), args: []string{"OBJECT", "IDLETIME", "k"}},
+	} {
+		if !reflect.DeepEqual(c.cmd.Commands(), c.args) {
+			t.Fatalf("unexpected args %v, expected %v", c.cmd.Commands(), c.args)
+		}
+	}
+	for _, cmd := range []Completed{b.ObjectEncoding().Key("k").Build(), b.ObjectFreq().Key("k").Build(), b.ObjectIdletime().Key("k").Build()} {
+		if !cmd.IsReadOnly() {
+			t.Fatalf("%v should be read-only", cmd.Commands())
+		}
+	}
+}
+
+func TestMPopAndIntercardArgs(t *testing.T) {
+	b := NewBuilder(NoSlot)
+	for _, c := range []struct {
+		cmd   Completed
+		args  []string
+		block bool
+	}{
+		{cmd: b.Sintercard().Numkeys(2).Key("a", "b").Build(), args: []string{"SINTERCARD", "2", "a", "b"}},
+		{cmd: b.Sintercard().Numkeys(2).Key("a", "b").Limit(5).Build(), args: []string{"SINTERCARD", "2", "a", "b", "LIMIT", "5"}},
+		{cmd: b.Lmpop().Numkeys(2).Key("a", "b").Left().Build(), args: []string{"LMPOP", "2", "a", "b", "LEFT"}},
+		{cmd: b.Lmpop().Numkeys(2).Key("a", "b").Right().Count(3).Build(), args: []string{"LMPOP", "2", "a", "b", "RIGHT", "COUNT", "3"}},
+		{cmd: b.Blmpop().Timeout(1.5).Numkeys(2).Key("a", "b").Left().Count(3).Build(), args: []string{"BLMPOP", "1.5", "2", "a", "b", "LEFT", "COUNT", "3"}, block: true},
+		{cmd: b.Zmpop().Numkeys(2).Key("a", "b").Min().Build(), args: []string{"ZMPOP", "2", "a", "b", "MIN"}},
+		{cmd: b.Zmpop().Numkeys(2).Key("a", "b").Max().Count(3).Build(), args: []string{"ZMPOP", "2", "a", "b", "MAX", "COUNT", "3"}},
+		{cmd: b.Bzmpop().Timeout(0).Numkeys(2).Key("a", "b").Max().Build(), args: []string{"BZMPOP", "0", "2", "a", "b", "MAX"}, block: true},
+	} {
+		if !reflect.DeepEqual(c.cmd.Commands(), c.args) {
+			t.Fatalf("unexpected args %v, expected %v", c.cmd.Commands(), c.args)
+		}
+		if c.cmd.IsBlock() != c.block {
+			t.Fatalf("unexpected block %v of %v", c.cmd.IsBlock(), c.args)
+		}
+	}
+}
+
+func TestHashFieldExpirationArgs(t *testing.T) {
+	b := NewBuilder(NoSlot)
+	for _, c := range []struct {
+		cmd      Completed
+		args     []string
+		readonly bool
+	}{
+		{cmd: b.Hexpire().Key("k").Seconds(10).Fields().Numfields(2).Field("f1", "f2").Build(), args: []string{"HEXPIRE", "k", "10", "FIELDS", "2", "f1", "f2"}},
+		{cmd: b.Hexpire().Key("k").Seconds(10).Nx().Fields().Numfields(2).Field("f1").Field("f2").Build(), args: []string{"HEXPIRE", "k", "10", "NX", "FIELDS", "2", "f1", "f2"}},
+		{cmd: b.Hpexpire().Key("k").Milliseconds(100).Gt().Fields().Numfields(1).Field("f1").Build(), args: []string{"HPEXPIRE", "k", "100", "GT", "FIELDS", "1", "f1"}},
+		{cmd: b.Hexpireat().Key("k").UnixTimeSeconds(1).Xx().Fields().Numfields(1).Field("f1").Build(), args: []string{"HEXPIREAT", "k", "1", "XX", "FIELDS", "1", "f1"}},
+		{cmd: b.Hpexpireat().Key("k").UnixTimeMilliseconds(1).Lt().Fields().Numfields(1).Field("f1").Build(), args: []string{"HPEXPIREAT", "k", "1", "LT", "FIELDS", "1", "f1"}},
+		{cmd: b.Hpersist().Key("k").Fields().Numfields(2).Field("f2", "f1").Build(), args: []string{"HPERSIST", "k", "FIELDS", "2", "f2", "f1"}},
+		{cmd: b.Httl().Key("k").Fields().Numfields(2).Field("f1", "f2").Build(), args: []string{"HTTL", "k", "FIELDS", "2", "f1", "f2"}, readonly: true},
+		{cmd: b.Hpttl().Key("k").Fields().Numfields(1).Field("f1").Build(), args: []string{"HPTTL", "k", "FIELDS", "1", "f1"}, readonly: true},
+		{cmd: b.Hexpiretime().Key("k").Fields().Numfields(1).Field("f1").Build(), args: []string{"HEXPIRETIME", "k", "FIELDS", "1", "f1"}, readonly: true},
+		{cmd: b.Hpexpiretime().Key("k").Fields().Numfields(1).Field("f1").Build(), args: []string{"HPEXPIRETIME", "k", "FIELDS", "1", "f1"}, readonly: true},
+	} {
+		if !reflect.DeepEqual(c.cmd.Commands(), c.args) {
+			t.Fatalf("unexpected args %v, expected %v", c.cmd.Commands(), c.args)
+		}
+		if c.cmd.IsReadOnly() != c.readonly {
+			t.Fatalf("unexpected readonly %v of %v", c.cmd.IsReadOnly(), c.args)
+		}
+	}
+	if cmd := b.Httl().Key("k").Fields().Numfields(1).Field("f1").Cache(); !reflect.DeepEqual(cmd.Commands(), []string{"HTTL", "k", "FIELDS", "1", "f1"}) {
+		t.Fatalf("unexpected args %v", cmd.Commands())
+	}
+}
+
+func TestBitRangeArgs(t *testing.T) {
+	b := NewBuilder(NoSlot)
+	for _, c := range []struct {
+		cmd  Completed
+		args []string
+	}{
+		{cmd: b.Bitcount().Key("k").Build(), args: []string{"BITCOUNT", "k"}},
+		{cmd: b.Bitcount().Key("k").Start(0).End(-1).Build(), args: []string{"BITCOUNT", "k", "0", "-1"}},
+		{cmd: b.Bitcount().Key("k").Start(1).End(3).Byte().Build(), args: []string{"BITCOUNT", "k", "1", "3", "BYTE"}},
+		{cmd: b.Bitcount().Key("k").Start(5).End(30).Bit().Build(), args: []string{"BITCOUNT", "k", "5", "30", "BIT"}},
+		{cmd: b.Bitpos().Key("k").Bit(1).Build(), args: []string{"BITPOS", "k", "1"}},
+		{cmd: b.Bitpos().Key("k").Bit(0).Start(2).Build(), args: []string{"BITPOS", "k", "0", "2"}},
+		{cmd: b.Bitpos().Key("k").Bit(1).Start(0).End(-1).Build(), args: []string{"BITPOS", "k", "1", "0", "-1"}},
+		{cmd: b.Bitpos().Key("k").Bit(1).Start(1).End(3).Byte().Build(), args: []string{"BITPOS", "k", "1", "1", "3", "BYTE"}},
+		{cmd: b.Bitpos().Key("k").Bit(0).Start(7).End(15).Bit().Build(), args: []string{"BITPOS", "k", "0", "7", "15", "BIT"}},
+	} {
+		if !reflect.DeepEqual(c.cmd.Commands(), c.args) {
+			t.Fatalf("unexpected args %v, expected %v", c.cmd.Commands(), c.args)
+		}
+		if !c.cmd.IsReadOnly() {
+			t.Fatalf("%v should be readonly", c.args)
+		}
+	}
+	if cmd := b.Bitcount().Key("k").Start(5).End(30).Bit().Cache(); !reflect.DeepEqual(cmd.Commands(), []string{"BITCOUNT", "k", "5", "30", "BIT"}) {
+		t.Fatalf("unexpected args %v", cmd.Commands())
+	}
+	if cmd := b.Bitpos().Key("k").Bit(1).Start(1).End(3).Byte().Cache(); !reflect.DeepEqual(cmd.Commands(), []string{"BITPOS", "k", "1", "1", "3", "BYTE"}) {
+		t.Fatalf("unexpected args %v", cmd.Commands())
+	}
+}
+
+func TestXaddTrimArgs(t *testing.T) {
+	b := NewBuilder(NoSlot)
+	for _, c := range []struct {
+		cmd  Completed
+		args []string
+	}{
+		{cmd: b.Xadd().Key("s").Nomkstream().Id("*").FieldValue().FieldValue("f", "v").Build(), args: []string{"XADD", "s", "NOMKSTREAM", "*", "f", "v"}},
+		{cmd: b.Xadd().Key("s").Maxlen().Threshold("10").Id("*").FieldValue().FieldValue("f", "v").Build(), args: []string{"XADD", "s", "MAXLEN", "10", "*", "f", "v"}},
+		{cmd: b.Xadd().Key("s").Maxlen().Exact().Threshold("10").Id("*").FieldValue().FieldValue("f", "v").Build(), args: []string{"XADD", "s", "MAXLEN", "=", "10", "*", "f", "v"}},
+		{cmd: b.Xadd().Key("s").Maxlen().Almost().Threshold("1000").Limit(100).Id("*").FieldValue().FieldValue("f", "v").Build(), args: []string{"XADD", "s", "MAXLEN", "~", "1000", "LIMIT", "100", "*", "f", "v"}},
+		{cmd: b.Xadd().Key("s").Minid().Almost().Threshold("0-1").Id("1-1").FieldValue().FieldValue("f", "v").FieldValue("g", "w").Build(), args: []string{"XADD", "s", "MINID", "~", "0-1", "1-1", "f", "v", "g", "w"}},
+		{cmd: b.Xadd().Key("s").Nomkstream().Maxlen().Almost().Threshold("1000").Limit(100).Id("*").FieldValue().FieldValue("f", "v").Build(), args: []string{"XADD", "s", "NOMKSTREAM", "MAXLEN", "~", "1000", "LIMIT", "100", "*", "f", "v"}},
+		{cmd: b.Xadd().Key("s").Nomkstream().Minid().Exact().Threshold("0-1").Id("*").FieldValue().FieldValue("f", "v").Build(), args: []string{"XADD", "s", "NOMKSTREAM", "MINID", "=", "0-1", "*", "f", "v"}},
+	} {
+		if !reflect.DeepEqual(c.cmd.Commands(), c.args) {
+			t.Fatalf("unexpected args %v, expected %v", c.cmd.Commands(), c.args)
+		}
+		if c.cmd.IsReadOnly() {
+			t.Fatalf("%v should not be readonly", c.args)
+		}
+	}
+	if cmd := NewBuilder(InitSlot).Xadd().Key("s").Nomkstream().Id("*").FieldValue().FieldValue("f", "v").Build(); cmd.Slot() != slot("s") {
+		t.Fatalf("unexpected slot %v", cmd.Slot())
+	}
+}
+
+func TestZrangeUnifiedArgs(t *testing.T) {
+	b := NewBuilder(NoSlot)
+	for _, c := range []struct {
+		cmd  Completed
+		args []string
+	}{
+		{cmd: b.Zrange().Key("z").Min("(1").Max("5").Byscore().Build(), args: []string{"ZRANGE", "z", "(1", "5", "BYSCORE"}},
+		{cmd: b.Zrange().Key("z").Min("(1").Max("(5").Byscore().Limit(0, 10).Withscores().Build(), args: []string{"ZRANGE", "z", "(1", "(5", "BYSCORE", "LIMIT", "0", "10", "WITHSCORES"}},
+		{cmd: b.Zrange().Key("z").Min("-inf").Max("+inf").Byscore().Withscores().Build(), args: []string{"ZRANGE", "z", "-inf", "+inf", "BYSCORE", "WITHSCORES"}},
+		{cmd: b.Zrange().Key("z").Min("[a").Max("(c").Bylex().Limit(1, 2).Build(), args: []string{"ZRANGE", "z", "[a", "(c", "BYLEX", "LIMIT", "1", "2"}},
+		{cmd: b.Zrange().Key("z").Min("0").Max("-1").Rev().Withscores().Build(), args: []string{"ZRANGE", "z", "0", "-1", "REV", "WITHSCORES"}},
+		// with the REV, the Min is the higher bound and the Max is the lower one, like the ZREVRANGEBYSCORE.
+		{cmd: b.Zrange().Key("z").Min("(5").Max("1").Byscore().Rev().Limit(2, 3).Build(), args: []string{"ZRANGE", "z", "(5", "1", "BYSCORE", "REV", "LIMIT", "2", "3"}},
+		{cmd: b.Zrange().Key("z").Min("(5").Max("(1").Byscore().Rev().Limit(0, -1).Withscores().Build(), args: []string{"ZRANGE", "z", "(5", "(1", "BYSCORE", "REV", "LIMIT", "0", "-1", "WITHSCORES"}},
+		{cmd: b.Zrange().Key("z").Min("+").Max("[b").Bylex().Rev().Limit(0, 5).Build(), args: []string{"ZRANGE", "z", "+", "[b", "BYLEX", "REV", "LIMIT", "0", "5"}},
+	} {
+		if !reflect.DeepEqual(c.cmd.Commands(), c.args) {
+			t.Fatalf("unexpected args %v, expected %v", c.cmd.Commands(), c.args)
+		}
+		if !c.cmd.IsReadOnly() {
+			t.Fatalf("%v should be readonly", c.args)
+		}
+	}
+	if cmd := b.Zrange().Key("z").Min("(1").Max("(5").Byscore().Rev().Limit(0, 10).Withscores().Cache(); !reflect.DeepEqual(cmd.Commands(), []string{"ZRANGE", "z", "(1", "(5", "BYSCORE", "REV", "LIMIT", "0", "10", "WITHSCORES"}) {
+		t.Fatalf("unexpected args %v", cmd.Commands())
+	}
+}
+
+func TestZaddConditionArgs(t *testing.T) {
+	b := NewBuilder(NoSlot)
+	for _, c := range []struct {
+		cmd  Completed
+		args []string
+	}{
+		{cmd: b.Zadd().Key("z").Nx().ScoreMember().ScoreMember(1, "m").Build(), args: []string{"ZADD", "z", "NX", "1", "m"}},
+		{cmd: b.Zadd().Key("z").Nx().Ch().ScoreMember().ScoreMember(1, "m").Build(), args: []string{"ZADD", "z", "NX", "CH", "1", "m"}},
+		{cmd: b.Zadd().Key("z").Nx().Incr().ScoreMember().ScoreMember(1, "m").Build(), args: []string{"ZADD", "z", "NX", "INCR", "1", "m"}},
+		{cmd: b.Zadd().Key("z").Xx().Gt().ScoreMember().ScoreMember(1, "m").Build(), args: []string{"ZADD", "z", "XX", "GT", "1", "m"}},
+		{cmd: b.Zadd().Key("z").Xx().Lt().Ch().ScoreMember().ScoreMember(1, "m").Build(), args: []string{"ZADD", "z", "XX", "LT", "CH", "1", "m"}},
+		{cmd: b.Zadd().Key("z").Xx().Gt().Ch().Incr().ScoreMember().ScoreMember(1, "m").Build(), args: []string{"ZADD", "z", "XX", "GT", "CH", "INCR", "1", "m"}},
+		{cmd: b.Zadd().Key("z").Gt().Ch().ScoreMember().ScoreMember(1, "m").ScoreMember(2, "n").Build(), args: []string{"ZADD", "z", "GT", "CH", "1", "m", "2", "n"}},
+	} {
+		if !reflect.DeepEqual(c.cmd.Commands(), c.args) {
+			t.Fatalf("unexpected args %v, expected %v", c.cmd.Commands(), c.args)
+		}
+		if c.cmd.IsReadOnly() {
+			t.Fatalf("%v should not be readonly", c.args)
+		}
+	}
+}
@@ -293,7 +293,7 @@ func (c HexpireatUnixTimeSeconds) Fields() HexpireatFields {
 type Hexpiretime Incomplete
 
 func (b Builder) Hexpiretime() (c Hexpiretime) {
-	c = Hexpiretime{cs: get(), ks: b.ks}
+	c = Hexpiretime{cs: get(), ks: b.ks, cf: int16(readonly)}
 	c.cs.s = append(c.cs.s, "HEXPIRETIME")
 	return c
 }
@@ -320,6 +320,11 @@ func (c HexpiretimeField) Build() Completed {
 	return Completed{cs: c.cs, cf: uint16(c.cf), ks: c.ks}
 }
 
+func (c HexpiretimeField) Cache() Cacheable {
+	c.cs.Build()
+	return Cacheable{cs: c.cs, cf: uint16(c.cf), ks: c.ks}
+}
+
 type HexpiretimeFields Incomplete
 
 func (c HexpiretimeFields) Numfields(numfields int64) HexpiretimeNumfields {
@@ -890,7 +895,7 @@ func (c HpexpireatUnixTimeMilliseconds) Fields() HpexpireatFields {
 type Hpexpiretime Incomplete
 
 func (b Builder) Hpexpiretime() (c Hpexpiretime) {
-	c = Hpexpiretime{cs: get(), ks: b.ks}
+	c = Hpexpiretime{cs: get(), ks: b.ks, cf: int16(readonly)}
 	c.cs.s = append(c.cs.s, "HPEXPIRETIME")
 	return c
 }
@@ -917,6 +922,11 @@ func (c HpexpiretimeField) Build() Completed {
 	return Completed{cs: c.cs, cf: uint16(c.cf), ks: c.ks}
 }
 
+func (c HpexpiretimeField) Cache() Cacheable {
+	c.cs.Build()
+	return Cacheable{cs: c.cs, cf: uint16(c.cf), ks: c.ks}
+}
+
 type HpexpiretimeFields Incomplete
 
 func (c HpexpiretimeFields) Numfields(numfields int64) HpexpiretimeNumfields {
@@ -941,7 +951,7 @@ func (c HpexpiretimeNumfields) Field(field ...string) HpexpiretimeField {
 type Hpttl Incomplete
 
 func (b Builder) Hpttl() (c Hpttl) {
-	c = Hpttl{cs: get(), ks: b.ks}
+	c = Hpttl{cs: get(), ks: b.ks, cf: int16(readonly)}
 	c.cs.s = append(c.cs.s, "HPTTL")
 	return c
 }
@@ -968,6 +978,11 @@ func (c HpttlField) Build() Completed {
 	return Completed{cs: c.cs, cf: uint16(c.cf), ks: c.ks}
 }
 
+func (c HpttlField) Cache() Cacheable {
+	c.cs.Build()
+	return Cacheable{cs: c.cs, cf: uint16(c.cf), ks: c.ks}
+}
+
 type HpttlFields Incomplete
 
 func (c HpttlFields) Numfields(numfields int64) HpttlNumfields {
@@ -1236,7 +1251,7 @@ func (c HstrlenKey) Field(field string) HstrlenField {
 type Httl Incomplete
 
 func (b Builder) Httl() (c Httl) {
-	c = Httl{cs: get(), ks: b.ks}
+	c = Httl{cs: get(), ks: b.ks, cf: int16(readonly)}
 	c.cs.s = append(c.cs.s, "HTTL")
 	return c
 }
@@ -1263,6 +1278,11 @@ func (c HttlField) Build() Completed {
 	return Completed{cs: c.cs, cf: uint16(c.cf), ks: c.ks}
 }
 
+func (c HttlField) Cache() Cacheable {
+	c.cs.Build()
+	return Cacheable{cs: c.cs, cf: uint16(c.cf), ks: c.ks}
+}
+
 type HttlFields Incomplete
 
 func (c HttlFields) Numfields(numfields int64) HttlNumfields {
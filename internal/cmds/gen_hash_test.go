@@ -19,6 +19,7 @@ func hash0(s Builder) {
 	s.Hexpireat().Key("1").UnixTimeSeconds(1).Lt().Fields().Numfields(1).Field("1").Field("1").Build()
 	s.Hexpireat().Key("1").UnixTimeSeconds(1).Fields().Numfields(1).Field("1").Field("1").Build()
 	s.Hexpiretime().Key("1").Fields().Numfields(1).Field("1").Field("1").Build()
+	s.Hexpiretime().Key("1").Fields().Numfields(1).Field("1").Field("1").Cache()
 	s.Hget().Key("1").Field("1").Build()
 	s.Hget().Key("1").Field("1").Cache()
 	s.Hgetall().Key("1").Build()
@@ -44,7 +45,9 @@ func hash0(s Builder) {
 	s.Hpexpireat().Key("1").UnixTimeMilliseconds(1).Lt().Fields().Numfields(1).Field("1").Field("1").Build()
 	s.Hpexpireat().Key("1").UnixTimeMilliseconds(1).Fields().Numfields(1).Field("1").Field("1").Build()
 	s.Hpexpiretime().Key("1").Fields().Numfields(1).Field("1").Field("1").Build()
+	s.Hpexpiretime().Key("1").Fields().Numfields(1).Field("1").Field("1").Cache()
 	s.Hpttl().Key("1").Fields().Numfields(1).Field("1").Field("1").Build()
+	s.Hpttl().Key("1").Fields().Numfields(1).Field("1").Field("1").Cache()
 	s.Hrandfield().Key("1").Count(1).Withvalues().Build()
 	s.Hrandfield().Key("1").Count(1).Build()
 	s.Hrandfield().Key("1").Build()
@@ -60,6 +63,7 @@ func hash0(s Builder) {
 	s.Hstrlen().Key("1").Field("1").Build()
 	s.Hstrlen().Key("1").Field("1").Cache()
 	s.Httl().Key("1").Fields().Numfields(1).Field("1").Field("1").Build()
+	s.Httl().Key("1").Fields().Numfields(1).Field("1").Field("1").Cache()
 	s.Hvals().Key("1").Build()
 	s.Hvals().Key("1").Cache()
 }
@@ -266,16 +266,6 @@ func (c ZaddComparisonLt) ScoreMember() ZaddScoreMember {
 
 type ZaddConditionNx Incomplete
 
-func (c ZaddConditionNx) Gt() ZaddComparisonGt {
-	c.cs.s = append(c.cs.s, "GT")
-	return (ZaddComparisonGt)(c)
-}
-
-func (c ZaddConditionNx) Lt() ZaddComparisonLt {
-	c.cs.s = append(c.cs.s, "LT")
-	return (ZaddComparisonLt)(c)
-}
-
 func (c ZaddConditionNx) Ch() ZaddChangeCh {
 	c.cs.s = append(c.cs.s, "CH")
 	return (ZaddChangeCh)(c)
@@ -1444,6 +1434,7 @@ func (c ZrangeWithscores) Cache() Cacheable {
 
 type Zrangebylex Incomplete
 
+// Deprecated: since redis 6.2.0, use Zrange().Key(key).Min(min).Max(max).Bylex() instead.
 func (b Builder) Zrangebylex() (c Zrangebylex) {
 	c = Zrangebylex{cs: get(), ks: b.ks, cf: int16(readonly)}
 	c.cs.s = append(c.cs.s, "ZRANGEBYLEX")
@@ -1505,6 +1496,7 @@ func (c ZrangebylexMin) Max(max string) ZrangebylexMax {
 
 type Zrangebyscore Incomplete
 
+// Deprecated: since redis 6.2.0, use Zrange().Key(key).Min(min).Max(max).Byscore() instead.
 func (b Builder) Zrangebyscore() (c Zrangebyscore) {
 	c = Zrangebyscore{cs: get(), ks: b.ks, cf: int16(readonly)}
 	c.cs.s = append(c.cs.s, "ZRANGEBYSCORE")
@@ -1920,6 +1912,7 @@ func (c ZremrangebyscoreMin) Max(max string) ZremrangebyscoreMax {
 
 type Zrevrange Incomplete
 
+// Deprecated: since redis 6.2.0, use Zrange().Key(key).Min(start).Max(stop).Rev() instead.
 func (b Builder) Zrevrange() (c Zrevrange) {
 	c = Zrevrange{cs: get(), ks: b.ks, cf: int16(readonly)}
 	c.cs.s = append(c.cs.s, "ZREVRANGE")
@@ -1981,6 +1974,7 @@ func (c ZrevrangeWithscores) Cache() Cacheable {
 
 type Zrevrangebylex Incomplete
 
+// Deprecated: since redis 6.2.0, use Zrange().Key(key).Min(max).Max(min).Bylex().Rev() instead.
 func (b Builder) Zrevrangebylex() (c Zrevrangebylex) {
 	c = Zrevrangebylex{cs: get(), ks: b.ks, cf: int16(readonly)}
 	c.cs.s = append(c.cs.s, "ZREVRANGEBYLEX")
@@ -2042,6 +2036,7 @@ func (c ZrevrangebylexMin) Cache() Cacheable {
 
 type Zrevrangebyscore Incomplete
 
+// Deprecated: since redis 6.2.0, use Zrange().Key(key).Min(max).Max(min).Byscore().Rev() instead.
 func (b Builder) Zrevrangebyscore() (c Zrevrangebyscore) {
 	c = Zrevrangebyscore{cs: get(), ks: b.ks, cf: int16(readonly)}
 	c.cs.s = append(c.cs.s, "ZREVRANGEBYSCORE")
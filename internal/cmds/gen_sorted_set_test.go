@@ -11,18 +11,16 @@ func sorted_set0(s Builder) {
 	s.Bzmpop().Timeout(1).Numkeys(1).Key("1").Key("1").Max().Build()
 	s.Bzpopmax().Key("1").Key("1").Timeout(1).Build()
 	s.Bzpopmin().Key("1").Key("1").Timeout(1).Build()
-	s.Zadd().Key("1").Nx().Gt().Ch().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
-	s.Zadd().Key("1").Nx().Gt().Ch().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
-	s.Zadd().Key("1").Nx().Gt().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
-	s.Zadd().Key("1").Nx().Gt().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
-	s.Zadd().Key("1").Nx().Lt().Ch().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
-	s.Zadd().Key("1").Nx().Lt().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
-	s.Zadd().Key("1").Nx().Lt().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
 	s.Zadd().Key("1").Nx().Ch().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
+	s.Zadd().Key("1").Nx().Ch().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
 	s.Zadd().Key("1").Nx().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
 	s.Zadd().Key("1").Nx().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
 	s.Zadd().Key("1").Xx().Gt().Ch().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
+	s.Zadd().Key("1").Xx().Gt().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
+	s.Zadd().Key("1").Xx().Gt().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
 	s.Zadd().Key("1").Xx().Lt().Ch().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
+	s.Zadd().Key("1").Xx().Lt().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
+	s.Zadd().Key("1").Xx().Lt().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
 	s.Zadd().Key("1").Xx().Ch().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
 	s.Zadd().Key("1").Xx().Incr().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
 	s.Zadd().Key("1").Xx().ScoreMember().ScoreMember(1, "1").ScoreMember(1, "1").Build()
@@ -131,12 +129,12 @@ func sorted_set0(s Builder) {
 	s.Zrangestore().Dst("1").Src("1").Min("1").Max("1").Build()
 	s.Zrank().Key("1").Member("1").Withscore().Build()
 	s.Zrank().Key("1").Member("1").Withscore().Cache()
-}
-
-func sorted_set1(s Builder) {
 	s.Zrank().Key("1").Member("1").Build()
 	s.Zrank().Key("1").Member("1").Cache()
 	s.Zrem().Key("1").Member("1").Member("1").Build()
+}
+
+func sorted_set1(s Builder) {
 	s.Zremrangebylex().Key("1").Min("1").Max("1").Build()
 	s.Zremrangebyrank().Key("1").Start(1).Stop(1).Build()
 	s.Zremrangebyscore().Key("1").Min("1").Max("1").Build()
@@ -0,0 +1,245 @@
+package rueidis
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+const keyspacePattern = "__keyspace@*__:*"
+
+// KeyspaceCacheOption is the option of the NewKeyspaceCacheClient.
+type KeyspaceCacheOption struct {
+	// CacheSize is the max size in bytes of the local cache. Default value is DefaultCacheBytes.
+	CacheSize int
+	// ResubscribeInterval is the delay before subscribing the keyspace notifications of a node again after
+	// the previous subscription is broken. Default value is 1s.
+	ResubscribeInterval time.Duration
+	// ConfigureNotifications sends CONFIG SET notify-keyspace-events KA to every node before subscribing.
+	// Otherwise, the notify-keyspace-events must be configured on the servers to include at least "K" and "A".
+	ConfigureNotifications bool
+}
+
+// NewKeyspaceCacheClient wraps the client with a local cache for the DoCache and DoMultiCache, which is invalidated by
+// the keyspace notifications, for the deployments where the CLIENT TRACKING isn't available, for example, redis < 6.0 or
+// proxies without RESP3. The client should be created with the ClientOption.DisableCache, and it will be closed by the returned one.
+// It subscribes the keyspace notifications of all the nodes returned by the client.Nodes() with dedicated connections,
+// and returns an error if any of them fails to be subscribed.
+//
+// The keyspace notifications are best-effort, so the cache is less reliable than the one of the CLIENT TRACKING:
+//  1. Notifications are fire-and-forget. The whole cache is flushed whenever a subscription is broken,
+//     but notifications lost without breaking the connection, for example, dropped by the client-output-buffer-limit, can't be detected.
+//  2. Nodes added to the topology after the creation are not subscribed, and writes on them will not invalidate the cache.
+//  3. FLUSHALL and FLUSHDB don't emit keyspace notifications, and the notifications of all the databases are subscribed,
+//     so writes on other databases evict the same keys from the cache.
+//
+// MGET and JSON.MGET passed to the DoCache and DoMultiCache are sent without caching.
+func NewKeyspaceCacheClient(client Client, option KeyspaceCacheOption) (Client, error) {
+	if option.CacheSize <= 0 {
+		option.CacheSize = DefaultCacheBytes
+	}
+	if option.ResubscribeInterval <= 0 {
+		option.ResubscribeInterval = time.Second
+	}
+	c := &keyspaceClient{
+		Client:   client,
+		store:    newLRU(CacheStoreOption{CacheSizeEachConn: option.CacheSize}),
+		pending:  make(map[string]int),
+		dirty:    make(map[string]bool),
+		interval: option.ResubscribeInterval,
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	nodes := client.Nodes()
+	ready := make(chan error, len(nodes))
+	for _, node := range nodes {
+		if option.ConfigureNotifications {
+			if err := node.Do(c.ctx, node.B().ConfigSet().ParameterValue().ParameterValue("notify-keyspace-events", "KA").Build()).Error(); err != nil {
+				c.stop()
+				return nil, err
+			}
+		}
+		c.wg.Add(1)
+		go c.subscribe(node, ready)
+	}
+	for range nodes {
+		if err := <-ready; err != nil {
+			c.stop()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+type keyspaceClient struct {
+	Client
+	store    CacheStore
+	ctx      context.Context
+	cancel   context.CancelFunc
+	pending  map[string]int  // the number of in-flight cache misses of each key
+	dirty    map[string]bool // the keys invalidated while their cache misses are in flight
+	interval time.Duration
+	epoch    uint64 // the number of flushes, which invalidate all the in-flight cache misses
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+type keyspaceMiss struct {
+	cmd   Cacheable
+	ck    string
+	cc    string
+	epoch uint64
+	i     int
+}
+
+func (c *keyspaceClient) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) RedisResult {
+	return c.DoMultiCache(ctx, CT(cmd, ttl))[0]
+}
+
+func (c *keyspaceClient) DoMultiCache(ctx context.Context, multi ...CacheableTTL) []RedisResult {
+	results := make([]RedisResult, len(multi))
+	entries := make(map[int]CacheEntry)
+	var misses []keyspaceMiss
+	now := time.Now()
+	for i, ct := range multi {
+		if ct.Cmd.IsMGet() {
+			results[i] = c.Client.Do(ctx, Completed(ct.Cmd))
+			continue
+		}
+		ck, cc := cmds.CacheKey(ct.Cmd)
+		if v, entry := c.store.Flight(ck, cc, ct.TTL, now); v.typ != 0 {
+			results[i] = newResult(v, nil)
+		} else if entry != nil {
+			entries[i] = entry
+		} else {
+			misses = append(misses, keyspaceMiss{cmd: ct.Cmd, ck: ck, cc: cc, epoch: c.begin(ck), i: i})
+		}
+	}
+	if len(misses) != 0 {
+		c.fetch(ctx, misses, results)
+	}
+	for i, entry := range entries {
+		results[i] = newResult(entry.Wait(ctx))
+	}
+	return results
+}
+
+// fetch sends the missed cmds with the PTTL of their keys and updates the store with the replies.
+func (c *keyspaceClient) fetch(ctx context.Context, misses []keyspaceMiss, results []RedisResult) {
+	multi := make([]Completed, 0, len(misses)*2)
+	for _, m := range misses {
+		multi = append(multi, c.Client.B().Pttl().Key(m.ck).Build(), Completed(m.cmd))
+	}
+	now := time.Now()
+	resps := c.Client.DoMulti(ctx, multi...)
+	for j, m := range misses {
+		resp := resps[j*2+1]
+		if err := resp.Error(); err != nil && !IsRedisNil(err) {
+			c.store.Cancel(m.ck, m.cc, err)
+		} else {
+			cp := resp.val
+			cp.attrs = cacheMark
+			if pttl, err := resps[j*2].AsInt64(); err == nil && pttl >= 0 {
+				cp.setExpireAt(now.Add(time.Duration(pttl) * time.Millisecond).UnixMilli())
+			}
+			resp.val.setExpireAt(c.store.Update(m.ck, m.cc, cp))
+		}
+		if c.end(m.ck, m.epoch) {
+			c.store.Delete([]RedisMessage{{typ: '$', string: m.ck}}) // the reply may be stale
+		}
+		results[m.i] = resp
+	}
+}
+
+// begin marks a cache miss of the key in flight and returns the current epoch.
+func (c *keyspaceClient) begin(key string) uint64 {
+	c.mu.Lock()
+	c.pending[key]++
+	epoch := c.epoch
+	c.mu.Unlock()
+	return epoch
+}
+
+// end unmarks a cache miss of the key and reports whether it has been invalidated since the begin.
+func (c *keyspaceClient) end(key string, epoch uint64) (invalidated bool) {
+	c.mu.Lock()
+	invalidated = c.dirty[key] || c.epoch != epoch
+	if c.pending[key]--; c.pending[key] <= 0 {
+		delete(c.pending, key)
+		delete(c.dirty, key)
+	}
+	c.mu.Unlock()
+	return invalidated
+}
+
+func (c *keyspaceClient) invalidate(key string) {
+	c.mu.Lock()
+	if c.pending[key] > 0 {
+		c.dirty[key] = true
+	}
+	c.mu.Unlock()
+	c.store.Delete([]RedisMessage{{typ: '$', string: key}})
+}
+
+func (c *keyspaceClient) flush() {
+	c.mu.Lock()
+	c.epoch++
+	c.mu.Unlock()
+	c.store.Delete(nil)
+}
+
+// subscribe keeps subscribing the keyspace notifications of the node until the client is closed.
+// The result of the first subscription is sent to the ready.
+func (c *keyspaceClient) subscribe(node Client, ready chan<- error) {
+	defer c.wg.Done()
+	once := sync.Once{}
+	notify := func(err error) {
+		once.Do(func() { ready <- err })
+	}
+	for c.ctx.Err() == nil {
+		dc, cancel := node.Dedicate()
+		wait := dc.SetPubSubHooks(PubSubHooks{
+			OnMessage: func(m PubSubMessage) {
+				if i := strings.Index(m.Channel, "__:"); i >= 0 {
+					c.invalidate(m.Channel[i+3:])
+				}
+			},
+			OnSubscription: func(s PubSubSubscription) {
+				if s.Kind == "psubscribe" {
+					notify(nil)
+				}
+			},
+		})
+		err := dc.Do(c.ctx, dc.B().Psubscribe().Pattern(keyspacePattern).Build()).Error()
+		if err == nil {
+			select {
+			case err = <-wait:
+			case <-c.ctx.Done():
+				err = c.ctx.Err()
+			}
+		}
+		cancel()
+		c.flush() // notifications may be lost while the subscription is broken.
+		if err == nil {
+			err = ErrClosing
+		}
+		notify(err)
+		select {
+		case <-time.After(c.interval):
+		case <-c.ctx.Done():
+		}
+	}
+}
+
+func (c *keyspaceClient) stop() {
+	c.cancel()
+	c.wg.Wait()
+	c.store.Close(ErrClosing)
+}
+
+func (c *keyspaceClient) Close() {
+	c.stop()
+	c.Client.Close()
+}
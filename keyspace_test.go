@@ -0,0 +1,188 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+type keyspaceDedicated struct {
+	DedicatedClient
+	hooks PubSubHooks
+	wait  chan error
+	err   error
+	mu    sync.Mutex
+}
+
+func (d *keyspaceDedicated) B() Builder {
+	return cmds.NewBuilder(cmds.NoSlot)
+}
+
+func (d *keyspaceDedicated) SetPubSubHooks(hooks PubSubHooks) <-chan error {
+	d.mu.Lock()
+	d.hooks = hooks
+	d.mu.Unlock()
+	return d.wait
+}
+
+func (d *keyspaceDedicated) Do(ctx context.Context, cmd Completed) RedisResult {
+	if d.err != nil {
+		return newErrResult(d.err)
+	}
+	d.mu.Lock()
+	hooks := d.hooks
+	d.mu.Unlock()
+	hooks.OnSubscription(PubSubSubscription{Kind: "psubscribe", Channel: cmd.Commands()[1], Count: 1})
+	return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+}
+
+func (d *keyspaceDedicated) notify(key string) {
+	d.mu.Lock()
+	hooks := d.hooks
+	d.mu.Unlock()
+	hooks.OnMessage(PubSubMessage{Pattern: keyspacePattern, Channel: "__keyspace@0__:" + key, Message: "set"})
+}
+
+func TestKeyspaceCacheClient(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var fetches int32
+	var dedicates int32
+	var closed int32
+	dc := &keyspaceDedicated{wait: make(chan error, 1)}
+	var onFetch func()
+	reply := RedisMessage{typ: '$', string: "v"}
+	c := &client{
+		BFn: func() Builder {
+			return cmds.NewBuilder(cmds.NoSlot)
+		},
+		DoFn: func(ctx context.Context, cmd Completed) RedisResult {
+			return newResult(RedisMessage{typ: '*', values: []RedisMessage{{typ: '$', string: "m"}}}, nil)
+		},
+		DoMultiFn: func(ctx context.Context, multi ...Completed) []RedisResult {
+			atomic.AddInt32(&fetches, 1)
+			if onFetch != nil {
+				onFetch()
+			}
+			resps := make([]RedisResult, 0, len(multi))
+			for _, cmd := range multi {
+				if cmd.Commands()[0] == "PTTL" {
+					resps = append(resps, newResult(RedisMessage{typ: ':', integer: 100000}, nil))
+				} else {
+					resps = append(resps, newResult(reply, nil))
+				}
+			}
+			return resps
+		},
+		DedicateFn: func() (DedicatedClient, func()) {
+			atomic.AddInt32(&dedicates, 1)
+			return dc, func() {}
+		},
+		CloseFn: func() {
+			atomic.AddInt32(&closed, 1)
+		},
+	}
+	kc, err := NewKeyspaceCacheClient(c, KeyspaceCacheOption{ResubscribeInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	ctx := context.Background()
+	get := func(key string) RedisResult {
+		return kc.DoCache(ctx, kc.B().Get().Key(key).Cache(), time.Minute)
+	}
+	expect := func(resp RedisResult, hit bool, n int32) {
+		t.Helper()
+		if v, err := resp.ToString(); err != nil || v != "v" || resp.IsCacheHit() != hit {
+			t.Fatalf("unexpected response %v %v %v", v, err, resp.IsCacheHit())
+		}
+		if f := atomic.LoadInt32(&fetches); f != n {
+			t.Fatalf("unexpected fetches %v, expected %v", f, n)
+		}
+	}
+
+	expect(get("k"), false, 1)
+	expect(get("k"), true, 1)
+	if ttl := get("k").CacheTTL(); ttl <= 0 || ttl > 60 {
+		t.Fatalf("unexpected cache ttl %v", ttl)
+	}
+
+	t.Run("Invalidated By Notifications", func(t *testing.T) {
+		dc.notify("k")
+		expect(get("k"), false, 2)
+		expect(get("k"), true, 2)
+	})
+
+	t.Run("Invalidated While Fetching", func(t *testing.T) {
+		onFetch = func() { dc.notify("f") }
+		expect(get("f"), false, 3)
+		onFetch = nil
+		expect(get("f"), false, 4)
+		expect(get("f"), true, 4)
+	})
+
+	t.Run("Multi", func(t *testing.T) {
+		resps := kc.DoMultiCache(ctx, CT(kc.B().Get().Key("k").Cache(), time.Minute), CT(kc.B().Get().Key("m").Cache(), time.Minute))
+		expect(resps[0], true, 5)
+		expect(resps[1], false, 5)
+		if v, err := kc.DoCache(ctx, kc.B().Mget().Key("k").Cache(), time.Minute).AsStrSlice(); err != nil || v[0] != "m" {
+			t.Fatalf("MGET should be sent without caching %v %v", v, err)
+		}
+	})
+
+	t.Run("Errors Not Cached", func(t *testing.T) {
+		reply = RedisMessage{typ: '-', string: "WRONGTYPE Operation against a key holding the wrong kind of value"}
+		if err := get("e").Error(); err == nil {
+			t.Fatal("unexpected nil err")
+		}
+		reply = RedisMessage{typ: '$', string: "v"}
+		expect(get("e"), false, 7)
+	})
+
+	t.Run("Flushed When Subscription Broken", func(t *testing.T) {
+		dc.wait <- errors.New("broken")
+		for atomic.LoadInt32(&dedicates) != 2 {
+			time.Sleep(time.Millisecond)
+		}
+		expect(get("k"), false, 8)
+	})
+
+	kc.Close()
+	if atomic.LoadInt32(&closed) != 1 {
+		t.Fatal("the client should be closed")
+	}
+}
+
+func TestKeyspaceCacheClientSubscribeFailure(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	e := errors.New("psubscribe")
+	var closed int32
+	var configs [][]string
+	c := &client{
+		BFn: func() Builder {
+			return cmds.NewBuilder(cmds.NoSlot)
+		},
+		DoFn: func(ctx context.Context, cmd Completed) RedisResult {
+			configs = append(configs, cmd.Commands())
+			return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+		},
+		DedicateFn: func() (DedicatedClient, func()) {
+			return &keyspaceDedicated{wait: make(chan error), err: e}, func() {}
+		},
+		CloseFn: func() {
+			atomic.AddInt32(&closed, 1)
+		},
+	}
+	if _, err := NewKeyspaceCacheClient(c, KeyspaceCacheOption{ConfigureNotifications: true}); err != e {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if len(configs) != 1 || len(configs[0]) != 4 || configs[0][2] != "notify-keyspace-events" || configs[0][3] != "KA" {
+		t.Fatalf("unexpected commands %v", configs)
+	}
+	if atomic.LoadInt32(&closed) != 0 {
+		t.Fatal("the client should not be closed on failure")
+	}
+}
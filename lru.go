@@ -24,12 +24,27 @@ const (
 )
 
 type cacheEntry struct {
-	err  error
-	ch   chan struct{}
-	kc   *keyCache
-	cmd  string
-	val  RedisMessage
-	size int
+	err   error
+	ch    chan struct{}
+	kc    *keyCache
+	cmd   string
+	val   RedisMessage
+	stale RedisMessage
+	size  int
+	at    int64 // unix milli of the request that fetches the val
+}
+
+// load returns the stale value while the entry is being refreshed by DoCacheSWR and the stale value is not expired.
+func (e *cacheEntry) load(now time.Time) RedisMessage {
+	if e.val.typ == 0 && e.stale.typ != 0 && e.stale.relativePTTL(now) > 0 {
+		return e.stale
+	}
+	return e.val
+}
+
+// outdated reports whether the val was fetched longer than the fresh duration ago, regardless of its server side ttl.
+func (e *cacheEntry) outdated(fresh time.Duration, now time.Time) bool {
+	return now.UnixMilli()-e.at >= fresh.Milliseconds()
 }
 
 func (e *cacheEntry) Wait(ctx context.Context) (RedisMessage, error) {
@@ -80,7 +95,7 @@ func (c *lru) Flight(key, cmd string, ttl time.Duration, now time.Time) (v Redis
 	if kc, ok = c.store[key]; ok {
 		if ele = kc.cache[cmd]; ele != nil {
 			e = ele.Value.(*cacheEntry)
-			v = e.val
+			v = e.load(now)
 			back = c.list.Back()
 		}
 	}
@@ -112,7 +127,7 @@ func (c *lru) Flight(key, cmd string, ttl time.Duration, now time.Time) (v Redis
 	if ele = kc.cache[cmd]; ele != nil {
 		if e = ele.Value.(*cacheEntry); e.val.typ == 0 || e.val.relativePTTL(now) > 0 {
 			atomic.AddUint32(&kc.hits, 1)
-			v = e.val
+			v = e.load(now)
 			c.list.MoveToBack(ele)
 			ce = e
 			goto ret
@@ -123,7 +138,7 @@ func (c *lru) Flight(key, cmd string, ttl time.Duration, now time.Time) (v Redis
 	}
 	atomic.AddUint32(&kc.miss, 1)
 	v.setExpireAt(now.Add(ttl).UnixMilli())
-	kc.cache[cmd] = c.list.PushBack(&cacheEntry{cmd: cmd, kc: kc, val: v, ch: make(chan struct{})})
+	kc.cache[cmd] = c.list.PushBack(&cacheEntry{cmd: cmd, kc: kc, val: v, ch: make(chan struct{}), at: now.UnixMilli()})
 ret:
 	c.mu.Unlock()
 	return v, ce
@@ -138,7 +153,7 @@ func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []RedisResult
 		if kc, ok := c.store[key]; ok {
 			if ele := kc.cache[cmd]; ele != nil {
 				e := ele.Value.(*cacheEntry)
-				v := e.val
+				v := e.load(now)
 				if v.typ == 0 {
 					entries[i] = e
 				} else if v.relativePTTL(now) > 0 {
@@ -192,7 +207,7 @@ func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []RedisResult
 		}
 		if ele := kc.cache[cmd]; ele != nil {
 			e := ele.Value.(*cacheEntry)
-			v := e.val
+			v := e.load(now)
 			if v.typ == 0 {
 				entries[i] = e
 			} else if v.relativePTTL(now) > 0 {
@@ -210,7 +225,7 @@ func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []RedisResult
 		atomic.AddUint32(&kc.miss, 1)
 		v := RedisMessage{}
 		v.setExpireAt(now.Add(multi[i].TTL).UnixMilli())
-		kc.cache[cmd] = c.list.PushBack(&cacheEntry{cmd: cmd, kc: kc, val: v, ch: make(chan struct{})})
+		kc.cache[cmd] = c.list.PushBack(&cacheEntry{cmd: cmd, kc: kc, val: v, ch: make(chan struct{}), at: now.UnixMilli()})
 		missed[j] = i
 		j++
 	}
@@ -218,6 +233,41 @@ func (c *lru) Flights(now time.Time, multi []CacheableTTL, results []RedisResult
 	return missed[:j]
 }
 
+// FlightSWR is like the Flight, but if the cached value was fetched longer than the fresh duration ago, it returns the cached value
+// with refresh set to true and turns the entry into a pending one that keeps serving the stale value until the
+// response of the refresh request is delivered by the Update or the stale value is expired.
+func (c *lru) FlightSWR(key, cmd string, ttl, fresh time.Duration, now time.Time) (v RedisMessage, ce CacheEntry, refresh bool) {
+	if v, ce = c.Flight(key, cmd, ttl, now); v.typ == 0 || !c.outdated(key, cmd, fresh, now) {
+		return v, ce, false
+	}
+	c.mu.Lock()
+	if kc, ok := c.store[key]; ok {
+		if ele := kc.cache[cmd]; ele != nil {
+			if e := ele.Value.(*cacheEntry); e.val.typ != 0 && e.outdated(fresh, now) { // not being refreshed yet
+				n := &cacheEntry{cmd: cmd, kc: kc, stale: e.val, ch: make(chan struct{}), at: now.UnixMilli()}
+				n.val.setExpireAt(now.Add(ttl).UnixMilli())
+				ele.Value = n
+				c.size -= e.size
+				refresh = true
+			}
+		}
+	}
+	c.mu.Unlock()
+	return v, nil, refresh
+}
+
+func (c *lru) outdated(key, cmd string, fresh time.Duration, now time.Time) (outdated bool) {
+	c.mu.RLock()
+	if kc, ok := c.store[key]; ok {
+		if ele := kc.cache[cmd]; ele != nil {
+			e := ele.Value.(*cacheEntry)
+			outdated = e.val.typ != 0 && e.outdated(fresh, now)
+		}
+	}
+	c.mu.RUnlock()
+	return outdated
+}
+
 func (c *lru) Update(key, cmd string, value RedisMessage) (pxat int64) {
 	var ch chan struct{}
 	c.mu.Lock()
@@ -232,6 +282,7 @@ func (c *lru) Update(key, cmd string, value RedisMessage) (pxat int64) {
 					value.setExpireAt(pxat)
 				}
 				e.val = value
+				e.stale = RedisMessage{}
 				e.size = entryBaseSize + 2*(len(key)+len(cmd)) + value.approximateSize()
 				c.size += e.size
 				ch = e.ch
@@ -296,7 +347,8 @@ func (c *lru) purge(key string, kc *keyCache) {
 		for cmd, ele := range kc.cache {
 			if ele != nil {
 				e := ele.Value.(*cacheEntry)
-				if e.val.typ == 0 { // do not delete pending entries
+				if e.val.typ == 0 { // do not delete pending entries, but drop their stale values
+					e.stale = RedisMessage{}
 					continue
 				}
 				c.list.Remove(ele)
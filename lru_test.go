@@ -451,6 +451,69 @@ func TestLRU(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("FlightSWR", func(t *testing.T) {
+		lru := setup(t)
+		if v, _, refresh := lru.FlightSWR("0", "GET", TTL, TTL, time.Now()); v.string != "0" || refresh {
+			t.Fatalf("got unexpected value from the FlightSWR within the fresh ttl: %v %v", v, refresh)
+		}
+		time.Sleep(TTL / 5)
+		if v, _, refresh := lru.FlightSWR("0", "GET", TTL, TTL/10, time.Now()); v.string != "0" || !refresh {
+			t.Fatalf("got unexpected value from the FlightSWR after the fresh ttl: %v %v", v, refresh)
+		}
+		if v, _, refresh := lru.FlightSWR("0", "GET", TTL, TTL/10, time.Now()); v.string != "0" || refresh {
+			t.Fatalf("got unexpected value from the FlightSWR while refreshing: %v %v", v, refresh)
+		}
+		if v, _ := lru.Flight("0", "GET", TTL, time.Now()); v.string != "0" {
+			t.Fatalf("got unexpected value from the Flight while refreshing: %v", v)
+		}
+		if v, _ := flights(lru, time.Now(), TTL, "GET", "0"); v.string != "0" {
+			t.Fatalf("got unexpected value from the Flights while refreshing: %v", v)
+		}
+		if pxat := lru.Update("0", "GET", RedisMessage{typ: '+', string: "1"}); !roughly(time.Duration(pxat-time.Now().UnixMilli())*time.Millisecond, TTL) {
+			t.Fatalf("got unexpected pxat from the Update: %v", pxat)
+		}
+		if v, _, refresh := lru.FlightSWR("0", "GET", TTL, TTL/10, time.Now()); v.string != "1" || refresh {
+			t.Fatalf("got unexpected value from the FlightSWR after refreshed: %v %v", v, refresh)
+		}
+	})
+
+	t.Run("FlightSWR Short Server PTTL", func(t *testing.T) {
+		lru := setup(t)
+		if v, _, refresh := lru.FlightSWR("0", "GET", TTL, TTL/4, time.Now()); v.string != "0" || refresh {
+			t.Fatalf("got unexpected value from the FlightSWR right after fetched: %v %v", v, refresh)
+		}
+		if v, _, refresh := lru.FlightSWR("0", "GET", TTL, TTL/4, time.Now().Add(TTL/4)); v.string != "0" || !refresh {
+			t.Fatalf("got unexpected value from the FlightSWR after the fresh ttl: %v %v", v, refresh)
+		}
+	})
+
+	t.Run("FlightSWR Invalidated", func(t *testing.T) {
+		lru := setup(t)
+		time.Sleep(TTL / 5)
+		if _, _, refresh := lru.FlightSWR("0", "GET", TTL, TTL/10, time.Now()); !refresh {
+			t.Fatalf("the FlightSWR should refresh")
+		}
+		lru.Delete([]RedisMessage{{string: "0"}})
+		if v, entry := lru.Flight("0", "GET", TTL, time.Now()); v.typ != 0 || entry == nil {
+			t.Fatalf("got unexpected value from the Flight after invalidated: %v %v", v, entry)
+		}
+		lru.Cancel("0", "GET", errors.New("err"))
+		if v, entry := lru.Flight("0", "GET", TTL, time.Now()); v.typ != 0 || entry != nil {
+			t.Fatalf("got unexpected value from the Flight after canceled: %v %v", v, entry)
+		}
+	})
+
+	t.Run("FlightSWR Stale Expired", func(t *testing.T) {
+		lru := setup(t)
+		time.Sleep(TTL / 5)
+		if _, _, refresh := lru.FlightSWR("0", "GET", TTL, TTL/10, time.Now()); !refresh {
+			t.Fatalf("the FlightSWR should refresh")
+		}
+		if v, entry := lru.Flight("0", "GET", TTL, time.Now().Add(PTTL*time.Millisecond)); v.typ != 0 || entry == nil {
+			t.Fatalf("got unexpected value from the Flight after the stale value expired: %v %v", v, entry)
+		}
+	})
 }
 
 func flights(lru *lru, now time.Time, ttl time.Duration, args ...string) (RedisMessage, CacheEntry) {
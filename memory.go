@@ -0,0 +1,99 @@
+package rueidis
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+)
+
+// KeyMemoryUsage is the number of bytes that a key and its value take in the memory, reported by the MEMORY USAGE.
+type KeyMemoryUsage struct {
+	Key   string
+	Bytes int64
+}
+
+// MemoryUsage returns the number of bytes that the key and its value take in the memory by the MEMORY USAGE key SAMPLES samples.
+// A negative samples uses the default of redis, which is 5, and a zero samples counts all the nested values.
+// It returns a redis nil error, which can be checked by the IsRedisNil, if the key doesn't exist.
+func MemoryUsage(client Client, ctx context.Context, key string, samples int64) (int64, error) {
+	return client.Do(ctx, memoryUsageCmd(client, key, samples)).AsInt64()
+}
+
+// TopMemoryUsage SCANs the keys matching the match pattern, count keys per SCAN, and returns the n largest of them
+// by the MemoryUsage in descending order. In cluster mode, the keys of every master node are scanned.
+// Keys deleted during the scan are skipped. It is meant for finding memory hogs in operation, and it is expensive
+// for a large keyspace because it sends a MEMORY USAGE for every scanned key.
+func TopMemoryUsage(client Client, ctx context.Context, match string, count int64, n int, samples int64) ([]KeyMemoryUsage, error) {
+	if count <= 0 {
+		count = 100
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+	top := make(memoryUsageHeap, 0, n)
+	if c, ok := client.(*clusterClient); ok {
+		for _, node := range c.masters() {
+			if err := topMemoryUsage(node, ctx, match, count, n, samples, &top); err != nil {
+				return nil, err
+			}
+		}
+	} else if err := topMemoryUsage(client, ctx, match, count, n, samples, &top); err != nil {
+		return nil, err
+	}
+	sort.Slice(top, func(i, j int) bool { return top[i].Bytes > top[j].Bytes })
+	return top, nil
+}
+
+func topMemoryUsage(client Client, ctx context.Context, match string, count int64, n int, samples int64, top *memoryUsageHeap) error {
+	var cursor uint64
+	for {
+		entry, err := client.Do(ctx, client.B().Scan().Cursor(cursor).Match(match).Count(count).Build()).AsScanEntry()
+		if err != nil {
+			return err
+		}
+		if len(entry.Elements) != 0 {
+			cmds := make(Commands, len(entry.Elements))
+			for i, k := range entry.Elements {
+				cmds[i] = memoryUsageCmd(client, k, samples)
+			}
+			for i, resp := range client.DoMulti(ctx, cmds...) {
+				size, err := resp.AsInt64()
+				if IsRedisNil(err) {
+					continue // the key has been deleted after the scan.
+				} else if err != nil {
+					return err
+				}
+				if len(*top) < n {
+					heap.Push(top, KeyMemoryUsage{Key: entry.Elements[i], Bytes: size})
+				} else if (*top)[0].Bytes < size {
+					(*top)[0] = KeyMemoryUsage{Key: entry.Elements[i], Bytes: size}
+					heap.Fix(top, 0)
+				}
+			}
+		}
+		if cursor = entry.Cursor; cursor == 0 {
+			return nil
+		}
+	}
+}
+
+func memoryUsageCmd(client Client, key string, samples int64) Completed {
+	if samples < 0 {
+		return client.B().MemoryUsage().Key(key).Build()
+	}
+	return client.B().MemoryUsage().Key(key).Samples(samples).Build()
+}
+
+// memoryUsageHeap is a min heap of KeyMemoryUsage, so that the smallest of the top n is replaced first.
+type memoryUsageHeap []KeyMemoryUsage
+
+func (h memoryUsageHeap) Len() int           { return len(h) }
+func (h memoryUsageHeap) Less(i, j int) bool { return h[i].Bytes < h[j].Bytes }
+func (h memoryUsageHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *memoryUsageHeap) Push(x any)        { *h = append(*h, x.(KeyMemoryUsage)) }
+func (h *memoryUsageHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,148 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMemoryUsage(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	m := &mockConn{}
+	client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+		return m
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	m.DoFn = func(cmd Completed) RedisResult {
+		switch strings.Join(cmd.Commands(), " ") {
+		case "MEMORY USAGE k SAMPLES 0":
+			return newResult(RedisMessage{typ: ':', integer: 100}, nil)
+		case "MEMORY USAGE k":
+			return newResult(RedisMessage{typ: ':', integer: 50}, nil)
+		case "MEMORY USAGE missing SAMPLES 5":
+			return newResult(RedisMessage{typ: '_'}, nil)
+		}
+		return newErrResult(errors.New("unexpected command"))
+	}
+	if n, err := MemoryUsage(client, context.Background(), "k", 0); err != nil || n != 100 {
+		t.Fatalf("unexpected response %v %v", n, err)
+	}
+	if n, err := MemoryUsage(client, context.Background(), "k", -1); err != nil || n != 50 {
+		t.Fatalf("unexpected response %v %v", n, err)
+	}
+	if _, err := MemoryUsage(client, context.Background(), "missing", 5); !IsRedisNil(err) {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestTopMemoryUsage(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	sizes := map[string]int64{"k1": 10, "k2": 40, "k3": 20, "k4": 30, "k5": 5}
+	scan := func(cmd Completed) RedisResult {
+		cursor := cmd.Commands()[1]
+		if !reflect.DeepEqual(cmd.Commands(), []string{"SCAN", cursor, "MATCH", "k*", "COUNT", "100"}) {
+			t.Fatalf("unexpected command %v", cmd.Commands())
+		}
+		keys, next := []string{"k1", "k2", "gone"}, "1"
+		if cursor == "1" {
+			keys, next = []string{"k3", "k4", "k5"}, "0"
+		}
+		elements := make([]RedisMessage, len(keys))
+		for i, k := range keys {
+			elements[i] = RedisMessage{typ: '+', string: k}
+		}
+		return newResult(RedisMessage{typ: '*', values: []RedisMessage{{typ: '+', string: next}, {typ: '*', values: elements}}}, nil)
+	}
+	usage := func(multi ...Completed) *redisresults {
+		resps := make([]RedisResult, len(multi))
+		for i, cmd := range multi {
+			if cmd.Commands()[0] != "MEMORY" || cmd.Commands()[3] != "SAMPLES" {
+				t.Fatalf("unexpected command %v", cmd.Commands())
+			}
+			if size, ok := sizes[cmd.Commands()[2]]; ok {
+				resps[i] = newResult(RedisMessage{typ: ':', integer: size}, nil)
+			} else {
+				resps[i] = newResult(RedisMessage{typ: '_'}, nil)
+			}
+		}
+		return &redisresults{s: resps}
+	}
+
+	t.Run("single client", func(t *testing.T) {
+		m := &mockConn{DoFn: scan, DoMultiFn: usage}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		top, err := TopMemoryUsage(client, context.Background(), "k*", 0, 3, 5)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if !reflect.DeepEqual(top, []KeyMemoryUsage{{Key: "k2", Bytes: 40}, {Key: "k4", Bytes: 30}, {Key: "k3", Bytes: 20}}) {
+			t.Fatalf("unexpected top %v", top)
+		}
+		if top, err := TopMemoryUsage(client, context.Background(), "k*", 0, 10, 5); err != nil || len(top) != 5 || top[4].Key != "k5" {
+			t.Fatalf("unexpected top %v %v", top, err)
+		}
+		if top, err := TopMemoryUsage(client, context.Background(), "k*", 0, 0, 5); err != nil || len(top) != 0 {
+			t.Fatalf("unexpected top %v %v", top, err)
+		}
+	})
+
+	t.Run("cluster client", func(t *testing.T) {
+		var scanned []string
+		client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				DoFn: func(cmd Completed) RedisResult {
+					if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+						return slotsMultiResp
+					}
+					if cmd.Commands()[1] == "0" {
+						scanned = append(scanned, dst)
+					}
+					return scan(cmd)
+				},
+				DoMultiFn: usage,
+			}
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		top, err := TopMemoryUsage(client, context.Background(), "k*", 100, 2, 5)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if !reflect.DeepEqual(top, []KeyMemoryUsage{{Key: "k2", Bytes: 40}, {Key: "k2", Bytes: 40}}) {
+			t.Fatalf("unexpected top %v", top)
+		}
+		if len(scanned) != 2 {
+			t.Fatalf("every master should be scanned %v", scanned)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		m := &mockConn{DoFn: scan, DoMultiFn: func(multi ...Completed) *redisresults {
+			resps := make([]RedisResult, len(multi))
+			for i := range resps {
+				resps[i] = newResult(RedisMessage{typ: '-', string: "ERR usage " + strconv.Itoa(i)}, nil)
+			}
+			return &redisresults{s: resps}
+		}}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if _, err := TopMemoryUsage(client, context.Background(), "k*", 0, 3, 5); err == nil || err.Error() != "usage 0" {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
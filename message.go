@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -47,6 +48,17 @@ func IsRedisErr(err error) (ret *RedisError, ok bool) {
 	return ret, ok && ret != Nil
 }
 
+// AnyError returns the first error of the resps returned from DoMulti or DoMultiCache, excluding redis nil responses.
+// It returns nil if all the commands succeeded.
+func AnyError(resps []RedisResult) error {
+	for _, resp := range resps {
+		if err := resp.Error(); err != nil && !IsRedisNil(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 // RedisError is an error response or a nil message from redis instance
 type RedisError RedisMessage
 
@@ -107,6 +119,33 @@ func (r *RedisError) IsBusyGroup() bool {
 	return strings.HasPrefix(r.string, "BUSYGROUP")
 }
 
+// IsWrongType checks if it is a redis WRONGTYPE message.
+func (r *RedisError) IsWrongType() bool {
+	return strings.HasPrefix(r.string, "WRONGTYPE")
+}
+
+// IsOOM checks if it is a redis OOM message.
+func (r *RedisError) IsOOM() bool {
+	return strings.HasPrefix(r.string, "OOM")
+}
+
+// IsLoading checks if it is a redis LOADING message.
+func (r *RedisError) IsLoading() bool {
+	return strings.HasPrefix(r.string, "LOADING")
+}
+
+// Code returns the leading token of the error message, such as "ERR", "MOVED" and "WRONGTYPE".
+// It returns an empty string for the nil message.
+func (r *RedisError) Code() string {
+	if r.IsNil() {
+		return ""
+	}
+	if i := strings.IndexByte(r.string, ' '); i >= 0 {
+		return r.string[:i]
+	}
+	return r.string
+}
+
 func newResult(val RedisMessage, err error) RedisResult {
 	return RedisResult{val: val, err: err}
 }
@@ -217,6 +256,16 @@ func (r RedisResult) DecodeJSON(v any) (err error) {
 	return
 }
 
+// DecodeStruct delegates to RedisMessage.DecodeStruct
+func (r RedisResult) DecodeStruct(v any) (err error) {
+	if r.err != nil {
+		err = r.err
+	} else {
+		err = r.val.DecodeStruct(v)
+	}
+	return
+}
+
 // AsInt64 delegates to RedisMessage.AsInt64
 func (r RedisResult) AsInt64() (v int64, err error) {
 	if r.err != nil {
@@ -327,6 +376,26 @@ func (r RedisResult) AsXRange() (v []XRangeEntry, err error) {
 	return
 }
 
+// AsXPending delegates to RedisMessage.AsXPending
+func (r RedisResult) AsXPending() (v XPending, err error) {
+	if r.err != nil {
+		err = r.err
+	} else {
+		v, err = r.val.AsXPending()
+	}
+	return
+}
+
+// AsXPendingExt delegates to RedisMessage.AsXPendingExt
+func (r RedisResult) AsXPendingExt() (v []XPendingExt, err error) {
+	if r.err != nil {
+		err = r.err
+	} else {
+		v, err = r.val.AsXPendingExt()
+	}
+	return
+}
+
 // AsZScore delegates to RedisMessage.AsZScore
 func (r RedisResult) AsZScore() (v ZScore, err error) {
 	if r.err != nil {
@@ -347,6 +416,26 @@ func (r RedisResult) AsZScores() (v []ZScore, err error) {
 	return
 }
 
+// AsVSim delegates to RedisMessage.AsVSim
+func (r RedisResult) AsVSim() (v []VSimEntry, err error) {
+	if r.err != nil {
+		err = r.err
+	} else {
+		v, err = r.val.AsVSim()
+	}
+	return
+}
+
+// AsCommandInfo delegates to RedisMessage.AsCommandInfo
+func (r RedisResult) AsCommandInfo() (v []CommandInfo, err error) {
+	if r.err != nil {
+		err = r.err
+	} else {
+		v, err = r.val.AsCommandInfo()
+	}
+	return
+}
+
 // AsXRead delegates to RedisMessage.AsXRead
 func (r RedisResult) AsXRead() (v map[string][]XRangeEntry, err error) {
 	if r.err != nil {
@@ -431,6 +520,16 @@ func (r RedisResult) AsStrMap() (v map[string]string, err error) {
 	return
 }
 
+// AsConfigMap delegates to RedisMessage.AsConfigMap
+func (r RedisResult) AsConfigMap() (v ConfigMap, err error) {
+	if r.err != nil {
+		err = r.err
+	} else {
+		v, err = r.val.AsConfigMap()
+	}
+	return
+}
+
 // AsIntMap delegates to RedisMessage.AsIntMap
 func (r RedisResult) AsIntMap() (v map[string]int64, err error) {
 	if r.err != nil {
@@ -441,6 +540,16 @@ func (r RedisResult) AsIntMap() (v map[string]int64, err error) {
 	return
 }
 
+// AsFieldIntMap delegates to RedisMessage.AsFieldIntMap
+func (r RedisResult) AsFieldIntMap(fields ...string) (v map[string]int64, err error) {
+	if r.err != nil {
+		err = r.err
+	} else {
+		v, err = r.val.AsFieldIntMap(fields...)
+	}
+	return
+}
+
 // AsScanEntry delegates to RedisMessage.AsScanEntry.
 func (r RedisResult) AsScanEntry() (v ScanEntry, err error) {
 	if r.err != nil {
@@ -613,6 +722,23 @@ func (m *RedisMessage) DecodeJSON(v any) (err error) {
 	return decoder.Decode(v)
 }
 
+// DecodeStruct check if message is a redis map response, or a RESP2 array of key value pairs, and decodes it into v,
+// which must be a non-nil pointer to a struct, such as the replies of CONFIG GET, XINFO STREAM, and HELLO.
+// A key is decoded into the field whose `redis` tag matches it, or whose name matches it case-insensitively if the field has no tag.
+// Fields tagged with `redis:"-"`, unknown keys, and nil values are skipped. Nested maps are decoded into structs or maps with string keys,
+// arrays into slices, and strings are converted to numbers and bools ("yes", "no", "true", "1", etc.) according to the field types.
+// A field of the RedisMessage type receives the raw message, and a field of the any type receives the result of the ToAny.
+func (m *RedisMessage) DecodeStruct(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: DecodeStruct requires a non-nil pointer to a struct, got %T", errParse, v)
+	}
+	if err := m.Error(); err != nil {
+		return err
+	}
+	return decodeStruct(m, rv.Elem())
+}
+
 // AsInt64 check if message is a redis string response, and parse it as int64
 func (m *RedisMessage) AsInt64() (val int64, err error) {
 	if m.IsInt64() {
@@ -865,6 +991,97 @@ func (m *RedisMessage) AsXRead() (ret map[string][]XRangeEntry, err error) {
 	return nil, fmt.Errorf("%w: redis message type %s is not a map/array/set", errParse, typeNames[typ])
 }
 
+// XPending is the summary form of the XPENDING command response
+type XPending struct {
+	// Consumers is the number of pending messages of each consumer having at least one.
+	Consumers map[string]int64
+	MinID     string
+	MaxID     string
+	Count     int64
+}
+
+// AsXPending converts the summary form of the XPENDING key group command response to XPending.
+// The MinID and MaxID are empty and the Consumers is nil if the pending entries list is empty.
+func (m *RedisMessage) AsXPending() (p XPending, err error) {
+	values, err := m.ToArray()
+	if err != nil {
+		return p, err
+	}
+	if len(values) != 4 {
+		return p, fmt.Errorf("%w: got %d, wanted 4", errParse, len(values))
+	}
+	if p.Count, err = values[0].AsInt64(); err != nil {
+		return p, err
+	}
+	if p.Count == 0 {
+		return p, nil
+	}
+	if p.MinID, err = values[1].ToString(); err != nil {
+		return p, err
+	}
+	if p.MaxID, err = values[2].ToString(); err != nil {
+		return p, err
+	}
+	consumers, err := values[3].ToArray()
+	if err != nil {
+		return p, err
+	}
+	p.Consumers = make(map[string]int64, len(consumers))
+	for _, c := range consumers {
+		if !c.IsArray() || len(c.values) != 2 {
+			return p, fmt.Errorf("%w: the consumer of XPENDING is not an array of length 2", errParse)
+		}
+		name, err := c.values[0].ToString()
+		if err != nil {
+			return p, err
+		}
+		if p.Consumers[name], err = c.values[1].AsInt64(); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// XPendingExt is the element type of the extended form of the XPENDING command response
+type XPendingExt struct {
+	ID       string
+	Consumer string
+	// Idle is the time elapsed since the message was last delivered to the Consumer.
+	Idle time.Duration
+	// DeliveryCount is the number of times the message has been delivered.
+	DeliveryCount int64
+}
+
+// AsXPendingExt converts the extended form of the XPENDING key group start end count command response to []XPendingExt.
+// It returns an empty slice if there is no pending message in the range.
+func (m *RedisMessage) AsXPendingExt() ([]XPendingExt, error) {
+	values, err := m.ToArray()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]XPendingExt, len(values))
+	for i, v := range values {
+		if !v.IsArray() || len(v.values) != 4 {
+			return nil, fmt.Errorf("%w: the entry of XPENDING is not an array of length 4", errParse)
+		}
+		if entries[i].ID, err = v.values[0].ToString(); err != nil {
+			return nil, err
+		}
+		if entries[i].Consumer, err = v.values[1].ToString(); err != nil {
+			return nil, err
+		}
+		idle, err := v.values[2].AsInt64()
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Idle = time.Duration(idle) * time.Millisecond
+		if entries[i].DeliveryCount, err = v.values[3].AsInt64(); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
 // ZScore is the element type of ZRANGE WITHSCORES, ZDIFF WITHSCORES and ZPOPMAX command response
 type ZScore struct {
 	Member string
@@ -915,6 +1132,133 @@ func (m *RedisMessage) AsZScores() ([]ZScore, error) {
 	return scores, nil
 }
 
+// VSimEntry is the element type of the VSIM WITHSCORES response.
+type VSimEntry struct {
+	Member string
+	Score  float64
+}
+
+// AsVSim converts the VSIM WITHSCORES response to []VSimEntry in the order of the similarity.
+// It accepts both the RESP3 map and the RESP2 flat array of members and scores. If the WITHATTRIBS is also used,
+// the attributes are skipped, including the RESP2 flat array of members, scores and attributes, which is told apart
+// by its attributes not being numbers. The response of VSIM without the WITHSCORES should be converted by the AsStrSlice instead.
+func (m *RedisMessage) AsVSim() ([]VSimEntry, error) {
+	if m.IsMap() {
+		entries := make([]VSimEntry, len(m.values)/2)
+		for i := range entries {
+			if err := toVSimEntry(&entries[i], m.values[i*2], m.values[i*2+1]); err != nil {
+				return nil, err
+			}
+		}
+		return entries, nil
+	}
+	arr, err := m.ToArray()
+	if err != nil {
+		return nil, err
+	}
+	stride := 2
+	if !vsimScored(arr) { // the RESP2 response of the WITHSCORES WITHATTRIBS
+		stride = 3
+	}
+	if len(arr)%stride != 0 {
+		return nil, fmt.Errorf("%w: VSIM response of length %d is neither pairs of members and scores nor triples with attributes", errParse, len(arr))
+	}
+	entries := make([]VSimEntry, len(arr)/stride)
+	for i := range entries {
+		if err = toVSimEntry(&entries[i], arr[i*stride], arr[i*stride+1]); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// vsimScored reports whether the arr is pairs of members and scores, where every second element is a number.
+func vsimScored(arr []RedisMessage) bool {
+	if len(arr)%2 != 0 {
+		return false
+	}
+	for i := 1; i < len(arr); i += 2 {
+		if _, err := arr[i].AsFloat64(); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func toVSimEntry(e *VSimEntry, member, score RedisMessage) (err error) {
+	if score.IsArray() && len(score.values) > 0 { // the score and the attributes of the WITHATTRIBS
+		score = score.values[0]
+	}
+	if e.Member, err = member.ToString(); err == nil {
+		e.Score, err = score.AsFloat64()
+	}
+	return err
+}
+
+// CommandInfo is the element type of the COMMAND and COMMAND INFO response.
+// FirstKey, LastKey and Step are the positions of the key arguments, where a negative LastKey counts from the end.
+type CommandInfo struct {
+	Name          string
+	Flags         []string
+	ACLCategories []string
+	Arity         int64
+	FirstKey      int64
+	LastKey       int64
+	Step          int64
+}
+
+// AsCommandInfo converts the COMMAND and COMMAND INFO response to []CommandInfo in the order of the response.
+// The info of an unknown command in the COMMAND INFO is left as a zero CommandInfo with an empty Name.
+// The ACLCategories is only filled by redis 6 and above.
+func (m *RedisMessage) AsCommandInfo() ([]CommandInfo, error) {
+	arr, err := m.ToArray()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]CommandInfo, len(arr))
+	for i, v := range arr {
+		if v.IsNil() {
+			continue
+		}
+		if err = toCommandInfo(&infos[i], v); err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
+func toCommandInfo(c *CommandInfo, m RedisMessage) (err error) {
+	info, err := m.ToArray()
+	if err != nil {
+		return err
+	}
+	if len(info) < 6 {
+		return fmt.Errorf("%w: command info has %d elements, expected at least 6", errParse, len(info))
+	}
+	if c.Name, err = info[0].ToString(); err != nil {
+		return err
+	}
+	if c.Arity, err = info[1].AsInt64(); err != nil {
+		return err
+	}
+	if c.Flags, err = info[2].AsStrSlice(); err != nil {
+		return err
+	}
+	if c.FirstKey, err = info[3].AsInt64(); err != nil {
+		return err
+	}
+	if c.LastKey, err = info[4].AsInt64(); err != nil {
+		return err
+	}
+	if c.Step, err = info[5].AsInt64(); err != nil {
+		return err
+	}
+	if len(info) > 6 {
+		c.ACLCategories, err = info[6].AsStrSlice()
+	}
+	return err
+}
+
 // ScanEntry is the element type of both SCAN, SSCAN, HSCAN and ZSCAN command response.
 type ScanEntry struct {
 	Elements []string
@@ -968,6 +1312,11 @@ func (m *RedisMessage) AsStrMap() (map[string]string, error) {
 	return nil, fmt.Errorf("%w: redis message type %s is not a map/array/set or its length is not even", errParse, typeNames[typ])
 }
 
+// AsConfigMap converts the CONFIG GET response, which is a flat array in RESP2 or a map in RESP3, to a ConfigMap.
+func (m *RedisMessage) AsConfigMap() (ConfigMap, error) {
+	return m.AsStrMap()
+}
+
 // AsIntMap check if message is a redis map/array/set response, and convert to map[string]int64.
 // redis nil element and other non integer element will be present as zero.
 func (m *RedisMessage) AsIntMap() (map[string]int64, error) {
@@ -996,11 +1345,48 @@ func (m *RedisMessage) AsIntMap() (map[string]int64, error) {
 	return nil, fmt.Errorf("%w: redis message type %s is not a map/array/set or its length is not even", errParse, typeNames[typ])
 }
 
+// Status codes of the per-field integers replied by the hash field expiration commands.
+const (
+	// FieldNotExists is replied for a field that doesn't exist or a key that doesn't exist.
+	FieldNotExists int64 = -2
+	// FieldNoExpiration is replied by HTTL, HPTTL, HEXPIRETIME, HPEXPIRETIME and HPERSIST for a field without an expiration.
+	FieldNoExpiration int64 = -1
+	// FieldConditionNotMet is replied by HEXPIRE, HPEXPIRE, HEXPIREAT and HPEXPIREAT if the NX, XX, GT or LT condition is not met.
+	FieldConditionNotMet int64 = 0
+	// FieldExpirationSet is replied by HEXPIRE, HPEXPIRE, HEXPIREAT and HPEXPIREAT if the expiration is set,
+	// and by HPERSIST if the expiration is removed.
+	FieldExpirationSet int64 = 1
+	// FieldDeleted is replied by HEXPIRE, HPEXPIRE, HEXPIREAT and HPEXPIREAT if the field is deleted because the expiration is in the past.
+	FieldDeleted int64 = 2
+)
+
+// AsFieldIntMap check if message is a redis array response of per-field integers, such as the replies of HEXPIRE, HPEXPIRE,
+// HEXPIREAT, HPEXPIREAT, HPERSIST, HTTL, HPTTL, HEXPIRETIME and HPEXPIRETIME, and maps each integer back to the field at
+// the same position of the given fields, which should be the fields passed to the command in the same order.
+// The integers are either the FieldNotExists, FieldNoExpiration, FieldConditionNotMet, FieldExpirationSet and FieldDeleted
+// status codes or the remaining TTLs and the expiration timestamps of the fields.
+func (m *RedisMessage) AsFieldIntMap(fields ...string) (map[string]int64, error) {
+	s, err := m.AsIntSlice()
+	if err != nil {
+		return nil, err
+	}
+	if len(s) != len(fields) {
+		return nil, fmt.Errorf("%w: got %d integers for %d fields", errParse, len(s), len(fields))
+	}
+	r := make(map[string]int64, len(fields))
+	for i, f := range fields {
+		r[f] = s[i]
+	}
+	return r, nil
+}
+
 type KeyValues struct {
 	Key    string
 	Values []string
 }
 
+// AsLMPop converts LMPOP/BLMPOP response to KeyValues. It returns a redis nil error if nothing is popped,
+// including when the BLMPOP is timed out.
 func (m *RedisMessage) AsLMPop() (kvs KeyValues, err error) {
 	if err = m.Error(); err != nil {
 		return KeyValues{}, err
@@ -1019,6 +1405,8 @@ type KeyZScores struct {
 	Values []ZScore
 }
 
+// AsZMPop converts ZMPOP/BZMPOP response to KeyZScores. It returns a redis nil error if nothing is popped,
+// including when the BZMPOP is timed out.
 func (m *RedisMessage) AsZMPop() (kvs KeyZScores, err error) {
 	if err = m.Error(); err != nil {
 		return KeyZScores{}, err
@@ -1161,12 +1549,16 @@ func (m *RedisMessage) AsFtAggregateCursor() (cursor, total int64, docs []map[st
 	return
 }
 
+// GeoLocation is a member returned by GEOSEARCH or GEORADIUS. The Dist, GeoHash, Longitude and Latitude are only
+// filled if the WITHDIST, WITHHASH and WITHCOORD are specified respectively.
 type GeoLocation struct {
 	Name                      string
 	Longitude, Latitude, Dist float64
 	GeoHash                   int64
 }
 
+// AsGeosearch decodes the response of GEOSEARCH or GEORADIUS. Redis replies the optional fields of each member
+// in the fixed order of distance, hash and coordinates regardless of the order of the WITHDIST, WITHHASH and WITHCOORD.
 func (m *RedisMessage) AsGeosearch() ([]GeoLocation, error) {
 	arr, err := m.ToArray()
 	if err != nil {
@@ -1201,10 +1593,14 @@ func (m *RedisMessage) AsGeosearch() ([]GeoLocation, error) {
 			if i < len(info) && info[i].values != nil {
 				cord := info[i].values
 				if len(cord) < 2 {
-					return nil, fmt.Errorf("got %d, expected 2", len(info))
+					return nil, fmt.Errorf("got %d, expected 2", len(cord))
+				}
+				if loc.Longitude, err = cord[0].AsFloat64(); err != nil {
+					return nil, err
+				}
+				if loc.Latitude, err = cord[1].AsFloat64(); err != nil {
+					return nil, err
 				}
-				loc.Longitude, _ = cord[0].AsFloat64()
-				loc.Latitude, _ = cord[1].AsFloat64()
 			}
 		}
 		geoLocations = append(geoLocations, loc)
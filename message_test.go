@@ -120,6 +120,56 @@ func TestIsRedisBusyGroup(t *testing.T) {
 	}
 }
 
+func TestAnyError(t *testing.T) {
+	e := &RedisError{typ: '-', string: "ERR"}
+	if err := AnyError(nil); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := AnyError([]RedisResult{newResult(RedisMessage{typ: '+', string: "OK"}, nil), newResult(RedisMessage{typ: '_'}, nil)}); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := AnyError([]RedisResult{newResult(RedisMessage{typ: '_'}, nil), newResult(RedisMessage(*e), nil), newErrResult(ErrClosing)}); err == nil || err.Error() != "ERR" {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := AnyError([]RedisResult{newResult(RedisMessage{typ: '+', string: "OK"}, nil), newErrResult(ErrClosing)}); err != ErrClosing {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestRedisErrorCode(t *testing.T) {
+	for _, c := range []struct {
+		err  string
+		code string
+		fn   func(e *RedisError) bool
+	}{
+		{err: "ERR unknown command", code: "ERR"},
+		{err: "MOVED 1 127.0.0.1:1", code: "MOVED", fn: func(e *RedisError) bool { _, ok := e.IsMoved(); return ok }},
+		{err: "ASK 1 127.0.0.1:1", code: "ASK", fn: func(e *RedisError) bool { _, ok := e.IsAsk(); return ok }},
+		{err: "TRYAGAIN Multiple keys request during rehashing of slot", code: "TRYAGAIN", fn: (*RedisError).IsTryAgain},
+		{err: "CLUSTERDOWN The cluster is down", code: "CLUSTERDOWN", fn: (*RedisError).IsClusterDown},
+		{err: "NOSCRIPT No matching script.", code: "NOSCRIPT", fn: (*RedisError).IsNoScript},
+		{err: "BUSYGROUP Consumer Group name already exists", code: "BUSYGROUP", fn: (*RedisError).IsBusyGroup},
+		{err: "WRONGTYPE Operation against a key holding the wrong kind of value", code: "WRONGTYPE", fn: (*RedisError).IsWrongType},
+		{err: "OOM command not allowed when used memory > 'maxmemory'.", code: "OOM", fn: (*RedisError).IsOOM},
+		{err: "LOADING Redis is loading the dataset in memory", code: "LOADING", fn: (*RedisError).IsLoading},
+		{err: "EXECABORT", code: "EXECABORT"},
+	} {
+		e := &RedisError{typ: '-', string: c.err}
+		if code := e.Code(); code != c.code {
+			t.Fatalf("unexpected code %v", code)
+		}
+		if c.fn != nil && !c.fn(e) {
+			t.Fatalf("unexpected predicate result of %v", c.err)
+		}
+		if c.fn == nil && (e.IsWrongType() || e.IsOOM() || e.IsLoading()) {
+			t.Fatalf("unexpected predicate result of %v", c.err)
+		}
+	}
+	if code := Nil.Code(); code != "" {
+		t.Fatalf("unexpected code %v", code)
+	}
+}
+
 //gocyclo:ignore
 func TestRedisResult(t *testing.T) {
 	//Add erroneous type
@@ -324,6 +374,14 @@ func TestRedisResult(t *testing.T) {
 		if _, err := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsIntSlice(); err == nil {
 			t.Fatal("AsIntSlice not failed as expected")
 		}
+		values = []RedisMessage{{integer: 1, typ: ':'}, {string: "1.5", typ: '$'}}
+		if _, err := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsIntSlice(); err == nil {
+			t.Fatal("AsIntSlice not failed as expected")
+		}
+		values = []RedisMessage{{integer: 1, typ: ':'}, {typ: '_'}, {string: "3", typ: '$'}}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsIntSlice(); err != nil || !reflect.DeepEqual(ret, []int64{1, 0, 3}) {
+			t.Fatalf("AsIntSlice not get value as expected %v %v", ret, err)
+		}
 	})
 
 	t.Run("AsFloatSlice", func(t *testing.T) {
@@ -340,6 +398,14 @@ func TestRedisResult(t *testing.T) {
 		if ret, _ := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsFloatSlice(); !reflect.DeepEqual(ret, []float64{1, 2, 3, 4}) {
 			t.Fatal("AsFloatSlice not get value as expected")
 		}
+		values = []RedisMessage{{string: "m1", typ: '$'}, {string: "1.5", typ: '$'}}
+		if _, err := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsFloatSlice(); err == nil {
+			t.Fatal("AsFloatSlice not failed as expected")
+		}
+		values = []RedisMessage{{string: "1.5", typ: '$'}, {typ: '_'}, {string: "inf", typ: ','}}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsFloatSlice(); err != nil || !reflect.DeepEqual(ret, []float64{1.5, 0, math.Inf(1)}) {
+			t.Fatalf("AsFloatSlice not get value as expected %v %v", ret, err)
+		}
 	})
 
 	t.Run("AsBoolSlice", func(t *testing.T) {
@@ -353,6 +419,10 @@ func TestRedisResult(t *testing.T) {
 		if ret, _ := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsBoolSlice(); !reflect.DeepEqual(ret, []bool{true, false, true}) {
 			t.Fatal("AsBoolSlice not get value as expected")
 		}
+		values = []RedisMessage{{integer: 1, typ: ':'}, {typ: '_'}, {string: "x", typ: '$'}, {integer: 0, typ: ':'}}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsBoolSlice(); err != nil || !reflect.DeepEqual(ret, []bool{true, false, false, false}) {
+			t.Fatalf("AsBoolSlice not get value as expected %v %v", ret, err)
+		}
 	})
 
 	t.Run("AsMap", func(t *testing.T) {
@@ -404,6 +474,33 @@ func TestRedisResult(t *testing.T) {
 		}
 	})
 
+	t.Run("AsFieldIntMap", func(t *testing.T) {
+		if _, err := (RedisResult{err: errors.New("other")}).AsFieldIntMap("f1"); err == nil {
+			t.Fatal("AsFieldIntMap not failed as expected")
+		}
+		if _, err := (RedisResult{val: RedisMessage{typ: '-'}}).AsFieldIntMap("f1"); err == nil {
+			t.Fatal("AsFieldIntMap not failed as expected")
+		}
+		values := []RedisMessage{{integer: FieldDeleted, typ: ':'}, {integer: FieldNotExists, typ: ':'}, {integer: FieldExpirationSet, typ: ':'}}
+		if _, err := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsFieldIntMap("f1", "f2"); !errors.Is(err, errParse) {
+			t.Fatalf("AsFieldIntMap not failed as expected %v", err)
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsFieldIntMap("f1", "f2", "f3"); err != nil || !reflect.DeepEqual(map[string]int64{
+			"f1": FieldDeleted,
+			"f2": FieldNotExists,
+			"f3": FieldExpirationSet,
+		}, ret) {
+			t.Fatalf("AsFieldIntMap not get value as expected %v %v", ret, err)
+		}
+		values = []RedisMessage{{integer: 100, typ: ':'}, {integer: FieldNoExpiration, typ: ':'}}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: values}}).AsFieldIntMap("f2", "f1"); err != nil || !reflect.DeepEqual(map[string]int64{
+			"f2": 100,
+			"f1": FieldNoExpiration,
+		}, ret) {
+			t.Fatalf("AsFieldIntMap not get value as expected %v %v", ret, err)
+		}
+	})
+
 	t.Run("ToMap", func(t *testing.T) {
 		if _, err := (RedisResult{err: errors.New("other")}).ToMap(); err == nil {
 			t.Fatal("ToMap not failed as expected")
@@ -491,6 +588,57 @@ func TestRedisResult(t *testing.T) {
 		}
 	})
 
+	t.Run("AsXPending", func(t *testing.T) {
+		if _, err := (RedisResult{err: errors.New("other")}).AsXPending(); err == nil {
+			t.Fatal("AsXPending not failed as expected")
+		}
+		if _, err := (RedisResult{val: RedisMessage{typ: '-'}}).AsXPending(); err == nil {
+			t.Fatal("AsXPending not failed as expected")
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: ':', integer: 3},
+			{typ: '$', string: "1-0"},
+			{typ: '$', string: "3-0"},
+			{typ: '*', values: []RedisMessage{
+				{typ: '*', values: []RedisMessage{{typ: '$', string: "c1"}, {typ: '$', string: "2"}}},
+				{typ: '*', values: []RedisMessage{{typ: '$', string: "c2"}, {typ: '$', string: "1"}}},
+			}},
+		}}}).AsXPending(); err != nil || !reflect.DeepEqual(XPending{
+			Count:     3,
+			MinID:     "1-0",
+			MaxID:     "3-0",
+			Consumers: map[string]int64{"c1": 2, "c2": 1},
+		}, ret) {
+			t.Fatalf("AsXPending not get value as expected %v %v", ret, err)
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: ':', integer: 0}, {typ: '_'}, {typ: '_'}, {typ: '_'},
+		}}}).AsXPending(); err != nil || !reflect.DeepEqual(XPending{}, ret) {
+			t.Fatalf("AsXPending not get value as expected %v %v", ret, err)
+		}
+	})
+
+	t.Run("AsXPendingExt", func(t *testing.T) {
+		if _, err := (RedisResult{err: errors.New("other")}).AsXPendingExt(); err == nil {
+			t.Fatal("AsXPendingExt not failed as expected")
+		}
+		if _, err := (RedisResult{val: RedisMessage{typ: '-'}}).AsXPendingExt(); err == nil {
+			t.Fatal("AsXPendingExt not failed as expected")
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '*', values: []RedisMessage{{typ: '$', string: "1-0"}, {typ: '$', string: "c1"}, {typ: ':', integer: 1500}, {typ: ':', integer: 2}}},
+			{typ: '*', values: []RedisMessage{{typ: '$', string: "2-0"}, {typ: '$', string: "c2"}, {typ: ':', integer: 10}, {typ: ':', integer: 1}}},
+		}}}).AsXPendingExt(); err != nil || !reflect.DeepEqual([]XPendingExt{
+			{ID: "1-0", Consumer: "c1", Idle: 1500 * time.Millisecond, DeliveryCount: 2},
+			{ID: "2-0", Consumer: "c2", Idle: 10 * time.Millisecond, DeliveryCount: 1},
+		}, ret) {
+			t.Fatalf("AsXPendingExt not get value as expected %v %v", ret, err)
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*'}}).AsXPendingExt(); err != nil || ret == nil || len(ret) != 0 {
+			t.Fatalf("AsXPendingExt not get value as expected %v %v", ret, err)
+		}
+	})
+
 	t.Run("AsXRead", func(t *testing.T) {
 		if _, err := (RedisResult{err: errors.New("other")}).AsXRead(); err == nil {
 			t.Fatal("AsXRead not failed as expected")
@@ -600,6 +748,121 @@ func TestRedisResult(t *testing.T) {
 		}
 	})
 
+	t.Run("AsVSim", func(t *testing.T) {
+		if _, err := (RedisResult{err: errors.New("other")}).AsVSim(); err == nil {
+			t.Fatal("AsVSim not failed as expected")
+		}
+		if _, err := (RedisResult{val: RedisMessage{typ: '-'}}).AsVSim(); err == nil {
+			t.Fatal("AsVSim not failed as expected")
+		}
+		expected := []VSimEntry{{Member: "m2", Score: 0.9}, {Member: "m1", Score: 0.5}}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '$', string: "m2"},
+			{typ: '$', string: "0.9"},
+			{typ: '$', string: "m1"},
+			{typ: '$', string: "0.5"},
+		}}}).AsVSim(); err != nil || !reflect.DeepEqual(expected, ret) {
+			t.Fatalf("AsVSim not get value as expected %v %v", ret, err)
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '%', values: []RedisMessage{
+			{typ: '$', string: "m2"},
+			{typ: ',', string: "0.9"},
+			{typ: '$', string: "m1"},
+			{typ: ',', string: "0.5"},
+		}}}).AsVSim(); err != nil || !reflect.DeepEqual(expected, ret) {
+			t.Fatalf("AsVSim not get value as expected %v %v", ret, err)
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '%', values: []RedisMessage{
+			{typ: '$', string: "m2"},
+			{typ: '*', values: []RedisMessage{{typ: ',', string: "0.9"}, {typ: '$', string: `{"a":1}`}}},
+			{typ: '$', string: "m1"},
+			{typ: '*', values: []RedisMessage{{typ: ',', string: "0.5"}, {typ: '_'}}},
+		}}}).AsVSim(); err != nil || !reflect.DeepEqual(expected, ret) {
+			t.Fatalf("AsVSim not get value as expected %v %v", ret, err)
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '$', string: "m2"},
+			{typ: '$', string: "0.9"},
+			{typ: '$', string: `{"a":1}`},
+			{typ: '$', string: "m1"},
+			{typ: '$', string: "0.5"},
+			{typ: '_'},
+		}}}).AsVSim(); err != nil || !reflect.DeepEqual(expected, ret) {
+			t.Fatalf("AsVSim not get value as expected %v %v", ret, err)
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '$', string: "m2"},
+			{typ: '$', string: "0.9"},
+			{typ: '_'},
+		}}}).AsVSim(); err != nil || !reflect.DeepEqual(expected[:1], ret) {
+			t.Fatalf("AsVSim not get value as expected %v %v", ret, err)
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*'}}).AsVSim(); err != nil || ret == nil || len(ret) != 0 {
+			t.Fatalf("AsVSim not get value as expected %v %v", ret, err)
+		}
+	})
+
+	t.Run("AsConfigMap", func(t *testing.T) {
+		if _, err := (RedisResult{err: errors.New("other")}).AsConfigMap(); err == nil {
+			t.Fatal("AsConfigMap not failed as expected")
+		}
+		if _, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{{typ: '$', string: "timeout"}}}}).AsConfigMap(); err == nil {
+			t.Fatal("AsConfigMap not failed as expected")
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '$', string: "timeout"}, {typ: '$', string: "0"},
+		}}}).AsConfigMap(); err != nil || !reflect.DeepEqual(ConfigMap{"timeout": "0"}, ret) {
+			t.Fatalf("AsConfigMap not get value as expected %v %v", ret, err)
+		}
+	})
+
+	t.Run("AsCommandInfo", func(t *testing.T) {
+		if _, err := (RedisResult{err: errors.New("other")}).AsCommandInfo(); err == nil {
+			t.Fatal("AsCommandInfo not failed as expected")
+		}
+		if _, err := (RedisResult{val: RedisMessage{typ: '-'}}).AsCommandInfo(); err == nil {
+			t.Fatal("AsCommandInfo not failed as expected")
+		}
+		expected := []CommandInfo{
+			{Name: "get", Arity: 2, Flags: []string{"readonly", "fast"}, FirstKey: 1, LastKey: 1, Step: 1, ACLCategories: []string{"@read", "@string", "@fast"}},
+			{},
+			{Name: "mset", Arity: -3, Flags: []string{"write", "denyoom"}, FirstKey: 1, LastKey: -1, Step: 2},
+			{Name: "ping", Arity: -1, Flags: []string{}, ACLCategories: []string{}},
+		}
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '*', values: []RedisMessage{
+				{typ: '$', string: "get"},
+				{typ: ':', integer: 2},
+				{typ: '~', values: []RedisMessage{{typ: '+', string: "readonly"}, {typ: '+', string: "fast"}}},
+				{typ: ':', integer: 1},
+				{typ: ':', integer: 1},
+				{typ: ':', integer: 1},
+				{typ: '~', values: []RedisMessage{{typ: '+', string: "@read"}, {typ: '+', string: "@string"}, {typ: '+', string: "@fast"}}},
+				{typ: '*', values: []RedisMessage{}},
+			}},
+			{typ: '_'},
+			{typ: '*', values: []RedisMessage{
+				{typ: '$', string: "mset"},
+				{typ: ':', integer: -3},
+				{typ: '*', values: []RedisMessage{{typ: '+', string: "write"}, {typ: '+', string: "denyoom"}}},
+				{typ: ':', integer: 1},
+				{typ: ':', integer: -1},
+				{typ: ':', integer: 2},
+			}},
+			{typ: '*', values: []RedisMessage{
+				{typ: '$', string: "ping"},
+				{typ: ':', integer: -1},
+				{typ: '~', values: []RedisMessage{}},
+				{typ: ':', integer: 0},
+				{typ: ':', integer: 0},
+				{typ: ':', integer: 0},
+				{typ: '~', values: []RedisMessage{}},
+			}},
+		}}}).AsCommandInfo(); err != nil || !reflect.DeepEqual(expected, ret) {
+			t.Fatalf("AsCommandInfo not get value as expected %v %v", ret, err)
+		}
+	})
+
 	t.Run("AsLMPop", func(t *testing.T) {
 		if _, err := (RedisResult{err: errors.New("other")}).AsLMPop(); err == nil {
 			t.Fatal("AsLMPop not failed as expected")
@@ -607,6 +870,9 @@ func TestRedisResult(t *testing.T) {
 		if _, err := (RedisResult{val: RedisMessage{typ: '-'}}).AsLMPop(); err == nil {
 			t.Fatal("AsLMPop not failed as expected")
 		}
+		if _, err := (RedisResult{val: RedisMessage{typ: '_'}}).AsLMPop(); !IsRedisNil(err) {
+			t.Fatalf("AsLMPop not failed with redis nil on blocking timeout %v", err)
+		}
 		if ret, _ := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
 			{typ: '+', string: "k"},
 			{typ: '*', values: []RedisMessage{
@@ -628,6 +894,9 @@ func TestRedisResult(t *testing.T) {
 		if _, err := (RedisResult{val: RedisMessage{typ: '-'}}).AsZMPop(); err == nil {
 			t.Fatal("AsZMPop not failed as expected")
 		}
+		if _, err := (RedisResult{val: RedisMessage{typ: '_'}}).AsZMPop(); !IsRedisNil(err) {
+			t.Fatalf("AsZMPop not failed with redis nil on blocking timeout %v", err)
+		}
 		if ret, _ := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
 			{typ: '+', string: "k"},
 			{typ: '*', values: []RedisMessage{
@@ -1200,6 +1469,45 @@ func TestRedisResult(t *testing.T) {
 		}}}).AsGeosearch(); err == nil {
 			t.Fatal("AsGeosearch not failed as expected")
 		}
+		//With unparsable coordinates
+		for _, cord := range [][]RedisMessage{
+			{{typ: '$', string: "wrong longitude"}, {typ: '$', string: "139.6503"}},
+			{{typ: '$', string: "35.6762"}, {typ: '$', string: "wrong latitude"}},
+		} {
+			if _, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+				{typ: '*', values: []RedisMessage{
+					{typ: '$', string: "k2"},
+					{typ: '*', values: cord},
+				}},
+			}}}).AsGeosearch(); err == nil {
+				t.Fatal("AsGeosearch not failed as expected")
+			}
+		}
+		//WithDist, WithHash, WithCoord in RESP2
+		if ret, err := (RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '*', values: []RedisMessage{
+				{typ: '$', string: "k1"},
+				{typ: '$', string: "0.0000"},
+				{typ: ':', integer: 3479099956230698},
+				{typ: '*', values: []RedisMessage{
+					{typ: '$', string: "13.36138933897018433"},
+					{typ: '$', string: "38.11555639549629859"},
+				}},
+			}},
+		}}}).AsGeosearch(); err != nil || !reflect.DeepEqual([]GeoLocation{
+			{Name: "k1", GeoHash: 3479099956230698, Longitude: 13.36138933897018433, Latitude: 38.11555639549629859},
+		}, ret) {
+			t.Fatalf("AsGeosearch not get value as expected %v %v", ret, err)
+		}
+	})
+
+	t.Run("GEODIST", func(t *testing.T) {
+		if v, err := (RedisResult{val: RedisMessage{typ: '$', string: "166274.1516"}}).AsFloat64(); err != nil || v != 166274.1516 {
+			t.Fatalf("unexpected distance %v %v", v, err)
+		}
+		if _, err := (RedisResult{val: RedisMessage{typ: '_'}}).AsFloat64(); !IsRedisNil(err) {
+			t.Fatalf("unexpected err %v for missing members", err)
+		}
 	})
 
 	t.Run("IsCacheHit", func(t *testing.T) {
@@ -1711,6 +2019,85 @@ func TestRedisMessage(t *testing.T) {
 		}
 	})
 
+	t.Run("AsXPending", func(t *testing.T) {
+		for _, m := range []*RedisMessage{
+			{typ: '_'},
+			{typ: '*', values: []RedisMessage{{typ: ':', integer: 1}}},
+			{typ: '*', values: []RedisMessage{{typ: '+', string: "x"}, {typ: '_'}, {typ: '_'}, {typ: '_'}}},
+			{typ: '*', values: []RedisMessage{{typ: ':', integer: 1}, {typ: ':', integer: 1}, {typ: '$', string: "1-0"}, {typ: '*'}}},
+			{typ: '*', values: []RedisMessage{{typ: ':', integer: 1}, {typ: '$', string: "1-0"}, {typ: ':', integer: 1}, {typ: '*'}}},
+			{typ: '*', values: []RedisMessage{{typ: ':', integer: 1}, {typ: '$', string: "1-0"}, {typ: '$', string: "1-0"}, {typ: '_'}}},
+			{typ: '*', values: []RedisMessage{{typ: ':', integer: 1}, {typ: '$', string: "1-0"}, {typ: '$', string: "1-0"}, {typ: '*', values: []RedisMessage{{typ: '$', string: "c1"}}}}},
+			{typ: '*', values: []RedisMessage{{typ: ':', integer: 1}, {typ: '$', string: "1-0"}, {typ: '$', string: "1-0"}, {typ: '*', values: []RedisMessage{
+				{typ: '*', values: []RedisMessage{{typ: ':', integer: 1}, {typ: '$', string: "1"}}},
+			}}}},
+			{typ: '*', values: []RedisMessage{{typ: ':', integer: 1}, {typ: '$', string: "1-0"}, {typ: '$', string: "1-0"}, {typ: '*', values: []RedisMessage{
+				{typ: '*', values: []RedisMessage{{typ: '$', string: "c1"}, {typ: '$', string: "x"}}},
+			}}}},
+		} {
+			if _, err := m.AsXPending(); err == nil {
+				t.Fatalf("AsXPending not failed as expected %v", m)
+			}
+		}
+	})
+
+	t.Run("AsXPendingExt", func(t *testing.T) {
+		entry := func(values ...RedisMessage) *RedisMessage {
+			return &RedisMessage{typ: '*', values: []RedisMessage{{typ: '*', values: values}}}
+		}
+		for _, m := range []*RedisMessage{
+			{typ: '_'},
+			entry(RedisMessage{typ: '$', string: "1-0"}),
+			entry(RedisMessage{typ: ':', integer: 1}, RedisMessage{typ: '$', string: "c1"}, RedisMessage{typ: ':', integer: 1}, RedisMessage{typ: ':', integer: 1}),
+			entry(RedisMessage{typ: '$', string: "1-0"}, RedisMessage{typ: ':', integer: 1}, RedisMessage{typ: ':', integer: 1}, RedisMessage{typ: ':', integer: 1}),
+			entry(RedisMessage{typ: '$', string: "1-0"}, RedisMessage{typ: '$', string: "c1"}, RedisMessage{typ: '$', string: "x"}, RedisMessage{typ: ':', integer: 1}),
+			entry(RedisMessage{typ: '$', string: "1-0"}, RedisMessage{typ: '$', string: "c1"}, RedisMessage{typ: ':', integer: 1}, RedisMessage{typ: '$', string: "x"}),
+		} {
+			if _, err := m.AsXPendingExt(); err == nil {
+				t.Fatalf("AsXPendingExt not failed as expected %v", m)
+			}
+		}
+	})
+
+	t.Run("AsVSim", func(t *testing.T) {
+		for _, m := range []*RedisMessage{
+			{typ: '_'},
+			{typ: '%', values: []RedisMessage{{typ: ':', integer: 1}, {typ: ',', string: "0.5"}}},
+			{typ: '%', values: []RedisMessage{{typ: '$', string: "m1"}, {typ: '$', string: "x"}}},
+			{typ: '*', values: []RedisMessage{{typ: '$', string: "m1"}, {typ: '$', string: "x"}}},
+			{typ: '*', values: []RedisMessage{{typ: '$', string: "m1"}, {typ: '$', string: "x"}, {typ: '_'}}},
+			{typ: '*', values: []RedisMessage{{typ: '$', string: "m1"}, {typ: '$', string: "0.5"}, {typ: '_'}, {typ: '$', string: "m2"}}},
+		} {
+			if _, err := m.AsVSim(); err == nil {
+				t.Fatalf("AsVSim not failed as expected %v", m)
+			}
+		}
+	})
+
+	t.Run("AsCommandInfo", func(t *testing.T) {
+		info := func(values ...RedisMessage) *RedisMessage {
+			return &RedisMessage{typ: '*', values: []RedisMessage{{typ: '*', values: values}}}
+		}
+		name, arity, pos := RedisMessage{typ: '$', string: "get"}, RedisMessage{typ: ':', integer: 2}, RedisMessage{typ: ':', integer: 1}
+		flags := RedisMessage{typ: '~', values: []RedisMessage{{typ: '+', string: "readonly"}}}
+		for _, m := range []*RedisMessage{
+			{typ: '_'},
+			{typ: '*', values: []RedisMessage{{typ: ':', integer: 1}}},
+			info(name, arity, flags, pos, pos),
+			info(RedisMessage{typ: ':', integer: 1}, arity, flags, pos, pos, pos),
+			info(name, name, flags, pos, pos, pos),
+			info(name, arity, pos, pos, pos, pos),
+			info(name, arity, flags, name, pos, pos),
+			info(name, arity, flags, pos, name, pos),
+			info(name, arity, flags, pos, pos, name),
+			info(name, arity, flags, pos, pos, pos, pos),
+		} {
+			if _, err := m.AsCommandInfo(); err == nil {
+				t.Fatalf("AsCommandInfo not failed as expected %v", m)
+			}
+		}
+	})
+
 	t.Run("AsXRead", func(t *testing.T) {
 		if _, err := (&RedisMessage{typ: '_'}).AsXRead(); err == nil {
 			t.Fatal("AsXRead did not fail as expected")
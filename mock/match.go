@@ -17,6 +17,16 @@ func Match(cmd ...string) gomock.Matcher {
 	)
 }
 
+// MatchCmd is like Match, but the expected command is built by the same command builder, for example:
+// mock.MatchCmd(client.B().Get().Key("key").Build()). It accepts rueidis.Completed, rueidis.Cacheable and rueidis.CacheableTTL.
+func MatchCmd(cmd any) gomock.Matcher {
+	expect, ok := commands(cmd).([]string)
+	if !ok {
+		panic(fmt.Sprintf("mock.MatchCmd only accepts redis commands, got %T", cmd))
+	}
+	return Match(append([]string(nil), expect...)...)
+}
+
 type cmdMatcher struct {
 	expect []string
 }
@@ -17,6 +17,32 @@ func TestMatch_Completed(t *testing.T) {
 	}
 }
 
+func TestMatchCmd(t *testing.T) {
+	builder := cmds.NewBuilder(cmds.NoSlot)
+	for _, expect := range []any{
+		builder.Get().Key("k").Build(),
+		builder.Get().Key("k").Cache(),
+		rueidis.CacheableTTL{Cmd: builder.Get().Key("k").Cache()},
+	} {
+		m := MatchCmd(expect)
+		if !m.Matches(builder.Get().Key("k").Build()) {
+			t.Fatalf("not matched %s", m.String())
+		}
+		if m.Matches(builder.Get().Key("other").Build()) {
+			t.Fatalf("unexpected matched %s", m.String())
+		}
+	}
+}
+
+func TestMatchCmd_Panic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("MatchCmd should panic on non-command values")
+		}
+	}()
+	MatchCmd("GET")
+}
+
 func TestMatchFn_Completed(t *testing.T) {
 	cmd := cmds.NewBuilder(cmds.NoSlot).Get().Key("k").Build()
 	if m := MatchFn(func(cmd []string) bool {
@@ -22,6 +22,12 @@ type singleconnect struct {
 	g sync.WaitGroup
 }
 
+// redial tracks the consecutive failed dials of a pipeline wire, so that the next dial is not attempted until the at.
+type redial struct {
+	at       time.Time
+	attempts int
+}
+
 type batchcache struct {
 	cIndexes []int
 	commands []CacheableTTL
@@ -57,10 +63,13 @@ type conn interface {
 	Close()
 	Dial() error
 	Override(conn)
-	Acquire() wire
+	Acquire(ctx context.Context) (wire, error)
 	Store(w wire)
+	Pin() wire
 	Addr() string
 	SetOnCloseHook(func(error))
+	Stats() ConnStat
+	FlushCache(keys []string)
 }
 
 var _ conn = (*mux)(nil)
@@ -71,18 +80,29 @@ type mux struct {
 	clhks  atomic.Value
 	dpool  *pool
 	spool  *pool
+	limit  *connlimit
 	wireFn wireFn
+	stats  *connstats
 	dst    string
 	wire   []atomic.Value
 	sc     []*singleconnect
+	rd     []redial
 	mu     []sync.Mutex
+	delay  func(attempt int) time.Duration
 	maxp   int
+	minc   int
 }
 
 func makeMux(dst string, option *ClientOption, dialFn dialFn) *mux {
 	dead := deadFn()
+	stats := &connstats{}
 	connFn := func() (net.Conn, error) {
-		return dialFn(dst, option)
+		atomic.AddUint64(&stats.dials, 1)
+		conn, err := dialFn(dst, option)
+		if err != nil {
+			return nil, err
+		}
+		return &statsConn{Conn: conn, s: stats}, nil
 	}
 	wireFn := func(pipeFn pipeFn) func() wire {
 		return func() (w wire) {
@@ -94,21 +114,33 @@ func makeMux(dst string, option *ClientOption, dialFn dialFn) *mux {
 			return w
 		}
 	}
-	return newMux(dst, option, (*pipe)(nil), dead, wireFn(newPipe), wireFn(newPipeNoBg))
+	m := newMux(dst, option, (*pipe)(nil), dead, wireFn(newPipe), wireFn(newPipeNoBg))
+	m.stats = stats
+	if m.delay = option.ReconnectBackoff; m.delay == nil {
+		m.delay = defaultReconnectBackoff
+	}
+	return m
 }
 
-func newMux(dst string, option *ClientOption, init, dead wire, wireFn wireFn, wireNoBgFn wireFn) *mux {
-	var multiplex int
-	if option.PipelineMultiplex >= 0 {
-		multiplex = 1 << option.PipelineMultiplex
-	} else {
-		multiplex = 1
+// defaultReconnectBackoff is an exponential backoff, starting from 10ms and capped at 640ms, with jitter in its second half,
+// so that clients disconnected at the same time will not reconnect at the same time.
+func defaultReconnectBackoff(attempt int) time.Duration {
+	if attempt > 7 {
+		attempt = 7
 	}
+	d := 10 * time.Millisecond << (attempt - 1)
+	return d/2 + time.Duration(util.FastRand(int(d/2)))
+}
+
+func newMux(dst string, option *ClientOption, init, dead wire, wireFn wireFn, wireNoBgFn wireFn) *mux {
+	multiplex := pipelineConns(option.PipelineMultiplex)
 	m := &mux{dst: dst, init: init, dead: dead, wireFn: wireFn,
 		wire: make([]atomic.Value, multiplex),
 		mu:   make([]sync.Mutex, multiplex),
 		sc:   make([]*singleconnect, multiplex),
+		rd:   make([]redial, multiplex),
 		maxp: runtime.GOMAXPROCS(0),
+		minc: option.MinConns,
 	}
 	m.clhks.Store(emptyclhks)
 	for i := 0; i < len(m.wire); i++ {
@@ -116,6 +148,13 @@ func newMux(dst string, option *ClientOption, init, dead wire, wireFn wireFn, wi
 	}
 	m.dpool = newPool(option.BlockingPoolSize, dead, wireFn)
 	m.spool = newPool(option.BlockingPoolSize, dead, wireNoBgFn)
+	m.dpool.expire(option.MaxConnIdleTime, option.ConnLifetime, option.MinConns)
+	m.spool.expire(option.MaxConnIdleTime, option.ConnLifetime, 0)
+	if option.MaxConns > 0 {
+		m.limit = newConnLimit(option.MaxConns) // the NewClient ensures at least one is left for blocking commands
+		m.dpool.limit = m.limit
+		m.spool.limit = m.limit
+	}
 	return m
 }
 
@@ -127,7 +166,7 @@ func (m *mux) setCloseHookOnWire(i uint16, w wire) {
 	if w != m.dead && w != m.init {
 		w.SetOnCloseHook(func(err error) {
 			if err != ErrClosing {
-				if m.wire[i].CompareAndSwap(w, m.init) {
+				if m.unpipe(i, w) {
 					m.clhks.Load().(func(error))(err)
 				}
 			}
@@ -139,13 +178,21 @@ func (m *mux) Override(cc conn) {
 	if m2, ok := cc.(*mux); ok {
 		for i := 0; i < len(m.wire) && i < len(m2.wire); i++ {
 			w := m2.wire[i].Load().(wire)
+			if m.limit != nil && w != m2.init && w != m2.dead && !m.limit.try() {
+				continue // leave it to be dialed again under the MaxConns of the new m
+			}
 			m.setCloseHookOnWire(uint16(i), w) // bind the new m to the old w
-			m.wire[i].CompareAndSwap(m.init, w)
+			if !m.wire[i].CompareAndSwap(m.init, w) && m.limit != nil && w != m2.init && w != m2.dead {
+				m.limit.give()
+			}
 		}
 	}
 }
 
-func (m *mux) _pipe(i uint16) (w wire, err error) {
+// _pipe returns the pipeline wire i, and dials it if it is not established. Under the MaxConns, the dial takes
+// one of the connections from the connlimit and waits for it until the ctx is done, and the connection is held
+// by the wire until it is broken or closed.
+func (m *mux) _pipe(ctx context.Context, i uint16) (w wire, err error) {
 	if w = m.wire[i].Load().(wire); w != m.init {
 		return w, nil
 	}
@@ -164,11 +211,23 @@ func (m *mux) _pipe(i uint16) (w wire, err error) {
 	}
 
 	if w = m.wire[i].Load().(wire); w == m.init {
-		if w = m.wireFn(); w != m.dead {
+		if rd := &m.rd[i]; rd.attempts > 0 && time.Now().Before(rd.at) {
+			w, err = m.dead, m.dead.Error() // fail fast instead of making the callers wait for the backoff.
+		} else if err = m.take(ctx); err != nil {
+			w = m.dead
+		} else if w = m.wireFn(); w != m.dead {
+			*rd = redial{}
 			m.setCloseHookOnWire(i, w)
 			m.wire[i].Store(w)
 		} else {
+			if m.limit != nil {
+				m.limit.give()
+			}
 			if err = w.Error(); err != ErrClosing {
+				if m.delay != nil {
+					rd.attempts++
+					rd.at = time.Now().Add(m.delay(rd.attempts))
+				}
 				m.clhks.Load().(func(error))(err)
 			}
 		}
@@ -187,13 +246,38 @@ func (m *mux) _pipe(i uint16) (w wire, err error) {
 }
 
 func (m *mux) pipe(i uint16) wire {
-	w, _ := m._pipe(i)
+	w, _ := m._pipe(context.Background(), i)
 	return w // this should never be nil
 }
 
+func (m *mux) take(ctx context.Context) error {
+	if m.limit != nil {
+		return m.limit.take(ctx)
+	}
+	return nil
+}
+
+// unpipe makes the broken wire i be dialed again and gives its connection back to the connlimit.
+func (m *mux) unpipe(i uint16, w wire) bool {
+	if m.wire[i].CompareAndSwap(w, m.init) {
+		if m.limit != nil {
+			m.limit.give()
+		}
+		return true
+	}
+	return false
+}
+
 func (m *mux) Dial() error {
-	_, err := m._pipe(0)
-	return err
+	if _, err := m._pipe(context.Background(), 0); err != nil || m.minc <= 0 {
+		return err
+	}
+	for i := 1; i < len(m.wire); i++ {
+		if _, err := m._pipe(context.Background(), uint16(i)); err != nil {
+			return err
+		}
+	}
+	return m.dpool.Warmup(m.minc)
 }
 
 func (m *mux) Info() map[string]RedisMessage {
@@ -209,12 +293,18 @@ func (m *mux) Error() error {
 }
 
 func (m *mux) DoStream(ctx context.Context, cmd Completed) RedisResultStream {
-	wire := m.spool.Acquire()
+	wire, err := m.spool.AcquireContext(ctx)
+	if err != nil {
+		return RedisResultStream{e: err}
+	}
 	return wire.DoStream(ctx, m.spool, cmd)
 }
 
 func (m *mux) DoMultiStream(ctx context.Context, multi ...Completed) MultiRedisResultStream {
-	wire := m.spool.Acquire()
+	wire, err := m.spool.AcquireContext(ctx)
+	if err != nil {
+		return MultiRedisResultStream{e: err}
+	}
 	return wire.DoMultiStream(ctx, m.spool, multi...)
 }
 
@@ -224,10 +314,19 @@ func (m *mux) Do(ctx context.Context, cmd Completed) (resp RedisResult) {
 	} else {
 		resp = m.pipeline(ctx, cmd)
 	}
+	m.invalidateOwn(cmd)
 	return resp
 }
 
 func (m *mux) DoMulti(ctx context.Context, multi ...Completed) (resp *redisresults) {
+	resp = m.doMulti(ctx, multi)
+	for _, cmd := range multi {
+		m.invalidateOwn(cmd)
+	}
+	return resp
+}
+
+func (m *mux) doMulti(ctx context.Context, multi []Completed) (resp *redisresults) {
 	for _, cmd := range multi {
 		if cmd.IsBlock() {
 			goto block
@@ -239,8 +338,25 @@ block:
 	return m.blockingMulti(ctx, multi)
 }
 
+// invalidateOwn deletes the cached responses of the key read and modified by GETDEL or GETEX with options from the wire serving
+// the DoCache of the key, because redis sends the invalidation notification of our own modification after the response,
+// and a following DoCache of the key should not be served by the stale cached response before the notification arrives.
+func (m *mux) invalidateOwn(cmd Completed) {
+	if !cmd.IsWrite() {
+		return
+	}
+	if s := cmd.Commands(); len(s) >= 2 && (s[0] == "GETDEL" || (s[0] == "GETEX" && len(s) > 2)) {
+		if w := m.wire[cmd.Slot()&uint16(len(m.wire)-1)].Load().(wire); w != m.init && w != m.dead {
+			w.FlushCache(s[1:2])
+		}
+	}
+}
+
 func (m *mux) blocking(ctx context.Context, cmd Completed) (resp RedisResult) {
-	wire := m.dpool.Acquire()
+	wire, err := m.dpool.AcquireContext(ctx)
+	if err != nil {
+		return newErrResult(err)
+	}
 	resp = wire.Do(ctx, cmd)
 	if resp.NonRedisError() != nil { // abort the wire if blocking command return early (ex. context.DeadlineExceeded)
 		wire.Close()
@@ -249,8 +365,19 @@ func (m *mux) blocking(ctx context.Context, cmd Completed) (resp RedisResult) {
 	return resp
 }
 
+func errresults(n int, err error) (resp *redisresults) {
+	resp = resultsp.Get(n, n)
+	for i := range resp.s {
+		resp.s[i] = newErrResult(err)
+	}
+	return resp
+}
+
 func (m *mux) blockingMulti(ctx context.Context, cmd []Completed) (resp *redisresults) {
-	wire := m.dpool.Acquire()
+	wire, err := m.dpool.AcquireContext(ctx)
+	if err != nil {
+		return errresults(len(cmd), err)
+	}
 	resp = wire.DoMulti(ctx, cmd...)
 	for _, res := range resp.s {
 		if res.NonRedisError() != nil { // abort the wire if blocking command return early (ex. context.DeadlineExceeded)
@@ -264,20 +391,26 @@ func (m *mux) blockingMulti(ctx context.Context, cmd []Completed) (resp *redisre
 
 func (m *mux) pipeline(ctx context.Context, cmd Completed) (resp RedisResult) {
 	slot := slotfn(len(m.wire), cmd.Slot(), cmd.NoReply())
-	wire := m.pipe(slot)
+	wire, err := m._pipe(ctx, slot)
+	if err != nil {
+		return newErrResult(err)
+	}
 	if resp = wire.Do(ctx, cmd); isBroken(resp.NonRedisError(), wire) {
-		m.wire[slot].CompareAndSwap(wire, m.init)
+		m.unpipe(slot, wire)
 	}
 	return resp
 }
 
 func (m *mux) pipelineMulti(ctx context.Context, cmd []Completed) (resp *redisresults) {
 	slot := slotfn(len(m.wire), cmd[0].Slot(), cmd[0].NoReply())
-	wire := m.pipe(slot)
+	wire, err := m._pipe(ctx, slot)
+	if err != nil {
+		return errresults(len(cmd), err)
+	}
 	resp = wire.DoMulti(ctx, cmd...)
 	for _, r := range resp.s {
 		if isBroken(r.NonRedisError(), wire) {
-			m.wire[slot].CompareAndSwap(wire, m.init)
+			m.unpipe(slot, wire)
 			return resp
 		}
 	}
@@ -286,10 +419,13 @@ func (m *mux) pipelineMulti(ctx context.Context, cmd []Completed) (resp *redisre
 
 func (m *mux) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) RedisResult {
 	slot := cmd.Slot() & uint16(len(m.wire)-1)
-	wire := m.pipe(slot)
+	wire, err := m._pipe(ctx, slot)
+	if err != nil {
+		return newErrResult(err)
+	}
 	resp := wire.DoCache(ctx, cmd, ttl)
 	if isBroken(resp.NonRedisError(), wire) {
-		m.wire[slot].CompareAndSwap(wire, m.init)
+		m.unpipe(slot, wire)
 	}
 	return resp
 }
@@ -344,11 +480,14 @@ func (m *mux) DoMultiCache(ctx context.Context, multi ...CacheableTTL) (results
 }
 
 func (m *mux) doMultiCache(ctx context.Context, slot uint16, multi []CacheableTTL) (resps *redisresults) {
-	wire := m.pipe(slot)
+	wire, err := m._pipe(ctx, slot)
+	if err != nil {
+		return errresults(len(multi), err)
+	}
 	resps = wire.DoMultiCache(ctx, multi...)
 	for _, r := range resps.s {
 		if isBroken(r.NonRedisError(), wire) {
-			m.wire[slot].CompareAndSwap(wire, m.init)
+			m.unpipe(slot, wire)
 			return resps
 		}
 	}
@@ -357,16 +496,28 @@ func (m *mux) doMultiCache(ctx context.Context, slot uint16, multi []CacheableTT
 
 func (m *mux) Receive(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
 	slot := slotfn(len(m.wire), subscribe.Slot(), subscribe.NoReply())
-	wire := m.pipe(slot)
-	err := wire.Receive(ctx, subscribe, fn)
+	wire, err := m._pipe(ctx, slot)
+	if err != nil {
+		return err
+	}
+	err = wire.Receive(ctx, subscribe, fn)
 	if isBroken(err, wire) {
-		m.wire[slot].CompareAndSwap(wire, m.init)
+		m.unpipe(slot, wire)
 	}
 	return err
 }
 
-func (m *mux) Acquire() wire {
-	return m.dpool.Acquire()
+func (m *mux) Acquire(ctx context.Context) (wire, error) {
+	return m.dpool.AcquireContext(ctx)
+}
+
+// Pin returns one of the auto pipelining wires for sending a sequence of commands on the same connection.
+func (m *mux) Pin() wire {
+	var i uint16
+	if len(m.wire) > 1 {
+		i = uint16(util.FastRand(len(m.wire)))
+	}
+	return m.pipe(i)
 }
 
 func (m *mux) Store(w wire) {
@@ -379,12 +530,29 @@ func (m *mux) Close() {
 	for i := 0; i < len(m.wire); i++ {
 		if prev := m.wire[i].Swap(m.dead).(wire); prev != m.init && prev != m.dead {
 			prev.Close()
+			if m.limit != nil {
+				m.limit.give()
+			}
 		}
 	}
 	m.dpool.Close()
 	m.spool.Close()
 }
 
+func (m *mux) Stats() ConnStat {
+	s := m.stats.stat(m.dst)
+	if m.limit != nil {
+		s.Waiting = m.limit.waits()
+	}
+	return s
+}
+
+func (m *mux) FlushCache(keys []string) {
+	for i := 0; i < len(m.wire); i++ {
+		m.wire[i].Load().(wire).FlushCache(keys)
+	}
+}
+
 func (m *mux) Addr() string {
 	return m.dst
 }
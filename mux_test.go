@@ -36,11 +36,115 @@ func setupMuxWithOption(wires []*mockWire, option *ClientOption) (conn *mux, che
 	}
 }
 
+func TestMuxReconnectBackoff(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var attempts []int
+	var dials int
+	e := errors.New("any")
+	fail := true
+	n1, n2 := net.Pipe()
+	defer n1.Close()
+	defer n2.Close()
+	m := makeMux("", &ClientOption{
+		ReconnectBackoff: func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			return time.Hour
+		},
+	}, func(dst string, opt *ClientOption) (net.Conn, error) {
+		dials++
+		if fail {
+			return nil, e
+		}
+		return n1, nil
+	})
+	if err := m.Dial(); err != e || dials != 1 {
+		t.Fatalf("unexpected return %v %v", err, dials)
+	}
+	for i := 0; i < 3; i++ {
+		if err := m.Dial(); err != e || dials != 1 {
+			t.Fatalf("unexpected return %v %v, the dial should fail fast during the backoff", err, dials)
+		}
+	}
+	m.rd[0].at = time.Now() // the backoff passes
+	if err := m.Dial(); err != e || dials != 2 {
+		t.Fatalf("unexpected return %v %v", err, dials)
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Fatalf("unexpected attempts %v", attempts)
+	}
+	fail = false
+	m.rd[0].at = time.Now()
+	go func() {
+		mock := &redisMock{buf: bufio.NewReader(n2), conn: n2, t: t}
+		mock.Expect("HELLO", "3").
+			Reply(RedisMessage{
+				typ: '%',
+				values: []RedisMessage{
+					{typ: '+', string: "proto"},
+					{typ: ':', integer: 3},
+				},
+			})
+		mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").ReplyString("OK")
+		mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).ReplyError("UNKNOWN COMMAND")
+		mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).ReplyError("UNKNOWN COMMAND")
+		mock.Expect("PING").ReplyString("OK")
+		mock.Close()
+	}()
+	if err := m.Dial(); err != nil || dials != 3 {
+		t.Fatalf("unexpected return %v %v", err, dials)
+	}
+	if len(attempts) != 2 || m.rd[0] != (redial{}) {
+		t.Fatalf("unexpected attempts %v %v", attempts, m.rd[0])
+	}
+	m.Close()
+}
+
+func TestMuxReconnectBackoffEachWire(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var dials int
+	e := errors.New("any")
+	m := makeMux("", &ClientOption{
+		PipelineMultiplex: 1,
+		ReconnectBackoff: func(attempt int) time.Duration {
+			return time.Hour
+		},
+	}, func(dst string, opt *ClientOption) (net.Conn, error) {
+		dials++
+		return nil, e
+	})
+	defer m.Close()
+	if _, err := m._pipe(context.Background(), 0); err != e || dials != 1 {
+		t.Fatalf("unexpected return %v %v", err, dials)
+	}
+	if _, err := m._pipe(context.Background(), 1); err != e || dials != 2 {
+		t.Fatalf("unexpected return %v %v, the other wire should not be backed off", err, dials)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Do(ctx, cmds.NewCompleted([]string{"PING"})).Error(); err != e || dials != 2 {
+		t.Fatalf("unexpected return %v %v", err, dials)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("the command should not wait for the backoff")
+	}
+}
+
+func TestDefaultReconnectBackoff(t *testing.T) {
+	for attempt, max := range []time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 7: 640 * time.Millisecond, 8: 640 * time.Millisecond} {
+		if max == 0 {
+			continue
+		}
+		if d := defaultReconnectBackoff(attempt); d < max/2 || d >= max {
+			t.Fatalf("unexpected backoff %v for attempt %v", d, attempt)
+		}
+	}
+}
+
 func TestNewMuxDailErr(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	c := 0
 	e := errors.New("any")
-	m := makeMux("", &ClientOption{}, func(dst string, opt *ClientOption) (net.Conn, error) {
+	m := makeMux("", &ClientOption{ReconnectBackoff: func(attempt int) time.Duration { return 0 }}, func(dst string, opt *ClientOption) (net.Conn, error) {
 		c++
 		return nil, e
 	})
@@ -56,7 +160,7 @@ func TestNewMuxDailErr(t *testing.T) {
 	if err := m.Dial(); err != e { // c = 3
 		t.Fatalf("unexpected return %v", err)
 	}
-	if w := m.Acquire(); w != m.dead {
+	if w, _ := m.Acquire(context.Background()); w != m.dead {
 		t.Fatalf("unexpected wire %v", w)
 	}
 	if c != 4 {
@@ -64,6 +168,149 @@ func TestNewMuxDailErr(t *testing.T) {
 	}
 }
 
+func TestNewMuxConnExpiry(t *testing.T) {
+	m := makeMux("", &ClientOption{MaxConnIdleTime: time.Minute, ConnLifetime: time.Hour}, func(dst string, opt *ClientOption) (net.Conn, error) {
+		return nil, errors.New("any")
+	})
+	for _, p := range []*pool{m.dpool, m.spool} {
+		if p.idle != time.Minute || p.life != time.Hour || p.born == nil {
+			t.Fatalf("unexpected pool expiry %v %v", p.idle, p.life)
+		}
+	}
+}
+
+func TestMuxMaxConns(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	blocking := make(chan struct{})
+	response := make(chan RedisResult)
+	m, checkClean := setupMuxWithOption([]*mockWire{
+		{}, // the pipeline connection
+		{
+			DoFn: func(cmd Completed) RedisResult {
+				blocking <- struct{}{}
+				return <-response
+			},
+		},
+	}, &ClientOption{MaxConns: 2}) // one pipeline connection and one for blocking commands
+	defer checkClean(t)
+	defer m.Close()
+	if err := m.Dial(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	go func() {
+		if val, err := m.Do(context.Background(), cmds.NewBlockingCompleted([]string{"BLPOP"})).ToString(); err != nil || val != "BLOCK_RESPONSE" {
+			t.Errorf("unexpected response %v %v", val, err)
+		}
+		close(blocking)
+	}()
+	<-blocking
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	multi := make(chan struct{})
+	go func() {
+		for m.Stats().Waiting != 1 {
+			runtime.Gosched()
+		}
+		for _, resp := range m.DoMulti(ctx, cmds.NewBlockingCompleted([]string{"BLPOP"}), cmds.NewCompleted([]string{"GET"})).s {
+			if err := resp.Error(); err != context.DeadlineExceeded {
+				t.Errorf("unexpected err %v", err)
+			}
+		}
+		close(multi)
+	}()
+	if err := m.Do(ctx, cmds.NewBlockingCompleted([]string{"BLPOP"})).Error(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	<-multi
+	if s := m.DoStream(ctx, cmds.NewCompleted([]string{"GET"})); s.Error() != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", s.Error())
+	}
+	if s := m.DoMultiStream(ctx, cmds.NewCompleted([]string{"GET"})); s.Error() != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", s.Error())
+	}
+	if _, err := m.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if w := m.Stats().Waiting; w != 0 {
+		t.Fatalf("unexpected waiting %v", w)
+	}
+
+	response <- newResult(RedisMessage{typ: '+', string: "BLOCK_RESPONSE"}, nil)
+	<-blocking
+}
+
+func TestMuxMaxConnsPipeline(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	broken := errors.New("broken")
+	m, checkClean := setupMuxWithOption([]*mockWire{
+		{
+			DoFn: func(cmd Completed) RedisResult {
+				return newErrResult(broken)
+			},
+			ErrorFn: func() error {
+				return broken
+			},
+		},
+		{}, // the connections taken by the Acquire
+		{},
+		{
+			DoFn: func(cmd Completed) RedisResult {
+				return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+			},
+		},
+	}, &ClientOption{MaxConns: 2})
+	defer checkClean(t)
+	defer m.Close()
+	if err := m.Dial(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET"})).Error(); err != broken {
+		t.Fatalf("unexpected err %v", err)
+	}
+	// the broken pipeline connection is given back, and the Acquire takes it.
+	w1, err := m.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	w2, err := m.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	waiting := make(chan struct{})
+	go func() {
+		for m.Stats().Waiting != 1 {
+			runtime.Gosched()
+		}
+		close(waiting)
+	}()
+	if err := m.Do(ctx, cmds.NewCompleted([]string{"GET"})).Error(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	<-waiting
+	for _, resp := range m.DoMulti(ctx, cmds.NewCompleted([]string{"GET"})).s {
+		if err := resp.Error(); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	if err := m.DoCache(ctx, Cacheable(cmds.NewCompleted([]string{"GET"})), time.Second).Error(); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if err := m.Receive(ctx, cmds.NewCompleted([]string{"SUBSCRIBE"}), func(message PubSubMessage) {}); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	m.Store(w1)
+	if v, err := m.Do(context.Background(), cmds.NewCompleted([]string{"GET"})).ToString(); err != nil || v != "OK" {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	m.Store(w2)
+}
+
 func TestNewMux(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	n1, n2 := net.Pipe()
@@ -115,6 +362,111 @@ func TestNewMuxPipelineMultiplex(t *testing.T) {
 	}
 }
 
+func TestMuxDialMinConns(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	t.Run("warmup pipes and blocking pool", func(t *testing.T) {
+		var pipes, blocks int64
+		m := newMux("", &ClientOption{PipelineMultiplex: 1, MinConns: 3}, (*mockWire)(nil), (*mockWire)(nil), func() wire {
+			atomic.AddInt64(&pipes, 1)
+			return &mockWire{}
+		}, func() wire {
+			return &mockWire{}
+		})
+		m.dpool.make = func() wire {
+			atomic.AddInt64(&blocks, 1)
+			return &mockWire{}
+		}
+		if err := m.Dial(); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if atomic.LoadInt64(&pipes) != 2 || atomic.LoadInt64(&blocks) != 3 {
+			t.Fatalf("unexpected warmup %v %v", pipes, blocks)
+		}
+	})
+	t.Run("warmup err", func(t *testing.T) {
+		e := errors.New("any")
+		m := newMux("", &ClientOption{MinConns: 2}, (*mockWire)(nil), (*mockWire)(nil), func() wire {
+			return &mockWire{}
+		}, func() wire {
+			return &mockWire{}
+		})
+		m.dpool.make = func() wire {
+			return &mockWire{ErrorFn: func() error { return e }}
+		}
+		if err := m.Dial(); err != e {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
+
+func TestMuxInvalidateOwn(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var mu sync.Mutex
+	newWire := func() *mockWire {
+		cached := map[string]bool{}
+		return &mockWire{
+			DoFn: func(cmd Completed) RedisResult {
+				return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+			},
+			DoMultiFn: func(multi ...Completed) *redisresults {
+				return &redisresults{s: []RedisResult{newResult(RedisMessage{typ: '+', string: "OK"}, nil)}}
+			},
+			DoCacheFn: func(cmd Cacheable, ttl time.Duration) RedisResult {
+				mu.Lock()
+				defer mu.Unlock()
+				key := cmd.Commands()[1]
+				if cached[key] {
+					return newResult(RedisMessage{typ: '+', string: "hit"}, nil)
+				}
+				cached[key] = true
+				return newResult(RedisMessage{typ: '+', string: "miss"}, nil)
+			},
+			FlushCacheFn: func(keys []string) {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, key := range keys {
+					delete(cached, key)
+				}
+			},
+		}
+	}
+	wires := make([]*mockWire, 4)
+	for i := range wires {
+		wires[i] = newWire()
+	}
+	m, _ := setupMuxWithOption(wires, &ClientOption{PipelineMultiplex: 2})
+	defer m.Close()
+
+	get := func() string {
+		v, err := m.DoCache(context.Background(), Cacheable(cmds.NewReadOnlyCompleted([]string{"GET", "a"}).SetSlot("a")), time.Second).ToString()
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return v
+	}
+	for _, c := range [][]string{
+		{"GETDEL", "a"},
+		{"GETEX", "a", "PX", "1"},
+	} {
+		for _, multi := range []bool{false, true} {
+			get()
+			if v := get(); v != "hit" {
+				t.Fatalf("unexpected response %v", v)
+			}
+			// the Do and DoMulti are sent to a random wire, but the eviction should be done on the wire serving the DoCache.
+			cmd := cmds.NewCompleted(c).SetSlot(c[1])
+			if multi {
+				m.DoMulti(context.Background(), cmd)
+			} else {
+				m.Do(context.Background(), cmd)
+			}
+			if v := get(); v != "miss" {
+				t.Fatalf("unexpected response after %v: %v", c[0], v)
+			}
+		}
+	}
+}
+
 func TestMuxAddr(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	m := makeMux("dst1", &ClientOption{}, nil)
@@ -199,7 +551,7 @@ func TestMuxReuseWire(t *testing.T) {
 			t.Fatalf("unexpected dial error %v", err)
 		}
 
-		wire1 := m.Acquire()
+		wire1, _ := m.Acquire(context.Background())
 
 		go func() {
 			// this should use the second wire
@@ -243,7 +595,7 @@ func TestMuxReuseWire(t *testing.T) {
 			t.Fatalf("unexpected dial error %v", err)
 		}
 
-		wire1 := m.Acquire()
+		wire1, _ := m.Acquire(context.Background())
 		m.Store(wire1)
 
 		if !cleaned {
@@ -470,7 +822,7 @@ func TestMuxDelegation(t *testing.T) {
 		defer m.Close()
 
 		for i := range wires {
-			m._pipe(uint16(i))
+			m._pipe(context.Background(), uint16(i))
 		}
 
 		builder := cmds.NewBuilder(cmds.NoSlot)
@@ -512,7 +864,7 @@ func TestMuxDelegation(t *testing.T) {
 		defer m.Close()
 
 		for i := range wires {
-			m._pipe(uint16(i))
+			m._pipe(context.Background(), uint16(i))
 		}
 
 		builder := cmds.NewBuilder(cmds.NoSlot)
@@ -841,6 +1193,7 @@ type mockWire struct {
 	CleanSubscriptionsFn func()
 	SetPubSubHooksFn     func(hooks PubSubHooks) <-chan error
 	SetOnCloseHookFn     func(fn func(error))
+	FlushCacheFn         func(keys []string)
 }
 
 func (m *mockWire) Do(ctx context.Context, cmd Completed) RedisResult {
@@ -911,6 +1264,12 @@ func (m *mockWire) SetOnCloseHook(fn func(error)) {
 	}
 }
 
+func (m *mockWire) FlushCache(keys []string) {
+	if m.FlushCacheFn != nil {
+		m.FlushCacheFn(keys)
+	}
+}
+
 func (m *mockWire) Info() map[string]RedisMessage {
 	if m.InfoFn != nil {
 		return m.InfoFn()
@@ -279,6 +279,42 @@ func TestNewHashRepository(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Concurrent Save", func(t *testing.T) {
+		e := repo.NewEntity()
+		if err := repo.Save(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+		copies := make([]*HashTestStruct, 10)
+		for i := range copies {
+			c := *e
+			copies[i] = &c
+		}
+		errs := make(chan error, len(copies))
+		for _, c := range copies {
+			go func(c *HashTestStruct) {
+				errs <- repo.Save(ctx, c)
+			}(c)
+		}
+		succeeded := 0
+		for range copies {
+			if err := <-errs; err == nil {
+				succeeded++
+			} else if err != ErrVersionMismatch {
+				t.Fatalf("unexpected err %v", err)
+			}
+		}
+		if succeeded != 1 {
+			t.Fatalf("only one concurrent save should succeed, got %d", succeeded)
+		}
+		ei, err := repo.Fetch(ctx, e.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ei.Ver != 2 {
+			t.Fatalf("unexpected ver %d", ei.Ver)
+		}
+	})
 }
 
 type HashTestTTLStruct struct {
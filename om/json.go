@@ -65,6 +65,34 @@ func (r *JSONRepository[T]) FetchCache(ctx context.Context, id string, ttl time.
 	return v, err
 }
 
+// MGet fetches the entities whose names are `{prefix}:{id}` by JSON.MGET, or by JSON.GETs grouped by slots in cluster mode.
+// The returned map is keyed by the ids, and the ids of entities that don't exist are not in the map.
+func (r *JSONRepository[T]) MGet(ctx context.Context, ids []string) (map[string]*T, error) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = key(r.prefix, id)
+	}
+	records, err := rueidis.JsonMGet(r.client, ctx, keys, ".")
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]*T, len(ids))
+	for i, id := range ids {
+		msg := records[keys[i]]
+		record, err := msg.ToString()
+		if rueidis.IsRedisNil(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ret[id], err = r.decode(record); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
 func (r *JSONRepository[T]) decode(record string) (*T, error) {
 	var v T
 	if err := json.NewDecoder(strings.NewReader(record)).Decode(&v); err != nil {
@@ -127,6 +155,26 @@ func (r *JSONRepository[T]) SaveMulti(ctx context.Context, entities ...*T) []err
 	return errs
 }
 
+// SetField updates only the value at the JSON path of the entity under the redis key of `{prefix}:{id}` by JSON.SET.
+// It also increments the `redis:",ver"` field, so concurrent Save with the previous version will fail with ErrVersionMismatch.
+// It returns redis nil error if the entity does not exist.
+func (r *JSONRepository[T]) SetField(ctx context.Context, id string, path string, value any) error {
+	return jsonSetFieldScript.Exec(ctx, r.client, []string{key(r.prefix, id)}, []string{r.schema.ver.name, path, rueidis.JSON(value)}).Error()
+}
+
+// Merge applies the value as a JSON merge patch (RFC 7386) at the JSON path of the entity under the redis key of `{prefix}:{id}` by JSON.MERGE,
+// where null values in the patch delete the corresponding fields. Like SetField, it increments the `redis:",ver"` field,
+// and it returns redis nil error if the entity does not exist.
+func (r *JSONRepository[T]) Merge(ctx context.Context, id string, path string, value any) error {
+	return jsonMergeScript.Exec(ctx, r.client, []string{key(r.prefix, id)}, []string{r.schema.ver.name, path, rueidis.JSON(value)}).Error()
+}
+
+// GetField decodes the value at the JSON path of the entity under the redis key of `{prefix}:{id}` into the value parameter.
+// Note that the result of a JSONPath starting with `$` is an array of matched values.
+func (r *JSONRepository[T]) GetField(ctx context.Context, id string, path string, value any) error {
+	return r.client.Do(ctx, r.client.B().JsonGet().Key(key(r.prefix, id)).Path(path).Build()).DecodeJSON(value)
+}
+
 // Remove the entity under the redis key of `{prefix}:{id}`.
 func (r *JSONRepository[T]) Remove(ctx context.Context, id string) error {
 	return r.client.Do(ctx, r.client.B().Del().Key(key(r.prefix, id)).Build()).Error()
@@ -191,3 +239,15 @@ then
 end
 return nil
 `)
+
+var jsonSetFieldScript = rueidis.NewLuaScript(`
+if redis.call('EXISTS',KEYS[1]) == 0 then return nil end
+redis.call('JSON.SET',KEYS[1],ARGV[2],ARGV[3])
+return redis.call('JSON.NUMINCRBY',KEYS[1],ARGV[1],1)
+`)
+
+var jsonMergeScript = rueidis.NewLuaScript(`
+if redis.call('EXISTS',KEYS[1]) == 0 then return nil end
+redis.call('JSON.MERGE',KEYS[1],ARGV[2],ARGV[3])
+return redis.call('JSON.NUMINCRBY',KEYS[1],ARGV[1],1)
+`)
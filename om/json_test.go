@@ -106,6 +106,77 @@ func TestNewJSONRepository(t *testing.T) {
 			}
 		})
 
+		t.Run("SetField", func(t *testing.T) {
+			if err := repo.(*JSONRepository[JSONTestStruct]).SetField(ctx, e.Key, "$.Nested.F1", "f1"); err != nil {
+				t.Fatal(err)
+			}
+			var f1 []string
+			if err := repo.(*JSONRepository[JSONTestStruct]).GetField(ctx, e.Key, "$.Nested.F1", &f1); err != nil {
+				t.Fatal(err)
+			}
+			if len(f1) != 1 || f1[0] != "f1" {
+				t.Fatalf("unexpected field %v", f1)
+			}
+			ei, err := repo.Fetch(ctx, e.Key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ei.Nested.F1 != "f1" || ei.Ver != 2 {
+				t.Fatalf("unexpected entity %v", ei)
+			}
+			if err := repo.Save(ctx, e); err != ErrVersionMismatch {
+				t.Fatalf("save should fail if ErrVersionMismatch, got: %v", err)
+			}
+			if err := repo.(*JSONRepository[JSONTestStruct]).SetField(ctx, "notfound", "$.Nested.F1", "f1"); !rueidis.IsRedisNil(err) {
+				t.Fatalf("unexpected err %v", err)
+			}
+			e.Nested.F1 = "f1"
+			e.Ver = 2
+			if err := repo.Save(ctx, e); err != nil { // restore
+				t.Fatal(err)
+			}
+		})
+
+		t.Run("MGet", func(t *testing.T) {
+			es, err := repo.(*JSONRepository[JSONTestStruct]).MGet(ctx, []string{e.Key, "notfound"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(es) != 1 || !reflect.DeepEqual(es[e.Key], e) {
+				t.Fatalf("unexpected entities %v", es)
+			}
+			if _, ok := es["notfound"]; ok {
+				t.Fatalf("entities not found should not be in the map")
+			}
+			if es, err = repo.(*JSONRepository[JSONTestStruct]).MGet(ctx, nil); err != nil || len(es) != 0 {
+				t.Fatalf("unexpected entities %v %v", es, err)
+			}
+		})
+
+		t.Run("Merge", func(t *testing.T) {
+			if err := repo.(*JSONRepository[JSONTestStruct]).Merge(ctx, e.Key, "$", map[string]any{"Nested": map[string]any{"F1": "merged"}}); err != nil {
+				t.Fatal(err)
+			}
+			ei, err := repo.Fetch(ctx, e.Key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ei.Nested.F1 != "merged" || !reflect.DeepEqual(ei.Val, e.Val) || ei.Ver != e.Ver+1 {
+				t.Fatalf("unexpected entity %v", ei)
+			}
+			if err := repo.Save(ctx, e); err != ErrVersionMismatch {
+				t.Fatalf("save should fail if ErrVersionMismatch, got: %v", err)
+			}
+			if err := repo.(*JSONRepository[JSONTestStruct]).Merge(ctx, "notfound", "$", map[string]any{"Val": nil}); !rueidis.IsRedisNil(err) {
+				t.Fatalf("unexpected err %v", err)
+			}
+			ei.Nested.F1 = e.Nested.F1
+			if err := repo.Save(ctx, ei); err != nil { // restore
+				t.Fatal(err)
+			}
+			e.Ver = ei.Ver
+		})
+
 		t.Run("FetchCache", func(t *testing.T) {
 			ei, err := repo.FetchCache(ctx, e.Key, time.Minute)
 			if err != nil {
@@ -25,6 +25,20 @@ const LibVer = "1.0.40"
 
 var noHello = regexp.MustCompile("unknown command .?(HELLO|hello).?")
 
+// optionalInit reports whether the err of the init cmd can be ignored: errors of READONLY, and CLIENT NO-TOUCH or
+// CLIENT NO-EVICT rejected as unknown subcommands by redis < 7.2 or redis < 7.0, so that the connection is still usable without them.
+func optionalInit(cmd []string, err error) bool {
+	if cmd[0] == "READONLY" {
+		return true
+	}
+	if cmd[0] == "CLIENT" && len(cmd) > 1 && (cmd[1] == "NO-TOUCH" || cmd[1] == "NO-EVICT") {
+		if re, ok := err.(*RedisError); ok {
+			return strings.Contains(strings.ToLower(re.string), "unknown subcommand")
+		}
+	}
+	return false
+}
+
 type wire interface {
 	Do(ctx context.Context, cmd Completed) RedisResult
 	DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) RedisResult
@@ -41,6 +55,7 @@ type wire interface {
 	CleanSubscriptions()
 	SetPubSubHooks(hooks PubSubHooks) <-chan error
 	SetOnCloseHook(fn func(error))
+	FlushCache(keys []string)
 }
 
 type redisresults struct {
@@ -94,12 +109,15 @@ type pipe struct {
 	w               *bufio.Writer
 	close           chan struct{}
 	onInvalidations func([]RedisMessage)
+	onPush          func(PushMessage)
 	r2psFn          func() (p *pipe, err error) // func to build pipe for resp2 pubsub
 	r2pipe          *pipe                       // internal pipe for resp2 pubsub only
 	ssubs           *subs                       // pubsub smessage subscriptions
 	nsubs           *subs                       // pubsub  message subscriptions
 	psubs           *subs                       // pubsub pmessage subscriptions
 	info            map[string]RedisMessage
+	reset           [][]string               // RESET and the commands after the HELLO to initialize the connection again, nil if RESET is not used
+	helloFn         func() ([]string, error) // func to build the HELLO with the latest credentials after the RESET
 	timeout         time.Duration
 	pinggap         time.Duration
 	maxFlushDelay   time.Duration
@@ -114,6 +132,31 @@ type pipe struct {
 	r2ps            bool // identify this pipe is used for resp2 pubsub or not
 }
 
+// credentials returns the username and password from the AuthCredentialsFn if it is set, otherwise from the option.
+func credentials(option *ClientOption, addr net.Addr) (username, password string, err error) {
+	if option.AuthCredentialsFn == nil {
+		return option.Username, option.Password, nil
+	}
+	authCredentials, err := option.AuthCredentialsFn(AuthCredentialsContext{Address: addr})
+	if err != nil {
+		return "", "", err
+	}
+	return authCredentials.Username, authCredentials.Password, nil
+}
+
+func hello(option *ClientOption, username, password string) []string {
+	helloCmd := []string{"HELLO", "3"}
+	if password != "" && username == "" {
+		helloCmd = append(helloCmd, "AUTH", "default", password)
+	} else if username != "" {
+		helloCmd = append(helloCmd, "AUTH", username, password)
+	}
+	if option.ClientName != "" {
+		helloCmd = append(helloCmd, "SETNAME", option.ClientName)
+	}
+	return helloCmd
+}
+
 type pipeFn func(connFn func() (net.Conn, error), option *ClientOption) (p *pipe, err error)
 
 func newPipe(connFn func() (net.Conn, error), option *ClientOption) (p *pipe, err error) {
@@ -161,30 +204,12 @@ func _newPipe(connFn func() (net.Conn, error), option *ClientOption, r2ps, nobg
 	p.pshks.Store(emptypshks)
 	p.clhks.Store(emptyclhks)
 
-	username := option.Username
-	password := option.Password
-	if option.AuthCredentialsFn != nil {
-		authCredentialsContext := AuthCredentialsContext{
-			Address: conn.RemoteAddr(),
-		}
-		authCredentials, err := option.AuthCredentialsFn(authCredentialsContext)
-		if err != nil {
-			p.Close()
-			return nil, err
-		}
-		username = authCredentials.Username
-		password = authCredentials.Password
-	}
-
-	helloCmd := []string{"HELLO", "3"}
-	if password != "" && username == "" {
-		helloCmd = append(helloCmd, "AUTH", "default", password)
-	} else if username != "" {
-		helloCmd = append(helloCmd, "AUTH", username, password)
-	}
-	if option.ClientName != "" {
-		helloCmd = append(helloCmd, "SETNAME", option.ClientName)
+	username, password, err := credentials(option, conn.RemoteAddr())
+	if err != nil {
+		p.Close()
+		return nil, err
 	}
+	helloCmd := hello(option, username, password)
 
 	init := make([][]string, 0, 5)
 	if option.ClientTrackingOptions == nil {
@@ -207,6 +232,9 @@ func _newPipe(connFn func() (net.Conn, error), option *ClientOption, r2ps, nobg
 	if option.ClientNoEvict {
 		init = append(init, []string{"CLIENT", "NO-EVICT", "ON"})
 	}
+	for _, script := range option.PreloadScripts {
+		init = append(init, []string{"SCRIPT", "LOAD", script})
+	}
 	if len(option.ClientSetInfo) == 2 {
 		init = append(init, []string{"CLIENT", "SETINFO", "LIB-NAME", option.ClientSetInfo[0]}, []string{"CLIENT", "SETINFO", "LIB-VER", option.ClientSetInfo[1]})
 	} else {
@@ -232,8 +260,7 @@ func _newPipe(connFn func() (net.Conn, error), option *ClientOption, r2ps, nobg
 				err = r.Error()
 			}
 			if err != nil {
-				if init[i][0] == "READONLY" {
-					// ignore READONLY command error
+				if optionalInit(init[i], err) {
 					continue
 				}
 				if re, ok := err.(*RedisError); ok {
@@ -241,9 +268,11 @@ func _newPipe(connFn func() (net.Conn, error), option *ClientOption, r2ps, nobg
 						r2 = true
 						continue
 					} else if init[i][0] == "CLIENT" {
-						err = fmt.Errorf("%s: %v\n%w", re.string, init[i], ErrNoCache)
+						err = fmt.Errorf("%s: %v\n%w", re.string, redactCommand(option, init[i]), ErrNoCache)
 					} else if r2 {
 						continue
+					} else if init[i][0] == "SCRIPT" {
+						err = preloadErr(init[i][2], re)
 					}
 				}
 				p.Close()
@@ -260,8 +289,20 @@ func _newPipe(connFn func() (net.Conn, error), option *ClientOption, r2ps, nobg
 				vv, _ := strconv.ParseInt(v[0], 10, 32)
 				p.version = int32(vv)
 			}
+			if !option.DisableReset && supportReset(ver.string) {
+				p.reset = append([][]string{{"RESET"}}, init[1:len(init)-2-len(option.PreloadScripts)]...) // scripts and lib info are kept by RESET
+				addr := conn.RemoteAddr()
+				p.helloFn = func() ([]string, error) { // credentials are not kept, because they may be rotated by the AuthCredentialsFn
+					username, password, err := credentials(option, addr)
+					if err != nil {
+						return nil, err
+					}
+					return hello(option, username, password), nil
+				}
+			}
 		}
 		p.onInvalidations = option.OnInvalidations
+		p.onPush = option.OnPush
 	} else {
 		if !option.DisableCache {
 			p.Close()
@@ -288,6 +329,9 @@ func _newPipe(connFn func() (net.Conn, error), option *ClientOption, r2ps, nobg
 		if option.ClientNoEvict {
 			init = append(init, []string{"CLIENT", "NO-EVICT", "ON"})
 		}
+		for _, script := range option.PreloadScripts {
+			init = append(init, []string{"SCRIPT", "LOAD", script})
+		}
 		if len(option.ClientSetInfo) == 2 {
 			init = append(init, []string{"CLIENT", "SETINFO", "LIB-NAME", option.ClientSetInfo[0]}, []string{"CLIENT", "SETINFO", "LIB-VER", option.ClientSetInfo[1]})
 		} else {
@@ -298,11 +342,13 @@ func _newPipe(connFn func() (net.Conn, error), option *ClientOption, r2ps, nobg
 			resp := p.DoMulti(ctx, cmds.NewMultiCompleted(init)...)
 			defer resultsp.Put(resp)
 			for i, r := range resp.s[:len(resp.s)-2] { // skip error checking on the last CLIENT SETINFO
-				if init[i][0] == "READONLY" {
-					// ignore READONLY command error
-					continue
-				}
 				if err = r.Error(); err != nil {
+					if optionalInit(init[i], err) {
+						continue
+					}
+					if re, ok := err.(*RedisError); ok && init[i][0] == "SCRIPT" {
+						err = preloadErr(init[i][2], re)
+					}
 					p.Close()
 					return nil, err
 				}
@@ -310,7 +356,7 @@ func _newPipe(connFn func() (net.Conn, error), option *ClientOption, r2ps, nobg
 		}
 	}
 	if !nobg {
-		if p.onInvalidations != nil || option.AlwaysPipelining {
+		if p.onInvalidations != nil || p.onPush != nil || option.AlwaysPipelining {
 			p.background()
 		}
 		if p.timeout > 0 && p.pinggap > 0 {
@@ -320,6 +366,19 @@ func _newPipe(connFn func() (net.Conn, error), option *ClientOption, r2ps, nobg
 	return p, nil
 }
 
+// supportReset reports whether the redis version supports the RESET command.
+func supportReset(version string) bool {
+	v, ok := parseServerVersion(version)
+	return ok && !v.less(capabilities["RESET"])
+}
+
+func preloadErr(script string, err *RedisError) error {
+	if len(script) > 32 {
+		script = script[:32] + "..."
+	}
+	return fmt.Errorf("failed to preload script %q: %w", script, err)
+}
+
 func (p *pipe) background() {
 	atomic.CompareAndSwapInt32(&p.state, 0, 1)
 	p.once.Do(func() { go p._background() })
@@ -595,12 +654,16 @@ func (p *pipe) backgroundPing() {
 			}
 			ch := make(chan error, 1)
 			tm := time.NewTimer(p.timeout)
+			start := time.Now()
 			go func() { ch <- p.Do(context.Background(), cmds.PingCmd).NonRedisError() }()
 			select {
 			case <-tm.C:
 				err = context.DeadlineExceeded
 			case err = <-ch:
 				tm.Stop()
+				if err == nil {
+					recordRTT(p.conn, time.Since(start))
+				}
 			}
 			if err != nil && atomic.LoadInt32(&p.blcksig) != 0 {
 				err = nil
@@ -616,11 +679,12 @@ func (p *pipe) backgroundPing() {
 
 func (p *pipe) handlePush(values []RedisMessage) (reply bool, unsubscribe bool) {
 	if len(values) < 2 {
+		if len(values) == 1 && p.onPush != nil {
+			p.onPush(PushMessage{Kind: values[0].string})
+		}
 		return
 	}
-	// TODO: handle other push data
-	// tracking-redir-broken
-	// server-cpu-usage
+	// other push data, such as tracking-redir-broken and server-cpu-usage, are delivered to the onPush.
 	switch values[0].string {
 	case "invalidate":
 		if p.cache != nil {
@@ -678,6 +742,10 @@ func (p *pipe) handlePush(values []RedisMessage) (reply bool, unsubscribe bool)
 			p.pshks.Load().(*pshks).hooks.OnSubscription(PubSubSubscription{Kind: values[0].string, Channel: values[1].string, Count: values[2].integer})
 		}
 		return true, false
+	default:
+		if p.onPush != nil {
+			p.onPush(PushMessage{Kind: values[0].string, Values: values[1:]})
+		}
 	}
 	return false, false
 }
@@ -748,6 +816,8 @@ func (p *pipe) Receive(ctx context.Context, subscribe Completed, fn func(message
 	return p.Error()
 }
 
+// CleanSubscriptions unsubscribes all channels, and also RESETs the connection if supported before it is returned to the pool,
+// so that states left by the previous user, such as an unfinished MULTI or WATCH, will not be seen by the next one.
 func (p *pipe) CleanSubscriptions() {
 	if atomic.LoadInt32(&p.state) == 1 {
 		if p.version >= 7 {
@@ -756,6 +826,24 @@ func (p *pipe) CleanSubscriptions() {
 			p.DoMulti(context.Background(), cmds.UnsubscribeCmd, cmds.PUnsubscribeCmd)
 		}
 	}
+	if p.reset != nil && p.Error() == nil {
+		helloCmd, err := p.helloFn()
+		if err != nil {
+			p.Close() // the connection can't be authenticated again after RESET, so it is closed and will be dropped by the pool.
+			return
+		}
+		reset := make([][]string, 0, len(p.reset)+1)
+		reset = append(append(append(reset, p.reset[0]), helloCmd), p.reset[1:]...)
+		resp := p.DoMulti(context.Background(), cmds.NewMultiCompleted(reset)...)
+		for i, r := range resp.s {
+			if err := r.Error(); err != nil && !optionalInit(reset[i], err) {
+				p.Close() // the connection is in an unknown state, so it is closed and will be dropped by the pool.
+				break
+			}
+		}
+		resultsp.Put(resp)
+		p.FlushCache(nil) // the tracking is turned off by RESET, so the cached responses may have missed invalidations.
+	}
 }
 
 func (p *pipe) SetPubSubHooks(hooks PubSubHooks) <-chan error {
@@ -841,7 +929,7 @@ func (p *pipe) Do(ctx context.Context, cmd Completed) (resp RedisResult) {
 			goto queue
 		}
 		dl, ok := ctx.Deadline()
-		if !ok && ctx.Done() != nil {
+		if ctx.Done() != nil && (!ok || cmd.IsBlock()) { // blocking commands should return once the ctx is canceled
 			p.background()
 			goto queue
 		}
@@ -878,6 +966,22 @@ abort:
 	return newErrResult(ctx.Err())
 }
 
+// FlushCache deletes the cached responses of the keys, or all the cached responses if the keys is nil.
+func (p *pipe) FlushCache(keys []string) {
+	if p.cache == nil {
+		return
+	}
+	if keys == nil {
+		p.cache.Delete(nil)
+		return
+	}
+	msgs := make([]RedisMessage, len(keys))
+	for i, key := range keys {
+		msgs[i] = RedisMessage{typ: typeBlobString, string: key}
+	}
+	p.cache.Delete(msgs)
+}
+
 func (p *pipe) DoMulti(ctx context.Context, multi ...Completed) *redisresults {
 	resp := resultsp.Get(len(multi), len(multi))
 	if err := ctx.Err(); err != nil {
@@ -890,6 +994,7 @@ func (p *pipe) DoMulti(ctx context.Context, multi ...Completed) *redisresults {
 	cmds.CompletedCS(multi[0]).Verify()
 
 	isOptIn := multi[0].IsOptIn() // len(multi) > 0 should have already been checked by upper layer
+	isBlock := false
 	noReply := 0
 
 	for _, cmd := range multi {
@@ -912,6 +1017,7 @@ func (p *pipe) DoMulti(ctx context.Context, multi ...Completed) *redisresults {
 
 	for _, cmd := range multi {
 		if cmd.IsBlock() {
+			isBlock = true
 			atomic.AddInt32(&p.blcksig, 1)
 			defer func() {
 				for _, r := range resp.s {
@@ -941,7 +1047,7 @@ func (p *pipe) DoMulti(ctx context.Context, multi ...Completed) *redisresults {
 			goto queue
 		}
 		dl, ok := ctx.Deadline()
-		if !ok && ctx.Done() != nil {
+		if ctx.Done() != nil && (!ok || isBlock) { // blocking commands should return once the ctx is canceled
 			p.background()
 			goto queue
 		}
@@ -1013,24 +1119,41 @@ func (s *RedisResultStream) Error() error {
 func (s *RedisResultStream) WriteTo(w io.Writer) (n int64, err error) {
 	if err = s.e; err == nil && s.n > 0 {
 		var clean bool
-		if n, err, clean = streamTo(s.w.r, w); !clean {
-			s.e = err // err must not be nil in case of !clean
-			s.n = 1
-		}
-		if s.n--; s.n == 0 {
-			atomic.AddInt32(&s.w.blcksig, -1)
-			atomic.AddInt32(&s.w.waits, -1)
-			if s.e == nil {
-				s.e = io.EOF
-			} else {
-				s.w.Close()
-			}
-			s.p.Store(s.w)
-		}
+		n, err, clean = streamTo(s.w.r, w)
+		s.next(err, clean)
+	}
+	return n, err
+}
+
+// WriteRawTo is similar to the WriteTo, but writes the exact RESP bytes of the redis response, including its attributes,
+// to the given writer. It works with all types of responses. Redis error responses are written as they are without errors,
+// and the underlying connection is closed if any error happens.
+func (s *RedisResultStream) WriteRawTo(w io.Writer) (n int64, err error) {
+	if err = s.e; err == nil && s.n > 0 {
+		n, err = rawTo(s.w.r, w)
+		s.next(err, err == nil)
 	}
 	return n, err
 }
 
+// next counts a read response and recycles the underlying connection after all responses are read.
+func (s *RedisResultStream) next(err error, clean bool) {
+	if !clean {
+		s.e = err // err must not be nil in case of !clean
+		s.n = 1
+	}
+	if s.n--; s.n == 0 {
+		atomic.AddInt32(&s.w.blcksig, -1)
+		atomic.AddInt32(&s.w.waits, -1)
+		if s.e == nil {
+			s.e = io.EOF
+		} else {
+			s.w.Close()
+		}
+		s.p.Store(s.w)
+	}
+}
+
 func (p *pipe) DoStream(ctx context.Context, pool *pool, cmd Completed) RedisResultStream {
 	cmds.CompletedCS(cmd).Verify()
 
@@ -1240,11 +1363,35 @@ func (p *pipe) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) Re
 	}
 	ck, cc := cmds.CacheKey(cmd)
 	now := time.Now()
+	if fresh, ok := ctx.Value(swrKey{}).(time.Duration); ok {
+		if store, ok := p.cache.(swrStore); ok {
+			v, entry, refresh := store.FlightSWR(ck, cc, ttl, fresh, now)
+			if refresh {
+				go p.refreshCache(ck, cc, cmds.NewCompleted(append([]string(nil), cmd.Commands()...)))
+			}
+			if v.typ != 0 {
+				return newResult(v, nil)
+			} else if entry != nil {
+				return newResult(entry.Wait(ctx))
+			}
+			return p.doCacheMiss(ctx, ck, cc, cmd)
+		}
+	}
 	if v, entry := p.cache.Flight(ck, cc, ttl, now); v.typ != 0 {
 		return newResult(v, nil)
 	} else if entry != nil {
 		return newResult(entry.Wait(ctx))
 	}
+	return p.doCacheMiss(ctx, ck, cc, cmd)
+}
+
+// refreshCache sends the cmd for the entry turned into pending by the swrStore.FlightSWR.
+// The response will be delivered to the entry by the background reading goroutine.
+func (p *pipe) refreshCache(ck, cc string, cmd Completed) {
+	p.doCacheMiss(context.Background(), ck, cc, Cacheable(cmd))
+}
+
+func (p *pipe) doCacheMiss(ctx context.Context, ck, cc string, cmd Cacheable) RedisResult {
 	resp := p.DoMulti(
 		ctx,
 		cmds.OptInCmd,
@@ -280,6 +280,96 @@ func TestNewPipe(t *testing.T) {
 		n1.Close()
 		n2.Close()
 	})
+	t.Run("Unsupported ClientNoTouch and ClientNoEvict", func(t *testing.T) {
+		n1, n2 := net.Pipe()
+		mock := &redisMock{buf: bufio.NewReader(n2), conn: n2, t: t}
+		go func() {
+			mock.Expect("HELLO", "3").
+				Reply(RedisMessage{
+					typ: '%',
+					values: []RedisMessage{
+						{typ: '+', string: "proto"},
+						{typ: ':', integer: 3},
+					},
+				})
+			mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").
+				ReplyString("OK")
+			mock.Expect("CLIENT", "NO-TOUCH", "ON").
+				ReplyError("ERR unknown subcommand 'NO-TOUCH'. Try CLIENT HELP.")
+			mock.Expect("CLIENT", "NO-EVICT", "ON").
+				ReplyError("ERR Unknown subcommand or wrong number of arguments for 'NO-EVICT'. Try CLIENT HELP.")
+			mock.Expect("CLIENT", "SETINFO", "LIB-NAME", "libname").
+				ReplyString("OK")
+			mock.Expect("CLIENT", "SETINFO", "LIB-VER", "1").
+				ReplyString("OK")
+		}()
+		p, err := newPipe(func() (net.Conn, error) { return n1, nil }, &ClientOption{
+			ClientNoEvict: true,
+			ClientSetInfo: []string{"libname", "1"},
+			ClientNoTouch: true,
+		})
+		if err != nil {
+			t.Fatalf("pipe setup failed: %v", err)
+		}
+		go func() { mock.Expect("PING").ReplyString("OK") }()
+		p.Close()
+		mock.Close()
+		n1.Close()
+		n2.Close()
+	})
+	t.Run("Unsupported ClientNoTouch and ClientNoEvict AlwaysRESP2", func(t *testing.T) {
+		n1, n2 := net.Pipe()
+		mock := &redisMock{buf: bufio.NewReader(n2), conn: n2, t: t}
+		go func() {
+			mock.Expect("CLIENT", "NO-TOUCH", "ON").
+				ReplyError("ERR unknown subcommand 'NO-TOUCH'. Try CLIENT HELP.")
+			mock.Expect("CLIENT", "NO-EVICT", "ON").
+				ReplyError("ERR unknown subcommand 'NO-EVICT'. Try CLIENT HELP.")
+			mock.Expect("CLIENT", "SETINFO", "LIB-NAME", "libname").
+				ReplyString("OK")
+			mock.Expect("CLIENT", "SETINFO", "LIB-VER", "1").
+				ReplyString("OK")
+		}()
+		p, err := newPipe(func() (net.Conn, error) { return n1, nil }, &ClientOption{
+			ClientNoEvict: true,
+			ClientSetInfo: []string{"libname", "1"},
+			ClientNoTouch: true,
+			AlwaysRESP2:   true,
+			DisableCache:  true,
+		})
+		if err != nil {
+			t.Fatalf("pipe setup failed: %v", err)
+		}
+		go func() { mock.Expect("PING").ReplyString("OK") }()
+		p.Close()
+		mock.Close()
+		n1.Close()
+		n2.Close()
+	})
+	t.Run("ClientNoEvict Other Error", func(t *testing.T) {
+		n1, n2 := net.Pipe()
+		mock := &redisMock{buf: bufio.NewReader(n2), conn: n2, t: t}
+		go func() {
+			mock.Expect("CLIENT", "NO-EVICT", "ON").
+				ReplyError("NOPERM this user has no permissions to run the 'client|no-evict' command")
+			mock.Expect("CLIENT", "SETINFO", "LIB-NAME", "libname").
+				ReplyString("OK")
+			mock.Expect("CLIENT", "SETINFO", "LIB-VER", "1").
+				ReplyString("OK")
+		}()
+		_, err := newPipe(func() (net.Conn, error) { return n1, nil }, &ClientOption{
+			ClientNoEvict: true,
+			ClientSetInfo: []string{"libname", "1"},
+			AlwaysRESP2:   true,
+			DisableCache:  true,
+		})
+		if ret, ok := IsRedisErr(err); !ok || !strings.HasPrefix(ret.Error(), "NOPERM") {
+			t.Fatalf("unexpected err %v", err)
+		}
+		mock.Close()
+		n1.Close()
+		n2.Close()
+	})
 	t.Run("Auth with Username", func(t *testing.T) {
 		n1, n2 := net.Pipe()
 		mock := &redisMock{buf: bufio.NewReader(n2), conn: n2, t: t}
@@ -491,6 +581,74 @@ func TestNewPipe(t *testing.T) {
 		n1.Close()
 		n2.Close()
 	})
+	t.Run("PreloadScripts", func(t *testing.T) {
+		n1, n2 := net.Pipe()
+		mock := &redisMock{buf: bufio.NewReader(n2), conn: n2, t: t}
+		go func() {
+			mock.Expect("HELLO", "3").
+				Reply(RedisMessage{
+					typ: '%',
+					values: []RedisMessage{
+						{typ: '+', string: "proto"},
+						{typ: ':', integer: 3},
+					},
+				})
+			mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").
+				ReplyString("OK")
+			mock.Expect("SCRIPT", "LOAD", "return 1").
+				ReplyString("e0e1f9fabfc9d4800c877a703b823ac0578ff8db")
+			mock.Expect("SCRIPT", "LOAD", "return 2").
+				ReplyString("7f923f79fe76194c868d7e1d0820de36700eb649")
+			mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+				ReplyError("UNKNOWN COMMAND")
+			mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+				ReplyError("UNKNOWN COMMAND")
+		}()
+		p, err := newPipe(func() (net.Conn, error) { return n1, nil }, &ClientOption{
+			PreloadScripts: []string{"return 1", "return 2"},
+		})
+		if err != nil {
+			t.Fatalf("pipe setup failed: %v", err)
+		}
+		go func() { mock.Expect("PING").ReplyString("OK") }()
+		p.Close()
+		mock.Close()
+		n1.Close()
+		n2.Close()
+	})
+	t.Run("PreloadScripts Error", func(t *testing.T) {
+		n1, n2 := net.Pipe()
+		mock := &redisMock{buf: bufio.NewReader(n2), conn: n2, t: t}
+		go func() {
+			mock.Expect("HELLO", "3").
+				Reply(RedisMessage{
+					typ: '%',
+					values: []RedisMessage{
+						{typ: '+', string: "proto"},
+						{typ: ':', integer: 3},
+					},
+				})
+			mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").
+				ReplyString("OK")
+			mock.Expect("SCRIPT", "LOAD", "return (").
+				ReplyError("ERR Error compiling script")
+			mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+				ReplyError("UNKNOWN COMMAND")
+			mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+				ReplyError("UNKNOWN COMMAND")
+			mock.Expect("PING").ReplyString("OK")
+		}()
+		_, err := newPipe(func() (net.Conn, error) { return n1, nil }, &ClientOption{
+			PreloadScripts: []string{"return ("},
+		})
+		var re *RedisError
+		if err == nil || !strings.HasPrefix(err.Error(), `failed to preload script "return ("`) || !errors.As(err, &re) {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		mock.Close()
+		n1.Close()
+		n2.Close()
+	})
 }
 
 func TestNewRESP2Pipe(t *testing.T) {
@@ -865,6 +1023,34 @@ func TestWriteMultiFlush(t *testing.T) {
 	}
 }
 
+func TestDoMultiErrorIsolation(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	go func() {
+		mock.Expect("GET", "a").Expect("LPOP", "a").Expect("GET", "b").
+			ReplyString("1").
+			ReplyError("WRONGTYPE Operation against a key holding the wrong kind of value").
+			ReplyString("2")
+	}()
+	resps := p.DoMulti(context.Background(),
+		cmds.NewCompleted([]string{"GET", "a"}),
+		cmds.NewCompleted([]string{"LPOP", "a"}),
+		cmds.NewCompleted([]string{"GET", "b"})).s
+	if v, err := resps[0].ToString(); err != nil || v != "1" {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	if err, ok := IsRedisErr(resps[1].Error()); !ok || !err.IsWrongType() {
+		t.Fatalf("unexpected response %v", resps[1])
+	}
+	if v, err := resps[2].ToString(); err != nil || v != "2" {
+		t.Fatalf("unexpected response %v %v", v, err)
+	}
+	if err := AnyError(resps); err == nil || err.Error() != resps[1].Error().Error() {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
 func TestWriteMultiPipelineFlush(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	p, mock, cancel, _ := setup(t, ClientOption{})
@@ -1063,6 +1249,54 @@ func TestDoMultiStreamRecycle(t *testing.T) {
 	}
 }
 
+func TestDoMultiStreamRecycleRaw(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	go func() {
+		mock.Expect("HGETALL", "h").Expect("GET", "k")
+		mock.conn.Write([]byte("%1\r\n$1\r\nf\r\n$1\r\nv\r\n-WRONGTYPE\r\n"))
+	}()
+	conns := newPool(1, nil, nil)
+	s := p.DoMultiStream(context.Background(), conns, cmds.NewCompleted([]string{"HGETALL", "h"}), cmds.NewCompleted([]string{"GET", "k"}))
+	for _, expected := range []string{"%1\r\n$1\r\nf\r\n$1\r\nv\r\n", "-WRONGTYPE\r\n"} {
+		buf := bytes.NewBuffer(nil)
+		if n, err := s.WriteRawTo(buf); err != nil || buf.String() != expected || n != int64(len(expected)) {
+			t.Fatalf("unexpected raw %q %v %v", buf.String(), n, err)
+		}
+	}
+	if s.HasNext() {
+		t.Fatalf("unexpected HasNext")
+	}
+	if err := s.Error(); err != io.EOF {
+		t.Errorf("unexpected err %v\n", err)
+	}
+	if w := conns.Acquire(); w != p {
+		t.Errorf("pipe is not recycled\n")
+	}
+}
+
+func TestDoStreamRawMalformed(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	p, mock, _, closeConn := setup(t, ClientOption{})
+	defer closeConn()
+	go func() {
+		mock.Expect("GET", "k")
+		mock.conn.Write([]byte("X\r\n"))
+	}()
+	conns := newPool(1, nil, nil)
+	s := p.DoStream(context.Background(), conns, cmds.NewCompleted([]string{"GET", "k"}))
+	if _, err := s.WriteRawTo(io.Discard); err == nil {
+		t.Fatalf("unexpected nil err")
+	}
+	if s.Error() == nil || p.Error() == nil {
+		t.Fatalf("the pipe should be closed after malformed responses")
+	}
+	if len(conns.list) != 0 {
+		t.Fatalf("unexpected pool length %v", len(conns.list))
+	}
+}
+
 func TestDoMultiStreamRecycleDestinationFull(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	p, mock, cancel, _ := setup(t, ClientOption{})
@@ -1254,6 +1488,110 @@ func TestClientSideCaching(t *testing.T) {
 	}
 }
 
+func TestClientSideCachingSWR(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+
+	expectCSC := func(resp string) {
+		mock.Expect("CLIENT", "CACHING", "YES").
+			Expect("MULTI").
+			Expect("PTTL", "a").
+			Expect("GET", "a").
+			Expect("EXEC").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK").
+			Reply(RedisMessage{typ: '*', values: []RedisMessage{
+				{typ: ':', integer: -1},
+				{typ: '+', string: resp},
+			}})
+	}
+	go func() {
+		expectCSC("1")
+		expectCSC("2")
+	}()
+
+	ctx := context.WithValue(context.Background(), swrKey{}, 10*time.Millisecond)
+	get := func() RedisMessage {
+		v, err := p.DoCache(ctx, Cacheable(cmds.NewCompleted([]string{"GET", "a"})), 10*time.Second).ToMessage()
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return v
+	}
+	if v := get(); v.string != "1" || v.IsCacheHit() {
+		t.Fatalf("unexpected response %v", v)
+	}
+	if v := get(); v.string != "1" || !v.IsCacheHit() {
+		t.Fatalf("unexpected response %v", v)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if v := get(); v.string != "1" || !v.IsCacheHit() {
+		t.Fatalf("unexpected stale response %v", v)
+	}
+	for v := get(); v.string != "2"; v = get() {
+		if v.string != "1" || !v.IsCacheHit() {
+			t.Fatalf("unexpected response %v", v)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClientSideCachingFlushCache(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+
+	expectCSC := func(key, resp string) {
+		mock.Expect("CLIENT", "CACHING", "YES").
+			Expect("MULTI").
+			Expect("PTTL", key).
+			Expect("GET", key).
+			Expect("EXEC").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK").
+			Reply(RedisMessage{typ: '*', values: []RedisMessage{
+				{typ: ':', integer: -1},
+				{typ: '+', string: resp},
+			}})
+	}
+	get := func(key string) RedisMessage {
+		v, err := p.DoCache(context.Background(), Cacheable(cmds.NewCompleted([]string{"GET", key})), 10*time.Second).ToMessage()
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return v
+	}
+
+	go func() {
+		expectCSC("a", "1")
+		expectCSC("b", "1")
+		expectCSC("a", "2")
+		expectCSC("a", "3")
+		expectCSC("b", "3")
+	}()
+	get("a")
+	get("b")
+	p.FlushCache([]string{"a"})
+	if v := get("a"); v.string != "2" || v.IsCacheHit() {
+		t.Fatalf("unexpected response %v", v)
+	}
+	if v := get("b"); v.string != "1" || !v.IsCacheHit() {
+		t.Fatalf("unexpected response %v", v)
+	}
+	p.FlushCache(nil)
+	if v := get("a"); v.string != "3" || v.IsCacheHit() {
+		t.Fatalf("unexpected response %v", v)
+	}
+	if v := get("b"); v.string != "3" || v.IsCacheHit() {
+		t.Fatalf("unexpected response %v", v)
+	}
+}
+
 func TestClientSideCachingExecAbort(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	p, mock, cancel, _ := setup(t, ClientOption{})
@@ -2421,6 +2759,112 @@ func TestOnInvalidations(t *testing.T) {
 	}
 }
 
+func TestOnInvalidationsAfterCacheDeleted(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var p *pipe
+	ch := make(chan RedisMessage)
+	p, mock, cancel, _ := setup(t, ClientOption{
+		OnInvalidations: func(messages []RedisMessage) {
+			if messages != nil {
+				v, _ := p.cache.Flight("a", "GET", time.Second, time.Now())
+				ch <- v
+			}
+		},
+	})
+
+	go func() {
+		mock.Expect("CLIENT", "CACHING", "YES").
+			Expect("MULTI").
+			Expect("PTTL", "a").
+			Expect("GET", "a").
+			Expect("EXEC").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK").
+			ReplyString("OK").
+			Reply(RedisMessage{typ: '*', values: []RedisMessage{
+				{typ: ':', integer: -1},
+				{typ: '+', string: "1"},
+			}})
+	}()
+	if v, _ := p.DoCache(context.Background(), Cacheable(cmds.NewCompleted([]string{"GET", "a"})), 10*time.Second).ToString(); v != "1" {
+		t.Fatalf("unexpected response %v", v)
+	}
+	if v := p.DoCache(context.Background(), Cacheable(cmds.NewCompleted([]string{"GET", "a"})), 10*time.Second); !v.IsCacheHit() {
+		t.Fatalf("unexpected cache miss")
+	}
+
+	go func() {
+		mock.Expect().Reply(RedisMessage{
+			typ: '>',
+			values: []RedisMessage{
+				{typ: '+', string: "invalidate"},
+				{typ: '*', values: []RedisMessage{{typ: '+', string: "a"}}},
+			},
+		})
+	}()
+	if v := <-ch; v.typ != 0 {
+		t.Fatalf("the cached response should be deleted before the callback %v", v)
+	}
+	p.cache.Cancel("a", "GET", ErrClosing) // clean up the pending entry made by the Flight in the callback
+	cancel()
+}
+
+func TestOnPush(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	ch := make(chan PushMessage)
+	invalidations := make(chan []RedisMessage)
+	p, mock, cancel, _ := setup(t, ClientOption{
+		OnInvalidations: func(messages []RedisMessage) {
+			invalidations <- messages
+		},
+		OnPush: func(msg PushMessage) {
+			ch <- msg
+		},
+	})
+
+	go func() {
+		mock.Expect().Reply(RedisMessage{
+			typ: '>',
+			values: []RedisMessage{
+				{typ: '+', string: "invalidate"},
+				{typ: '*', values: []RedisMessage{{typ: '+', string: "a"}}},
+			},
+		})
+		mock.Expect().Reply(RedisMessage{
+			typ: '>',
+			values: []RedisMessage{
+				{typ: '+', string: "server-cpu-usage"},
+				{typ: ':', integer: 90},
+			},
+		})
+		mock.Expect().Reply(RedisMessage{
+			typ:    '>',
+			values: []RedisMessage{{typ: '+', string: "tracking-redir-broken"}},
+		})
+		mock.Expect("GET", "a").ReplyString("b")
+	}()
+
+	if messages := <-invalidations; messages[0].string != "a" {
+		t.Fatalf("unexpected invlidation %v", messages)
+	}
+	if msg := <-ch; msg.Kind != "server-cpu-usage" || len(msg.Values) != 1 || msg.Values[0].integer != 90 {
+		t.Fatalf("unexpected push %v", msg)
+	}
+	if msg := <-ch; msg.Kind != "tracking-redir-broken" || len(msg.Values) != 0 {
+		t.Fatalf("unexpected push %v", msg)
+	}
+	if v, _ := p.Do(context.Background(), cmds.NewCompleted([]string{"GET", "a"})).ToString(); v != "b" {
+		t.Fatalf("unexpected response %v", v)
+	}
+
+	go cancel()
+
+	if messages := <-invalidations; messages != nil {
+		t.Fatalf("unexpected invlidation %v", messages)
+	}
+}
+
 func TestMultiHalfErr(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	p, mock, _, closeConn := setup(t, ClientOption{})
@@ -3629,6 +4073,42 @@ func TestCancelContext_DoMulti_Block(t *testing.T) {
 	shutdown()
 }
 
+func TestCancelContext_Do_Block_WithDeadline(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	p, mock, shutdown, _ := setup(t, ClientOption{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+
+	go func() {
+		mock.Expect("BLPOP", "a", "0")
+		cancel()
+		mock.Expect().ReplyString("OK")
+	}()
+
+	if err := p.Do(ctx, cmds.NewBlockingCompleted([]string{"BLPOP", "a", "0"})).NonRedisError(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected err %v", err)
+	}
+	shutdown()
+}
+
+func TestCancelContext_DoMulti_Block_WithDeadline(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	p, mock, shutdown, _ := setup(t, ClientOption{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+
+	go func() {
+		mock.Expect("BLPOP", "a", "0")
+		cancel()
+		mock.Expect().ReplyString("OK")
+	}()
+
+	if err := p.DoMulti(ctx, cmds.NewBlockingCompleted([]string{"BLPOP", "a", "0"})).s[0].NonRedisError(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected err %v", err)
+	}
+	shutdown()
+}
+
 func TestCancelContext_DoMultiStream(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	p, _, _, _ := setup(t, ClientOption{})
@@ -4384,3 +4864,143 @@ func TestNoHelloRegex(t *testing.T) {
 		})
 	}
 }
+
+func TestCleanSubscriptionsReset(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	hello := RedisMessage{
+		typ: '%',
+		values: []RedisMessage{
+			{typ: '+', string: "version"},
+			{typ: '+', string: "7.2.0"},
+			{typ: '+', string: "proto"},
+			{typ: ':', integer: 3},
+		},
+	}
+	setupReset := func(t *testing.T, option ClientOption) (*pipe, *redisMock) {
+		n1, n2 := net.Pipe()
+		mock := &redisMock{t: t, buf: bufio.NewReader(n2), conn: n2}
+		helloCmd := []string{"HELLO", "3"}
+		if option.AuthCredentialsFn != nil {
+			helloCmd = append(helloCmd, "AUTH", "u", "p1")
+		}
+		if option.ClientName != "" {
+			helloCmd = append(helloCmd, "SETNAME", option.ClientName)
+		}
+		go func() {
+			mock.Expect(helloCmd...).Reply(hello)
+			mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").ReplyString("OK")
+			mock.Expect("SELECT", "1").ReplyString("OK")
+			mock.Expect("SCRIPT", "LOAD", "return 1").ReplyString("e0e1f9fabfc9d4800c877a703b823ac0578ff8db")
+			mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).ReplyError("UNKNOWN COMMAND")
+			mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).ReplyError("UNKNOWN COMMAND")
+		}()
+		option.SelectDB = 1
+		option.PreloadScripts = []string{"return 1"}
+		option.CacheSizeEachConn = DefaultCacheBytes
+		p, err := newPipe(func() (net.Conn, error) { return n1, nil }, &option)
+		if err != nil {
+			t.Fatalf("pipe setup failed: %v", err)
+		}
+		return p, mock
+	}
+	closePipe := func(p *pipe, mock *redisMock) {
+		go func() { mock.Expect("PING").ReplyString("OK") }()
+		p.Close()
+		mock.Close()
+	}
+	t.Run("RESET", func(t *testing.T) {
+		p, mock := setupReset(t, ClientOption{})
+		go func() {
+			mock.Expect("RESET").ReplyString("RESET")
+			mock.Expect("HELLO", "3").Reply(hello)
+			mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").ReplyString("OK")
+			mock.Expect("SELECT", "1").ReplyString("OK")
+		}()
+		p.CleanSubscriptions()
+		if err := p.Error(); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		closePipe(p, mock)
+	})
+	t.Run("RESET ClientName", func(t *testing.T) {
+		p, mock := setupReset(t, ClientOption{ClientName: "cn"})
+		go func() {
+			mock.Expect("RESET").ReplyString("RESET")
+			mock.Expect("HELLO", "3", "SETNAME", "cn").Reply(hello) // RESET clears the connection name
+			mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").ReplyString("OK")
+			mock.Expect("SELECT", "1").ReplyString("OK")
+		}()
+		p.CleanSubscriptions()
+		if err := p.Error(); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		closePipe(p, mock)
+	})
+	t.Run("RESET AuthCredentialsFn", func(t *testing.T) {
+		rotated := int32(0)
+		p, mock := setupReset(t, ClientOption{AuthCredentialsFn: func(ctx AuthCredentialsContext) (AuthCredentials, error) {
+			if atomic.LoadInt32(&rotated) == 1 {
+				return AuthCredentials{Username: "u", Password: "p2"}, nil
+			}
+			return AuthCredentials{Username: "u", Password: "p1"}, nil
+		}})
+		atomic.StoreInt32(&rotated, 1)
+		go func() {
+			mock.Expect("RESET").ReplyString("RESET")
+			mock.Expect("HELLO", "3", "AUTH", "u", "p2").Reply(hello) // the credentials rotated after connecting are used
+			mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").ReplyString("OK")
+			mock.Expect("SELECT", "1").ReplyString("OK")
+		}()
+		p.CleanSubscriptions()
+		if err := p.Error(); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		closePipe(p, mock)
+	})
+	t.Run("RESET AuthCredentialsFn Error", func(t *testing.T) {
+		rotated := int32(0)
+		p, mock := setupReset(t, ClientOption{AuthCredentialsFn: func(ctx AuthCredentialsContext) (AuthCredentials, error) {
+			if atomic.LoadInt32(&rotated) == 1 {
+				return AuthCredentials{}, errors.New("rotating")
+			}
+			return AuthCredentials{Username: "u", Password: "p1"}, nil
+		}})
+		atomic.StoreInt32(&rotated, 1)
+		go func() {
+			mock.Expect("PING").ReplyString("OK")
+			mock.Close()
+		}()
+		p.CleanSubscriptions()
+		if err := p.Error(); err != ErrClosing {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+	t.Run("RESET Error", func(t *testing.T) {
+		p, mock := setupReset(t, ClientOption{})
+		go func() {
+			mock.Expect("RESET").ReplyString("RESET")
+			mock.Expect("HELLO", "3").ReplyError("WRONGPASS")
+			mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").ReplyString("OK")
+			mock.Expect("SELECT", "1").ReplyString("OK")
+			mock.Expect("PING").ReplyString("OK")
+			mock.Close()
+		}()
+		p.CleanSubscriptions()
+		if err := p.Error(); err != ErrClosing {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+	t.Run("DisableReset", func(t *testing.T) {
+		p, mock := setupReset(t, ClientOption{DisableReset: true})
+		p.CleanSubscriptions()
+		closePipe(p, mock)
+	})
+}
+
+func TestSupportReset(t *testing.T) {
+	for v, expected := range map[string]bool{"": false, "6": false, "6.0.16": false, "6.2.0": true, "7.0.0": true} {
+		if supportReset(v) != expected {
+			t.Fatalf("unexpected support of %v", v)
+		}
+	}
+}
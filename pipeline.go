@@ -0,0 +1,72 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotExecuted is returned from Future.Result if the Pipeline of the Future has not been executed.
+var ErrNotExecuted = errors.New("the pipeline is not executed yet")
+
+// Future is the handle of a command queued in a Pipeline. Its result is available after the Pipeline.Exec.
+type Future struct {
+	resp RedisResult
+	done bool
+}
+
+// Result returns the RedisResult of the command after the Pipeline.Exec, or a result with ErrNotExecuted before that.
+func (f *Future) Result() RedisResult {
+	if !f.done {
+		return newErrResult(ErrNotExecuted)
+	}
+	return f.resp
+}
+
+// Pipeline queues commands and sends them at once by a DoMulti on Exec, so that commands built conditionally across
+// many code paths don't need to be correlated with their results by indexes. It is not safe for concurrent use.
+type Pipeline struct {
+	client  Client
+	queued  Commands
+	futures []*Future
+}
+
+// NewPipeline creates an empty Pipeline on the client.
+func NewPipeline(client Client) *Pipeline {
+	return &Pipeline{client: client}
+}
+
+// B is the getter function to the command builder for the pipeline
+func (p *Pipeline) B() Builder {
+	return p.client.B()
+}
+
+// Do queues the cmd and returns its Future. The cmd is not sent until the Exec.
+func (p *Pipeline) Do(cmd Completed) *Future {
+	f := &Future{}
+	p.queued = append(p.queued, cmd)
+	p.futures = append(p.futures, f)
+	return f
+}
+
+// Len returns the number of commands queued and not yet executed.
+func (p *Pipeline) Len() int {
+	return len(p.queued)
+}
+
+// Exec sends the queued commands by a DoMulti and resolves their Futures. Each command may fail individually, and
+// the error is available from its Future. The Pipeline is emptied after the Exec and can be used to queue commands again.
+func (p *Pipeline) Exec(ctx context.Context) {
+	if len(p.queued) == 0 {
+		return
+	}
+	for i, resp := range p.client.DoMulti(ctx, p.queued...) {
+		p.futures[i].resp = resp
+		p.futures[i].done = true
+	}
+	for i := range p.queued {
+		p.queued[i] = Completed{}
+		p.futures[i] = nil
+	}
+	p.queued = p.queued[:0]
+	p.futures = p.futures[:0]
+}
@@ -0,0 +1,77 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var sent [][]string
+	m := &mockConn{
+		DoMultiFn: func(multi ...Completed) *redisresults {
+			resps := make([]RedisResult, len(multi))
+			for i, cmd := range multi {
+				sent = append(sent, append([]string(nil), cmd.Commands()...))
+				if cmd.Commands()[0] == "INCR" {
+					resps[i] = newResult(RedisMessage{typ: '-', string: "ERR not an integer"}, nil)
+				} else {
+					resps[i] = newResult(RedisMessage{typ: '+', string: strings.Join(cmd.Commands(), " ")}, nil)
+				}
+			}
+			return &redisresults{s: resps}
+		},
+	}
+	client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+		return m
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	p := NewPipeline(client)
+	p.Exec(context.Background())
+	if len(sent) != 0 {
+		t.Fatalf("unexpected sent %v", sent)
+	}
+
+	get := p.Do(p.B().Get().Key("a").Build())
+	incr := p.Do(p.B().Incr().Key("b").Build())
+	set := p.Do(p.B().Set().Key("c").Value("d").Build())
+	if p.Len() != 3 {
+		t.Fatalf("unexpected len %v", p.Len())
+	}
+	if err := get.Result().Error(); !errors.Is(err, ErrNotExecuted) {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("commands should not be sent before Exec %v", sent)
+	}
+
+	p.Exec(context.Background())
+	if len(sent) != 3 || p.Len() != 0 {
+		t.Fatalf("unexpected sent %v", sent)
+	}
+	if v, err := get.Result().ToString(); err != nil || v != "GET a" {
+		t.Fatalf("unexpected result %v %v", v, err)
+	}
+	if err := incr.Result().Error(); err == nil {
+		t.Fatalf("unexpected err %v", err)
+	} else if _, ok := IsRedisErr(err); !ok {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if v, err := set.Result().ToString(); err != nil || v != "SET c d" {
+		t.Fatalf("unexpected result %v %v", v, err)
+	}
+
+	again := p.Do(p.B().Get().Key("e").Build())
+	p.Exec(context.Background())
+	if v, err := again.Result().ToString(); err != nil || v != "GET e" || len(sent) != 4 {
+		t.Fatalf("unexpected result %v %v", v, err)
+	}
+	if v, err := get.Result().ToString(); err != nil || v != "GET a" {
+		t.Fatalf("resolved futures should not be changed %v %v", v, err)
+	}
+}
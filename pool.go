@@ -1,6 +1,11 @@
 package rueidis
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 func newPool(cap int, dead wire, makeFn func() wire) *pool {
 	if cap <= 0 {
@@ -11,22 +16,105 @@ func newPool(cap int, dead wire, makeFn func() wire) *pool {
 		size: 0,
 		dead: dead,
 		make: makeFn,
-		list: make([]wire, 0, cap),
+		list: make([]pooled, 0, cap),
 		cond: sync.NewCond(&sync.Mutex{}),
 	}
 }
 
+type pooled struct {
+	w  wire
+	at time.Time // when the wire is stored into the pool
+}
+
 type pool struct {
-	dead wire
-	cond *sync.Cond
-	make func() wire
-	list []wire
-	size int
-	down bool
+	dead  wire
+	cond  *sync.Cond
+	limit *connlimit
+	make  func() wire
+	born  map[wire]time.Time
+	timer *time.Timer
+	list  []pooled
+	size  int
+	min   int
+	idle  time.Duration
+	life  time.Duration
+	down  bool
+}
+
+// expire makes the pool close wires that have been idle in the pool longer than the idle
+// or have been established longer than the life. Zero durations disable them respectively.
+// The reaper keeps at least min wires established even if they have been idle longer than the idle.
+func (p *pool) expire(idle, life time.Duration, min int) {
+	p.idle, p.life, p.min = idle, life, min
+	if life > 0 {
+		p.born = make(map[wire]time.Time)
+	}
+}
+
+// connlimit bounds the number of wires in use taken from the pools sharing it.
+type connlimit struct {
+	ch      chan struct{}
+	waiting int64
+}
+
+func newConnLimit(n int) *connlimit {
+	return &connlimit{ch: make(chan struct{}, n)}
+}
+
+func (l *connlimit) take(ctx context.Context) error {
+	select {
+	case l.ch <- struct{}{}:
+		return nil
+	default:
+	}
+	atomic.AddInt64(&l.waiting, 1)
+	defer atomic.AddInt64(&l.waiting, -1)
+	select {
+	case l.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// try takes one without waiting, and reports whether it is taken.
+func (l *connlimit) try() bool {
+	select {
+	case l.ch <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *connlimit) give() {
+	<-l.ch
+}
+
+func (l *connlimit) waits() int64 {
+	return atomic.LoadInt64(&l.waiting)
+}
+
+// AcquireContext is like the Acquire, but if the pool is limited by a connlimit, it waits for the limit until the ctx is done.
+func (p *pool) AcquireContext(ctx context.Context) (wire, error) {
+	if p.limit != nil {
+		if err := p.limit.take(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return p.acquire(), nil
 }
 
 func (p *pool) Acquire() (v wire) {
+	if p.limit != nil {
+		_ = p.limit.take(context.Background())
+	}
+	return p.acquire()
+}
+
+func (p *pool) acquire() (v wire) {
 	p.cond.L.Lock()
+retry:
 	for len(p.list) == 0 && p.size == cap(p.list) && !p.down {
 		p.cond.Wait()
 	}
@@ -35,10 +123,19 @@ func (p *pool) Acquire() (v wire) {
 	} else if len(p.list) == 0 {
 		p.size++
 		v = p.make()
+		if p.born != nil {
+			p.born[v] = time.Now()
+		}
 	} else {
 		i := len(p.list) - 1
-		v = p.list[i]
+		e := p.list[i]
+		p.list[i] = pooled{}
 		p.list = p.list[:i]
+		if p.expired(e, time.Now()) {
+			p.discard(e.w)
+			goto retry
+		}
+		v = e.w
 	}
 	p.cond.L.Unlock()
 	return v
@@ -46,21 +143,113 @@ func (p *pool) Acquire() (v wire) {
 
 func (p *pool) Store(v wire) {
 	p.cond.L.Lock()
-	if !p.down && v.Error() == nil {
-		p.list = append(p.list, v)
+	now := time.Now()
+	if !p.down && v.Error() == nil && !p.expired(pooled{w: v, at: now}, now) {
+		p.list = append(p.list, pooled{w: v, at: now})
+		if p.timer == nil && (p.idle > 0 || p.life > 0) {
+			p.timer = time.AfterFunc(p.interval(), p.reap)
+		}
 	} else {
-		p.size--
-		v.Close()
+		p.discard(v)
 	}
 	p.cond.L.Unlock()
 	p.cond.Signal()
+	if p.limit != nil {
+		p.limit.give()
+	}
+}
+
+func (p *pool) expired(e pooled, now time.Time) bool {
+	if p.idle > 0 && now.Sub(e.at) >= p.idle {
+		return true
+	}
+	return p.outlived(e.w, now)
+}
+
+func (p *pool) outlived(w wire, now time.Time) bool {
+	if p.life > 0 {
+		if born, ok := p.born[w]; ok && now.Sub(born) >= p.life {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *pool) discard(v wire) {
+	p.size--
+	delete(p.born, v)
+	v.Close()
+}
+
+func (p *pool) interval() time.Duration {
+	d := p.idle
+	if d <= 0 || (p.life > 0 && p.life < d) {
+		d = p.life
+	}
+	return d / 2
+}
+
+// reap closes expired wires in the pool periodically until the pool is empty or only has the min wires left,
+// where the oldest idle wires are closed first. Wires established longer than the life are closed regardless of the min.
+func (p *pool) reap() {
+	p.cond.L.Lock()
+	now := time.Now()
+	list, reaped := p.list[:0], false
+	for _, e := range p.list {
+		if p.outlived(e.w, now) || (p.size > p.min && p.expired(e, now)) {
+			p.discard(e.w)
+			reaped = true
+		} else {
+			list = append(list, e)
+		}
+	}
+	for i := len(list); i < len(p.list); i++ {
+		p.list[i] = pooled{}
+	}
+	p.list = list
+	if len(p.list) != 0 && !p.down && (p.size > p.min || p.life > 0) {
+		p.timer.Reset(p.interval())
+	} else {
+		p.timer = nil
+	}
+	p.cond.L.Unlock()
+	if reaped {
+		p.cond.Broadcast()
+	}
+}
+
+// Warmup fills the pool with at least n established wires, capped by the pool size.
+// It returns the first error of the wires if any of them can't be established.
+func (p *pool) Warmup(n int) (err error) {
+	if n > cap(p.list) {
+		n = cap(p.list)
+	}
+	if p.limit != nil && n > cap(p.limit.ch)-len(p.limit.ch) {
+		n = cap(p.limit.ch) - len(p.limit.ch) // the rest are held by the pipeline connections or by others
+	}
+	ws := make([]wire, 0, n)
+	for len(ws) < n {
+		w := p.Acquire()
+		ws = append(ws, w)
+		if err = w.Error(); err != nil {
+			break
+		}
+	}
+	for _, w := range ws {
+		p.Store(w)
+	}
+	return err
 }
 
 func (p *pool) Close() {
 	p.cond.L.Lock()
 	p.down = true
-	for _, w := range p.list {
-		w.Close()
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	for _, e := range p.list {
+		e.w.Close()
 	}
 	p.cond.L.Unlock()
 	p.cond.Broadcast()
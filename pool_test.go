@@ -1,10 +1,12 @@
 package rueidis
 
 import (
+	"context"
 	"errors"
 	"runtime"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var dead = deadFn()
@@ -89,6 +91,22 @@ func TestPool(t *testing.T) {
 		}
 	})
 
+	t.Run("Warmup", func(t *testing.T) {
+		pool, count := setup(10)
+		if err := pool.Warmup(5); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if atomic.LoadInt32(count) != 5 || len(pool.list) != 5 {
+			t.Fatalf("pool is not warmed up")
+		}
+		if err := pool.Warmup(20); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if atomic.LoadInt32(count) != 10 || len(pool.list) != 10 {
+			t.Fatalf("pool must not exceed the size limit on warmup")
+		}
+	})
+
 	t.Run("Close", func(t *testing.T) {
 		pool, count := setup(2)
 		w1 := pool.Acquire()
@@ -210,4 +228,169 @@ func TestPoolError(t *testing.T) {
 			t.Fatalf("unexpected acquire count")
 		}
 	})
+	t.Run("WarmupErr", func(t *testing.T) {
+		pool, count := setup(10)
+		if err := pool.Warmup(5); err == nil || err.Error() != "any" {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if atomic.LoadInt32(count) != 2 || len(pool.list) != 1 || pool.size != 1 {
+			t.Fatalf("pool should stop warming up on error")
+		}
+	})
+}
+
+//gocyclo:ignore
+func TestPoolExpire(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	setup := func(size int, idle, life time.Duration, min int) (*pool, *int32, *int32) {
+		var count, closed int32
+		p := newPool(size, dead, func() wire {
+			atomic.AddInt32(&count, 1)
+			return &mockWire{
+				CloseFn: func() {
+					atomic.AddInt32(&closed, 1)
+				},
+			}
+		})
+		p.expire(idle, life, min)
+		return p, &count, &closed
+	}
+
+	t.Run("Idle", func(t *testing.T) {
+		pool, count, closed := setup(10, time.Hour, 0, 0)
+		defer pool.Close()
+		w := pool.Acquire()
+		pool.Store(w)
+		if pool.Acquire() != w || atomic.LoadInt32(count) != 1 {
+			t.Fatalf("pool should reuse the wire before it is idle for too long")
+		}
+		pool.list = append(pool.list, pooled{w: w, at: time.Now().Add(-time.Hour)})
+		if pool.Acquire() == w || atomic.LoadInt32(count) != 2 || atomic.LoadInt32(closed) != 1 || pool.size != 1 {
+			t.Fatalf("pool should close the idle wire")
+		}
+	})
+
+	t.Run("Lifetime", func(t *testing.T) {
+		pool, count, closed := setup(10, 0, time.Hour, 0)
+		defer pool.Close()
+		w := pool.Acquire()
+		pool.Store(w)
+		if pool.Acquire() != w || atomic.LoadInt32(count) != 1 {
+			t.Fatalf("pool should reuse the wire before its lifetime")
+		}
+		pool.born[w] = time.Now().Add(-time.Hour)
+		pool.Store(w)
+		if len(pool.list) != 0 || atomic.LoadInt32(closed) != 1 || pool.size != 0 || len(pool.born) != 0 {
+			t.Fatalf("pool should close the wire exceeding its lifetime")
+		}
+		if pool.Acquire() == w || atomic.LoadInt32(count) != 2 {
+			t.Fatalf("pool should make a new wire")
+		}
+	})
+
+	t.Run("Reap", func(t *testing.T) {
+		pool, _, closed := setup(10, 30*time.Millisecond, 0, 0)
+		defer pool.Close()
+		ws := []wire{pool.Acquire(), pool.Acquire(), pool.Acquire()}
+		for _, w := range ws {
+			pool.Store(w)
+		}
+		for atomic.LoadInt32(closed) != int32(len(ws)) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		pool.cond.L.Lock()
+		size, length, timer := pool.size, len(pool.list), pool.timer
+		pool.cond.L.Unlock()
+		if size != 0 || length != 0 {
+			t.Fatalf("pool should reap idle wires")
+		}
+		if timer != nil {
+			t.Fatalf("pool should stop reaping once it is empty")
+		}
+	})
+
+	t.Run("Reap Min", func(t *testing.T) {
+		pool, _, closed := setup(10, 30*time.Millisecond, 0, 2)
+		defer pool.Close()
+		ws := []wire{pool.Acquire(), pool.Acquire(), pool.Acquire()}
+		for _, w := range ws {
+			pool.Store(w)
+		}
+		for atomic.LoadInt32(closed) != 1 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(60 * time.Millisecond)
+		pool.cond.L.Lock()
+		size, list, timer := pool.size, append([]pooled(nil), pool.list...), pool.timer
+		pool.cond.L.Unlock()
+		if size != 2 || len(list) != 2 || list[0].w != ws[1] || list[1].w != ws[2] || atomic.LoadInt32(closed) != 1 {
+			t.Fatalf("pool should keep the min wires and reap the oldest one")
+		}
+		if timer != nil {
+			t.Fatalf("pool should stop reaping once only the min wires are left")
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		pool, _, closed := setup(10, time.Hour, 0, 0)
+		pool.Store(pool.Acquire())
+		if pool.timer == nil {
+			t.Fatalf("pool should start reaping")
+		}
+		pool.Close()
+		if pool.timer != nil || atomic.LoadInt32(closed) != 1 {
+			t.Fatalf("pool should stop reaping after closed")
+		}
+	})
+}
+
+func TestPoolLimit(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var count int32
+	fn := func() wire {
+		atomic.AddInt32(&count, 1)
+		return &mockWire{}
+	}
+	limit := newConnLimit(2)
+	p1, p2 := newPool(10, dead, fn), newPool(10, dead, fn)
+	p1.limit, p2.limit = limit, limit
+	defer p1.Close()
+	defer p2.Close()
+
+	if err := p1.Warmup(5); err != nil || atomic.LoadInt32(&count) != 2 {
+		t.Fatalf("warmup should be capped by the limit %v %v", count, err)
+	}
+	w1 := p1.Acquire()
+	w2, err := p2.AcquireContext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p2.AcquireContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if limit.waits() != 0 {
+		t.Fatalf("unexpected waits %v", limit.waits())
+	}
+
+	acquired := make(chan wire)
+	go func() {
+		w, _ := p2.AcquireContext(context.Background())
+		acquired <- w
+	}()
+	for limit.waits() != 1 {
+		runtime.Gosched()
+	}
+	p1.Store(w1)
+	w3 := <-acquired
+	if w3 == nil || len(limit.ch) != 2 {
+		t.Fatalf("unexpected wire %v and in use %v", w3, len(limit.ch))
+	}
+	p2.Store(w2)
+	p2.Store(w3)
+	if len(limit.ch) != 0 {
+		t.Fatalf("unexpected in use %v", len(limit.ch))
+	}
 }
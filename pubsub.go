@@ -15,6 +15,14 @@ type PubSubMessage struct {
 	Message string
 }
 
+// PushMessage represents an out-of-band RESP3 push message delivered to the ClientOption.OnPush.
+type PushMessage struct {
+	// Kind is the first element of the push message, such as "tracking-redir-broken"
+	Kind string
+	// Values are the rest elements of the push message
+	Values []RedisMessage
+}
+
 // PubSubSubscription represent a pubsub "subscribe", "unsubscribe", "psubscribe" or "punsubscribe" event.
 type PubSubSubscription struct {
 	// Kind is "subscribe", "unsubscribe", "psubscribe" or "punsubscribe"
@@ -0,0 +1,20 @@
+package rueidis
+
+import (
+	"bytes"
+	"context"
+)
+
+// DoRaw sends the cmd with the client.DoStream and returns the exact RESP bytes of its response read from the connection,
+// including the attributes, which is useful for proxies, transparent caching layers, and protocol debuggers.
+// Redis error responses are returned as bytes without errors, and the returned error is only about sending the cmd
+// or reading the response. Like the DoStream, the cmd is sent through a dedicated connection acquired from the
+// connection pool, which is recycled after the whole response is read, and the normal Do is not affected.
+func DoRaw(client Client, ctx context.Context, cmd Completed) ([]byte, error) {
+	s := client.DoStream(ctx, cmd)
+	buf := bytes.NewBuffer(nil)
+	if _, err := s.WriteRawTo(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,51 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+func TestDoRaw(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	p, mock, cancel, _ := setup(t, ClientOption{})
+	defer cancel()
+	conns := newPool(1, nil, nil)
+	client := newSingleClientWithConn(&mockConn{
+		DoStreamFn: func(cmd Completed) RedisResultStream {
+			return p.DoStream(context.Background(), conns, cmd)
+		},
+	}, cmds.NewBuilder(cmds.NoSlot), false, nil, false)
+
+	go func() {
+		mock.Expect("HELLO", "3")
+		mock.conn.Write([]byte(">2\r\n$10\r\ninvalidate\r\n*1\r\n$1\r\na\r\n|1\r\n+ttl\r\n:3\r\n%1\r\n+proto\r\n:3\r\n"))
+	}()
+	raw, err := DoRaw(client, context.Background(), client.B().Hello().Protover(3).Build())
+	if err != nil || string(raw) != "|1\r\n+ttl\r\n:3\r\n%1\r\n+proto\r\n:3\r\n" {
+		t.Fatalf("unexpected raw %q %v", raw, err)
+	}
+	if w := conns.Acquire(); w != p {
+		t.Fatalf("pipe is not recycled")
+	}
+	conns.Store(p)
+
+	go func() {
+		mock.Expect("GET", "k").ReplyError("WRONGTYPE")
+	}()
+	if raw, err = DoRaw(client, context.Background(), client.B().Get().Key("k").Build()); err != nil || string(raw) != "-WRONGTYPE\r\n" {
+		t.Fatalf("unexpected raw %q %v", raw, err)
+	}
+
+	e := errors.New("stream")
+	client = newSingleClientWithConn(&mockConn{
+		DoStreamFn: func(cmd Completed) RedisResultStream {
+			return RedisResultStream{e: e}
+		},
+	}, cmds.NewBuilder(cmds.NoSlot), false, nil, false)
+	if raw, err = DoRaw(client, context.Background(), client.B().Get().Key("k").Build()); err != e || raw != nil {
+		t.Fatalf("unexpected raw %q %v", raw, err)
+	}
+}
@@ -0,0 +1,64 @@
+package rueidis
+
+import "strings"
+
+// Redacted replaces the sensitive arguments of commands redacted by the RedactCommand.
+const Redacted = "<redacted>"
+
+// RedactCommand is the default ClientOption.CommandRedactor. It returns the cmd with its credentials replaced by the Redacted,
+// including the arguments of AUTH, the AUTH option of HELLO and MIGRATE, and CONFIG SET of the requirepass and masterauth.
+// The cmd is returned as is if it has nothing to redact. Otherwise, a copy is returned, and the cmd is not modified.
+func RedactCommand(cmd []string) []string {
+	if len(cmd) < 2 {
+		return cmd
+	}
+	var redacted []string
+	redact := func(i int) {
+		if redacted == nil {
+			redacted = append([]string(nil), cmd...)
+		}
+		redacted[i] = Redacted
+	}
+	switch strings.ToUpper(cmd[0]) {
+	case "AUTH":
+		for i := 1; i < len(cmd); i++ {
+			redact(i)
+		}
+	case "HELLO", "MIGRATE":
+		for i := 1; i < len(cmd); i++ {
+			n := 0
+			switch strings.ToUpper(cmd[i]) {
+			case "AUTH":
+				if n = 1; strings.ToUpper(cmd[0]) == "HELLO" {
+					n = 2 // the username and the password
+				}
+			case "AUTH2":
+				n = 2
+			}
+			for ; n > 0 && i+1 < len(cmd); n-- {
+				i++
+				redact(i)
+			}
+		}
+	case "CONFIG":
+		if strings.ToUpper(cmd[1]) != "SET" {
+			break
+		}
+		for i := 2; i+1 < len(cmd); i += 2 {
+			if p := strings.ToLower(cmd[i]); p == "requirepass" || p == "masterauth" {
+				redact(i + 1)
+			}
+		}
+	}
+	if redacted == nil {
+		return cmd
+	}
+	return redacted
+}
+
+func redactCommand(option *ClientOption, cmd []string) []string {
+	if option.CommandRedactor != nil {
+		return option.CommandRedactor(cmd)
+	}
+	return RedactCommand(cmd)
+}
@@ -0,0 +1,44 @@
+package rueidis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactCommand(t *testing.T) {
+	for _, c := range []struct {
+		cmd      []string
+		expected []string
+	}{
+		{cmd: nil, expected: nil},
+		{cmd: []string{"AUTH"}, expected: []string{"AUTH"}},
+		{cmd: []string{"GET", "AUTH"}, expected: []string{"GET", "AUTH"}},
+		{cmd: []string{"AUTH", "pass"}, expected: []string{"AUTH", Redacted}},
+		{cmd: []string{"auth", "user", "pass"}, expected: []string{"auth", Redacted, Redacted}},
+		{cmd: []string{"HELLO", "3", "AUTH", "user", "pass", "SETNAME", "name"}, expected: []string{"HELLO", "3", "AUTH", Redacted, Redacted, "SETNAME", "name"}},
+		{cmd: []string{"HELLO", "3", "SETNAME", "name"}, expected: []string{"HELLO", "3", "SETNAME", "name"}},
+		{cmd: []string{"HELLO", "3", "AUTH", "user"}, expected: []string{"HELLO", "3", "AUTH", Redacted}},
+		{cmd: []string{"MIGRATE", "h", "6379", "", "0", "10", "AUTH", "pass", "KEYS", "a"}, expected: []string{"MIGRATE", "h", "6379", "", "0", "10", "AUTH", Redacted, "KEYS", "a"}},
+		{cmd: []string{"MIGRATE", "h", "6379", "", "0", "10", "AUTH2", "user", "pass", "KEYS", "a"}, expected: []string{"MIGRATE", "h", "6379", "", "0", "10", "AUTH2", Redacted, Redacted, "KEYS", "a"}},
+		{cmd: []string{"CONFIG", "SET", "maxmemory", "1", "requirepass", "pass", "masterauth", "pass"}, expected: []string{"CONFIG", "SET", "maxmemory", "1", "requirepass", Redacted, "masterauth", Redacted}},
+		{cmd: []string{"CONFIG", "GET", "requirepass"}, expected: []string{"CONFIG", "GET", "requirepass"}},
+	} {
+		origin := append([]string(nil), c.cmd...)
+		if redacted := RedactCommand(c.cmd); !reflect.DeepEqual(redacted, c.expected) {
+			t.Fatalf("unexpected redacted %v of %v", redacted, c.cmd)
+		}
+		if len(origin) != 0 && !reflect.DeepEqual(origin, c.cmd) {
+			t.Fatalf("the cmd should not be modified %v", c.cmd)
+		}
+	}
+}
+
+func TestRedactCommandOption(t *testing.T) {
+	if cmd := redactCommand(&ClientOption{}, []string{"AUTH", "pass"}); !reflect.DeepEqual(cmd, []string{"AUTH", Redacted}) {
+		t.Fatalf("unexpected redacted %v", cmd)
+	}
+	option := &ClientOption{CommandRedactor: func(cmd []string) []string { return []string{cmd[0], "?"} }}
+	if cmd := redactCommand(option, []string{"AUTH", "pass"}); !reflect.DeepEqual(cmd, []string{"AUTH", "?"}) {
+		t.Fatalf("unexpected redacted %v", cmd)
+	}
+}
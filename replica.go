@@ -0,0 +1,34 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoReplica is returned from DoReplica if the shard serving the slot of the cmd has no replica of the given index,
+// or the client is not a cluster client.
+var ErrNoReplica = errors.New("the redis replica of the given index does not exist")
+
+// ErrReplicaNotReadOnly is returned from DoReplica if the cmd is not a read-only command.
+var ErrReplicaNotReadOnly = errors.New("DoReplica only accepts read-only commands")
+
+type replicaDoer interface {
+	doReplica(ctx context.Context, cmd Completed, replica int) RedisResult
+}
+
+// DoReplica sends the read-only cmd to the replica of the given index of the shard serving the slot of the cmd,
+// which is useful for diagnostics and tests, such as observing the replication lag and read-your-writes experiments.
+// Replicas of a shard are indexed from 0 in the order reported by CLUSTER SLOTS or CLUSTER SHARDS, which may change after
+// the topology is refreshed. Commands without keys are sent to the replicas of an arbitrary shard.
+// A READONLY is sent before the cmd on the same connection, unless the replicas are already in READONLY mode
+// by the ReplicaOnly or SendToReplicas. The reply is returned as is without following redirections or retrying.
+// It only supports cluster clients and returns ErrNoReplica for others.
+func DoReplica(client Client, ctx context.Context, cmd Completed, replica int) RedisResult {
+	if !cmd.IsReadOnly() {
+		return newErrResult(ErrReplicaNotReadOnly)
+	}
+	if c, ok := client.(replicaDoer); ok {
+		return c.doReplica(ctx, cmd, replica)
+	}
+	return newErrResult(ErrNoReplica)
+}
@@ -0,0 +1,105 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDoReplica(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var mu sync.Mutex
+	sent := map[string][][]string{}
+	setup := func(t *testing.T, sendToReplicas func(cmd Completed) bool) *clusterClient {
+		client, err := newClusterClient(&ClientOption{
+			InitAddress:    []string{"127.0.0.1:0"},
+			SendToReplicas: sendToReplicas,
+		}, func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				DoFn: func(cmd Completed) RedisResult {
+					if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+						return slotsMultiResp
+					}
+					mu.Lock()
+					sent[dst] = append(sent[dst], cmd.Commands())
+					mu.Unlock()
+					return newResult(RedisMessage{typ: '+', string: dst}, nil)
+				},
+				DoMultiFn: func(multi ...Completed) *redisresults {
+					resps := make([]RedisResult, 0, len(multi))
+					mu.Lock()
+					for _, cmd := range multi {
+						sent[dst] = append(sent[dst], cmd.Commands())
+						resps = append(resps, newResult(RedisMessage{typ: '+', string: dst}, nil))
+					}
+					mu.Unlock()
+					return &redisresults{s: resps}
+				},
+			}
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client
+	}
+
+	t.Run("Not ReadOnly", func(t *testing.T) {
+		client := setup(t, nil)
+		defer client.Close()
+		if err := DoReplica(client, context.Background(), client.B().Set().Key("a").Value("v").Build(), 0).Error(); err != ErrReplicaNotReadOnly {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Not Cluster", func(t *testing.T) {
+		m := &mockConn{}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn { return m })
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if err := DoReplica(client, context.Background(), client.B().Get().Key("a").Build(), 0).Error(); err != ErrNoReplica {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("No Replica", func(t *testing.T) {
+		client := setup(t, nil)
+		defer client.Close()
+		for _, i := range []int{-1, 1} {
+			if err := DoReplica(client, context.Background(), client.B().Get().Key("a").Build(), i).Error(); !errors.Is(err, ErrNoReplica) {
+				t.Fatalf("unexpected err %v", err)
+			}
+		}
+	})
+
+	t.Run("READONLY Handshake", func(t *testing.T) {
+		client := setup(t, nil)
+		defer client.Close()
+		// the slot of "a" is 15495, which is served by the master 127.0.2.1:0 and the replica 127.0.3.1:1
+		if v, err := DoReplica(client, context.Background(), client.B().Get().Key("a").Build(), 0).ToString(); err != nil || v != "127.0.3.1:1" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !reflect.DeepEqual(sent["127.0.3.1:1"], [][]string{{"READONLY"}, {"GET", "a"}}) {
+			t.Fatalf("unexpected commands %v", sent["127.0.3.1:1"])
+		}
+		delete(sent, "127.0.3.1:1")
+	})
+
+	t.Run("Replicas Already READONLY", func(t *testing.T) {
+		client := setup(t, func(cmd Completed) bool { return false })
+		defer client.Close()
+		if v, err := DoReplica(client, context.Background(), client.B().Get().Key("b").Build(), 0).ToString(); err != nil || v != "127.0.1.1:1" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !reflect.DeepEqual(sent["127.0.1.1:1"], [][]string{{"GET", "b"}}) {
+			t.Fatalf("unexpected commands %v", sent["127.0.1.1:1"])
+		}
+	})
+}
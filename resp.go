@@ -365,6 +365,140 @@ next:
 	}
 }
 
+const rawChunked = -2
+
+// rawTo copies the exact bytes of the next redis response, including its attributes, from the i to the w.
+// Push messages before the response are skipped like the streamTo. Unlike the streamTo, any error leaves the i unclean.
+func rawTo(i *bufio.Reader, w io.Writer) (n int64, err error) {
+	for {
+		var peek []byte
+		if peek, err = i.Peek(1); err != nil {
+			return 0, err
+		}
+		if peek[0] != typePush {
+			_, n, err = rawCopy(i, w)
+			return n, err
+		}
+		if _, _, err = rawCopy(i, io.Discard); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// rawCopy copies the exact bytes of the next message from the i to the w and returns its type.
+func rawCopy(i *bufio.Reader, w io.Writer) (typ byte, n int64, err error) {
+	var length, nn int64
+	if typ, length, n, err = rawLine(i, w); err != nil {
+		return typ, n, err
+	}
+	switch typ {
+	case typeBlobString, typeBlobErr, typeVerbatimString:
+		if length != rawChunked {
+			nn, err = rawBlob(i, w, length)
+			return typ, n + nn, err
+		}
+		for {
+			var chunk byte
+			chunk, length, nn, err = rawLine(i, w)
+			if n += nn; err != nil {
+				return typ, n, err
+			}
+			if chunk != typeChunk {
+				return typ, n, errors.New(unknownMessageType + strconv.Itoa(int(chunk)))
+			}
+			if length == 0 {
+				return typ, n, nil
+			}
+			nn, err = rawBlob(i, w, length)
+			if n += nn; err != nil {
+				return typ, n, err
+			}
+		}
+	case typeArray, typeSet, typePush, typeMap, typeAttribute:
+		if length == rawChunked {
+			for next := byte(0); next != typeEnd; {
+				next, nn, err = rawCopy(i, w)
+				if n += nn; err != nil {
+					return typ, n, err
+				}
+			}
+		} else {
+			if typ == typeMap || typ == typeAttribute {
+				length *= 2
+			}
+			for j := int64(0); j < length; j++ {
+				_, nn, err = rawCopy(i, w)
+				if n += nn; err != nil {
+					return typ, n, err
+				}
+			}
+		}
+		if typ == typeAttribute { // the attributes are followed by the attributed message
+			typ, nn, err = rawCopy(i, w)
+			n += nn
+		}
+	}
+	return typ, n, err
+}
+
+// rawLine copies a line, including its CRLF, from the i to the w. It returns the type of the line and also the length
+// if the type is an aggregate or a blob. The length is rawChunked for streamed aggregates and blobs.
+func rawLine(i *bufio.Reader, w io.Writer) (typ byte, length int64, n int64, err error) {
+	var line []byte
+	for {
+		line, err = i.ReadSlice('\n')
+		if n == 0 && len(line) != 0 {
+			typ = line[0]
+		}
+		if len(line) != 0 {
+			nn, werr := w.Write(line)
+			if n += int64(nn); werr != nil {
+				return typ, 0, n, werr
+			}
+		}
+		if err != bufio.ErrBufferFull {
+			break
+		}
+	}
+	if err != nil {
+		return typ, 0, n, err
+	}
+	if n < 3 {
+		return typ, 0, n, errors.New(unexpectedNoCRLF)
+	}
+	switch typ {
+	case typeBlobString, typeBlobErr, typeVerbatimString, typeChunk, typeArray, typeSet, typePush, typeMap, typeAttribute:
+		if int64(len(line)) != n {
+			return typ, 0, n, errors.New(unexpectedNumByte + strconv.Itoa(int(line[0])))
+		}
+		if bs := line[1 : len(line)-2]; len(bs) == 1 && bs[0] == '?' {
+			length = rawChunked
+		} else if length, err = strconv.ParseInt(string(bs), 10, 64); err != nil || length < -1 {
+			return typ, 0, n, errors.New(unexpectedNumByte + strconv.Itoa(int(bs[0])))
+		}
+	case typeSimpleString, typeSimpleErr, typeInteger, typeNull, typeEnd, typeFloat, typeBool, typeBigNumber:
+	default:
+		return typ, 0, n, errors.New(unknownMessageType + strconv.Itoa(int(typ)))
+	}
+	return typ, length, n, nil
+}
+
+// rawBlob copies the blob of the length and its CRLF from the i to the w.
+func rawBlob(i *bufio.Reader, w io.Writer, length int64) (n int64, err error) {
+	if length < 0 {
+		return 0, nil
+	}
+	lr := lrs.Get().(*io.LimitedReader)
+	lr.R = i
+	lr.N = length + 2
+	n, err = io.Copy(w, lr)
+	lrs.Put(lr)
+	if err == nil && n != length+2 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
 func writeCmd(o *bufio.Writer, cmd []string) (err error) {
 	err = writeN(o, '*', len(cmd))
 	for _, m := range cmd {
@@ -699,3 +699,83 @@ func randN(n int) (v int) {
 	}
 	return
 }
+
+func TestReadRaw(t *testing.T) {
+	for _, data := range []string{
+		"+OK\r\n",
+		"-ERR word\r\n",
+		":-1234567\r\n",
+		"_\r\n",
+		",1.23\r\n",
+		"#t\r\n",
+		"(3492890328409238509324850943850943825024385\r\n",
+		"$10\r\nHello word\r\n",
+		"$0\r\n\r\n",
+		"$-1\r\n",
+		"*-1\r\n",
+		"!21\r\nSYNTAX invalid syntax\r\n",
+		"=15\r\ntxt:Some string\r\n",
+		"$?\r\n;4\r\nHell\r\n;5\r\no wor\r\n;1\r\nd\r\n;0\r\n",
+		"*3\r\n:1\r\n$1\r\n2\r\n*1\r\n+3\r\n",
+		"*?\r\n:1\r\n:2\r\n:3\r\n.\r\n",
+		"%2\r\n+first\r\n:1\r\n+second\r\n%?\r\n+k\r\n~1\r\n+v\r\n.\r\n",
+		"|1\r\n+key-popularity\r\n%1\r\n$1\r\na\r\n,0.1923\r\n*2\r\n:2039123\r\n:9543892\r\n",
+	} {
+		for i := 1; i <= len(data); i++ {
+			buf := bytes.NewBuffer(nil)
+			n, err := rawTo(bufio.NewReader(io.LimitReader(strings.NewReader(data), int64(i))), buf)
+			if i < len(data) {
+				if err == nil {
+					t.Fatalf("unexpected no error: %q %v", data, i)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected err %q %v", data, err)
+				}
+				if buf.String() != data {
+					t.Fatalf("unexpected raw %q, expected %q", buf.String(), data)
+				}
+				if n != int64(len(data)) {
+					t.Fatalf("unexpected n %v %q", n, data)
+				}
+			}
+		}
+	}
+}
+
+func TestReadRawSkipPush(t *testing.T) {
+	data := ">2\r\n+ignore\r\n*?\r\n+ignore\r\n.\r\n:-1234567\r\n:1\r\n"
+	i := bufio.NewReader(strings.NewReader(data))
+	buf := bytes.NewBuffer(nil)
+	if n, err := rawTo(i, buf); err != nil || buf.String() != ":-1234567\r\n" || n != int64(buf.Len()) {
+		t.Fatalf("unexpected raw %q %v %v", buf.String(), n, err)
+	}
+	if m, err := readNextMessage(i); err != nil || m.integer != 1 {
+		t.Fatalf("the next response should be left unread %v %v", m, err)
+	}
+}
+
+func TestReadRawLongLine(t *testing.T) {
+	data := "*2\r\n+" + strings.Repeat("a", 100) + "\r\n$3\r\nabc\r\n"
+	buf := bytes.NewBuffer(nil)
+	if n, err := rawTo(bufio.NewReaderSize(strings.NewReader(data), 16), buf); err != nil || buf.String() != data || n != int64(len(data)) {
+		t.Fatalf("unexpected raw %q %v %v", buf.String(), n, err)
+	}
+}
+
+func TestReadRawErr(t *testing.T) {
+	for _, c := range []struct {
+		data string
+		err  string
+	}{
+		{data: "+\n", err: unexpectedNoCRLF},
+		{data: "X\r\n", err: unknownMessageType + strconv.Itoa('X')},
+		{data: "$a\r\n", err: unexpectedNumByte + strconv.Itoa('a')},
+		{data: "*-2\r\n", err: unexpectedNumByte + strconv.Itoa('-')},
+		{data: "$?\r\n+OK\r\n", err: unknownMessageType + strconv.Itoa('+')},
+	} {
+		if _, err := rawTo(bufio.NewReader(strings.NewReader(c.data)), io.Discard); err == nil || err.Error() != c.err {
+			t.Fatalf("unexpected err %v of %q", err, c.data)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package rueidis
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// RetryPolicy decides whether a command should be retried after it failed with a non-redis error, such as a connection error,
+// or with a TRYAGAIN or CLUSTERDOWN error in cluster mode. The attempts is the number of attempts already made, starting from 1.
+// If the retry is true, the command will be retried after the delay.
+type RetryPolicy func(cmd Completed, attempts int, err error) (delay time.Duration, retry bool)
+
+// NewIdempotentRetryPolicy returns a RetryPolicy that only retries read-only commands and the commands whose names,
+// the first token of the command, are listed in the idempotent, such as "SET" and "DEL". Other writes, such as INCR and LPUSH,
+// are never retried to avoid applying them twice. The retries are delayed by the backoff exponentially, which is capped at 32 times the backoff,
+// and stop after the maxAttempts. A non-positive maxAttempts means no limit.
+func NewIdempotentRetryPolicy(maxAttempts int, backoff time.Duration, idempotent ...string) RetryPolicy {
+	names := make(map[string]struct{}, len(idempotent))
+	for _, name := range idempotent {
+		names[strings.ToUpper(name)] = struct{}{}
+	}
+	return func(cmd Completed, attempts int, err error) (time.Duration, bool) {
+		if maxAttempts > 0 && attempts >= maxAttempts {
+			return 0, false
+		}
+		if !cmd.IsReadOnly() {
+			if _, ok := names[strings.ToUpper(cmd.Commands()[0])]; !ok {
+				return 0, false
+			}
+		}
+		shift := attempts - 1
+		if shift > 5 {
+			shift = 5
+		}
+		return backoff << shift, true
+	}
+}
+
+// retryable reports whether the cmd should be retried according to the policy, and waits for the delay given by the policy.
+// If the policy is nil, only read-only commands are retried immediately.
+func retryable(policy RetryPolicy, ctx context.Context, cmd Completed, attempts int, err error) bool {
+	if policy == nil {
+		return cmd.IsReadOnly()
+	}
+	delay, ok := policy(cmd, attempts, err)
+	return ok && waitRetry(ctx, delay)
+}
+
+// retryableMulti is like retryable, but all the multi should be retried.
+func retryableMulti(policy RetryPolicy, ctx context.Context, multi []Completed, attempts int, err error) bool {
+	if policy == nil {
+		return allReadOnly(multi)
+	}
+	delay, ok := delayMulti(policy, multi, attempts, err)
+	return ok && waitRetry(ctx, delay)
+}
+
+// retryableCache is like retryable, but cacheable commands are always retried if the policy is nil.
+func retryableCache(policy RetryPolicy, ctx context.Context, cmd Cacheable, attempts int, err error) bool {
+	if policy == nil {
+		return true
+	}
+	delay, ok := policy(Completed(cmd), attempts, err)
+	return ok && waitRetry(ctx, delay)
+}
+
+// retryableMultiCache is like retryableCache, but all the multi should be retried.
+func retryableMultiCache(policy RetryPolicy, ctx context.Context, multi []CacheableTTL, attempts int, err error) bool {
+	if policy == nil {
+		return true
+	}
+	var max time.Duration
+	for _, cmd := range multi {
+		delay, ok := policy(Completed(cmd.Cmd), attempts, err)
+		if !ok {
+			return false
+		}
+		if delay > max {
+			max = delay
+		}
+	}
+	return waitRetry(ctx, max)
+}
+
+func delayMulti(policy RetryPolicy, multi []Completed, attempts int, err error) (max time.Duration, ok bool) {
+	for _, cmd := range multi {
+		delay, ok := policy(cmd, attempts, err)
+		if !ok {
+			return 0, false
+		}
+		if delay > max {
+			max = delay
+		}
+	}
+	return max, true
+}
+
+func waitRetry(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
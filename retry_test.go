@@ -0,0 +1,39 @@
+package rueidis
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+func TestNewIdempotentRetryPolicy(t *testing.T) {
+	policy := NewIdempotentRetryPolicy(3, time.Millisecond, "set", "DEL")
+	builder := cmds.NewBuilder(cmds.NoSlot)
+	err := errors.New("any")
+
+	for _, c := range []struct {
+		cmd      Completed
+		attempts int
+		delay    time.Duration
+		retry    bool
+	}{
+		{cmd: builder.Get().Key("k").Build(), attempts: 1, delay: time.Millisecond, retry: true},
+		{cmd: builder.Get().Key("k").Build(), attempts: 2, delay: 2 * time.Millisecond, retry: true},
+		{cmd: builder.Get().Key("k").Build(), attempts: 3, retry: false},
+		{cmd: builder.Set().Key("k").Value("v").Build(), attempts: 1, delay: time.Millisecond, retry: true},
+		{cmd: builder.Del().Key("k").Build(), attempts: 1, delay: time.Millisecond, retry: true},
+		{cmd: builder.Incr().Key("k").Build(), attempts: 1, retry: false},
+		{cmd: builder.Lpush().Key("k").Element("v").Build(), attempts: 1, retry: false},
+	} {
+		if delay, retry := policy(c.cmd, c.attempts, err); delay != c.delay || retry != c.retry {
+			t.Fatalf("unexpected result for %v %v: %v %v", c.cmd.Commands(), c.attempts, delay, retry)
+		}
+	}
+
+	policy = NewIdempotentRetryPolicy(0, time.Millisecond)
+	if delay, retry := policy(builder.Get().Key("k").Build(), 100, err); delay != 32*time.Millisecond || !retry {
+		t.Fatalf("unexpected result %v %v", delay, retry)
+	}
+}
@@ -51,6 +51,10 @@ var (
 	ErrReplicaOnlyNotSupported = errors.New("ReplicaOnly is not supported for single client")
 	// ErrWrongPipelineMultiplex means wrong value for ClientOption.PipelineMultiplex
 	ErrWrongPipelineMultiplex = errors.New("ClientOption.PipelineMultiplex must not be bigger than MaxPipelineMultiplex")
+	// ErrWrongMaxConns means the ClientOption.MaxConns can't hold the pipeline connections of the ClientOption.PipelineMultiplex and one more
+	ErrWrongMaxConns = errors.New("ClientOption.MaxConns must be bigger than the 2^PipelineMultiplex pipeline connections")
+	// ErrUnixSocketNotSupported means a unix domain socket address is used with TLS, Sentinel or other addresses in ClientOption.InitAddress
+	ErrUnixSocketNotSupported = errors.New("unix domain socket address must be the only one in InitAddress and can't be used with TLSConfig or Sentinel")
 )
 
 // ClientOption should be passed to NewClient to construct a Client
@@ -66,35 +70,79 @@ type ClientOption struct {
 	Dialer    net.Dialer
 	TLSConfig *tls.Config
 
-	// DialFn allows for a custom function to be used to create net.Conn connections
+	// TLSServerNameFn, if set, returns the ServerName used to verify the certificate of the redis node at the addr,
+	// including nodes discovered from the cluster topology and redirected by MOVED or ASK, so that nodes with different
+	// hostnames can be verified with their own certificates. The TLSConfig is cloned with the ServerName for each connection.
+	// Use TLSServerNameFromAddr to derive it from the host of the addr even if the TLSConfig.ServerName is set for the InitAddress.
+	// By default, the TLSConfig.ServerName is used for all nodes, or the host of each addr is used if it is empty.
+	TLSServerNameFn func(addr string) string
+
+	// DialFn allows for a custom function to be used to create net.Conn connections.
+	// The TLSConfig passed to it already has the ServerName returned by the TLSServerNameFn if set.
 	DialFn func(string, *net.Dialer, *tls.Config) (conn net.Conn, err error)
 
+	// DialCtxFn, if set, is used instead of the DialFn and the built-in dialer to create net.Conn connections to the addr,
+	// for example, through a SOCKS proxy or a service mesh. It is used for all connections, including reconnections,
+	// connections to sentinels, and connections to the cluster nodes discovered or redirected by MOVED or ASK.
+	// The ctx is only for establishing the connection, and it is done once the Dialer.Timeout is reached or the DialCtxFn returns. The cfg is the TLSConfig with the ServerName returned by
+	// the TLSServerNameFn if set, and it is nil if the TLSConfig is not set. The returned net.Conn should already complete
+	// its TLS handshake if the cfg is not nil.
+	DialCtxFn func(ctx context.Context, addr string, cfg *tls.Config) (conn net.Conn, err error)
+
 	// NewCacheStoreFn allows a custom client side caching store for each connection
 	NewCacheStoreFn NewCacheStoreFn
 
 	// OnInvalidations is a callback function in case of client-side caching invalidation received.
+	// It is called with the invalidated keys after their cached responses are deleted from the local cache,
+	// or with nil if all the cached responses of the connection are deleted, for example, the connection is closed.
 	// Note that this function must be fast, otherwise other redis messages will be blocked.
+	// Hand the keys over to another goroutine if it needs to do heavy work or to call redis.
 	OnInvalidations func([]RedisMessage)
 
+	// OnPush is a callback function in case of RESP3 push messages received, which are not handled by the client internally,
+	// such as "tracking-redir-broken". Invalidations and pubsub messages are not delivered to it.
+	// Note that this function must be fast, otherwise other redis messages will be blocked.
+	OnPush func(PushMessage)
+
 	// SendToReplicas is a function that returns true if the command should be sent to replicas.
-	// currently only used for cluster client.
+	// currently only used for cluster client. Connections to replicas are set up with the READONLY command,
+	// and commands of a slot are sent to its master if the slot has no healthy replica.
 	// NOTE: This function can't be used with ReplicaOnly option.
 	SendToReplicas func(cmd Completed) bool
 
+	// CircuitBreaker, if set, enables the per node circuit breaker of the cluster client.
+	// Read-only commands of a node with an open circuit are sent to other nodes serving the same slots, which are
+	// the replicas of the shard, and other commands fail fast with the ErrCircuitOpen. See CircuitBreaker.
+	CircuitBreaker *CircuitBreaker
+
 	// Sentinel options, including MasterSet and Auth options
 	Sentinel SentinelOption
 
 	// Redis AUTH parameters
-	Username   string
-	Password   string
+	Username string
+	Password string
+	// ClientName, if set, is assigned to every connection by HELLO SETNAME, including reconnections,
+	// so that connections can be attributed to the service in the CLIENT LIST. Connections are unnamed by default.
 	ClientName string
 
 	// AuthCredentialsFn allows for setting the AUTH username and password dynamically on each connection attempt to
-	// support rotating credentials
+	// support rotating credentials. It is invoked lazily before every new connection authenticates, including reconnections,
+	// and connections already authenticated are kept without being dropped.
 	AuthCredentialsFn func(AuthCredentialsContext) (AuthCredentials, error)
 
+	// CommandRedactor is used to redact the sensitive arguments of a command before the command is surfaced in errors.
+	// It must not modify the given cmd. The default is RedactCommand, which redacts the credentials of AUTH, HELLO, MIGRATE and CONFIG SET.
+	// Hooks and tracers wrapping the Client receive commands as they are, and they can also use RedactCommand before logging them.
+	CommandRedactor func(cmd []string) []string
+
+	// PreloadScripts are the lua scripts to be loaded by SCRIPT LOAD on every new connection, including reconnections
+	// and connections to every cluster node, so that their EVALSHA will not fail with NOSCRIPT on their first call.
+	// A connection fails to be established if any of the scripts fails to be loaded.
+	PreloadScripts []string
+
 	// ClientSetInfo will assign various info attributes to the current connection.
 	// Note that ClientSetInfo should have exactly 2 values, the lib name and the lib version respectively.
+	// The default is LibName and LibVer, and they are ignored by redis < 7.2 which doesn't support the CLIENT SETINFO.
 	ClientSetInfo []string
 
 	// InitAddress point to redis nodes.
@@ -102,6 +150,8 @@ type ClientOption struct {
 	// If len(InitAddress) == 1 and the address is not running in cluster mode, rueidis will fall back to the single client mode.
 	// If ClientOption.Sentinel.MasterSet is set, then InitAddress will be used to connect sentinels
 	// You can bypass this behaviour by using ClientOption.ForceSingleClient.
+	// An address can also be a unix domain socket, such as "unix:///var/run/redis.sock", which always uses the single client mode.
+	// It must be the only one in InitAddress and can't be used with TLSConfig or Sentinel.
 	InitAddress []string
 
 	// ClientTrackingOptions will be appended to CLIENT TRACKING ON command when the connection is established.
@@ -129,6 +179,33 @@ type ClientOption struct {
 	// The default is DefaultPoolSize.
 	BlockingPoolSize int
 
+	// MinConns, if positive, makes NewClient eagerly establish and authenticate all the pipeline connections
+	// and MinConns connections of the blocking pool to each redis instance dialed during NewClient, so that
+	// the first requests do not pay the handshake cost. NewClient fails if any of these connections can't be established.
+	// Each connection attempt is bounded by the Dialer.Timeout and the ConnWriteTimeout.
+	// MinConns is capped by the BlockingPoolSize. The blocking pool also keeps at least MinConns connections
+	// from being closed by the MaxConnIdleTime, but not by the ConnLifetime.
+	MinConns int
+
+	// MaxConns, if positive, limits the connections to each redis instance in use at the same time, including the pipeline
+	// connections of the PipelineMultiplex and the connections taken for blocking commands, DoStream, and dedicated clients.
+	// Once it is reached, commands needing a new connection wait for one to be returned until their ctx is done. That includes
+	// pipelined commands whose pipeline connection is not established yet or is broken, while established pipeline connections keep
+	// serving commands and hold their share until they are broken. It must be bigger than the pipeline connections, and the
+	// PipelineMultiplex of a single client chosen automatically is lowered to fit it. With it, a dedicated client takes its connection
+	// by its first command instead of by the Dedicated or the Dedicate, so that it waits with the ctx of the command.
+	// Idle connections kept in the pool are not counted, and they can be closed by the MaxConnIdleTime.
+	// The number of callers waiting is reported by the ConnStat.Waiting.
+	MaxConns int
+
+	// MaxConnIdleTime, if positive, makes connections idle in the blocking pool longer than it be closed and removed from the pool,
+	// so that they will not be reused after being closed silently by the server-side timeout or by proxies.
+	// Set it shorter than the server-side timeout. Pipeline connections are not affected because they are kept alive by PING.
+	MaxConnIdleTime time.Duration
+	// ConnLifetime, if positive, makes connections in the blocking pool established longer than it be closed
+	// instead of being reused once they are returned to the pool. Pipeline connections are not affected.
+	ConnLifetime time.Duration
+
 	// PipelineMultiplex determines how many tcp connections used to pipeline commands to one redis instance.
 	// The default for single and sentinel clients is 2, which means 4 connections (2^2).
 	// The default for cluster clients is 0, which means 1 connection (2^0).
@@ -151,12 +228,24 @@ type ClientOption struct {
 	// produce notable CPU usage reduction under load. Ref: https://github.com/redis/rueidis/issues/156
 	MaxFlushDelay time.Duration
 
+	// ReconnectBackoff decides how long a pipeline connection waits before dialing a redis instance again after consecutive failed dials.
+	// The attempt is the number of the consecutive failed dials of the connection, starting from 1. A non-positive delay means dialing immediately.
+	// The default is an exponential backoff from 10ms up to 640ms with jitter, which spreads reconnections of many clients
+	// after a redis restart. Commands don't wait for the delay. Instead, they fail fast with the error of the last dial until it passes.
+	// Note that the first reconnection after a connection is broken is always immediate.
+	ReconnectBackoff func(attempt int) time.Duration
+
 	// ShuffleInit is a handy flag that shuffles the InitAddress after passing to the NewClient() if it is true
 	ShuffleInit bool
-	// ClientNoTouch controls whether commands alter LRU/LFU stats
+	// ClientNoTouch controls whether commands alter LRU/LFU stats by CLIENT NO-TOUCH ON on every connection, including reconnections.
+	// It is skipped on redis < 7.2 which doesn't support the CLIENT NO-TOUCH.
 	ClientNoTouch bool
 	// DisableRetry disables retrying read-only commands under network errors
 	DisableRetry bool
+	// RetryPolicy, if set, decides which commands to retry and how long to wait before retrying under network errors,
+	// instead of retrying read-only commands immediately. See NewIdempotentRetryPolicy for a builtin policy.
+	// It is applied to Do, DoMulti, DoCache and DoMultiCache, and it has no effect if the DisableRetry is set.
+	RetryPolicy RetryPolicy
 	// DisableCache falls back Client.DoCache/Client.DoMultiCache to Client.Do/Client.DoMulti
 	DisableCache bool
 	// AlwaysPipelining makes rueidis.Client always pipeline redis commands even if they are not issued concurrently.
@@ -174,7 +263,13 @@ type ClientOption struct {
 	// When turned on and client eviction is configured,
 	// the current connection will be excluded from the client eviction process
 	// even if we're above the configured client eviction threshold.
+	// It is sent by CLIENT NO-EVICT ON on every connection, including reconnections, and skipped on redis < 7.0 which doesn't support it.
 	ClientNoEvict bool
+
+	// DisableReset disables sending RESET to a dedicated connection before it is returned to the pool.
+	// By default, on redis >= 6.2, the connection is RESET and initialized again, so that states left by aborted
+	// Dedicated callbacks, such as an unfinished MULTI or WATCH, will not leak to the next user of the connection.
+	DisableReset bool
 }
 
 // SentinelOption contains MasterSet,
@@ -274,6 +369,11 @@ type CoreClient interface {
 	// The cmd parameter is recycled after passing into Do() and should not be reused.
 	Do(ctx context.Context, cmd Completed) (resp RedisResult)
 	// DoMulti takes multiple redis commands and sends them together, reducing RTT from the user code.
+	// Each command has its own RedisResult: an error response of a command, such as WRONGTYPE, is confined to its own result
+	// and does not affect the others, while a connection error fails all the commands sent over the broken connection.
+	// Use AnyError to check if any of the commands failed.
+	// In cluster mode, the commands are grouped by their nodes and sent to each node in one pipeline concurrently, and
+	// the results are returned in the original order. Only the commands redirected by MOVED or ASK are regrouped and retried.
 	// The multi parameters are recycled after passing into DoMulti() and should not be reused.
 	DoMulti(ctx context.Context, multi ...Completed) (resp []RedisResult)
 	// Receive accepts SUBSCRIBE, SSUBSCRIBE, PSUBSCRIBE command and a message handler.
@@ -340,12 +440,23 @@ func NewClient(option ClientOption) (client Client, err error) {
 	if option.PipelineMultiplex > MaxPipelineMultiplex {
 		return nil, ErrWrongPipelineMultiplex
 	}
+	if option.MaxConns > 0 && option.MaxConns <= pipelineConns(option.PipelineMultiplex) {
+		return nil, ErrWrongMaxConns
+	}
+	for _, addr := range option.InitAddress {
+		if strings.HasPrefix(addr, unixPrefix) {
+			if len(option.InitAddress) != 1 || option.TLSConfig != nil || option.Sentinel.MasterSet != "" {
+				return nil, ErrUnixSocketNotSupported
+			}
+			option.ForceSingleClient = true
+		}
+	}
 	if option.Sentinel.MasterSet != "" {
-		option.PipelineMultiplex = singleClientMultiplex(option.PipelineMultiplex)
+		option.PipelineMultiplex = singleClientMultiplex(option.PipelineMultiplex, option.MaxConns)
 		return newSentinelClient(&option, makeConn)
 	}
 	if option.ForceSingleClient {
-		option.PipelineMultiplex = singleClientMultiplex(option.PipelineMultiplex)
+		option.PipelineMultiplex = singleClientMultiplex(option.PipelineMultiplex, option.MaxConns)
 		return newSingleClient(&option, nil, makeConn)
 	}
 	if client, err = newClusterClient(&option, makeConn); err != nil {
@@ -353,7 +464,7 @@ func NewClient(option ClientOption) (client Client, err error) {
 			return nil, err
 		}
 		if len(option.InitAddress) == 1 && (err.Error() == redisErrMsgCommandNotAllow || strings.Contains(strings.ToUpper(err.Error()), "CLUSTER")) {
-			option.PipelineMultiplex = singleClientMultiplex(option.PipelineMultiplex)
+			option.PipelineMultiplex = singleClientMultiplex(option.PipelineMultiplex, option.MaxConns)
 			client, err = newSingleClient(&option, client.(*clusterClient).single(), makeConn)
 		} else {
 			client.Close()
@@ -363,11 +474,14 @@ func NewClient(option ClientOption) (client Client, err error) {
 	return client, err
 }
 
-func singleClientMultiplex(multiplex int) int {
+func singleClientMultiplex(multiplex, maxConns int) int {
 	if multiplex == 0 {
 		if multiplex = int(math.Log2(float64(runtime.GOMAXPROCS(0)))); multiplex >= 2 {
 			multiplex = 2
 		}
+		for maxConns > 0 && multiplex > 0 && maxConns <= pipelineConns(multiplex) {
+			multiplex-- // leave at least one connection for blocking commands under the MaxConns
+		}
 	}
 	if multiplex < 0 {
 		multiplex = 0
@@ -375,21 +489,55 @@ func singleClientMultiplex(multiplex int) int {
 	return multiplex
 }
 
+// pipelineConns returns the number of the pipeline connections to each redis instance of the multiplex.
+func pipelineConns(multiplex int) int {
+	if multiplex < 0 {
+		return 1
+	}
+	return 1 << multiplex
+}
+
 func makeConn(dst string, opt *ClientOption) conn {
 	return makeMux(dst, opt, dial)
 }
 
 func dial(dst string, opt *ClientOption) (conn net.Conn, err error) {
+	cfg := opt.TLSConfig
+	if cfg != nil && opt.TLSServerNameFn != nil {
+		cfg = cfg.Clone()
+		cfg.ServerName = opt.TLSServerNameFn(dst)
+	}
+	if opt.DialCtxFn != nil {
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if opt.Dialer.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, opt.Dialer.Timeout)
+		}
+		defer cancel()
+		return opt.DialCtxFn(ctx, dst, cfg)
+	}
 	if opt.DialFn != nil {
-		return opt.DialFn(dst, &opt.Dialer, opt.TLSConfig)
+		return opt.DialFn(dst, &opt.Dialer, cfg)
 	}
-	if opt.TLSConfig != nil {
-		conn, err = tls.DialWithDialer(&opt.Dialer, "tcp", dst, opt.TLSConfig)
+	if strings.HasPrefix(dst, unixPrefix) {
+		return opt.Dialer.Dial("unix", dst[len(unixPrefix):])
+	}
+	if cfg != nil {
+		conn, err = tls.DialWithDialer(&opt.Dialer, "tcp", dst, cfg)
 	} else {
 		conn, err = opt.Dialer.Dial("tcp", dst)
 	}
 	return conn, err
 }
 
+// TLSServerNameFromAddr returns the host of the addr. It can be used as the ClientOption.TLSServerNameFn.
+func TLSServerNameFromAddr(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
 const redisErrMsgCommandNotAllow = "command is not allowed"
+
+const unixPrefix = "unix://"
 const dedicatedClientUsedAfterReleased = "DedicatedClient should not be used after recycled"
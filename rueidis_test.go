@@ -14,6 +14,7 @@ import (
 	"math/big"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -231,6 +232,52 @@ func TestForceSingleClient(t *testing.T) {
 	<-done
 }
 
+func TestUnixSocketClient(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	path := filepath.Join(t.TempDir(), "redis.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := make(chan struct{})
+	go func() {
+		mock, err := accept(t, ln)
+		if err != nil {
+			return
+		}
+		mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+			ReplyError("UNKNOWN COMMAND")
+		mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+			ReplyError("UNKNOWN COMMAND")
+		mock.Expect("PING").ReplyString("OK")
+		mock.Close()
+		close(done)
+	}()
+	client, err := NewClient(ClientOption{InitAddress: []string{"unix://" + path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.(*singleClient); !ok {
+		t.Fatal("client should be a singleClient")
+	}
+	client.Close()
+	<-done
+}
+
+func TestUnixSocketClientNotSupported(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	for _, option := range []ClientOption{
+		{InitAddress: []string{"unix:///redis.sock", "127.0.0.1:6379"}},
+		{InitAddress: []string{"unix:///redis.sock"}, TLSConfig: &tls.Config{}},
+		{InitAddress: []string{"unix:///redis.sock"}, Sentinel: SentinelOption{MasterSet: "test"}},
+	} {
+		if _, err := NewClient(option); err != ErrUnixSocketNotSupported {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+}
+
 func TestTLSClient(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	pub, priv, err := ed25519.GenerateKey(rand.Reader)
@@ -326,14 +373,40 @@ func TestNewClientMaxMultiplex(t *testing.T) {
 	}
 }
 
+func TestNewClientMaxConns(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	for _, option := range []ClientOption{
+		{InitAddress: []string{"127.0.0.1:6379"}, MaxConns: 1},
+		{InitAddress: []string{"127.0.0.1:6379"}, MaxConns: 1, PipelineMultiplex: -1},
+		{InitAddress: []string{"127.0.0.1:6379"}, MaxConns: 4, PipelineMultiplex: 2},
+	} {
+		if _, err := NewClient(option); err != ErrWrongMaxConns {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+}
+
+func TestSingleClientMultiplexMaxConns(t *testing.T) {
+	for _, c := range []struct{ multiplex, maxConns, max int }{
+		{multiplex: 0, maxConns: 2, max: 0},
+		{multiplex: 0, maxConns: 3, max: 1},
+		{multiplex: 0, maxConns: 5, max: 2},
+		{multiplex: 2, maxConns: 2, max: 2}, // an explicit multiplex is kept and rejected by the NewClient
+	} {
+		if v := singleClientMultiplex(c.multiplex, c.maxConns); v > c.max || (c.multiplex != 0 && v != c.multiplex) {
+			t.Fatalf("unexpected value %v for %v", v, c)
+		}
+	}
+}
+
 func TestSingleClientMultiplex(t *testing.T) {
 	defer ShouldNotLeaked(SetupLeakDetection())
 	option := ClientOption{}
-	if v := singleClientMultiplex(option.PipelineMultiplex); v != 2 {
+	if v := singleClientMultiplex(option.PipelineMultiplex, option.MaxConns); v != 2 {
 		t.Fatalf("unexpected value %v", v)
 	}
 	option.PipelineMultiplex = -1
-	if v := singleClientMultiplex(option.PipelineMultiplex); v != 0 {
+	if v := singleClientMultiplex(option.PipelineMultiplex, option.MaxConns); v != 0 {
 		t.Fatalf("unexpected value %v", v)
 	}
 }
@@ -359,6 +432,88 @@ func TestCustomDialFnIsCalled(t *testing.T) {
 	}
 }
 
+func TestCustomDialCtxFnIsCalled(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	cfg := &tls.Config{ServerName: "seed.example.com"}
+	var addrs []string
+	option := ClientOption{
+		InitAddress: []string{"127.0.0.1:0"},
+		TLSConfig:   cfg,
+		DialFn: func(s string, dialer *net.Dialer, config *tls.Config) (conn net.Conn, err error) {
+			t.Fatalf("the DialFn should not be called if the DialCtxFn is set")
+			return nil, nil
+		},
+		DialCtxFn: func(ctx context.Context, addr string, config *tls.Config) (conn net.Conn, err error) {
+			addrs = append(addrs, addr)
+			if config != cfg {
+				t.Fatalf("the TLSConfig should be passed as is")
+			}
+			if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > DefaultDialTimeout {
+				t.Fatalf("unexpected deadline %v %v", deadline, ok)
+			}
+			return nil, errors.New("dial error")
+		},
+	}
+	if _, err := NewClient(option); err == nil || err.Error() != "dial error" {
+		t.Fatalf("expected dial error %v", err)
+	}
+	if len(addrs) == 0 || addrs[0] != "127.0.0.1:0" {
+		t.Fatalf("excepted ClientOption.DialCtxFn to be called %v", addrs)
+	}
+
+	option.Dialer.Timeout = -1
+	option.DialCtxFn = func(ctx context.Context, addr string, config *tls.Config) (conn net.Conn, err error) {
+		if _, ok := ctx.Deadline(); ok {
+			t.Fatalf("unexpected deadline")
+		}
+		return nil, errors.New("dial error")
+	}
+	if _, err := dial("127.0.0.1:0", &option); err == nil {
+		t.Fatalf("expected dial error")
+	}
+}
+
+func TestTLSServerNameFn(t *testing.T) {
+	cfg := &tls.Config{ServerName: "seed.example.com"}
+	names := map[string]string{}
+	option := &ClientOption{
+		TLSConfig:       cfg,
+		TLSServerNameFn: TLSServerNameFromAddr,
+		DialFn: func(dst string, dialer *net.Dialer, config *tls.Config) (conn net.Conn, err error) {
+			names[dst] = config.ServerName
+			if config == cfg {
+				t.Fatalf("the TLSConfig should be cloned")
+			}
+			return nil, errors.New("dial error")
+		},
+	}
+	for _, dst := range []string{"seed.example.com:6379", "node1.example.com:6380", "[::1]:6381"} {
+		if _, err := dial(dst, option); err == nil {
+			t.Fatalf("expected dial error")
+		}
+	}
+	if names["seed.example.com:6379"] != "seed.example.com" || names["node1.example.com:6380"] != "node1.example.com" || names["[::1]:6381"] != "::1" {
+		t.Fatalf("unexpected server names %v", names)
+	}
+	if cfg.ServerName != "seed.example.com" {
+		t.Fatalf("the TLSConfig should not be modified")
+	}
+
+	option.TLSServerNameFn = nil
+	option.DialFn = func(dst string, dialer *net.Dialer, config *tls.Config) (conn net.Conn, err error) {
+		if config != cfg {
+			t.Fatalf("the TLSConfig should be passed as is")
+		}
+		return nil, errors.New("dial error")
+	}
+	if _, err := dial("node1.example.com:6380", option); err == nil {
+		t.Fatalf("expected dial error")
+	}
+	if name := TLSServerNameFromAddr("noport"); name != "noport" {
+		t.Fatalf("unexpected server name %v", name)
+	}
+}
+
 func ExampleIsRedisNil() {
 	client, err := NewClient(ClientOption{InitAddress: []string{"127.0.0.1:6379"}})
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"math/rand"
 	"strconv"
 	"strings"
@@ -19,6 +20,7 @@ type ClientOption struct {
 	ClientBuilder func(option rueidis.ClientOption) (rueidis.Client, error)
 	ClientOption  rueidis.ClientOption
 	ClientTTL     time.Duration // TTL for the client marker, refreshed every 1/2 TTL. Defaults to 10s. The marker allows other client to know if this client is still alive.
+	NegativeTTL   time.Duration // TTL for caching the absence of a key when the fn returns ErrNotFound. Defaults to 0, which means the absence is not cached.
 }
 
 type CacheAsideClient interface {
@@ -33,8 +35,10 @@ func NewClient(option ClientOption) (cc CacheAsideClient, err error) {
 		option.ClientTTL = 10 * time.Second
 	}
 	ca := &Client{
-		waits: make(map[string]chan struct{}),
-		ttl:   option.ClientTTL,
+		waits:   make(map[string]chan struct{}),
+		flights: make(map[string]*flight),
+		ttl:     option.ClientTTL,
+		negttl:  option.NegativeTTL,
 	}
 	option.ClientOption.OnInvalidations = ca.onInvalidation
 	if option.ClientBuilder != nil {
@@ -50,13 +54,39 @@ func NewClient(option ClientOption) (cc CacheAsideClient, err error) {
 }
 
 type Client struct {
-	client rueidis.Client
-	ctx    context.Context
-	waits  map[string]chan struct{}
-	cancel context.CancelFunc
-	id     string
-	ttl    time.Duration
-	mu     sync.Mutex
+	client  rueidis.Client
+	ctx     context.Context
+	waits   map[string]chan struct{}
+	flights map[string]*flight
+	cancel  context.CancelFunc
+	id      string
+	ttl     time.Duration
+	negttl  time.Duration
+	mu      sync.Mutex
+}
+
+// flight is the in-process populating of a cache missed key, shared by concurrent Get calls of the same key.
+type flight struct {
+	done chan struct{}
+	val  string
+	err  error
+}
+
+// detached keeps the values of the ctx but neither its deadline nor its cancellation, like the context.WithoutCancel of go1.21.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
 }
 
 func (c *Client) onInvalidation(messages []rueidis.RedisMessage) {
@@ -149,18 +179,7 @@ retry:
 	resp := c.client.DoCache(ctx, c.client.B().Get().Key(key).Cache(), ttl)
 	val, err := resp.ToString()
 	if rueidis.IsRedisNil(err) && fn != nil { // cache miss, prepare to populate the value by fn()
-		var id string
-		if id, err = c.keepalive(); err == nil { // acquire client id
-			val, err = c.client.Do(ctx, c.client.B().Set().Key(key).Value(id).Nx().Get().Px(ttl).Build()).ToString()
-			if rueidis.IsRedisNil(err) { // successfully set client id on the key as a lock
-				if val, err = fn(ctx, key); err == nil {
-					err = setkey.Exec(ctx, c.client, []string{key}, []string{id, val, strconv.FormatInt(ttl.Milliseconds(), 10)}).Error()
-				}
-				if err != nil { // failed to populate the value, release the lock.
-					delkey.Exec(context.Background(), c.client, []string{key}, []string{id})
-				}
-			}
-		}
+		val, err = c.populate(ctx, ttl, key, fn)
 	}
 	if err != nil {
 		return val, err
@@ -184,6 +203,56 @@ retry:
 			goto retry
 		}
 	}
+	if err == nil && val == tombstone {
+		return "", ErrNotFound
+	}
+	return val, err
+}
+
+// populate coalesces concurrent populating of the same key in this process, so that the fn is invoked only once,
+// and all the callers share its result. The fn runs in its own goroutine, bounded by the ttl of the lock instead of the ctx
+// of the caller who starts it, so that the cancellation of any caller does not fail the others. Each caller stops waiting once its ctx is done.
+func (c *Client) populate(ctx context.Context, ttl time.Duration, key string, fn func(ctx context.Context, key string) (val string, err error)) (val string, err error) {
+	c.mu.Lock()
+	f := c.flights[key]
+	if f == nil {
+		f = &flight{done: make(chan struct{})}
+		c.flights[key] = f
+		go func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(detached{Context: ctx}, ttl)
+			f.val, f.err = c.fill(ctx, ttl, key, fn)
+			cancel()
+			c.mu.Lock()
+			delete(c.flights, key)
+			c.mu.Unlock()
+			close(f.done)
+		}(ctx)
+	}
+	c.mu.Unlock()
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (c *Client) fill(ctx context.Context, ttl time.Duration, key string, fn func(ctx context.Context, key string) (val string, err error)) (val string, err error) {
+	var id string
+	if id, err = c.keepalive(); err == nil { // acquire client id
+		val, err = c.client.Do(ctx, c.client.B().Set().Key(key).Value(id).Nx().Get().Px(ttl).Build()).ToString()
+		if rueidis.IsRedisNil(err) { // successfully set client id on the key as a lock
+			if val, err = fn(ctx, key); err == nil {
+				err = setkey.Exec(ctx, c.client, []string{key}, []string{id, val, strconv.FormatInt(ttl.Milliseconds(), 10)}).Error()
+			} else if err == ErrNotFound && c.negttl > 0 { // cache the absence of the key with a tombstone
+				val = tombstone
+				err = setkey.Exec(ctx, c.client, []string{key}, []string{id, val, strconv.FormatInt(c.negttl.Milliseconds(), 10)}).Error()
+			}
+			if err != nil { // failed to populate the value, release the lock.
+				delkey.Exec(context.Background(), c.client, []string{key}, []string{id})
+			}
+		}
+	}
 	return val, err
 }
 
@@ -209,6 +278,14 @@ func (c *Client) Close() {
 
 const PlaceholderPrefix = "rueidisid:"
 
+// tombstone is the cached value indicating the absence of a key. See ClientOption.NegativeTTL.
+const tombstone = "rueidisaside:nil"
+
+// ErrNotFound can be returned from the fn of Get to indicate that the key does not exist in the data source.
+// If the ClientOption.NegativeTTL is set, the absence will be cached for the NegativeTTL, and the Get will
+// return ErrNotFound until it expires. It allows callers to tell an absent key from an empty value.
+var ErrNotFound = errors.New("key not found")
+
 var (
 	delkey = rueidis.NewLuaScript(`if redis.call("GET",KEYS[1]) == ARGV[1] then return redis.call("DEL",KEYS[1]) else return 0 end`)
 	setkey = rueidis.NewLuaScript(`if redis.call("GET",KEYS[1]) == ARGV[1] then return redis.call("SET",KEYS[1],ARGV[2],"PX",ARGV[3]) else return 0 end`)
@@ -2,6 +2,7 @@ package rueidisaside
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"strconv"
 	"sync"
@@ -72,6 +73,57 @@ func TestCacheFilled(t *testing.T) {
 	}
 }
 
+func TestNegativeCache(t *testing.T) {
+	client, err := NewClient(ClientOption{
+		ClientOption: rueidis.ClientOption{InitAddress: addr},
+		NegativeTTL:  time.Millisecond * 500,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	key := strconv.Itoa(rand.Int())
+	sum := int64(0)
+	fn := func(ctx context.Context, key string) (val string, err error) {
+		if atomic.AddInt64(&sum, 1) == 1 {
+			return "", ErrNotFound
+		}
+		return "", nil
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(context.Background(), time.Second, key, fn); err != ErrNotFound {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.Get(context.Background(), time.Second, key, nil); err != ErrNotFound {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&sum) != 1 {
+		t.Fatalf("unexpected sum %v", sum)
+	}
+	time.Sleep(time.Millisecond * 600)
+	if val, err := client.Get(context.Background(), time.Second, key, fn); err != nil || val != "" {
+		t.Fatalf("unexpected result %v %v", val, err)
+	}
+}
+
+func TestNegativeCacheDisabled(t *testing.T) {
+	client := makeClient(t, addr)
+	defer client.Close()
+	key := strconv.Itoa(rand.Int())
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(context.Background(), time.Second, key, func(ctx context.Context, key string) (val string, err error) {
+			return "", ErrNotFound
+		})
+		if err != ErrNotFound {
+			t.Fatal(err)
+		}
+		if _, err = client.Get(context.Background(), time.Second, key, nil); !rueidis.IsRedisNil(err) {
+			t.Fatal(err)
+		}
+	}
+}
+
 func TestCacheDel(t *testing.T) {
 	client := makeClient(t, addr)
 	defer client.Close()
@@ -238,6 +290,101 @@ func TestDisconnect(t *testing.T) {
 	time.Sleep(client.ttl) // wait old refresh goroutine exit
 }
 
+func TestSingleFlight(t *testing.T) {
+	client := makeClient(t, addr)
+	defer client.Close()
+	key := strconv.Itoa(rand.Int())
+	sum := int64(0)
+	var wg sync.WaitGroup
+	wg.Add(100)
+	for i := 0; i < 100; i++ {
+		go func() {
+			defer wg.Done()
+			v, err := client.Get(context.Background(), time.Second, key, func(ctx context.Context, key string) (val string, err error) {
+				atomic.AddInt64(&sum, 1)
+				time.Sleep(time.Millisecond * 100)
+				return "1", nil
+			})
+			if err != nil || v != "1" {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if atomic.LoadInt64(&sum) != 1 {
+		t.Fatalf("unexpected sum %v", sum)
+	}
+}
+
+func TestSingleFlightErr(t *testing.T) {
+	client := makeClient(t, addr)
+	defer client.Close()
+	key := strconv.Itoa(rand.Int())
+	sum := int64(0)
+	e := errors.New("fn err")
+	for j := 0; j < 2; j++ {
+		var wg sync.WaitGroup
+		wg.Add(100)
+		for i := 0; i < 100; i++ {
+			go func() {
+				defer wg.Done()
+				_, err := client.Get(context.Background(), time.Second, key, func(ctx context.Context, key string) (val string, err error) {
+					atomic.AddInt64(&sum, 1)
+					time.Sleep(time.Millisecond * 100)
+					return "", e
+				})
+				if err != e {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		if atomic.LoadInt64(&sum) != int64(j+1) { // the err should not be cached
+			t.Fatalf("unexpected sum %v", sum)
+		}
+	}
+}
+
+func TestSingleFlightLeaderCancel(t *testing.T) {
+	client := makeClient(t, addr)
+	defer client.Close()
+	key := strconv.Itoa(rand.Int())
+	ctx, cancel := context.WithCancel(context.Background())
+	leading := make(chan struct{})
+	proceed := make(chan struct{})
+	done := make(chan struct{})
+	calls := int64(0)
+	go func() {
+		_, err := client.Get(ctx, time.Second, key, func(ctx context.Context, key string) (val string, err error) {
+			atomic.AddInt64(&calls, 1)
+			close(leading)
+			<-proceed
+			return "1", ctx.Err() // the fn is not canceled by the leader
+		})
+		if err != context.Canceled {
+			t.Error(err)
+		}
+		close(done)
+	}()
+	<-leading
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		cancel()
+		<-done
+		close(proceed)
+	}()
+	v, err := client.Get(context.Background(), time.Second, key, func(ctx context.Context, key string) (val string, err error) {
+		atomic.AddInt64(&calls, 1)
+		return "2", nil
+	})
+	if err != nil || v != "1" {
+		t.Fatal(v, err)
+	}
+	if n := atomic.LoadInt64(&calls); n != 1 {
+		t.Fatalf("unexpected calls %v", n)
+	}
+}
+
 func TestMultipleClient(t *testing.T) {
 	clients := make([]CacheAsideClient, 10)
 	for i := 0; i < len(clients); i++ {
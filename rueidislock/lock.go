@@ -1,8 +1,10 @@
 package rueidislock
 
 import (
+	"container/list"
 	"context"
 	"errors"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,14 +19,46 @@ import (
 type LockerOption struct {
 	// ClientBuilder can be used to modify rueidis.Client used by Locker
 	ClientBuilder func(option rueidis.ClientOption) (rueidis.Client, error)
-	// KeyPrefix is the prefix of redis key for locks. Default value is "rueidislock".
+	// Client, if set, is used by the Locker instead of building a new one, and it will not be closed by the Locker.Close.
+	// The ClientOption, ClientBuilder and Addresses are then ignored. The Locker keeps using the client side caching of the client,
+	// whose invalidations must be delivered to the Locker by the Invalidations. Otherwise, NewLocker returns ErrClientCacheDisabled,
+	// unless the FallbackSETPX is set, with which the Locker works as if the ClientOption.DisableCache is set.
+	Client rueidis.Client
+	// Invalidations delivers the invalidations of the Client to the Locker. It is ignored if the Client is not set.
+	Invalidations *Invalidations
+	// KeyPrefix is the namespace of redis keys for locks, which separates them from the application data and locks of other Lockers.
+	// Lockers with different prefixes never interfere with each other even if they use the same lock names. Default value is "rueidislock".
 	KeyPrefix string
+	// KeyFn can be used to customize the redis key layout of the index-th key of the lock by name.
+	// It must return distinct keys for different names and indexes, and the keys must contain the prefix,
+	// otherwise NewLocker returns ErrKeyWithoutPrefix. Default keys are "{KeyPrefix}:{index}:{name}".
+	KeyFn func(prefix, name string, index int32) string
+	// ClusterHashTag wraps the name of the default keys in a hash tag, that is "{KeyPrefix}:{index}:{{name}}",
+	// so that all the keys of a lock are in the same slot of a redis cluster and can be operated together.
+	// Note that the keys of a lock will then be on the same node, and the lock will not survive the failure of that node.
+	// It is ignored if the KeyFn is set.
+	ClusterHashTag bool
+	// ValueFn can be used to customize the value stored in the redis keys to prove the ownership of each lock acquisition,
+	// for example, to embed a request id for log correlation. The values must be unique across acquisitions,
+	// otherwise a stale owner may release or extend the lock of another one. Default values are secure random strings.
+	ValueFn func() string
 	// ClientOption is passed to rueidis.NewClient or LockerOption.ClientBuilder to build a rueidis.Client
 	ClientOption rueidis.ClientOption
+	// Addresses, if set, spreads the keys of locks across independent redis deployments, one for each element, instead of
+	// putting them in the one at the ClientOption.InitAddress. A client is built for each deployment by the ClientOption with its
+	// InitAddress replaced, and the index-th key of a lock is always on the index-th deployment. A lock is then valid only if
+	// it is acquired on the majority of the deployments, so that it survives the failure of the minority of them.
+	// The number of deployments must be odd, and the KeyMajority is ignored. It is ignored if the Client is set.
+	Addresses [][]string
 	// KeyValidity is the validity duration of locks and will be extended periodically by the ExtendInterval. Default value is 5s.
 	KeyValidity time.Duration
 	// ExtendInterval is the interval to extend KeyValidity. Default value is 1s.
 	ExtendInterval time.Duration
+	// ExtendJitter, if positive, shifts the periodical extensions of each lock earlier by a random duration up to it,
+	// so that the extensions of locks acquired at the same time, for example, on startup, are spread over time instead of
+	// hitting redis in lockstep. Locks are never extended later than the ExtendInterval. It is capped at half of the ExtendInterval.
+	// Default value is 0, which disables the jitter.
+	ExtendJitter time.Duration
 	// TryNextAfter is the timeout duration before trying the next redis key for locks. Default value is 20ms.
 	TryNextAfter time.Duration
 	// KeyMajority is at least how many redis keys in a total of KeyMajority*2-1 should be acquired to be a valid lock.
@@ -35,20 +69,330 @@ type LockerOption struct {
 	NoLoopTracking bool
 	// Use SET PX instead of SET PXAT when acquiring locks to be compatible with Redis < 6.2
 	FallbackSETPX bool
+	// NoAutoExtend disables extending locks periodically. Locks are only valid for the KeyValidity, and the returned ctx
+	// will be canceled when the KeyValidity is reached or the keys are deleted. This reduces the load of redis for short critical sections.
+	NoAutoExtend bool
+	// Tracer, if set, is used to trace each lock acquired by WithContext and TryWithContext.
+	// Use rueidisotel.NewLockTracer to trace locks with OpenTelemetry.
+	Tracer Tracer
+	// OnLockLost, if set, is called with the name of a lock when the lock is lost before its cancel function is called,
+	// for example, its keys are taken over by others or fail to be extended. It should not block.
+	OnLockLost func(name string)
+	// OnExtendPartial, if set, is called with the name of a held lock and the number of its keys still held, whenever the lock is
+	// extended automatically but some of its keys are not, while the majority of them are. It is an early warning that redis is
+	// struggling before the lock is actually lost. It should not block.
+	OnExtendPartial func(name string, acked int32)
+	// NoCancelOnLoss keeps the context of a lock alive when the lock is lost, instead of canceling it by default.
+	// Losing a lock is then only notified by the OnLockLost and the LockLost event, and the caller must decide whether to abort
+	// and must call the cancel function of the lock eventually. Be careful that once a lock is lost, others may acquire it
+	// and run the critical section concurrently, so this should only be used when the remaining work is safe to be done twice.
+	NoCancelOnLoss bool
+	// EventBufferSize is the capacity of the channel returned by Locker.Events. Default value is 64.
+	// Events are dropped instead of blocking the locks when the channel is full.
+	EventBufferSize int
+	// FairQueue makes waiters of WithContext and TryWithDeadline on the same lock name of this Locker try the lock in their arrival order.
+	// By default, a released lock is tried by an arbitrary waiter, and a late arrival may win over earlier ones.
+	// A fair queue prevents waiters from starving but lowers the throughput under contention, because a lock is always
+	// handed over to the earliest waiter, even if it is not ready to run yet, instead of the one that arrives right after the release.
+	// It has no effect on TryWithContext and ForceWithContext, and it doesn't order waiters of different Lockers.
+	FairQueue bool
+	// KeepGates, if positive, is the number of the most recently used lock names whose in-process gates are kept for reuse
+	// after they are not held or waited anymore. This reduces allocations of a small set of hot locks. Gates of other names
+	// are still deleted once they are not used, and all the kept gates are dropped by Close.
+	KeepGates int
+	// ReconcileInterval, if positive, is the interval to read the PTTL of the keys of each lock acquired by the Locker.Acquire
+	// and reconcile its Lease.Validity with the TTLs on redis, in case they are changed by others, for example, by a PEXPIRE.
+	// The validity is then the majority-th longest PTTL of the keys still owned by the lock.
+	// It costs a round trip of the KeyMajority*2-1 keys per lock per interval. Note that with the client side caching,
+	// the TTLs changed by others are reverted by the Locker on their invalidations, so this is mainly useful when the cache is disabled.
+	ReconcileInterval time.Duration
+	// MinRetryInterval is the minimum delay before the WithContext, Acquire and TryWithDeadline retry a lock held by others,
+	// plus a random jitter up to the same duration, to not re-contend the lock aggressively. The retry happens earlier if the keys
+	// of the lock may be changed, for example, they are released by the same Locker or invalidated by the client side caching.
+	// Default value is 5ms. A negative value disables the delay and retries immediately.
+	MinRetryInterval time.Duration
+	// Reentrant makes the WithContext, TryWithContext and TryWithDeadline re-acquire a lock held by this Locker immediately
+	// if their ctx is derived from the context of that lock, which is the token of its owner, like a recursive mutex.
+	// Each re-acquisition must be released by its own cancel function, and the keys of the lock are only released once
+	// all of them and the original acquisition are released. The Acquire, Campaign and ForceWithContext are never reentrant.
+	// Canceling the original acquisition early doesn't release the lock before the re-acquisitions are released,
+	// but the lock is still lost if its keys fail to be extended or are taken over by others.
+	Reentrant bool
+	// BatchStrategy decides the order in which the WithContexts acquires the locks of multiple names and the backoff between its attempts.
+	// Services locking overlapping sets of names should share the same order to not keep failing each other.
+	// Default value is a SortedBatch with the MinBackoff of the MinRetryInterval, which acquires the locks in the lexicographical order.
+	BatchStrategy BatchStrategy
+}
+
+// LockEventType is the type of LockEvent
+type LockEventType int
+
+const (
+	// LockAcquired is emitted when a lock is acquired by WithContext, TryWithContext, TryWithDeadline, or ForceWithContext.
+	LockAcquired LockEventType = iota + 1
+	// LockExtended is emitted when the keys of a held lock are extended by the ExtendInterval.
+	LockExtended
+	// LockLost is emitted when a held lock is lost before its cancel function is called,
+	// for example, its keys are taken over by others or fail to be extended.
+	LockLost
+	// LockReleased is emitted when a held lock is released by its cancel function.
+	LockReleased
+)
+
+func (t LockEventType) String() string {
+	switch t {
+	case LockAcquired:
+		return "acquired"
+	case LockExtended:
+		return "extended"
+	case LockLost:
+		return "lost"
+	case LockReleased:
+		return "released"
+	}
+	return "unknown"
+}
+
+// LockEvent is a lifecycle event of a lock delivered by Locker.Events
+type LockEvent struct {
+	Time time.Time
+	Name string
+	Type LockEventType
+}
+
+// LockMode describes how a Locker acquires and watches locks. It is a combination of the TrackingLoop or TrackingNoLoop
+// with the SetPX. A LockMode without tracking means the client side caching is disabled, and the locks are checked periodically.
+type LockMode uint8
+
+const (
+	// TrackingLoop means the locks are watched by client side caching with CLIENT TRACKING ON OPTOUT.
+	TrackingLoop LockMode = 1 << iota
+	// TrackingNoLoop means the locks are watched by client side caching with CLIENT TRACKING ON OPTOUT NOLOOP.
+	TrackingNoLoop
+	// SetPX means the keys are set by SET NX PX instead of SET NX PXAT because of the LockerOption.FallbackSETPX.
+	SetPX
+)
+
+// Tracking reports whether the locks are watched by client side caching.
+func (m LockMode) Tracking() bool {
+	return m&(TrackingLoop|TrackingNoLoop) != 0
+}
+
+func (m LockMode) String() string {
+	switch m {
+	case TrackingLoop:
+		return "tracking"
+	case TrackingNoLoop:
+		return "tracking_noloop"
+	case TrackingLoop | SetPX:
+		return "tracking+setpx"
+	case TrackingNoLoop | SetPX:
+		return "tracking_noloop+setpx"
+	case SetPX:
+		return "setpx"
+	case 0:
+		return "polling"
+	}
+	return "unknown"
+}
+
+// Tracer traces lock acquisitions of a Locker. See rueidisotel.NewLockTracer for an OpenTelemetry implementation.
+type Tracer interface {
+	// Start is called when WithContext or TryWithContext starts to acquire the lock by name.
+	// The returned context will be the parent of the lock context, and the returned LockSpan will be
+	// ended once the lock is released or fails to be acquired.
+	Start(ctx context.Context, name string) (context.Context, LockSpan)
+}
+
+// LockSpan records the lifecycle of a lock started by the Tracer.
+type LockSpan interface {
+	// Acquired is called when the lock is acquired after waiting for the wait duration.
+	// The contended is true if the lock was held by others and had to be waited.
+	Acquired(wait time.Duration, contended bool)
+	// End is called once when the lock is released, or with the err if it fails to be acquired.
+	// The lost is true if the lock had been lost before its cancel function was called.
+	End(lost bool, err error)
+}
+
+// BatchStrategy decides how the Locker.WithContexts acquires the locks of multiple names. See SortedBatch for the default one.
+type BatchStrategy interface {
+	// Order returns the names in the order to be acquired. The names are distinct and can be reordered in place.
+	Order(names []string) []string
+	// Backoff returns the delay before the attempt-th retry, counting from 0, after some of the locks are held by others.
+	Backoff(attempt int) time.Duration
+}
+
+// SortedBatch is a BatchStrategy acquiring locks in the order of their names by the Less, or the lexicographical order if it is nil,
+// and backing off exponentially from the MinBackoff up to the MaxBackoff, plus a random jitter up to the same delay.
+type SortedBatch struct {
+	// Less reports whether the lock of the name a should be acquired before the one of the name b.
+	Less func(a, b string) bool
+	// MinBackoff is the delay before the first retry. Retries happen immediately if it is not positive.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay of retries. Default value is 64 times of the MinBackoff.
+	MaxBackoff time.Duration
+}
+
+func (s SortedBatch) Order(names []string) []string {
+	if s.Less == nil {
+		sort.Strings(names)
+	} else {
+		sort.SliceStable(names, func(i, j int) bool { return s.Less(names[i], names[j]) })
+	}
+	return names
+}
+
+func (s SortedBatch) Backoff(attempt int) time.Duration {
+	if s.MinBackoff <= 0 {
+		return 0
+	}
+	d := s.MaxBackoff
+	if d <= 0 {
+		d = s.MinBackoff * 64
+	}
+	if attempt < 32 && s.MinBackoff<<attempt < d {
+		d = s.MinBackoff << attempt
+	}
+	return d + time.Duration(util.FastRand(int(d)))
+}
+
+// Invalidations delivers the client side caching invalidations of a rueidis.Client shared by the LockerOption.Client to the Lockers using it.
+// Its OnInvalidations must be set as the ClientOption.OnInvalidations of the client, and the client must track the keys read by the Lockers
+// with the ClientOption.ClientTrackingOptions of []string{"OPTOUT"}, or []string{"OPTOUT", "NOLOOP"} if the LockerOption.NoLoopTracking is set.
+// The ClientOption.PipelineMultiplex of -1 is also recommended, which is what the Locker uses for the client built by itself.
+type Invalidations struct {
+	lockers map[*locker]struct{}
+	mu      sync.RWMutex
+}
+
+// OnInvalidations delivers the invalidations to the Lockers using the Invalidations.
+func (i *Invalidations) OnInvalidations(messages []rueidis.RedisMessage) {
+	i.mu.RLock()
+	for m := range i.lockers {
+		m.onInvalidations(messages)
+	}
+	i.mu.RUnlock()
+}
+
+func (i *Invalidations) add(m *locker) {
+	i.mu.Lock()
+	if i.lockers == nil {
+		i.lockers = make(map[*locker]struct{})
+	}
+	i.lockers[m] = struct{}{}
+	i.mu.Unlock()
+}
+
+func (i *Invalidations) remove(m *locker) {
+	i.mu.Lock()
+	delete(i.lockers, m)
+	i.mu.Unlock()
 }
 
 // Locker is the interface of rueidislock
 type Locker interface {
-	// WithContext acquires a distributed redis lock by name by waiting for it. It may return ErrLockerClosed.
+	// WithContext acquires a distributed redis lock by name by waiting for it. It may return ErrLockerClosed,
+	// or ErrMajorityUnreachable if the majority of the keys fail to be acquired after the TryNextAfter because of errors like network failures.
 	WithContext(ctx context.Context, name string) (context.Context, context.CancelFunc, error)
-	// TryWithContext tries to acquire a distributed redis lock by name without waiting. It may return ErrNotLocked.
+	// WithContexts acquires the distributed redis locks of all the names by waiting for them, and returns one context, which is canceled
+	// once any of the locks is lost, and one cancel function, which releases all of them. The locks are acquired one by one in the order of
+	// the LockerOption.BatchStrategy, where the first one is waited for like the WithContext and the rest are tried like the TryWithContext.
+	// If any of the rest is held by others, the acquired ones are released and all of them are retried after the backoff of the BatchStrategy,
+	// so that it never holds some of the locks while waiting for others. Duplicated names are acquired once.
+	// It may return the same errors as the WithContext, or ErrNoNames if no names are given.
+	WithContexts(ctx context.Context, names ...string) (context.Context, context.CancelFunc, error)
+	// Acquire acquires a distributed redis lock by name by waiting for it, like the WithContext, but returns the lock as a Lease,
+	// which is easier to be passed through layers of code than the context and its cancel function.
+	Acquire(ctx context.Context, name string) (Lease, error)
+	// AcquireAsync acquires a distributed redis lock by name like the Acquire, but returns immediately with a channel,
+	// which delivers exactly one LockResult once the lock is acquired or fails to be acquired, and is then closed.
+	// The lock is released once the ctx is done, so the caller that will not read the channel anymore must cancel the ctx.
+	AcquireAsync(ctx context.Context, name string) <-chan LockResult
+	// Campaign blocks until the leadership by name is won, that is, the distributed redis lock by name is acquired like the WithContext,
+	// and returns the leader context, which is canceled once the leadership is lost, and the resign function, which must be called
+	// eventually to step down. The leadership is kept by extending the lock every ExtendInterval even if the NoAutoExtend is set,
+	// so it lasts until it is resigned or lost. Use the LockerOption.OnLockLost or Events to be notified of the loss.
+	// It may return the same errors as the WithContext.
+	Campaign(ctx context.Context, name string) (context.Context, context.CancelFunc, error)
+	// TryWithContext tries to acquire a distributed redis lock by name without waiting. It may return ErrNotLocked if the lock is held by others,
+	// or ErrMajorityUnreachable.
 	TryWithContext(ctx context.Context, name string) (context.Context, context.CancelFunc, error)
+	// TryWithDeadline acquires a distributed redis lock by name by waiting for it at most the wait duration.
+	// It returns ErrNotLocked if the lock is not acquired in time, or the ctx.Err() if the ctx is done first.
+	TryWithDeadline(ctx context.Context, name string, wait time.Duration) (context.Context, context.CancelFunc, error)
+	// WaitFor blocks until the distributed redis lock by name is not held by anyone, or the ctx is done, without acquiring it.
+	// It is notified by the client side caching invalidations, so callers can retry TryWithContext immediately after it returns
+	// instead of polling. If the client side caching is disabled, the lock is checked periodically by the TryNextAfter.
+	// It may return ErrLockerClosed.
+	WaitFor(ctx context.Context, name string) error
+	// IsLocked checks whether the distributed redis lock by name is held by anyone, including this Locker, by EXISTS of its keys
+	// without acquiring it. It returns true if the majority of the keys exist, and an error only if neither the majority of the keys
+	// exist nor are absent because of errors. Since the lock may be acquired or released right after the check, it must only be used
+	// as an advisory signal, such as for load shedding, and never for correctness.
+	IsLocked(ctx context.Context, name string) (bool, error)
+	// Pending returns how many goroutines of this Locker are blocked waiting for the lock by name to be handed over to them.
+	// The one holding or trying the lock, and the ones backing off before retrying it, are not counted. It returns 0 for unknown names.
+	Pending(name string) int
 	// ForceWithContext takes over a distributed redis lock by canceling the original holder. It may return ErrNotLocked.
 	ForceWithContext(ctx context.Context, name string) (context.Context, context.CancelFunc, error)
-	// Client exports the underlying rueidis.Client
+	// Events returns the channel of the lifecycle events of the locks of this Locker. The events are delivered
+	// without blocking the locks, so they are dropped if the channel is full. The channel is closed by Close.
+	Events() <-chan LockEvent
+	// Mode returns the LockMode derived from the LockerOption. It doesn't change after the Locker is created.
+	Mode() LockMode
+	// Client exports the underlying rueidis.Client, which is the one of the first deployment if the LockerOption.Addresses is set.
 	Client() rueidis.Client
 	// Close closes the underlying rueidis.Client
 	Close()
+	// CloseRelease releases all the locks held by this Locker by deleting their keys if they are still owned by this Locker,
+	// so that others can acquire them immediately instead of waiting for them to expire, and then closes the Locker like the Close.
+	// It returns the ctx.Err() if the locks are not all released before the ctx is done, and the rest of them expire by their validity.
+	CloseRelease(ctx context.Context) error
+}
+
+// Lease is a distributed redis lock acquired by the Locker.Acquire.
+type Lease struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	extend   func(ctx context.Context) error
+	validity func() time.Duration
+}
+
+// Context returns the context of the lock, which is canceled once the lock is lost or released, the same as the one returned by the Locker.WithContext.
+func (l Lease) Context() context.Context {
+	return l.ctx
+}
+
+// Cancel releases the lock. It must be called eventually, even if the lock is lost.
+func (l Lease) Cancel() {
+	if l.cancel != nil {
+		l.cancel()
+	}
+}
+
+// Validity returns how long the lock remains valid without being extended. It returns 0 if the lock is lost or released.
+func (l Lease) Validity() time.Duration {
+	if l.validity == nil {
+		return 0
+	}
+	return l.validity()
+}
+
+// Extend extends the lock to the KeyValidity from now immediately, in addition to the periodical extensions by the ExtendInterval.
+// It is useful for the NoAutoExtend to keep a lock valid for a longer critical section.
+// It returns ErrNotLocked if the lock is lost or released, or ErrMajorityUnreachable if the majority of the keys fail to be extended.
+func (l Lease) Extend(ctx context.Context) error {
+	if l.extend == nil {
+		return ErrNotLocked
+	}
+	return l.extend(ctx)
+}
+
+// LockResult is the result of the Locker.AcquireAsync.
+type LockResult struct {
+	// Lease is the acquired lock, which must be canceled eventually if the Err is nil.
+	Lease Lease
+	Err   error
 }
 
 // NewLocker creates the distributed Locker backed by redis client side caching
@@ -62,26 +406,88 @@ func NewLocker(option LockerOption) (Locker, error) {
 	if option.ExtendInterval <= 0 {
 		option.ExtendInterval = option.KeyValidity / 2
 	}
+	if option.ExtendJitter < 0 {
+		option.ExtendJitter = 0
+	} else if option.ExtendJitter > option.ExtendInterval/2 {
+		option.ExtendJitter = option.ExtendInterval / 2
+	}
 	if option.TryNextAfter <= 0 {
 		option.TryNextAfter = time.Millisecond * 20
 	}
 	if option.KeyMajority <= 0 {
 		option.KeyMajority = 2
 	}
+	if option.EventBufferSize <= 0 {
+		option.EventBufferSize = 64
+	}
+	if option.MinRetryInterval == 0 {
+		option.MinRetryInterval = time.Millisecond * 5
+	}
+	if option.BatchStrategy == nil {
+		option.BatchStrategy = SortedBatch{MinBackoff: option.MinRetryInterval}
+	}
+	if option.KeyFn != nil && !strings.Contains(option.KeyFn(option.KeyPrefix, "", 0), option.KeyPrefix) {
+		return nil, ErrKeyWithoutPrefix
+	}
+	if len(option.Addresses) != 0 && option.Client == nil {
+		if len(option.Addresses)%2 == 0 {
+			return nil, ErrEvenAddresses
+		}
+		option.KeyMajority = int32(len(option.Addresses))/2 + 1
+	}
 	impl := &locker{
 		prefix:   option.KeyPrefix,
+		keyfn:    option.KeyFn,
+		valfn:    option.ValueFn,
+		hashtag:  option.ClusterHashTag,
 		validity: option.KeyValidity,
 		interval: option.ExtendInterval,
+		jitter:   option.ExtendJitter,
 		timeout:  option.TryNextAfter,
 		majority: option.KeyMajority,
 		totalcnt: option.KeyMajority*2 - 1,
 		gates:    make(map[string]*gate),
+		waits:    make(map[string]chan struct{}),
+		held:     make(map[chan struct{}]context.CancelFunc),
 		noloop:   option.NoLoopTracking,
 		setpx:    option.FallbackSETPX,
+		noext:    option.NoAutoExtend,
+		tracer:   option.Tracer,
+		onlost:   option.OnLockLost,
+		onpart:   option.OnExtendPartial,
+		keepctx:  option.NoCancelOnLoss,
+		fair:     option.FairQueue,
+		kept:     newKeptGates(option.KeepGates),
+		ttlcheck: option.ReconcileInterval,
+		retry:    option.MinRetryInterval,
+		reenter:  option.Reentrant,
+		batch:    option.BatchStrategy,
+		events:   make(chan LockEvent, option.EventBufferSize),
+		clock:    realClock{},
+	}
+	if option.KeyFn != nil {
+		impl.keys = make(map[string]keyref)
+	}
+
+	if option.Client != nil {
+		if option.Invalidations == nil {
+			if !option.FallbackSETPX {
+				return nil, ErrClientCacheDisabled
+			}
+			impl.noloop = true
+			impl.nocsc = true
+		} else {
+			impl.inv = option.Invalidations
+			impl.inv.add(impl)
+		}
+		impl.client = option.Client
+		impl.shared = true
+		return impl, nil
 	}
 
 	if option.ClientOption.DisableCache {
 		impl.noloop = true
+		impl.nocsc = true
 	} else {
 		if option.NoLoopTracking {
 			option.ClientOption.ClientTrackingOptions = []string{"OPTOUT", "NOLOOP"}
@@ -92,36 +498,127 @@ func NewLocker(option LockerOption) (Locker, error) {
 	}
 	option.ClientOption.PipelineMultiplex = -1 // this ensures the CSC goes to the same connection.
 
-	var err error
-	if option.ClientBuilder != nil {
-		impl.client, err = option.ClientBuilder(option.ClientOption)
-	} else {
-		impl.client, err = rueidis.NewClient(option.ClientOption)
+	if len(option.Addresses) != 0 {
+		impl.clients = make([]rueidis.Client, 0, len(option.Addresses))
+		for _, addrs := range option.Addresses {
+			opt := option.ClientOption
+			opt.InitAddress = addrs
+			client, err := buildClient(option.ClientBuilder, opt)
+			if err != nil {
+				for _, c := range impl.clients {
+					c.Close()
+				}
+				return nil, err
+			}
+			impl.clients = append(impl.clients, client)
+		}
+		impl.client = impl.clients[0]
+		return impl, nil
 	}
-	if err != nil {
+
+	var err error
+	if impl.client, err = buildClient(option.ClientBuilder, option.ClientOption); err != nil {
 		return nil, err
 	}
 	return impl, nil
 }
 
+func buildClient(builder func(option rueidis.ClientOption) (rueidis.Client, error), option rueidis.ClientOption) (rueidis.Client, error) {
+	if builder != nil {
+		return builder(option)
+	}
+	return rueidis.NewClient(option)
+}
+
 type locker struct {
 	client   rueidis.Client
+	clients  []rueidis.Client
+	tracer   Tracer
+	batch    BatchStrategy
+	inv      *Invalidations
+	kept     *keptgates
+	onlost   func(name string)
+	onpart   func(name string, acked int32)
+	events   chan LockEvent
+	clock    clock
 	gates    map[string]*gate
+	keys     map[string]keyref // the keys built by the keyfn of the gates
+	waits    map[string]chan struct{}
+	held     map[chan struct{}]context.CancelFunc
+	keyfn    func(prefix, name string, index int32) string
+	valfn    func() string
 	prefix   string
 	validity time.Duration
 	interval time.Duration
+	jitter   time.Duration
 	timeout  time.Duration
+	ttlcheck time.Duration
+	retry    time.Duration
 	mu       sync.RWMutex
+	wmu      sync.Mutex
+	hmu      sync.Mutex
 	majority int32
 	totalcnt int32
 	noloop   bool
 	setpx    bool
+	noext    bool
+	nocsc    bool
+	shared   bool
+	hashtag  bool
+	keepctx  bool
+	fair     bool
+	reenter  bool
+}
+
+// clock abstracts the time used by the locker to extend and expire locks, so that it can be faked in tests.
+type clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) timer
+	AfterFunc(d time.Duration, f func()) timer
+}
+
+type timer interface {
+	Chan() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) timer {
+	return realTimer{Timer: time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) timer {
+	return realTimer{Timer: time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) Chan() <-chan time.Time {
+	return t.C
 }
 
 type gate struct {
-	ch  chan struct{}
-	csc []chan struct{}
-	w   int
+	ch   chan struct{}
+	csc  []chan struct{}
+	q    []*ticket
+	w    int
+	n    int  // the number of waiters blocked in the waitgate
+	held bool // whether the gate is held by a try or handed over to a waiter
+}
+
+// ticket is the place of a waiter in the fair queue of a gate. It stays in the queue until the waiter acquires the lock
+// or gives up, so that the waiter keeps its place while retrying.
+type ticket struct {
+	ch chan struct{}
+	g  *gate // the gate whose queue the ticket is in
 }
 
 func makegate(size int32) *gate {
@@ -132,10 +629,126 @@ func makegate(size int32) *gate {
 	return &gate{ch: make(chan struct{}, 1), csc: csc}
 }
 
+// keptgates is an LRU of gates not used anymore, which can be reused by the same lock names.
+type keptgates struct {
+	order *list.List // of *keptgate, the most recently kept at the front
+	names map[string]*list.Element
+	size  int
+}
+
+type keptgate struct {
+	g    *gate
+	name string
+}
+
+func newKeptGates(size int) *keptgates {
+	if size <= 0 {
+		return nil
+	}
+	return &keptgates{order: list.New(), names: make(map[string]*list.Element, size), size: size}
+}
+
+func (k *keptgates) put(name string, g *gate) {
+	if e, ok := k.names[name]; ok {
+		e.Value.(*keptgate).g = g
+		k.order.MoveToFront(e)
+		return
+	}
+	k.names[name] = k.order.PushFront(&keptgate{name: name, g: g})
+	if k.order.Len() > k.size {
+		e := k.order.Back()
+		k.order.Remove(e)
+		delete(k.names, e.Value.(*keptgate).name)
+	}
+}
+
+func (k *keptgates) take(name string) *gate {
+	e, ok := k.names[name]
+	if !ok {
+		return nil
+	}
+	k.order.Remove(e)
+	delete(k.names, name)
+	g := e.Value.(*keptgate).g
+	for i, t := range g.q { // tickets of retrying waiters are left in the queue, and they should enqueue again.
+		t.g = nil
+		g.q[i] = nil
+	}
+	g.q = g.q[:0]
+	g.held = false
+	select { // drop the signals sent after the gate was not used
+	case <-g.ch:
+	default:
+	}
+	for _, ch := range g.csc {
+		select {
+		case <-ch:
+		default:
+		}
+	}
+	return g
+}
+
+func (k *keptgates) clear() {
+	k.order.Init()
+	k.names = make(map[string]*list.Element, k.size)
+}
+
+// newgate returns a kept gate of the name if any, or makes a new one. It must be called with the m.mu locked.
+func (m *locker) newgate(name string) (g *gate) {
+	if m.kept != nil {
+		if g = m.kept.take(name); g != nil {
+			return g
+		}
+	}
+	return makegate(m.totalcnt)
+}
+
+// keyref is the name and the index of a lock key built by the KeyFn.
+type keyref struct {
+	name  string
+	index int32
+}
+
+// addgate puts the g of the name into the gates, and indexes its keys if the KeyFn is set,
+// so that the invalidations of the keys can find the gate without calling the KeyFn for every gate.
+// It must be called with the m.mu locked.
+func (m *locker) addgate(name string, g *gate) {
+	m.gates[name] = g
+	if m.keys != nil {
+		for i := int32(0); i < m.totalcnt; i++ {
+			m.keys[m.keyname(name, i)] = keyref{name: name, index: i}
+		}
+	}
+}
+
+// retire deletes the g of the name which is not used anymore, and keeps it for reuse if the KeepGates is set.
+// It must be called with the m.mu locked.
+func (m *locker) retire(name string, g *gate) {
+	if m.gates[name] == g {
+		delete(m.gates, name)
+		if m.keys != nil {
+			for i := int32(0); i < m.totalcnt; i++ {
+				delete(m.keys, m.keyname(name, i))
+			}
+		}
+		if m.kept != nil {
+			m.kept.put(name, g)
+		}
+	}
+}
+
 func random() string {
 	return rueidis.BinaryString(util.RandomBytes())
 }
 
+func (m *locker) random() string {
+	if m.valfn != nil {
+		return m.valfn()
+	}
+	return random()
+}
+
 func keyname(prefix, name string, i int32) string {
 	ia := strconv.Itoa(int(i))
 	sb := strings.Builder{}
@@ -148,20 +761,70 @@ func keyname(prefix, name string, i int32) string {
 	return sb.String()
 }
 
-func (m *locker) acquire(ctx context.Context, key, val string, deadline time.Time, force bool) (err error) {
+func (m *locker) keyname(name string, i int32) string {
+	if m.keyfn != nil {
+		return m.keyfn(m.prefix, name, i)
+	}
+	if m.hashtag {
+		return keyname(m.prefix, "{"+name+"}", i)
+	}
+	return keyname(m.prefix, name, i)
+}
+
+// partial calls the OnExtendPartial if the held lock of the name has fewer than all of its keys but still the majority of them,
+// after the released number of keys are released.
+func (m *locker) partial(name string, released int32) {
+	if acked := m.totalcnt - released; m.onpart != nil && acked < m.totalcnt && acked >= m.majority {
+		m.onpart(name, acked)
+	}
+}
+
+// emit sends the event to the events channel without blocking. Events are not sent after the locker is closed.
+func (m *locker) emit(typ LockEventType, name string) {
+	m.mu.RLock()
+	if m.gates != nil {
+		select {
+		case m.events <- LockEvent{Type: typ, Name: name, Time: m.clock.Now()}:
+		default:
+		}
+	}
+	m.mu.RUnlock()
+}
+
+// parsekey is the reverse of the default keyname. It returns the name and the index of the key.
+func (m *locker) parsekey(key string) (name string, i int, ok bool) {
+	if len(key) <= len(m.prefix) || key[len(m.prefix)] != ':' || !strings.HasPrefix(key, m.prefix) {
+		return "", 0, false // not in the namespace of this locker.
+	}
+	ks := strings.SplitN(key[len(m.prefix)+1:], ":", 2)
+	if len(ks) != 2 {
+		return "", 0, false
+	}
+	name = ks[1]
+	if m.hashtag {
+		if len(name) < 2 || name[0] != '{' || name[len(name)-1] != '}' {
+			return "", 0, false
+		}
+		name = name[1 : len(name)-1]
+	}
+	i, err := strconv.Atoi(ks[0])
+	return name, i, err == nil
+}
+
+func (m *locker) acquire(ctx context.Context, client rueidis.Client, key, val string, deadline time.Time, force bool) (err error) {
 	ctx, cancel := context.WithTimeout(ctx, m.timeout)
 	var resp rueidis.RedisResult
 	if force {
 		if m.setpx {
-			resp = fcqms.Exec(ctx, m.client, []string{key}, []string{val, strconv.FormatInt(m.validity.Milliseconds(), 10)})
+			resp = fcqms.Exec(ctx, client, []string{key}, []string{val, strconv.FormatInt(m.validity.Milliseconds(), 10)})
 		} else {
-			resp = fcqat.Exec(ctx, m.client, []string{key}, []string{val, strconv.FormatInt(deadline.UnixMilli(), 10)})
+			resp = fcqat.Exec(ctx, client, []string{key}, []string{val, strconv.FormatInt(deadline.UnixMilli(), 10)})
 		}
 	} else {
 		if m.setpx {
-			resp = acqms.Exec(ctx, m.client, []string{key}, []string{val, strconv.FormatInt(m.validity.Milliseconds(), 10)})
+			resp = acqms.Exec(ctx, client, []string{key}, []string{val, strconv.FormatInt(m.validity.Milliseconds(), 10)})
 		} else {
-			resp = acqat.Exec(ctx, m.client, []string{key}, []string{val, strconv.FormatInt(deadline.UnixMilli(), 10)})
+			resp = acqat.Exec(ctx, client, []string{key}, []string{val, strconv.FormatInt(deadline.UnixMilli(), 10)})
 		}
 	}
 	cancel()
@@ -171,9 +834,9 @@ func (m *locker) acquire(ctx context.Context, key, val string, deadline time.Tim
 	return err
 }
 
-func (m *locker) script(ctx context.Context, script *rueidis.Lua, key, val string, deadline time.Time) error {
+func (m *locker) script(ctx context.Context, client rueidis.Client, script *rueidis.Lua, key, val string, deadline time.Time) error {
 	ctx, cancel := context.WithDeadline(ctx, deadline)
-	resp := script.Exec(ctx, m.client, []string{key}, []string{val, strconv.FormatInt(deadline.UnixMilli(), 10)})
+	resp := script.Exec(ctx, client, []string{key}, []string{val, strconv.FormatInt(deadline.UnixMilli(), 10)})
 	cancel()
 	if v, err := resp.AsInt64(); err != nil || v == 1 {
 		return err
@@ -181,65 +844,215 @@ func (m *locker) script(ctx context.Context, script *rueidis.Lua, key, val strin
 	return ErrNotLocked
 }
 
-func (m *locker) waitgate(ctx context.Context, name string) (g *gate, err error) {
+// reconciling reads the PTTL of the keys of a held lock by the ReconcileInterval and publishes the deadline observed on redis
+// to the extended, unless it has been moved by an extension during the read. It stops once the lock is released or lost.
+func (m *locker) reconciling(name, val string, state *int32, extended *int64, done chan struct{}) {
+	timer := m.clock.NewTimer(m.ttlcheck)
+	defer timer.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.Chan():
+		}
+		if atomic.LoadInt32(state) != lockHeld {
+			return
+		}
+		prev := atomic.LoadInt64(extended)
+		if deadline, ok := m.observe(name, val); ok {
+			atomic.CompareAndSwapInt64(extended, prev, deadline.UnixMilli())
+		}
+		timer.Reset(m.ttlcheck)
+	}
+}
+
+// observe returns the deadline of the lock derived from the PTTL of its keys. It returns false if the majority of the keys
+// are not owned by the val or have no TTL, and then the monitoring goroutines will handle the loss of the lock.
+func (m *locker) observe(name, val string) (deadline time.Time, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.ttlcheck)
+	defer cancel()
+	now := m.clock.Now()
+	var resps []rueidis.RedisResult
+	if m.clients == nil {
+		execs := make([]rueidis.LuaExec, m.totalcnt)
+		for i := int32(0); i < m.totalcnt; i++ {
+			execs[i] = rueidis.LuaExec{Keys: []string{m.keyname(name, i)}, Args: []string{val}}
+		}
+		resps = ownttl.ExecMulti(ctx, m.client, execs...)
+	} else {
+		resps = make([]rueidis.RedisResult, m.totalcnt)
+		for i, client := range m.clients {
+			resps[i] = ownttl.Exec(ctx, client, []string{m.keyname(name, int32(i))}, []string{val})
+		}
+	}
+	ttls := make([]int64, 0, m.totalcnt)
+	for _, resp := range resps {
+		if ttl, err := resp.AsInt64(); err == nil && ttl > 0 {
+			ttls = append(ttls, ttl)
+		}
+	}
+	if int32(len(ttls)) < m.majority {
+		return deadline, false
+	}
+	sort.Slice(ttls, func(i, j int) bool { return ttls[i] > ttls[j] })
+	return now.Add(time.Duration(ttls[m.majority-1]) * time.Millisecond), true
+}
+
+// waitgate waits for the gate of the name. If the t is not nil, the gate is waited in the fair queue by the t.
+func (m *locker) waitgate(ctx context.Context, name string, t *ticket) (g *gate, waited bool, err error) {
 	m.mu.Lock()
 	g, ok := m.gates[name]
 	if !ok {
 		if m.gates == nil {
 			m.mu.Unlock()
-			return nil, ErrLockerClosed
+			return nil, false, ErrLockerClosed
 		}
-		g = makegate(m.totalcnt)
+		g = m.newgate(name)
 		g.w++
-		m.gates[name] = g
+		g.held = true
+		g.enqueue(t)
+		m.addgate(name, g)
 		m.mu.Unlock()
-		return g, nil
-	} else {
-		g.w++
+		return g, false, nil
+	}
+	g.w++
+	g.enqueue(t)
+	if !g.held { // the gate is only used by tries of ForceWithContext which don't hold it.
+		g.held = true
 		m.mu.Unlock()
+		return g, false, nil
+	}
+	g.n++
+	m.mu.Unlock()
+	ch := g.ch
+	if t != nil {
+		ch = t.ch
 	}
 	select {
 	case <-ctx.Done():
 		m.mu.Lock()
-		if g.w--; g.w == 0 && m.gates[name] == g {
-			delete(m.gates, name)
+		g.n--
+		m.dequeue(g, t, true)
+		if g.w--; g.w == 0 {
+			m.retire(name, g)
+		} else if t == nil && g.n == 0 && m.gates != nil {
+			select { // take back the gate handed over to the leaving waiter, and hand it over again.
+			case <-g.ch:
+				m.signal(g)
+			default:
+			}
 		}
 		m.mu.Unlock()
-		return nil, ctx.Err()
-	case _, ok = <-g.ch:
+		return nil, true, ctx.Err()
+	case _, ok = <-ch:
 		if ok {
-			return g, nil
+			m.mu.Lock()
+			g.n--
+			m.mu.Unlock()
+			return g, true, nil
 		}
-		return nil, ErrLockerClosed
+		return nil, true, ErrLockerClosed
 	}
 }
 
+func (g *gate) enqueue(t *ticket) {
+	if t != nil && t.g != g { // the previous gate of the t may have been deleted while the t was retrying.
+		t.g = g
+		g.q = append(g.q, t)
+	}
+}
+
+// dequeue removes the t from the fair queue of the g. If the forward is true, the gate handed over to the t
+// but not taken is handed over to the next waiter. It must be called with the m.mu locked.
+func (m *locker) dequeue(g *gate, t *ticket, forward bool) {
+	if t == nil || t.g != g {
+		return
+	}
+	t.g = nil
+	for i, q := range g.q {
+		if q == t {
+			copy(g.q[i:], g.q[i+1:])
+			g.q[len(g.q)-1] = nil
+			g.q = g.q[:len(g.q)-1]
+			break
+		}
+	}
+	if forward && m.gates != nil {
+		select {
+		case <-t.ch:
+			m.signal(g)
+		default:
+		}
+	}
+}
+
+// signal hands the g over to one of its waiters, or the earliest one if the FairQueue is enabled.
+// The g is not held anymore if there is no waiter to hand over to. It must be called with the m.mu locked.
+func (m *locker) signal(g *gate) {
+	if m.gates == nil {
+		return
+	}
+	ch := g.ch
+	if m.fair {
+		if len(g.q) == 0 {
+			g.held = false
+			return
+		}
+		ch = g.q[0].ch
+	} else if g.n == 0 {
+		g.held = false
+		return
+	}
+	g.held = true
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// release releases the g used by a try, and hands it over to the next waiter if the try holds it.
+func (m *locker) release(name string, g *gate, own bool) {
+	m.mu.Lock()
+	if g.w--; g.w == 0 {
+		m.retire(name, g)
+	} else if own {
+		m.signal(g)
+	}
+	m.mu.Unlock()
+}
+
 func (m *locker) trygate(name string) (g *gate) {
 	m.mu.Lock()
 	if _, ok := m.gates[name]; !ok && m.gates != nil {
-		g = makegate(m.totalcnt)
+		g = m.newgate(name)
 		g.w++
-		m.gates[name] = g
+		g.held = true
+		m.addgate(name, g)
 	}
 	m.mu.Unlock()
 	return g
 }
 
-func (m *locker) forcegate(name string) (g *gate) {
+// forcegate returns the gate of the name, which is held by the caller only if it is not held by others.
+func (m *locker) forcegate(name string) (g *gate, own bool) {
 	m.mu.Lock()
 	if g = m.gates[name]; g == nil && m.gates != nil {
-		g = makegate(m.totalcnt)
-		m.gates[name] = g
+		g = m.newgate(name)
+		m.addgate(name, g)
 	}
 	if g != nil {
 		g.w++
+		if own = !g.held; own {
+			g.held = true
+		}
 	}
 	m.mu.Unlock()
-	return g
+	return g, own
 }
 
 func (m *locker) onInvalidations(messages []rueidis.RedisMessage) {
 	if messages == nil {
+		m.wakeall()
 		m.mu.RLock()
 		for _, g := range m.gates {
 			for _, ch := range g.csc {
@@ -253,11 +1066,10 @@ func (m *locker) onInvalidations(messages []rueidis.RedisMessage) {
 	}
 	for _, msg := range messages {
 		k, _ := msg.ToString()
-		if ks := strings.SplitN(k, ":", 3); len(ks) == 3 {
+		if name, n, ok := m.lookup(k); ok {
+			m.wakename(name)
 			m.mu.RLock()
-			g, ok := m.gates[ks[2]]
-			if ok {
-				n, _ := strconv.Atoi(ks[1])
+			if g, ok := m.gates[name]; ok {
 				select {
 				case g.csc[n] <- struct{}{}:
 				default:
@@ -268,151 +1080,835 @@ func (m *locker) onInvalidations(messages []rueidis.RedisMessage) {
 	}
 }
 
-func (m *locker) try(ctx context.Context, cancel context.CancelFunc, name string, g *gate, force bool) context.CancelFunc {
+// lookup returns the name and the index of the key, by the parsekey for the default keyname,
+// or by the keys indexed when the gates are added if the KeyFn is set.
+func (m *locker) lookup(key string) (name string, i int, ok bool) {
+	if m.keyfn == nil {
+		return m.parsekey(key)
+	}
+	m.mu.RLock()
+	r, ok := m.keys[key]
+	m.mu.RUnlock()
+	return r.name, int(r.index), ok
+}
+
+// watch returns a channel that will be closed when the keys of the name may be changed.
+func (m *locker) watch(name string) chan struct{} {
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+	if m.waits == nil {
+		return nil
+	}
+	ch, ok := m.waits[name]
+	if !ok {
+		ch = make(chan struct{})
+		m.waits[name] = ch
+	}
+	return ch
+}
+
+func (m *locker) wakename(name string) {
+	m.wmu.Lock()
+	if ch, ok := m.waits[name]; ok {
+		close(ch)
+		delete(m.waits, name)
+	}
+	m.wmu.Unlock()
+}
+
+func (m *locker) wakeall() {
+	m.wmu.Lock()
+	for name, ch := range m.waits {
+		close(ch)
+		delete(m.waits, name)
+	}
+	m.wmu.Unlock()
+}
+
+// clientOf returns the client of the redis deployment holding the i-th key of locks.
+func (m *locker) clientOf(i int32) rueidis.Client {
+	if m.clients == nil {
+		return m.client
+	}
+	return m.clients[i]
+}
+
+// doKeys sends the cmd built for each key of the lock by name to the redis deployment holding the key,
+// and returns their results in the order of the keys.
+func (m *locker) doKeys(ctx context.Context, name string, build func(client rueidis.Client, key string) rueidis.Completed) []rueidis.RedisResult {
+	if m.clients == nil {
+		cmds := make(rueidis.Commands, 0, m.totalcnt)
+		for i := int32(0); i < m.totalcnt; i++ {
+			cmds = append(cmds, build(m.client, m.keyname(name, i)))
+		}
+		return m.client.DoMulti(ctx, cmds...)
+	}
+	resps := make([]rueidis.RedisResult, m.totalcnt)
+	for i, client := range m.clients {
+		resps[i] = client.Do(ctx, build(client, m.keyname(name, int32(i))))
+	}
+	return resps
+}
+
+// free reports whether at least the majority of the keys of the name are absent. Reading the keys also makes them tracked.
+func (m *locker) free(ctx context.Context, name string) (bool, error) {
+	absent := int32(0)
+	for _, resp := range m.doKeys(ctx, name, func(client rueidis.Client, key string) rueidis.Completed {
+		return client.B().Get().Key(key).Build()
+	}) {
+		if err := resp.Error(); rueidis.IsRedisNil(err) {
+			absent++
+		} else if err != nil {
+			return false, err
+		}
+	}
+	return absent >= m.majority, nil
+}
+
+func (m *locker) IsLocked(ctx context.Context, name string) (bool, error) {
+	var present, absent int32
+	var err error
+	for _, resp := range m.doKeys(ctx, name, func(client rueidis.Client, key string) rueidis.Completed {
+		return client.B().Exists().Key(key).Build()
+	}) {
+		if n, e := resp.AsInt64(); e != nil {
+			err = e
+		} else if n > 0 {
+			present++
+		} else {
+			absent++
+		}
+	}
+	if present < m.majority && absent < m.majority {
+		return false, err
+	}
+	return present >= m.majority, nil
+}
+
+func (m *locker) WaitFor(ctx context.Context, name string) error {
+	for {
+		ch := m.watch(name) // watch before checking to not miss the invalidations in between
+		if ch == nil {
+			return ErrLockerClosed
+		}
+		if free, err := m.free(ctx, name); err != nil || free {
+			return err
+		}
+		var poll timer
+		var tick <-chan time.Time
+		if m.nocsc {
+			poll = m.clock.NewTimer(m.timeout)
+			tick = poll.Chan()
+		}
+		var err error
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-ch:
+		case <-tick:
+		}
+		if poll != nil {
+			poll.Stop()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// states of a lock in try for emitting events and handling the loss.
+const (
+	lockPending = iota
+	lockHeld
+	lockUnlocked
+	lockLost
+	lockAborted
+)
+
+// try returns the cancel function of the lock if it is acquired. Otherwise, it returns ErrMajorityUnreachable if
+// none of the keys is held by others but the majority of them fail to be acquired, or ErrNotLocked.
+// try acquires the lock of the name. If the l is not nil, it is filled to extend and inspect the lock once acquired.
+// The g is released once all the keys are released, and it is handed over to the next waiter if the own is true.
+func (m *locker) try(ctx context.Context, cancel context.CancelFunc, name string, g *gate, own, force bool, l *Lease) (context.CancelFunc, error) {
 	var err error
 
-	val := random()
-	deadline := time.Now().Add(m.validity)
-	cacneltm := time.AfterFunc(m.validity, cancel)
+	val := m.random()
+	deadline := m.clock.Now().Add(m.validity)
+	cacneltm := m.clock.AfterFunc(m.validity, cancel)
 	released := int32(0)
+	touched := int32(0) // whether any key has been acquired or may be changed by this try.
+	state := int32(lockPending)
+	extended := deadline.UnixMilli()
+	shifted := m.shifted() // the first extension of all the keys, which keeps them in sync while offsetting them from other locks.
 
 	done := make(chan struct{})
-	monitoring := func(err error, key string, deadline time.Time, csc chan struct{}) {
+	monitoring := func(err error, client rueidis.Client, key string, deadline time.Time, csc chan struct{}) {
+		if err != ErrNotLocked {
+			atomic.StoreInt32(&touched, 1)
+		}
+		failed := false // whether the key fails to be extended, instead of being released.
 		if err == nil {
-			for timer := time.NewTimer(m.interval); err == nil; {
+			interval := shifted
+			if m.noext {
+				interval = deadline.Sub(m.clock.Now())
+			}
+			for timer := m.clock.NewTimer(interval); err == nil; {
 				select {
 				case <-ctx.Done():
 					err = ctx.Err()
-				case <-timer.C:
-					deadline = deadline.Add(m.interval)
-					if err = m.script(ctx, extend, key, val, deadline); err == nil {
-						timer.Reset(m.interval)
+				case <-timer.Chan():
+					if m.noext {
+						if later := time.UnixMilli(atomic.LoadInt64(&extended)); later.After(deadline) {
+							deadline = later // the key has been extended by the Lease.Extend
+							timer.Reset(deadline.Sub(m.clock.Now()))
+							continue
+						}
+						err = ErrNotLocked // the key has expired
+						break
+					}
+					if deadline = deadline.Add(interval); time.UnixMilli(atomic.LoadInt64(&extended)).After(deadline) {
+						deadline = time.UnixMilli(atomic.LoadInt64(&extended))
+					}
+					if err = m.script(ctx, client, extend, key, val, deadline); err == nil {
+						// keys of the lock share the same deadlines, and only the first one extended emits the event.
+						if prev := atomic.LoadInt64(&extended); prev < deadline.UnixMilli() && atomic.CompareAndSwapInt64(&extended, prev, deadline.UnixMilli()) && atomic.LoadInt32(&state) == lockHeld {
+							m.emit(LockExtended, name)
+							m.partial(name, atomic.LoadInt32(&released))
+						}
+						interval = m.interval
+						timer.Reset(interval)
 						if !m.noloop {
 							<-csc
 						}
+					} else {
+						failed = ctx.Err() == nil
 					}
 				case <-csc:
-					if err = m.script(ctx, extend, key, val, deadline); err == nil {
+					if later := time.UnixMilli(atomic.LoadInt64(&extended)); later.After(deadline) {
+						deadline = later
+					}
+					if err = m.script(ctx, client, extend, key, val, deadline); err == nil {
 						if !m.noloop {
 							<-csc
 						}
+					} else {
+						failed = ctx.Err() == nil
 					}
 				}
 			}
 		}
 		if err != ErrNotLocked {
-			_ = m.script(context.Background(), delkey, key, val, deadline)
+			_ = m.script(context.Background(), client, delkey, key, val, deadline)
 		}
 		if released := atomic.AddInt32(&released, 1); released >= m.majority {
-			cancel()
-			if released == m.totalcnt {
-				close(done)
-				m.mu.Lock()
-				if g.w--; g.w == 0 {
-					if m.gates[name] == g {
-						delete(m.gates, name)
-					}
-				} else if m.gates != nil {
-					select {
-					case g.ch <- struct{}{}:
-					default:
+			if released == m.majority {
+				if atomic.CompareAndSwapInt32(&state, lockHeld, lockLost) {
+					m.emit(LockLost, name)
+					if m.onlost != nil {
+						m.onlost(name)
 					}
+				} else {
+					atomic.CompareAndSwapInt32(&state, lockPending, lockAborted) // not to emit LockAcquired for an already lost lock.
 				}
-				m.mu.Unlock()
 			}
+			if !m.keepctx || atomic.LoadInt32(&state) != lockLost {
+				cancel()
+			}
+			if released == m.totalcnt {
+				if atomic.LoadInt32(&state) == lockUnlocked {
+					m.emit(LockReleased, name)
+				}
+				m.unhold(done)
+				if atomic.LoadInt32(&touched) == 1 { // a try failed on keys held by others changes nothing to wake up.
+					m.wakename(name)
+				}
+				m.release(name, g, own)
+			}
+		} else if failed && atomic.LoadInt32(&state) == lockHeld {
+			m.partial(name, released)
 		}
 	}
 
-	acquire := func(err error, key string, ch chan struct{}, force bool) error {
+	acquire := func(err error, i int32, ch chan struct{}, force bool) error {
+		key, client := m.keyname(name, i), m.clientOf(i)
 		select {
 		case <-ch:
 		default:
 		}
 		if err != ErrNotLocked {
-			if err = m.acquire(ctx, key, val, deadline, force); force && err == nil {
+			if err = m.acquire(ctx, client, key, val, deadline, force); force && err == nil {
 				select {
 				case ch <- struct{}{}:
 				default:
 				}
 			}
 		}
-		go monitoring(err, key, deadline, ch)
+		go monitoring(err, client, key, deadline, ch)
 		return err
 	}
 
-	var i, acquired, failures int32
+	var i, acquired, failures, notlocked int32
 	for ; acquired < m.majority && failures < m.majority; i++ {
-		if err = acquire(err, keyname(m.prefix, name, i), g.csc[i], force); err == nil {
+		if err = acquire(err, i, g.csc[i], force); err == nil {
 			acquired++
-		} else {
-			failures++
+		} else if failures++; err == ErrNotLocked {
+			notlocked++
 		}
 	}
 	if i < m.totalcnt {
 		go func(i int32, err error) {
 			for ; i < m.totalcnt; i++ {
-				err = acquire(err, keyname(m.prefix, name, i), g.csc[i], force)
+				err = acquire(err, i, g.csc[i], force)
 			}
 		}(i, err)
 	}
 	if cacneltm.Stop() && failures < m.majority {
-		return func() {
+		if atomic.CompareAndSwapInt32(&state, lockPending, lockHeld) {
+			m.emit(LockAcquired, name)
+		}
+		if l != nil {
+			l.extend = func(ctx context.Context) error {
+				if atomic.LoadInt32(&state) != lockHeld {
+					return ErrNotLocked
+				}
+				// publish the new deadline first, so that the monitoring goroutines notified by the extension don't move it back.
+				deadline := m.clock.Now().Add(m.validity)
+				for prev := atomic.LoadInt64(&extended); prev < deadline.UnixMilli() && !atomic.CompareAndSwapInt64(&extended, prev, deadline.UnixMilli()); {
+					prev = atomic.LoadInt64(&extended)
+				}
+				var succeeded, notlocked int32
+				for i := int32(0); i < m.totalcnt; i++ {
+					if err := m.script(ctx, m.clientOf(i), extend, m.keyname(name, i), val, deadline); err == nil {
+						succeeded++
+					} else if err == ErrNotLocked {
+						notlocked++
+					}
+				}
+				if succeeded < m.majority {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					if notlocked == 0 {
+						return ErrMajorityUnreachable
+					}
+					return ErrNotLocked
+				}
+				m.emit(LockExtended, name)
+				return nil
+			}
+			l.validity = func() time.Duration {
+				if atomic.LoadInt32(&state) != lockHeld {
+					return 0
+				}
+				if d := time.UnixMilli(atomic.LoadInt64(&extended)).Sub(m.clock.Now()); d > 0 {
+					return d
+				}
+				return 0
+			}
+			if m.ttlcheck > 0 {
+				go m.reconciling(name, val, &state, &extended, done)
+			}
+		}
+		unlock := func() {
+			atomic.CompareAndSwapInt32(&state, lockHeld, lockUnlocked)
 			cancel()
 			<-done
 		}
+		m.hold(done, unlock)
+		return unlock, nil
 	}
-	return nil
+	if failures >= m.majority && notlocked == 0 && ctx.Err() == nil {
+		return nil, ErrMajorityUnreachable
+	}
+	return nil, ErrNotLocked
 }
 
 func (m *locker) ForceWithContext(ctx context.Context, name string) (context.Context, context.CancelFunc, error) {
 	ctx, cancel := context.WithCancel(ctx)
-	if g := m.forcegate(name); g != nil {
-		if cancel := m.try(ctx, cancel, name, g, true); cancel != nil {
-			return ctx, cancel, nil
+	err := ErrNotLocked
+	if g, own := m.forcegate(name); g != nil {
+		var unlock context.CancelFunc
+		if unlock, err = m.try(ctx, cancel, name, g, own, true, nil); unlock != nil {
+			return ctx, unlock, nil
 		}
 	}
 	cancel()
-	return ctx, cancel, ErrNotLocked
+	return ctx, cancel, err
 }
 
 func (m *locker) TryWithContext(ctx context.Context, name string) (context.Context, context.CancelFunc, error) {
+	if rctx, cancel, ok := m.reentered(ctx, name); ok {
+		return rctx, cancel, nil
+	}
+	if m.tracer != nil {
+		return m.traced(ctx, name, m.tryWithContext)
+	}
+	ctx, cancel, _, err := m.tryWithContext(ctx, name)
+	return ctx, cancel, err
+}
+
+func (m *locker) tryWithContext(ctx context.Context, name string) (context.Context, context.CancelFunc, bool, error) {
 	ctx, cancel := context.WithCancel(ctx)
+	err := ErrNotLocked
 	if g := m.trygate(name); g != nil {
-		if cancel := m.try(ctx, cancel, name, g, false); cancel != nil {
-			return ctx, cancel, nil
+		var unlock context.CancelFunc
+		if unlock, err = m.try(ctx, cancel, name, g, true, false, nil); unlock != nil {
+			ctx, unlock = m.reentrant(ctx, name, unlock)
+			return ctx, unlock, false, nil
 		}
 	}
 	cancel()
-	return ctx, cancel, ErrNotLocked
+	return ctx, cancel, err != ErrMajorityUnreachable, err
 }
 
 func (m *locker) WithContext(ctx context.Context, name string) (context.Context, context.CancelFunc, error) {
+	if rctx, cancel, ok := m.reentered(ctx, name); ok {
+		return rctx, cancel, nil
+	}
+	fn := func(ctx context.Context, name string) (context.Context, context.CancelFunc, bool, error) {
+		return m.withContext(ctx, ctx, name, nil)
+	}
+	if m.tracer != nil {
+		return m.traced(ctx, name, fn)
+	}
+	ctx, cancel, _, err := fn(ctx, name)
+	return ctx, cancel, err
+}
+
+func (m *locker) WithContexts(ctx context.Context, names ...string) (context.Context, context.CancelFunc, error) {
+	return m.batched(ctx, names, m.WithContext, m.TryWithContext)
+}
+
+// batched acquires the locks of the names in the order of the BatchStrategy, by the wait for the first one and by the try for the rest.
+// Each lock is acquired with the context of the previous one, so that losing any of them cancels the context of the last one.
+func (m *locker) batched(ctx context.Context, names []string, wait, try func(ctx context.Context, name string) (context.Context, context.CancelFunc, error)) (context.Context, context.CancelFunc, error) {
+	if len(names) == 0 {
+		lctx, cancel := context.WithCancel(ctx)
+		cancel()
+		return lctx, cancel, ErrNoNames
+	}
+	seen := make(map[string]struct{}, len(names))
+	distinct := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			distinct = append(distinct, name)
+		}
+	}
+	names = m.batch.Order(distinct)
+	cancels := make([]context.CancelFunc, 0, len(names))
+	unlock := func() {
+		for i := len(cancels) - 1; i >= 0; i-- {
+			cancels[i]()
+		}
+		cancels = cancels[:0]
+	}
+	for attempt := 0; ; attempt++ {
+		lctx, err := ctx, error(nil)
+		for i, name := range names {
+			var cancel context.CancelFunc
+			if i == 0 {
+				lctx, cancel, err = wait(lctx, name)
+			} else {
+				lctx, cancel, err = try(lctx, name)
+			}
+			if err != nil {
+				break
+			}
+			cancels = append(cancels, cancel)
+		}
+		if err == nil {
+			lctx, cancel := context.WithCancel(lctx)
+			held := cancels
+			once := sync.Once{}
+			return lctx, func() {
+				once.Do(func() {
+					cancel()
+					for i := len(held) - 1; i >= 0; i-- {
+						held[i]()
+					}
+				})
+			}, nil
+		}
+		if unlock(); err == ErrNotLocked {
+			err = m.sleep(ctx, m.batch.Backoff(attempt))
+		}
+		if err != nil {
+			lctx, cancel := context.WithCancel(ctx)
+			cancel()
+			return lctx, cancel, err
+		}
+	}
+}
+
+// sleep waits for the d or until the ctx is done, and returns the error of the ctx.
+func (m *locker) sleep(ctx context.Context, d time.Duration) error {
+	if d > 0 {
+		timer := m.clock.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+		case <-timer.Chan():
+		}
+	}
+	return ctx.Err()
+}
+
+func (m *locker) Acquire(ctx context.Context, name string) (Lease, error) {
+	l := Lease{}
+	fn := func(ctx context.Context, name string) (context.Context, context.CancelFunc, bool, error) {
+		return m.withContext(ctx, ctx, name, &l)
+	}
+	var err error
+	if m.tracer != nil {
+		l.ctx, l.cancel, err = m.traced(ctx, name, fn)
+	} else {
+		l.ctx, l.cancel, _, err = fn(ctx, name)
+	}
+	if err != nil {
+		return Lease{ctx: l.ctx, cancel: l.cancel}, err
+	}
+	return l, nil
+}
+
+func (m *locker) AcquireAsync(ctx context.Context, name string) <-chan LockResult {
+	ch := make(chan LockResult, 1) // buffered, so that the result is never blocked by the caller.
+	go func() {
+		lease, err := m.Acquire(ctx, name)
+		ch <- LockResult{Lease: lease, Err: err}
+		close(ch)
+	}()
+	return ch
+}
+
+func (m *locker) Campaign(ctx context.Context, name string) (context.Context, context.CancelFunc, error) {
+	if !m.noext {
+		return m.WithContext(ctx, name)
+	}
+	l, err := m.Acquire(ctx, name)
+	if err != nil {
+		return l.ctx, l.cancel, err
+	}
+	go m.keepalive(l)
+	return l.ctx, l.cancel, nil
+}
+
+// reentrantKey is the context key of the reentry of a lock held by a locker.
+type reentrantKey struct {
+	m    *locker
+	name string
+}
+
+// reentry counts the acquisitions of a reentrant lock, and releases the lock by the unlock once they are all released.
+type reentry struct {
+	ctx    context.Context
+	unlock context.CancelFunc
+	mu     sync.Mutex
+	n      int
+}
+
+func (r *reentry) acquire() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.n == 0 || r.ctx.Err() != nil {
+		return false
+	}
+	r.n++
+	return true
+}
+
+// release returns a function releasing one acquisition of the r only once.
+func (r *reentry) release() context.CancelFunc {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			r.n--
+			n := r.n
+			r.mu.Unlock()
+			if n == 0 {
+				r.unlock()
+			}
+		})
+	}
+}
+
+// reentrant makes the lock of the name held by the ctx and the unlock reentrant, if the Reentrant is set.
+func (m *locker) reentrant(ctx context.Context, name string, unlock context.CancelFunc) (context.Context, context.CancelFunc) {
+	if !m.reenter {
+		return ctx, unlock
+	}
+	r := &reentry{unlock: unlock, n: 1}
+	r.ctx = context.WithValue(ctx, reentrantKey{m: m, name: name}, r)
+	return r.ctx, r.release()
+}
+
+// reentered re-acquires the lock of the name if the ctx is derived from the context of that lock held by the m.
+func (m *locker) reentered(ctx context.Context, name string) (context.Context, context.CancelFunc, bool) {
+	if !m.reenter {
+		return nil, nil, false
+	}
+	r, ok := ctx.Value(reentrantKey{m: m, name: name}).(*reentry)
+	if !ok || !r.acquire() {
+		return nil, nil, false
+	}
+	release := r.release()
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, func() {
+		cancel()
+		release()
+	}, true
+}
+
+// shifted returns the ExtendInterval minus a random ExtendJitter.
+func (m *locker) shifted() time.Duration {
+	if m.jitter <= 0 {
+		return m.interval
+	}
+	return m.interval - time.Duration(util.FastRand(int(m.jitter)))
+}
+
+// keepalive extends the l every ExtendInterval until it is lost or released.
+func (m *locker) keepalive(l Lease) {
+	timer := m.clock.NewTimer(m.shifted())
+	defer timer.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-timer.Chan():
+			if err := l.Extend(l.ctx); err == ErrNotLocked || l.ctx.Err() != nil {
+				return
+			}
+			timer.Reset(m.interval)
+		}
+	}
+}
+
+func (m *locker) TryWithDeadline(ctx context.Context, name string, wait time.Duration) (context.Context, context.CancelFunc, error) {
+	if rctx, cancel, ok := m.reentered(ctx, name); ok {
+		return rctx, cancel, nil
+	}
+	wctx, wcancel := context.WithTimeout(ctx, wait)
+	defer wcancel()
+	fn := func(ctx context.Context, name string) (context.Context, context.CancelFunc, bool, error) {
+		lctx, cancel, contended, err := m.withContext(ctx, wctx, name, nil)
+		if err == context.DeadlineExceeded && ctx.Err() == nil {
+			err = ErrNotLocked
+		}
+		return lctx, cancel, contended, err
+	}
+	if m.tracer != nil {
+		return m.traced(ctx, name, fn)
+	}
+	ctx, cancel, _, err := fn(ctx, name)
+	return ctx, cancel, err
+}
+
+// withContext waits for the gate of the name until the wait is done, and returns a lock derived from the ctx.
+// If the l is not nil, it is filled by the try that acquires the lock.
+func (m *locker) withContext(ctx, wait context.Context, name string, l *Lease) (context.Context, context.CancelFunc, bool, error) {
+	contended := false
+	var t *ticket
+	if m.fair {
+		t = &ticket{ch: make(chan struct{}, 1)}
+	}
 	for {
 		ctx, cancel := context.WithCancel(ctx)
-		g, err := m.waitgate(ctx, name)
-		if g != nil {
-			if cancel := m.try(ctx, cancel, name, g, false); cancel != nil {
-				return ctx, cancel, nil
+		g, waited, err := m.waitgate(wait, name, t)
+		if contended = contended || waited; g != nil {
+			ch := m.watch(name) // watch before trying to not miss the invalidations in between
+			unlock, terr := m.try(ctx, cancel, name, g, true, false, l)
+			if unlock != nil {
+				m.mu.Lock()
+				m.dequeue(g, t, false)
+				m.mu.Unlock()
+				ctx, unlock = m.reentrant(ctx, name, unlock)
+				return ctx, unlock, contended, nil
+			}
+			if terr == ErrMajorityUnreachable {
+				cancel()
+				m.mu.Lock()
+				m.dequeue(g, t, true)
+				m.mu.Unlock()
+				return ctx, cancel, contended, terr
+			}
+			contended = true
+			if cancel(); m.backoff(wait, ch) != nil {
+				m.mu.Lock()
+				m.dequeue(g, t, true)
+				m.mu.Unlock()
+				return ctx, cancel, contended, wait.Err()
 			}
+			continue
 		}
 		if cancel(); err != nil {
-			return ctx, cancel, err
+			return ctx, cancel, contended, err
 		}
 	}
 }
 
+// backoff waits for the MinRetryInterval with jitter before retrying a lock held by others,
+// or until the ch is closed because the keys of the lock may be changed. It returns the error of the ctx if it is done.
+func (m *locker) backoff(ctx context.Context, ch chan struct{}) error {
+	if m.retry < 0 || ch == nil {
+		return nil
+	}
+	timer := m.clock.NewTimer(m.retry + time.Duration(util.FastRand(int(m.retry))))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-ch:
+	case <-timer.Chan():
+	}
+	return nil
+}
+
+func (m *locker) traced(ctx context.Context, name string, fn func(ctx context.Context, name string) (context.Context, context.CancelFunc, bool, error)) (context.Context, context.CancelFunc, error) {
+	start := m.clock.Now()
+	sctx, span := m.tracer.Start(ctx, name)
+	lctx, cancel, contended, err := fn(sctx, name)
+	if err != nil {
+		span.End(false, err)
+		return lctx, cancel, err
+	}
+	span.Acquired(m.clock.Now().Sub(start), contended)
+	once := sync.Once{}
+	return lctx, func() {
+		once.Do(func() {
+			lost := lctx.Err() != nil && sctx.Err() == nil
+			cancel()
+			span.End(lost, nil)
+		})
+	}, nil
+}
+
+func (m *locker) Pending(name string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if g, ok := m.gates[name]; ok {
+		return g.n
+	}
+	return 0
+}
+
+func (m *locker) Events() <-chan LockEvent {
+	return m.events
+}
+
+func (m *locker) Mode() (mode LockMode) {
+	if !m.nocsc {
+		if m.noloop {
+			mode = TrackingNoLoop
+		} else {
+			mode = TrackingLoop
+		}
+	}
+	if m.setpx {
+		mode |= SetPX
+	}
+	return mode
+}
+
 func (m *locker) Client() rueidis.Client {
 	return m.client
 }
 
 func (m *locker) Close() {
+	m.closeGates()
+	m.closeClients()
+}
+
+func (m *locker) CloseRelease(ctx context.Context) (err error) {
+	m.closeGates() // stop waiters from taking over the released locks.
+	m.hmu.Lock()
+	unlocks := make([]context.CancelFunc, 0, len(m.held))
+	for _, unlock := range m.held {
+		unlocks = append(unlocks, unlock)
+	}
+	m.held = nil
+	m.hmu.Unlock()
+	released := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		wg.Add(len(unlocks))
+		for _, unlock := range unlocks {
+			go func(unlock context.CancelFunc) {
+				unlock()
+				wg.Done()
+			}(unlock)
+		}
+		wg.Wait()
+		close(released)
+	}()
+	select {
+	case <-released:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	m.closeClients()
+	return err
+}
+
+// hold registers the unlock of a held lock for the CloseRelease until its done is closed by the unhold.
+func (m *locker) hold(done chan struct{}, unlock context.CancelFunc) {
+	m.hmu.Lock()
+	select {
+	case <-done:
+	default:
+		if m.held != nil {
+			m.held[done] = unlock
+		}
+	}
+	m.hmu.Unlock()
+}
+
+// unhold closes the done of a lock once all its keys are released and unregisters its unlock.
+func (m *locker) unhold(done chan struct{}) {
+	m.hmu.Lock()
+	delete(m.held, done)
+	close(done)
+	m.hmu.Unlock()
+}
+
+func (m *locker) closeGates() {
 	m.mu.Lock()
+	if m.gates != nil {
+		close(m.events)
+	}
 	for _, g := range m.gates {
 		close(g.ch)
+		for _, t := range g.q {
+			close(t.ch)
+		}
 	}
 	m.gates = nil
+	if m.kept != nil {
+		m.kept.clear()
+	}
 	m.mu.Unlock()
-	m.client.Close()
+	m.wmu.Lock()
+	for _, ch := range m.waits {
+		close(ch)
+	}
+	m.waits = nil
+	m.wmu.Unlock()
+}
+
+func (m *locker) closeClients() {
+	if m.shared {
+		if m.inv != nil {
+			m.inv.remove(m)
+		}
+		return
+	}
+	if m.clients == nil {
+		m.client.Close()
+	}
+	for _, c := range m.clients {
+		c.Close()
+	}
 }
 
 var (
@@ -422,10 +1918,28 @@ var (
 	acqat  = rueidis.NewLuaScript(`local r = redis.call("SET",KEYS[1],ARGV[1],"NX","PXAT",ARGV[2]);redis.call("GET",KEYS[1]);return r`)
 	fcqms  = rueidis.NewLuaScript(`local r = redis.call("SET",KEYS[1],ARGV[1],"PX",ARGV[2]);redis.call("GET",KEYS[1]);return r`)
 	fcqat  = rueidis.NewLuaScript(`local r = redis.call("SET",KEYS[1],ARGV[1],"PXAT",ARGV[2]);redis.call("GET",KEYS[1]);return r`)
+	ownttl = rueidis.NewLuaScript(`if redis.call("GET",KEYS[1]) == ARGV[1] then return redis.call("PTTL",KEYS[1]) end;return -2`)
 )
 
 // ErrNotLocked is returned from the Locker.TryWithContext when it fails
 var ErrNotLocked = errors.New("not locked")
 
+// ErrMajorityUnreachable is returned from the Locker when the majority of the redis keys fail to be acquired
+// because of errors other than being held by others, for example, the redis is unreachable.
+var ErrMajorityUnreachable = errors.New("majority of the lock keys are unreachable")
+
 // ErrLockerClosed is returned from the Locker.WithContext when the Locker is closed
 var ErrLockerClosed = errors.New("locker closed")
+
+// ErrNoNames is returned from the Locker.WithContexts when no names are given, because there is no lock to guard the returned context.
+var ErrNoNames = errors.New("no names to lock")
+
+// ErrKeyWithoutPrefix is returned from NewLocker if the keys returned by the LockerOption.KeyFn don't contain the KeyPrefix.
+var ErrKeyWithoutPrefix = errors.New("the lock keys must contain the KeyPrefix")
+
+// ErrClientCacheDisabled is returned from NewLocker if the LockerOption.Client is set without the LockerOption.Invalidations,
+// which means the client side caching of the client is not available to the Locker, and the FallbackSETPX is not set.
+var ErrClientCacheDisabled = errors.New("the client side caching of LockerOption.Client must be delivered by LockerOption.Invalidations")
+
+// ErrEvenAddresses is returned from NewLocker if the LockerOption.Addresses has an even number of deployments.
+var ErrEvenAddresses = errors.New("the number of lock deployments must be odd")
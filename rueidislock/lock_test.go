@@ -5,7 +5,9 @@ import (
 	"errors"
 	"math/rand"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,7 +16,7 @@ import (
 
 var address = []string{"127.0.0.1:6379"}
 
-func newLocker(t *testing.T, noLoop, setpx, nocsc bool) *locker {
+func newLocker(t testing.TB, noLoop, setpx, nocsc bool) *locker {
 	impl, err := NewLocker(LockerOption{
 		ClientOption:   rueidis.ClientOption{InitAddress: address, DisableCache: nocsc},
 		NoLoopTracking: noLoop,
@@ -74,6 +76,198 @@ func TestNewLocker_WithClientBuilder(t *testing.T) {
 	}
 }
 
+type deploymentClient struct {
+	nopClient
+	addrs  []string
+	closed *int32
+}
+
+func (c *deploymentClient) Close() {
+	atomic.AddInt32(c.closed, 1)
+}
+
+func TestNewLocker_Addresses(t *testing.T) {
+	var closed int32
+	builder := func(option rueidis.ClientOption) (rueidis.Client, error) {
+		if option.InitAddress[0] == "bad" {
+			return nil, errors.New("bad")
+		}
+		return &deploymentClient{addrs: option.InitAddress, closed: &closed}, nil
+	}
+	l, err := NewLocker(LockerOption{
+		ClientBuilder: builder,
+		KeyMajority:   5,
+		Addresses:     [][]string{{"a"}, {"b"}, {"c"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	impl := l.(*locker)
+	if impl.majority != 2 || impl.totalcnt != 3 {
+		t.Fatalf("unexpected majority %v and totalcnt %v", impl.majority, impl.totalcnt)
+	}
+	for i, addr := range []string{"a", "b", "c"} {
+		if c := impl.clientOf(int32(i)).(*deploymentClient); c.addrs[0] != addr {
+			t.Fatalf("unexpected client of the key %d: %v", i, c.addrs)
+		}
+	}
+	if l.Client() != impl.clientOf(0) {
+		t.Fatal("client mismatched")
+	}
+	l.Close()
+	if closed != 3 {
+		t.Fatalf("unexpected closed clients %v", closed)
+	}
+
+	closed = 0
+	if _, err = NewLocker(LockerOption{ClientBuilder: builder, Addresses: [][]string{{"a"}, {"b"}, {"bad"}}}); err == nil || err.Error() != "bad" {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if closed != 2 {
+		t.Fatalf("built clients should be closed on failure %v", closed)
+	}
+	if _, err = NewLocker(LockerOption{ClientBuilder: builder, Addresses: [][]string{{"a"}, {"b"}}}); err != ErrEvenAddresses {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if l, err = NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, Addresses: [][]string{{"a"}, {"b"}}}); err != nil || l.(*locker).clients != nil {
+		t.Fatalf("the Addresses should be ignored with the Client %v", err)
+	}
+}
+
+func TestLocker_Addresses(t *testing.T) {
+	for _, nocsc := range []bool{false, true} {
+		locker, err := NewLocker(LockerOption{
+			ClientOption: rueidis.ClientOption{DisableCache: nocsc},
+			Addresses:    [][]string{address, address, address},
+			KeyPrefix:    "addresses",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err = locker.TryWithContext(context.Background(), lck); err != ErrNotLocked {
+			t.Fatalf("unexpected err %v", err)
+		}
+		cancel()
+		<-ctx.Done()
+		_, cancel, err = locker.TryWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		locker.Close()
+	}
+}
+
+func TestLocker_Mode(t *testing.T) {
+	for _, c := range []struct {
+		option LockerOption
+		mode   LockMode
+		str    string
+	}{
+		{option: LockerOption{}, mode: TrackingLoop, str: "tracking"},
+		{option: LockerOption{NoLoopTracking: true}, mode: TrackingNoLoop, str: "tracking_noloop"},
+		{option: LockerOption{FallbackSETPX: true}, mode: TrackingLoop | SetPX, str: "tracking+setpx"},
+		{option: LockerOption{NoLoopTracking: true, FallbackSETPX: true}, mode: TrackingNoLoop | SetPX, str: "tracking_noloop+setpx"},
+		{option: LockerOption{ClientOption: rueidis.ClientOption{DisableCache: true}}, mode: 0, str: "polling"},
+		{option: LockerOption{ClientOption: rueidis.ClientOption{DisableCache: true}, FallbackSETPX: true}, mode: SetPX, str: "setpx"},
+	} {
+		option := c.option
+		option.ClientBuilder = func(option rueidis.ClientOption) (rueidis.Client, error) {
+			return nil, nil
+		}
+		l, err := NewLocker(option)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := l.Mode(); mode != c.mode || mode.String() != c.str || mode.Tracking() != !option.ClientOption.DisableCache {
+			t.Fatalf("unexpected mode %v for %v", mode, c.str)
+		}
+		if n := testing.AllocsPerRun(10, func() { l.Mode() }); n != 0 {
+			t.Fatalf("unexpected allocations %v", n)
+		}
+	}
+}
+
+func TestNewLocker_WithClient(t *testing.T) {
+	inv := &Invalidations{}
+	client, err := rueidis.NewClient(rueidis.ClientOption{
+		InitAddress:           address,
+		ClientTrackingOptions: []string{"OPTOUT"},
+		PipelineMultiplex:     -1,
+		OnInvalidations:       inv.OnInvalidations,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	l, err := NewLocker(LockerOption{Client: client, Invalidations: inv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Client() != client {
+		t.Fatal("client mismatched")
+	}
+	if mode := l.Mode(); mode != TrackingLoop {
+		t.Fatalf("unexpected mode %v", mode)
+	}
+	l2, err := NewLocker(LockerOption{Client: client, Invalidations: inv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l2.Close()
+
+	lck := strconv.Itoa(rand.Int())
+	_, cancel, err := l.WithContext(context.Background(), lck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waited := make(chan error)
+	go func() {
+		waited <- l2.WaitFor(context.Background(), lck) // it is only woken up by the invalidations without polling.
+	}()
+	time.Sleep(time.Millisecond * 100)
+	cancel()
+	if err := <-waited; err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	if len(inv.lockers) != 1 {
+		t.Fatalf("the closed locker should not receive invalidations")
+	}
+	if err := client.Do(context.Background(), client.B().Ping().Build()).Error(); err != nil {
+		t.Fatalf("the client should not be closed by the locker %v", err)
+	}
+}
+
+func TestNewLocker_WithClient_CacheDisabled(t *testing.T) {
+	if _, err := NewLocker(LockerOption{Client: nopClient{}}); err != ErrClientCacheDisabled {
+		t.Fatalf("unexpected err %v", err)
+	}
+	l, err := NewLocker(LockerOption{Client: nopClient{}, FallbackSETPX: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if impl := l.(*locker); !impl.nocsc || !impl.noloop || l.Mode() != SetPX {
+		t.Fatalf("unexpected mode %v", l.Mode())
+	}
+	inv := &Invalidations{}
+	if l, err = NewLocker(LockerOption{Client: nopClient{}, Invalidations: inv, NoLoopTracking: true}); err != nil {
+		t.Fatal(err)
+	}
+	if l.Mode() != TrackingNoLoop || len(inv.lockers) != 1 {
+		t.Fatalf("unexpected mode %v", l.Mode())
+	}
+	l.Close()
+	if len(inv.lockers) != 0 {
+		t.Fatal("the closed locker should be removed from the invalidations")
+	}
+}
+
 func TestLocker_WithContext_MultipleLocker(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
 		lockers := make([]*locker, 10)
@@ -126,6 +320,81 @@ func TestLocker_WithContext_MultipleLocker(t *testing.T) {
 	}
 }
 
+func TestLocker_WithContext_MixedModes(t *testing.T) {
+	test := func(t *testing.T, a, b *locker) {
+		defer a.Close()
+		defer b.Close()
+		a.timeout = time.Second
+		b.timeout = time.Second
+
+		lck := strconv.Itoa(rand.Int())
+		for _, pair := range [][2]*locker{{a, b}, {b, a}} {
+			holder, waiter := pair[0], pair[1]
+			_, cancel, err := holder.WithContext(context.Background(), lck)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := waiter.TryWithContext(context.Background(), lck); err != ErrNotLocked {
+				t.Fatalf("unexpected err %v", err)
+			}
+			acquired := make(chan context.CancelFunc)
+			go func() {
+				_, cancel, err := waiter.WithContext(context.Background(), lck)
+				if err != nil {
+					t.Error(err)
+				}
+				acquired <- cancel
+			}()
+			select {
+			case <-acquired:
+				t.Fatalf("the lock should not be acquired by both modes")
+			case <-time.After(time.Millisecond * 100):
+			}
+			cancel()
+			select {
+			case cancel := <-acquired:
+				if _, _, err := holder.TryWithContext(context.Background(), lck); err != ErrNotLocked {
+					t.Fatalf("unexpected err %v", err)
+				}
+				cancel()
+			case <-time.After(time.Second * 5):
+				t.Fatalf("the lock should be acquired after released by the other mode")
+			}
+		}
+
+		sum := 0
+		var wg sync.WaitGroup
+		wg.Add(2)
+		for _, l := range []*locker{a, b} {
+			go func(l *locker) {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					_, cancel, err := l.WithContext(context.Background(), lck)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					sum++
+					cancel()
+				}
+			}(l)
+		}
+		wg.Wait()
+		if sum != 100 {
+			t.Fatalf("unexpected sum %v", sum)
+		}
+	}
+	t.Run("SET PX and Tracking", func(t *testing.T) {
+		test(t, newLocker(t, true, true, true), newLocker(t, false, false, false))
+	})
+	t.Run("SET PX and Tracking NoLoop", func(t *testing.T) {
+		test(t, newLocker(t, true, true, true), newLocker(t, true, false, false))
+	})
+	t.Run("SET PX Tracking and Tracking", func(t *testing.T) {
+		test(t, newLocker(t, true, true, false), newLocker(t, false, false, false))
+	})
+}
+
 func TestLocker_WithContext_UnlockByClientSideCaching(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx bool) {
 		locker := newLocker(t, noLoop, setpx, false)
@@ -162,24 +431,30 @@ func TestLocker_WithContext_UnlockByClientSideCaching(t *testing.T) {
 	})
 }
 
-func TestLocker_WithContext_UnlockBySelfForceWithContext(t *testing.T) {
+func TestLocker_WithContext_KeyFn(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx bool) {
 		locker := newLocker(t, noLoop, setpx, false)
 		locker.timeout = time.Second
+		locker.keyfn = func(prefix, name string, index int32) string {
+			return "tenant/" + prefix + "/" + name + "/" + strconv.Itoa(int(index))
+		}
 		defer locker.Close()
 		lck := strconv.Itoa(rand.Int())
 		ctx, cancel, err := locker.WithContext(context.Background(), lck)
 		if err != nil {
 			t.Fatal(err)
 		}
-		go func() {
-			_, cancel2, err2 := locker.ForceWithContext(context.Background(), lck)
-			if err2 != nil {
-				t.Errorf("unexpected err %v", err2)
-				return
+		client := newClient(t)
+		defer client.Close()
+		for i := int32(0); i < locker.majority; i++ {
+			key := "tenant/" + locker.prefix + "/" + lck + "/" + strconv.Itoa(int(i))
+			if err := client.Do(context.Background(), client.B().Get().Key(key).Build()).Error(); err != nil {
+				t.Fatal(err)
 			}
-			cancel2()
-		}()
+			if err := client.Do(context.Background(), client.B().Del().Key(key).Build()).Error(); err != nil {
+				t.Fatal(err)
+			}
+		}
 		<-ctx.Done()
 		cancel()
 		if !errors.Is(ctx.Err(), context.Canceled) {
@@ -197,27 +472,193 @@ func TestLocker_WithContext_UnlockBySelfForceWithContext(t *testing.T) {
 	})
 }
 
-func TestLocker_WithContext_UnlockByOtherForceWithContext(t *testing.T) {
+func TestLocker_WithContext_ValueFn(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx bool) {
+		locker := newLocker(t, noLoop, setpx, false)
+		locker.timeout = time.Second
+		lck := strconv.Itoa(rand.Int())
+		locker.valfn = func() string {
+			return "req-" + lck
+		}
+		defer locker.Close()
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cancel()
+		client := newClient(t)
+		defer client.Close()
+		for i := int32(0); i < locker.majority; i++ {
+			if v, err := client.Do(context.Background(), client.B().Get().Key(keyname(locker.prefix, lck, i)).Build()).ToString(); err != nil || v != "req-"+lck {
+				t.Fatalf("unexpected value %v %v", v, err)
+			}
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("unexpected err %v", ctx.Err())
+		}
+	}
+	t.Run("Tracking Loop", func(t *testing.T) {
+		test(t, false, false)
+	})
+	t.Run("Tracking NoLoop", func(t *testing.T) {
+		test(t, true, false)
+	})
+	t.Run("SET PX", func(t *testing.T) {
+		test(t, true, true)
+	})
+}
+
+func TestLocker_ClusterHashTag_Keys(t *testing.T) {
+	m := &locker{prefix: "rueidislock", hashtag: true}
+	for i := int32(0); i < 3; i++ {
+		key := m.keyname("a:b", i)
+		if exp := "rueidislock:" + strconv.Itoa(int(i)) + ":{a:b}"; key != exp {
+			t.Fatalf("unexpected key %v", key)
+		}
+		if name, n, ok := m.parsekey(key); !ok || name != "a:b" || n != int(i) {
+			t.Fatalf("unexpected parsed key %v %v %v", name, n, ok)
+		}
+	}
+	if _, _, ok := m.parsekey("rueidislock:0:a"); ok {
+		t.Fatalf("unexpected parsed key without hash tag")
+	}
+}
+
+func TestLocker_KeyPrefix_Keys(t *testing.T) {
+	m := &locker{prefix: "app:locks"}
+	key := m.keyname("a:b", 1)
+	if key != "app:locks:1:a:b" {
+		t.Fatalf("unexpected key %v", key)
+	}
+	if name, n, ok := m.parsekey(key); !ok || name != "a:b" || n != 1 {
+		t.Fatalf("unexpected parsed key %v %v %v", name, n, ok)
+	}
+	for _, key := range []string{"rueidislock:0:a", "app:locksx:0:a", "app:locks", "app:locks:0", "app:locks:x:a", "app:0:a"} {
+		if _, _, ok := m.parsekey(key); ok {
+			t.Fatalf("unexpected parsed key %v", key)
+		}
+	}
+}
+
+func TestNewLocker_KeyWithoutPrefix(t *testing.T) {
+	_, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, KeyFn: func(prefix, name string, index int32) string {
+		return name + ":" + strconv.Itoa(int(index))
+	}})
+	if err != ErrKeyWithoutPrefix {
+		t.Fatalf("unexpected err %v", err)
+	}
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, KeyPrefix: "app", KeyFn: func(prefix, name string, index int32) string {
+		return "{" + name + "}:" + prefix + ":" + strconv.Itoa(int(index))
+	}})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	l.Close()
+}
+
+func TestLocker_KeyFn_Invalidations(t *testing.T) {
+	calls := int64(0)
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, KeyPrefix: "app", KeyFn: func(prefix, name string, index int32) string {
+		atomic.AddInt64(&calls, 1)
+		return "{" + name + "}:" + prefix + ":" + strconv.Itoa(int(index))
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	m := l.(*locker)
+	gates := make([]*gate, 100)
+	for i := range gates {
+		gates[i] = m.trygate(strconv.Itoa(i))
+	}
+	atomic.StoreInt64(&calls, 0)
+	// the name of an invalidated key is found without calling the KeyFn for every gate.
+	if name, i, ok := m.lookup("{7}:app:1"); !ok || name != "7" || i != 1 {
+		t.Fatalf("unexpected lookup %v %v %v", name, i, ok)
+	}
+	if _, _, ok := m.lookup("{x}:app:0"); ok {
+		t.Fatal("unexpected lookup of an unknown key")
+	}
+	if n := atomic.LoadInt64(&calls); n != 0 {
+		t.Fatalf("unexpected KeyFn calls %v", n)
+	}
+	for i, g := range gates {
+		m.release(strconv.Itoa(i), g, true)
+	}
+	if n := len(m.keys); n != 0 {
+		t.Fatalf("unexpected indexed keys %v", n)
+	}
+}
+
+func TestLocker_WithContext_KeyPrefix(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		lockers := make([]*locker, 2)
+		for i := range lockers {
+			impl, err := NewLocker(LockerOption{
+				ClientOption:   rueidis.ClientOption{InitAddress: address, DisableCache: nocsc},
+				KeyPrefix:      "prefix" + strconv.Itoa(i),
+				NoLoopTracking: noLoop,
+				FallbackSETPX:  setpx,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer impl.Close()
+			lockers[i] = impl.(*locker)
+		}
+		lck := strconv.Itoa(rand.Int())
+		ctx0, cancel0, err := lockers[0].TryWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx1, cancel1, err := lockers[1].TryWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatalf("the same name should be locked by lockers with different prefixes %v", err)
+		}
+		cancel1()
+		if ctx0.Err() != nil || ctx1.Err() == nil {
+			t.Fatalf("releasing the lock of another prefix should not affect the other %v %v", ctx0.Err(), ctx1.Err())
+		}
+		if _, _, err := lockers[0].TryWithContext(context.Background(), lck); err != ErrNotLocked {
+			t.Fatalf("unexpected err %v", err)
+		}
+		cancel0()
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_WithContext_ClusterHashTag(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx bool) {
 		locker := newLocker(t, noLoop, setpx, false)
 		locker.timeout = time.Second
+		locker.hashtag = true
 		defer locker.Close()
 		lck := strconv.Itoa(rand.Int())
 		ctx, cancel, err := locker.WithContext(context.Background(), lck)
 		if err != nil {
 			t.Fatal(err)
 		}
-		go func() {
-			locker2 := newLocker(t, noLoop, setpx, false)
-			locker2.timeout = time.Second
-			defer locker2.Close()
-			_, cancel2, err2 := locker2.ForceWithContext(context.Background(), lck)
-			if err2 != nil {
-				t.Errorf("unexpected err %v", err2)
-				return
+		client := newClient(t)
+		defer client.Close()
+		for i := int32(0); i < locker.majority; i++ {
+			key := locker.prefix + ":" + strconv.Itoa(int(i)) + ":{" + lck + "}"
+			if err := client.Do(context.Background(), client.B().Get().Key(key).Build()).Error(); err != nil {
+				t.Fatal(err)
 			}
-			cancel2()
-		}()
+			if err := client.Do(context.Background(), client.B().Del().Key(key).Build()).Error(); err != nil {
+				t.Fatal(err)
+			}
+		}
 		<-ctx.Done()
 		cancel()
 		if !errors.Is(ctx.Err(), context.Canceled) {
@@ -235,13 +676,13 @@ func TestLocker_WithContext_UnlockByOtherForceWithContext(t *testing.T) {
 	})
 }
 
-func TestLocker_ForceWithContext_UnlockBySelfForceWithContext(t *testing.T) {
+func TestLocker_WithContext_UnlockBySelfForceWithContext(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx bool) {
 		locker := newLocker(t, noLoop, setpx, false)
 		locker.timeout = time.Second
 		defer locker.Close()
 		lck := strconv.Itoa(rand.Int())
-		ctx, cancel, err := locker.ForceWithContext(context.Background(), lck)
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -270,13 +711,13 @@ func TestLocker_ForceWithContext_UnlockBySelfForceWithContext(t *testing.T) {
 	})
 }
 
-func TestLocker_ForceWithContext_UnlockByOtherForceWithContext(t *testing.T) {
+func TestLocker_WithContext_UnlockByOtherForceWithContext(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx bool) {
 		locker := newLocker(t, noLoop, setpx, false)
 		locker.timeout = time.Second
 		defer locker.Close()
 		lck := strconv.Itoa(rand.Int())
-		ctx, cancel, err := locker.ForceWithContext(context.Background(), lck)
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -308,19 +749,92 @@ func TestLocker_ForceWithContext_UnlockByOtherForceWithContext(t *testing.T) {
 	})
 }
 
-func TestLocker_WithContext_ExtendByClientSideCaching(t *testing.T) {
+func TestLocker_ForceWithContext_UnlockBySelfForceWithContext(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx bool) {
 		locker := newLocker(t, noLoop, setpx, false)
 		locker.timeout = time.Second
 		defer locker.Close()
 		lck := strconv.Itoa(rand.Int())
-		ctx, cancel, err := locker.WithContext(context.Background(), lck)
+		ctx, cancel, err := locker.ForceWithContext(context.Background(), lck)
 		if err != nil {
 			t.Fatal(err)
 		}
 		go func() {
-			client := newClient(t)
-			defer client.Close()
+			_, cancel2, err2 := locker.ForceWithContext(context.Background(), lck)
+			if err2 != nil {
+				t.Errorf("unexpected err %v", err2)
+				return
+			}
+			cancel2()
+		}()
+		<-ctx.Done()
+		cancel()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	t.Run("Tracking Loop", func(t *testing.T) {
+		test(t, false, false)
+	})
+	t.Run("Tracking NoLoop", func(t *testing.T) {
+		test(t, true, false)
+	})
+	t.Run("SET PX", func(t *testing.T) {
+		test(t, true, true)
+	})
+}
+
+func TestLocker_ForceWithContext_UnlockByOtherForceWithContext(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx bool) {
+		locker := newLocker(t, noLoop, setpx, false)
+		locker.timeout = time.Second
+		defer locker.Close()
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.ForceWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			locker2 := newLocker(t, noLoop, setpx, false)
+			locker2.timeout = time.Second
+			defer locker2.Close()
+			_, cancel2, err2 := locker2.ForceWithContext(context.Background(), lck)
+			if err2 != nil {
+				t.Errorf("unexpected err %v", err2)
+				return
+			}
+			cancel2()
+		}()
+		<-ctx.Done()
+		cancel()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	t.Run("Tracking Loop", func(t *testing.T) {
+		test(t, false, false)
+	})
+	t.Run("Tracking NoLoop", func(t *testing.T) {
+		test(t, true, false)
+	})
+	t.Run("SET PX", func(t *testing.T) {
+		test(t, true, true)
+	})
+}
+
+func TestLocker_WithContext_ExtendByClientSideCaching(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx bool) {
+		locker := newLocker(t, noLoop, setpx, false)
+		locker.timeout = time.Second
+		defer locker.Close()
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			client := newClient(t)
+			defer client.Close()
 			for i := int32(0); i < locker.majority; i++ {
 				if err := client.Do(context.Background(), client.B().Pexpire().Key(keyname(locker.prefix, lck, i)).Milliseconds(100).Build()).Error(); err != nil {
 					t.Error(err)
@@ -383,84 +897,93 @@ func TestLocker_WithContext_AutoExtend(t *testing.T) {
 	}
 }
 
-func TestLocker_WithContext_DeadContext(t *testing.T) {
+func TestLocker_WithContext_ExtendJitter(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
 		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.validity = time.Second
+		locker.interval = time.Millisecond * 500
+		locker.jitter = time.Millisecond * 250
 		defer locker.Close()
 
-		ctx, cancel := context.WithCancel(context.Background())
-		cancel()
-		_, _, err := locker.WithContext(ctx, strconv.Itoa(rand.Int()))
-		if !errors.Is(err, context.Canceled) {
+		ctx, cancel, err := locker.WithContext(context.Background(), strconv.Itoa(rand.Int()))
+		if err != nil {
 			t.Fatal(err)
 		}
+		time.Sleep(locker.validity * 2)
+		if ctx.Err() != nil {
+			t.Fatalf("unexpected context canceled %v", ctx.Err())
+		}
+		cancel()
 	}
 	for _, nocsc := range []bool{false, true} {
 		t.Run("Tracking Loop", func(t *testing.T) {
 			test(t, false, false, nocsc)
 		})
-		t.Run("Tracking NoLoop", func(t *testing.T) {
-			test(t, true, false, nocsc)
-		})
 		t.Run("SET PX", func(t *testing.T) {
 			test(t, true, true, nocsc)
 		})
 	}
 }
 
-func TestLocker_WithContext_CancelContext(t *testing.T) {
-	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
-		locker := newLocker(t, noLoop, setpx, nocsc)
-		defer locker.Close()
-
-		lck := strconv.Itoa(rand.Int())
-		ctx, cancel, err := locker.WithContext(context.Background(), lck)
-
-		wg := sync.WaitGroup{}
-		wg.Add(100)
+func TestLocker_ExtendJitter(t *testing.T) {
+	for _, c := range []struct {
+		jitter   time.Duration
+		expected time.Duration
+	}{
+		{jitter: 0, expected: 0},
+		{jitter: -time.Second, expected: 0},
+		{jitter: 100 * time.Millisecond, expected: 100 * time.Millisecond},
+		{jitter: time.Hour, expected: 500 * time.Millisecond},
+	} {
+		l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, ExtendInterval: time.Second, ExtendJitter: c.jitter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := l.(*locker)
+		if m.jitter != c.expected {
+			t.Fatalf("unexpected jitter %v, expected %v", m.jitter, c.expected)
+		}
+		shifted := map[time.Duration]struct{}{}
 		for i := 0; i < 100; i++ {
-			go func() {
-				if _, _, err := locker.WithContext(ctx, lck); !errors.Is(err, context.Canceled) {
-					t.Error(err)
-				}
-				wg.Done()
-			}()
+			d := m.shifted()
+			if d > m.interval || d < m.interval-m.jitter {
+				t.Fatalf("unexpected shifted interval %v", d)
+			}
+			shifted[d] = struct{}{}
 		}
-		time.Sleep(time.Second * 3)
-		cancel()
-		wg.Wait()
-		if !errors.Is(ctx.Err(), context.Canceled) {
-			t.Fatal(err)
+		if (c.expected == 0) != (len(shifted) == 1) {
+			t.Fatalf("unexpected shifted intervals %v", shifted)
 		}
-	}
-	for _, nocsc := range []bool{false, true} {
-		t.Run("Tracking Loop", func(t *testing.T) {
-			test(t, false, false, nocsc)
-		})
-		t.Run("Tracking NoLoop", func(t *testing.T) {
-			test(t, true, false, nocsc)
-		})
-		t.Run("SET PX", func(t *testing.T) {
-			test(t, true, true, nocsc)
-		})
+		l.Close()
 	}
 }
 
-func TestLocker_TryWithContext(t *testing.T) {
+func TestLocker_WithContext_NoAutoExtend(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
 		locker := newLocker(t, noLoop, setpx, nocsc)
-		locker.timeout = time.Second
+		locker.validity = time.Second
+		locker.interval = time.Millisecond * 100
+		locker.noext = true
 		defer locker.Close()
 
 		lck := strconv.Itoa(rand.Int())
-		ctx, cancel, err := locker.TryWithContext(context.Background(), lck)
+		start := time.Now()
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
 		if err != nil {
 			t.Fatal(err)
 		}
-		if _, _, err := locker.TryWithContext(ctx, lck); err != ErrNotLocked {
-			t.Fatal(err)
+		defer cancel()
+		<-ctx.Done()
+		if elapsed := time.Since(start); elapsed < time.Millisecond*900 || elapsed > time.Millisecond*1500 {
+			t.Fatalf("unexpected lock duration %v", elapsed)
+		}
+		client := newClient(t)
+		defer client.Close()
+		for i := int32(0); i < locker.totalcnt; i++ {
+			if err := client.Do(context.Background(), client.B().Get().Key(keyname(locker.prefix, lck, i)).Build()).Error(); !rueidis.IsRedisNil(err) {
+				t.Fatalf("unexpected err %v", err)
+			}
 		}
-		cancel()
 	}
 	for _, nocsc := range []bool{false, true} {
 		t.Run("Tracking Loop", func(t *testing.T) {
@@ -475,21 +998,34 @@ func TestLocker_TryWithContext(t *testing.T) {
 	}
 }
 
-func TestLocker_ForceWithContextThenTryWithContext(t *testing.T) {
+func TestLocker_WithContext_AutoExtend_FakeClock(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		clk := newFakeClock()
 		locker := newLocker(t, noLoop, setpx, nocsc)
-		locker.timeout = time.Second
+		locker.clock = clk
+		locker.validity = time.Second * 2
+		locker.interval = time.Second
 		defer locker.Close()
 
 		lck := strconv.Itoa(rand.Int())
-		ctx, cancel, err := locker.ForceWithContext(context.Background(), lck)
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
 		if err != nil {
 			t.Fatal(err)
 		}
-		if _, _, err := locker.TryWithContext(ctx, lck); err != ErrNotLocked {
-			t.Fatal(err)
+		defer cancel()
+		clk.waitTimers(t, int(locker.totalcnt))
+		for i := 0; i < 3; i++ {
+			clk.Advance(locker.interval)
+			clk.waitTimers(t, int(locker.totalcnt))
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("unexpected context canceled %v", ctx.Err())
+		}
+		client := newClient(t)
+		defer client.Close()
+		if pttl, err := client.Do(context.Background(), client.B().Pttl().Key(keyname(locker.prefix, lck, 0)).Build()).AsInt64(); err != nil || pttl <= locker.validity.Milliseconds() {
+			t.Fatalf("unexpected pttl %v %v", pttl, err)
 		}
-		cancel()
 	}
 	for _, nocsc := range []bool{false, true} {
 		t.Run("Tracking Loop", func(t *testing.T) {
@@ -498,55 +1034,31 @@ func TestLocker_ForceWithContextThenTryWithContext(t *testing.T) {
 		t.Run("Tracking NoLoop", func(t *testing.T) {
 			test(t, true, false, nocsc)
 		})
-		t.Run("SET PX", func(t *testing.T) {
-			test(t, true, true, nocsc)
-		})
 	}
 }
 
-func TestLocker_TryWithContext_MultipleLocker(t *testing.T) {
+func TestLocker_WithContext_NoAutoExtend_FakeClock(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
-		lockers := make([]*locker, 10)
-		sum := make([]int, len(lockers))
-		for i := 0; i < len(lockers); i++ {
-			lockers[i] = newLocker(t, noLoop, setpx, nocsc)
-			lockers[i].timeout = time.Second
+		clk := newFakeClock()
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.clock = clk
+		locker.noext = true
+		defer locker.Close()
+
+		ctx, cancel, err := locker.WithContext(context.Background(), strconv.Itoa(rand.Int()))
+		if err != nil {
+			t.Fatal(err)
 		}
-		defer func() {
-			for _, locker := range lockers {
-				locker.Close()
-			}
-		}()
-		cnt := 1000
-		lck := strconv.Itoa(rand.Int())
-		ctx := context.Background()
-		var wg sync.WaitGroup
-		wg.Add(len(lockers))
-		for i, l := range lockers {
-			go func(i int, l *locker) {
-				defer wg.Done()
-				for j := 0; j < cnt; j++ {
-					for {
-						_, cancel, err := l.TryWithContext(ctx, lck)
-						if err != nil && err != ErrNotLocked {
-							t.Error(err)
-							return
-						}
-						if cancel != nil {
-							cancel()
-							sum[i]++
-							break
-						}
-						time.Sleep(time.Millisecond)
-					}
-				}
-			}(i, l)
+		defer cancel()
+		clk.waitTimers(t, int(locker.totalcnt))
+		if ctx.Err() != nil {
+			t.Fatalf("unexpected context canceled %v", ctx.Err())
 		}
-		wg.Wait()
-		for i, s := range sum {
-			if s != cnt {
-				t.Fatalf("unexpected sum %v %v %v", i, s, cnt)
-			}
+		clk.Advance(locker.validity)
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatalf("context should be canceled after the fake clock passes the validity")
 		}
 	}
 	for _, nocsc := range []bool{false, true} {
@@ -556,42 +1068,53 @@ func TestLocker_TryWithContext_MultipleLocker(t *testing.T) {
 		t.Run("Tracking NoLoop", func(t *testing.T) {
 			test(t, true, false, nocsc)
 		})
-		t.Run("SET PX", func(t *testing.T) {
-			test(t, true, true, nocsc)
-		})
 	}
 }
 
-func TestLocker_WithContext_MissingPeers(t *testing.T) {
+func TestLocker_Acquire(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
 		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.validity = time.Second
+		locker.noext = true
 		defer locker.Close()
 
 		lck := strconv.Itoa(rand.Int())
-		for i := 0; i < 100; i++ {
-			ctx, cancel, err := locker.WithContext(context.Background(), lck)
-			if err != nil {
-				t.Fatal(err)
-			}
-			locker.mu.RLock()
-			g := locker.gates[lck]
-			locker.mu.RUnlock()
-			var wg sync.WaitGroup
-			for i := 0; i < 100; i++ {
-				wg.Add(1)
-				go func() {
-					if _, _, err := locker.WithContext(ctx, lck); !errors.Is(err, context.Canceled) {
-						t.Error(err)
-					}
-					wg.Done()
-				}()
-			}
+		start := time.Now()
+		lease, err := locker.Acquire(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := lease.Validity(); v <= 0 || v > locker.validity {
+			t.Fatalf("unexpected validity %v", v)
+		}
+		if _, _, err := locker.TryWithContext(context.Background(), lck); err != ErrNotLocked {
+			t.Fatalf("unexpected err %v", err)
+		}
+		time.Sleep(locker.validity / 2)
+		if err := lease.Extend(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if v := lease.Validity(); v <= locker.validity/2 || v > locker.validity {
+			t.Fatalf("unexpected validity %v", v)
+		}
+		time.Sleep(locker.validity * 3 / 4)
+		if err := lease.Context().Err(); err != nil {
+			t.Fatalf("unexpected context canceled %v after %v", err, time.Since(start))
+		}
+		lease.Cancel()
+		if err := lease.Context().Err(); err != context.Canceled {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if v := lease.Validity(); v != 0 {
+			t.Fatalf("unexpected validity %v", v)
+		}
+		if err := lease.Extend(context.Background()); err != ErrNotLocked {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if _, cancel, err := locker.TryWithContext(context.Background(), lck); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		} else {
 			cancel()
-			select {
-			case g.ch <- struct{}{}:
-			default:
-			}
-			wg.Wait()
 		}
 	}
 	for _, nocsc := range []bool{false, true} {
@@ -607,34 +1130,1651 @@ func TestLocker_WithContext_MissingPeers(t *testing.T) {
 	}
 }
 
-func TestLocker_WithContext_Cleanup(t *testing.T) {
+func TestLocker_Acquire_ReconcileInterval(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
 		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.validity = time.Second
+		locker.ttlcheck = time.Millisecond * 100
+		locker.noext = true
 		defer locker.Close()
 
 		lck := strconv.Itoa(rand.Int())
-		_, cancel1, err := locker.WithContext(context.Background(), lck)
+		lease, err := locker.Acquire(context.Background(), lck)
 		if err != nil {
 			t.Fatal(err)
 		}
-		locker.mu.Lock()
-		locker.gates[lck].w--
-		locker.mu.Unlock()
-		ctx2, cancel2 := context.WithCancel(context.Background())
-		go func() {
-			time.Sleep(time.Second)
-			cancel2()
+		defer lease.Cancel()
+		client := newClient(t)
+		defer client.Close()
+		for i := int32(0); i < locker.totalcnt; i++ {
+			if err := client.Do(context.Background(), client.B().Pexpire().Key(keyname(locker.prefix, lck, i)).Milliseconds(5000).Build()).Error(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		time.Sleep(locker.ttlcheck * 3)
+		if v := lease.Validity(); v <= locker.validity || v > time.Second*5 {
+			t.Fatalf("unexpected validity %v", v)
+		}
+		if err := lease.Context().Err(); err != nil {
+			t.Fatalf("unexpected context canceled %v", err)
+		}
+	}
+	// with the client side caching, the TTLs changed by others are reverted on invalidations.
+	t.Run("Disable Cache", func(t *testing.T) {
+		test(t, true, false, true)
+	})
+	t.Run("SET PX", func(t *testing.T) {
+		test(t, true, true, true)
+	})
+}
+
+func TestLocker_AcquireAsync(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		res := <-locker.AcquireAsync(context.Background(), lck)
+		if res.Err != nil {
+			t.Fatal(res.Err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := locker.AcquireAsync(ctx, lck)
+		select {
+		case r := <-ch:
+			t.Fatalf("unexpected result %v", r)
+		case <-time.After(time.Millisecond * 100):
+		}
+		res.Lease.Cancel()
+		second := <-ch
+		if second.Err != nil {
+			t.Fatal(second.Err)
+		}
+		if _, ok := <-ch; ok {
+			t.Fatalf("the channel should be closed")
+		}
+		cancel() // the lock is released without calling the Lease.Cancel
+		<-second.Lease.Context().Done()
+		if _, cancel, err := locker.WithContext(context.Background(), lck); err != nil {
+			t.Fatal(err)
+		} else {
+			cancel()
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_AcquireAsync_Closed(t *testing.T) {
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	res := <-l.AcquireAsync(context.Background(), "lock")
+	if res.Err != ErrLockerClosed {
+		t.Fatalf("unexpected err %v", res.Err)
+	}
+}
+
+func TestLocker_Campaign_Closed(t *testing.T) {
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	ctx, resign, err := l.Campaign(context.Background(), "leader")
+	if err != ErrLockerClosed {
+		t.Fatalf("unexpected err %v", err)
+	}
+	resign()
+	if ctx.Err() == nil {
+		t.Fatal("leader context should be canceled")
+	}
+}
+
+func TestLocker_Campaign(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc, noext bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.validity = time.Millisecond * 500
+		locker.interval = time.Millisecond * 100
+		locker.noext = noext
+		defer locker.Close()
+
+		name := strconv.Itoa(rand.Int())
+		leader, resign, err := locker.Campaign(context.Background(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(locker.validity * 2)
+		if leader.Err() != nil {
+			t.Fatalf("unexpected leadership lost %v", leader.Err())
+		}
+
+		elected := make(chan struct{})
+		go func() {
+			_, resign, err := locker.Campaign(context.Background(), name)
+			if err != nil {
+				t.Error(err)
+			}
+			resign()
+			close(elected)
+		}()
+		select {
+		case <-elected:
+			t.Fatal("unexpected leader elected")
+		case <-time.After(locker.validity):
+		}
+		resign()
+		if leader.Err() == nil {
+			t.Fatal("leader context should be canceled after resigning")
+		}
+		<-elected
+	}
+	for _, noext := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, false, noext)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, false, noext)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, false, noext)
+		})
+	}
+}
+
+func TestLease_Zero(t *testing.T) {
+	l := Lease{}
+	l.Cancel()
+	if v := l.Validity(); v != 0 {
+		t.Fatalf("unexpected validity %v", v)
+	}
+	if err := l.Extend(context.Background()); err != ErrNotLocked {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestLocker_WithContext_DeadContext(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		defer locker.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err := locker.WithContext(ctx, strconv.Itoa(rand.Int()))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatal(err)
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_WithContext_CancelContext(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
+
+		wg := sync.WaitGroup{}
+		wg.Add(100)
+		for i := 0; i < 100; i++ {
+			go func() {
+				if _, _, err := locker.WithContext(ctx, lck); !errors.Is(err, context.Canceled) {
+					t.Error(err)
+				}
+				wg.Done()
+			}()
+		}
+		time.Sleep(time.Second * 3)
+		cancel()
+		wg.Wait()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Fatal(err)
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_WaitFor(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker1 := newLocker(t, noLoop, setpx, nocsc)
+		locker2 := newLocker(t, noLoop, setpx, nocsc)
+		defer locker1.Close()
+		defer locker2.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		if err := locker2.WaitFor(context.Background(), lck); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		_, cancel, err := locker1.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancelWait := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancelWait()
+		if err := locker2.WaitFor(ctx, lck); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected err %v", err)
+		}
+
+		done := make(chan error, 1)
+		for _, l := range []*locker{locker1, locker2} {
+			go func(l *locker) {
+				done <- l.WaitFor(context.Background(), lck)
+			}(l)
+			time.Sleep(time.Millisecond * 100)
+			select {
+			case err := <-done:
+				t.Fatalf("WaitFor should block until the lock is released %v", err)
+			default:
+			}
+		}
+		cancel()
+		for i := 0; i < 2; i++ {
+			if err := <-done; err != nil {
+				t.Fatalf("unexpected err %v", err)
+			}
+		}
+		if _, cancel, err := locker2.TryWithContext(context.Background(), lck); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		} else {
+			cancel()
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_WaitFor_Close(t *testing.T) {
+	locker1 := newLocker(t, false, false, false)
+	locker2 := newLocker(t, false, false, false)
+	defer locker1.Close()
+
+	lck := strconv.Itoa(rand.Int())
+	_, cancel, err := locker1.WithContext(context.Background(), lck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- locker2.WaitFor(context.Background(), lck)
+	}()
+	time.Sleep(time.Millisecond * 100)
+	locker2.Close()
+	if err := <-done; err != ErrLockerClosed {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestLocker_TryWithContext(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.timeout = time.Second
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.TryWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := locker.TryWithContext(ctx, lck); err != ErrNotLocked {
+			t.Fatal(err)
+		}
+		cancel()
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_IsLocked(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.timeout = time.Second
+		other := newLocker(t, noLoop, setpx, nocsc)
+		defer other.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		if locked, err := other.IsLocked(context.Background(), lck); err != nil || locked {
+			t.Fatalf("unexpected locked %v %v", locked, err)
+		}
+		_, cancel, err := locker.TryWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range []Locker{locker, other} {
+			if locked, err := l.IsLocked(context.Background(), lck); err != nil || !locked {
+				t.Fatalf("unexpected locked %v %v", locked, err)
+			}
+		}
+		cancel()
+		if locked, err := other.IsLocked(context.Background(), lck); err != nil || locked {
+			t.Fatalf("unexpected locked %v %v", locked, err)
+		}
+		locker.Close()
+		if _, err := locker.IsLocked(context.Background(), lck); err != rueidis.ErrClosing {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_IsLocked_Minority(t *testing.T) {
+	locker := newLocker(t, false, false, false)
+	defer locker.Close()
+	client := newClient(t)
+	defer client.Close()
+
+	lck := strconv.Itoa(rand.Int())
+	for i := int32(0); i < locker.majority-1; i++ {
+		if err := client.Do(context.Background(), client.B().Set().Key(keyname(locker.prefix, lck, i)).Value("v").Build()).Error(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if locked, err := locker.IsLocked(context.Background(), lck); err != nil || locked {
+		t.Fatalf("unexpected locked %v %v", locked, err)
+	}
+	if err := client.Do(context.Background(), client.B().Set().Key(keyname(locker.prefix, lck, locker.majority-1)).Value("v").Build()).Error(); err != nil {
+		t.Fatal(err)
+	}
+	if locked, err := locker.IsLocked(context.Background(), lck); err != nil || !locked {
+		t.Fatalf("unexpected locked %v %v", locked, err)
+	}
+	client.Do(context.Background(), client.B().Del().Key(keyname(locker.prefix, lck, 0)).Build())
+	client.Do(context.Background(), client.B().Del().Key(keyname(locker.prefix, lck, locker.majority-1)).Build())
+}
+
+func TestLocker_TryWithDeadline(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.timeout = time.Second
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.TryWithDeadline(context.Background(), lck, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		if _, _, err := locker.TryWithDeadline(context.Background(), lck, time.Millisecond*100); err != ErrNotLocked {
+			t.Fatal(err)
+		}
+		if time.Since(start) < time.Millisecond*100 {
+			t.Fatal("TryWithDeadline should wait for the wait duration")
+		}
+		dctx, dcancel := context.WithCancel(context.Background())
+		dcancel()
+		if _, _, err := locker.TryWithDeadline(dctx, lck, time.Second); !errors.Is(err, context.Canceled) {
+			t.Fatal(err)
+		}
+		time.AfterFunc(time.Millisecond*100, cancel)
+		ctx2, cancel2, err := locker.TryWithDeadline(context.Background(), lck, time.Second*3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ctx.Err() == nil {
+			t.Fatal("the previous lock should be released")
+		}
+		time.Sleep(time.Millisecond * 100)
+		if ctx2.Err() != nil {
+			t.Fatal("the lock should not be bounded by the wait duration")
+		}
+		cancel2()
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_ForceWithContextThenTryWithContext(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.timeout = time.Second
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.ForceWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := locker.TryWithContext(ctx, lck); err != ErrNotLocked {
+			t.Fatal(err)
+		}
+		cancel()
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_TryWithContext_MultipleLocker(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		lockers := make([]*locker, 10)
+		sum := make([]int, len(lockers))
+		for i := 0; i < len(lockers); i++ {
+			lockers[i] = newLocker(t, noLoop, setpx, nocsc)
+			lockers[i].timeout = time.Second
+		}
+		defer func() {
+			for _, locker := range lockers {
+				locker.Close()
+			}
+		}()
+		cnt := 1000
+		lck := strconv.Itoa(rand.Int())
+		ctx := context.Background()
+		var wg sync.WaitGroup
+		wg.Add(len(lockers))
+		for i, l := range lockers {
+			go func(i int, l *locker) {
+				defer wg.Done()
+				for j := 0; j < cnt; j++ {
+					for {
+						_, cancel, err := l.TryWithContext(ctx, lck)
+						if err != nil && err != ErrNotLocked {
+							t.Error(err)
+							return
+						}
+						if cancel != nil {
+							cancel()
+							sum[i]++
+							break
+						}
+						time.Sleep(time.Millisecond)
+					}
+				}
+			}(i, l)
+		}
+		wg.Wait()
+		for i, s := range sum {
+			if s != cnt {
+				t.Fatalf("unexpected sum %v %v %v", i, s, cnt)
+			}
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_WithContext_MissingPeers(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		for i := 0; i < 100; i++ {
+			ctx, cancel, err := locker.WithContext(context.Background(), lck)
+			if err != nil {
+				t.Fatal(err)
+			}
+			locker.mu.RLock()
+			g := locker.gates[lck]
+			locker.mu.RUnlock()
+			var wg sync.WaitGroup
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+				go func() {
+					if _, _, err := locker.WithContext(ctx, lck); !errors.Is(err, context.Canceled) {
+						t.Error(err)
+					}
+					wg.Done()
+				}()
+			}
+			cancel()
+			select {
+			case g.ch <- struct{}{}:
+			default:
+			}
+			wg.Wait()
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_MajorityUnreachable(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.timeout = time.Nanosecond // every acquisition fails with context.DeadlineExceeded, just like an unreachable redis.
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		if _, _, err := locker.TryWithContext(context.Background(), lck); err != ErrMajorityUnreachable {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if _, _, err := locker.WithContext(context.Background(), lck); err != ErrMajorityUnreachable {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if _, _, err := locker.ForceWithContext(context.Background(), lck); err != ErrMajorityUnreachable {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+type nopClient struct {
+	rueidis.Client
+}
+
+//gocyclo:ignore
+func TestLocker_FairQueue_Gate(t *testing.T) {
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, FairQueue: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := l.(*locker)
+	ctx := context.Background()
+
+	head := &ticket{ch: make(chan struct{}, 1)}
+	g, waited, err := m.waitgate(ctx, "n", head)
+	if g == nil || waited || err != nil {
+		t.Fatalf("unexpected waitgate result %v %v %v", g, waited, err)
+	}
+
+	order := make(chan int, 5)
+	tickets := make([]*ticket, 5)
+	for i := range tickets {
+		tickets[i] = &ticket{ch: make(chan struct{}, 1)}
+		go func(i int) {
+			if g, _, err := m.waitgate(ctx, "n", tickets[i]); err == nil && g != nil {
+				order <- i
+			}
+		}(i)
+		for m.Pending("n") != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// the head fails to acquire the lock and retries, it should not lose its place.
+	m.release("n", g, true)
+	if g2, _, err := m.waitgate(ctx, "n", head); g2 != g || err != nil {
+		t.Fatalf("the head should be granted again %v", err)
+	}
+	select {
+	case i := <-order:
+		t.Fatalf("unexpected waiter %d", i)
+	default:
+	}
+
+	// the head acquires the lock, and then waiters are granted in their arrival order.
+	m.mu.Lock()
+	m.dequeue(g, head, false)
+	m.mu.Unlock()
+	for i := range tickets {
+		m.release("n", g, true)
+		if got := <-order; got != i {
+			t.Fatalf("unexpected waiter %d, expected %d", got, i)
+		}
+		m.mu.Lock()
+		m.dequeue(g, tickets[i], false)
+		m.mu.Unlock()
+	}
+	m.release("n", g, true)
+	if _, ok := m.gates["n"]; ok {
+		t.Fatalf("gate should be deleted")
+	}
+
+	t.Run("Cancel", func(t *testing.T) {
+		first := &ticket{ch: make(chan struct{}, 1)}
+		g, _, _ := m.waitgate(ctx, "c", first)
+		cctx, cancel := context.WithCancel(ctx)
+		second := &ticket{ch: make(chan struct{}, 1)}
+		canceled := make(chan error)
+		go func() {
+			_, _, err := m.waitgate(cctx, "c", second)
+			canceled <- err
+		}()
+		for m.Pending("c") != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		third := &ticket{ch: make(chan struct{}, 1)}
+		granted := make(chan *gate)
+		go func() {
+			g, _, _ := m.waitgate(ctx, "c", third)
+			granted <- g
+		}()
+		for m.Pending("c") != 2 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		if err := <-canceled; err != context.Canceled {
+			t.Fatalf("unexpected err %v", err)
+		}
+		// the gate handed over to the leaving head should be forwarded to the next waiter.
+		m.mu.Lock()
+		first.ch <- struct{}{}
+		m.dequeue(g, first, true)
+		m.mu.Unlock()
+		if <-granted != g {
+			t.Fatalf("the third waiter should be granted")
+		}
+		if len(g.q) != 1 || g.q[0] != third {
+			t.Fatalf("unexpected queue %v", g.q)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		m.waitgate(ctx, "d", &ticket{ch: make(chan struct{}, 1)})
+		closed := make(chan error)
+		go func() {
+			_, _, err := m.waitgate(ctx, "d", &ticket{ch: make(chan struct{}, 1)})
+			closed <- err
+		}()
+		for m.Pending("d") != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		l.Close()
+		if err := <-closed; err != ErrLockerClosed {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
+
+func TestLocker_Gate_Force(t *testing.T) {
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	m := l.(*locker)
+	ctx := context.Background()
+
+	g, _, _ := m.waitgate(ctx, "f", nil)
+	if g2, own := m.forcegate("f"); g2 != g || own {
+		t.Fatalf("the held gate should not be owned by the force")
+	}
+	m.release("f", g, false)
+	wctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	if _, _, err := m.waitgate(wctx, "f", nil); err != context.DeadlineExceeded {
+		t.Fatalf("the gate should not be handed over by the force %v", err)
+	}
+	cancel()
+	m.release("f", g, true)
+	if len(m.gates) != 0 {
+		t.Fatalf("gate should be deleted")
+	}
+
+	g, own := m.forcegate("f")
+	if !own {
+		t.Fatalf("the new gate should be owned by the force")
+	}
+	granted := make(chan *gate)
+	go func() {
+		g, _, _ := m.waitgate(ctx, "f", nil)
+		granted <- g
+	}()
+	for m.Pending("f") != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	m.release("f", g, own)
+	if <-granted != g {
+		t.Fatalf("the gate should be handed over to the waiter")
+	}
+	if g2, own := m.forcegate("f"); g2 != g || own {
+		t.Fatalf("the held gate should not be owned by the force")
+	}
+	m.release("f", g, true)
+	if g2, _, _ := m.waitgate(ctx, "f", nil); g2 != g {
+		t.Fatalf("the gate only used by the force should be taken without waiting")
+	}
+}
+
+func TestLocker_Pending_Waiters(t *testing.T) {
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	m := l.(*locker)
+	ctx := context.Background()
+
+	g, _, _ := m.waitgate(ctx, "p", nil)
+	wctx, cancel := context.WithCancel(ctx)
+	granted := make(chan *gate, 5)
+	for i := 0; i < 5; i++ {
+		go func(i int) {
+			wctx := wctx
+			if i != 0 {
+				wctx = ctx
+			}
+			g, _, _ := m.waitgate(wctx, "p", nil)
+			granted <- g
+		}(i)
+	}
+	for m.Pending("p") != 5 {
+		time.Sleep(time.Millisecond)
+	}
+	// a try of the ForceWithContext uses the gate without waiting for it.
+	fg, own := m.forcegate("p")
+	if own || m.Pending("p") != 5 {
+		t.Fatalf("unexpected pending %v", m.Pending("p"))
+	}
+	m.release("p", fg, own)
+
+	cancel()
+	if <-granted != nil {
+		t.Fatal("the canceled waiter should not be granted")
+	}
+	if n := m.Pending("p"); n != 4 {
+		t.Fatalf("unexpected pending %v", n)
+	}
+	for i := 3; i >= 0; i-- {
+		m.release("p", g, true)
+		if g = <-granted; g == nil {
+			t.Fatal("the waiter should be granted")
+		}
+		if n := m.Pending("p"); n != i {
+			t.Fatalf("unexpected pending %v, expected %v", n, i)
+		}
+	}
+	m.release("p", g, true)
+	if n := m.Pending("p"); n != 0 || len(m.gates) != 0 {
+		t.Fatalf("unexpected pending %v", n)
+	}
+}
+
+func TestLocker_Gate_Stress(t *testing.T) {
+	for _, fair := range []bool{false, true} {
+		l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, FairQueue: fair})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := l.(*locker)
+		var holders, overlaps int32
+		var wg sync.WaitGroup
+		for i := 0; i < 200; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var tk *ticket
+				if fair {
+					tk = &ticket{ch: make(chan struct{}, 1)}
+				}
+				for j := 0; j < 10; j++ {
+					if i%10 == 0 { // forced tries share the gate without waiting.
+						g, own := m.forcegate("s")
+						time.Sleep(time.Microsecond)
+						m.release("s", g, own)
+						continue
+					}
+					timeout := 10 * time.Second
+					if i%3 == 0 { // impatient waiters leave with or without the gate handed over.
+						timeout = time.Duration(1+j%3) * time.Millisecond
+					}
+					ctx, cancel := context.WithTimeout(context.Background(), timeout)
+					g, _, err := m.waitgate(ctx, "s", tk)
+					cancel()
+					if err != nil {
+						if i%3 != 0 {
+							t.Errorf("the waiter is not woken up after the gate is released %v", err)
+						}
+						continue
+					}
+					if atomic.AddInt32(&holders, 1) > 1 {
+						atomic.AddInt32(&overlaps, 1)
+					}
+					time.Sleep(10 * time.Microsecond)
+					atomic.AddInt32(&holders, -1)
+					m.mu.Lock()
+					m.dequeue(g, tk, false)
+					m.mu.Unlock()
+					m.release("s", g, true)
+				}
+			}(i)
+		}
+		wg.Wait()
+		if overlaps != 0 {
+			t.Fatalf("the gate is held by %d waiters at the same time", overlaps)
+		}
+		m.mu.RLock()
+		if len(m.gates) != 0 {
+			t.Fatalf("gate should be deleted")
+		}
+		m.mu.RUnlock()
+		l.Close()
+	}
+}
+
+func TestLocker_KeepGates(t *testing.T) {
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, KeepGates: 2, FairQueue: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := l.(*locker)
+	ctx := context.Background()
+
+	g1, _, _ := m.waitgate(ctx, "a", nil)
+	g1.ch <- struct{}{} // a stale signal
+	m.release("a", g1, true)
+	if len(m.gates) != 0 || len(m.kept.names) != 1 {
+		t.Fatalf("gate should be kept instead of being used")
+	}
+	if g2, _, _ := m.waitgate(ctx, "a", nil); g2 != g1 || len(g1.ch) != 0 {
+		t.Fatalf("kept gate should be reused without stale signals")
+	}
+	if len(m.kept.names) != 0 {
+		t.Fatalf("reused gate should not be kept")
+	}
+	m.release("a", g1, true)
+
+	// a retrying waiter of the fair queue should enqueue again to the reused gate.
+	tk := &ticket{ch: make(chan struct{}, 1)}
+	g3 := m.trygate("b")
+	m.release("b", g3, true)
+	g3, _, _ = m.waitgate(ctx, "b", tk)
+	m.release("b", g3, true)
+	if g4, _, _ := m.waitgate(ctx, "b", tk); g4 != g3 || len(g4.q) != 1 || g4.q[0] != tk {
+		t.Fatalf("unexpected queue %v", g4.q)
+	}
+	m.release("b", g3, true)
+
+	g5, own := m.forcegate("c")
+	m.release("c", g5, own)
+	if len(m.kept.names) != 2 || m.kept.names["a"] != nil {
+		t.Fatalf("the least recently used gate should be dropped %v", m.kept.names)
+	}
+
+	l.Close()
+	if len(m.kept.names) != 0 || m.kept.order.Len() != 0 {
+		t.Fatalf("kept gates should be dropped by Close")
+	}
+
+	if l, _ := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}}); l.(*locker).kept != nil {
+		t.Fatalf("gates should not be kept by default")
+	}
+}
+
+func TestLocker_FairQueue(t *testing.T) {
+	locker, err := NewLocker(LockerOption{
+		ClientOption: rueidis.ClientOption{InitAddress: address},
+		FairQueue:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer locker.Close()
+
+	lck := strconv.Itoa(rand.Int())
+	_, cancel, err := locker.WithContext(context.Background(), lck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := make(chan int, 10)
+	for i := 0; i < cap(order); i++ {
+		go func(i int) {
+			_, cancel, err := locker.WithContext(context.Background(), lck)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			cancel()
+		}(i)
+		for locker.Pending(lck) != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	cancel()
+	for i := 0; i < cap(order); i++ {
+		if got := <-order; got != i {
+			t.Fatalf("unexpected waiter %d, expected %d", got, i)
+		}
+	}
+}
+
+func TestLocker_Events(t *testing.T) {
+	next := func(t *testing.T, events <-chan LockEvent, name string) LockEventType {
+		select {
+		case e := <-events:
+			if e.Name != name || e.Time.IsZero() {
+				t.Fatalf("unexpected event %v", e)
+			}
+			return e.Type
+		case <-time.After(time.Second * 5):
+			t.Fatal("no event")
+		}
+		return 0
+	}
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.validity = time.Second * 2
+		locker.interval = time.Millisecond * 500
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		_, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ := next(t, locker.Events(), lck); typ != LockAcquired {
+			t.Fatalf("unexpected event %v", typ)
+		}
+		if typ := next(t, locker.Events(), lck); typ != LockExtended {
+			t.Fatalf("unexpected event %v", typ)
+		}
+		cancel()
+		for typ := next(t, locker.Events(), lck); typ != LockReleased; typ = next(t, locker.Events(), lck) {
+			if typ != LockExtended {
+				t.Fatalf("unexpected event %v", typ)
+			}
+		}
+
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locker2 := newLocker(t, noLoop, setpx, nocsc)
+		defer locker2.Close()
+		_, cancel2, err := locker2.ForceWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cancel2()
+		<-ctx.Done()
+		cancel()
+		for typ := next(t, locker.Events(), lck); typ != LockLost; typ = next(t, locker.Events(), lck) {
+			if typ != LockAcquired && typ != LockExtended {
+				t.Fatalf("unexpected event %v", typ)
+			}
+		}
+
+		locker.Close()
+		for range locker.Events() {
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_NoCancelOnLoss(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		lost := make(chan string, 1)
+		impl, err := NewLocker(LockerOption{
+			ClientOption:   rueidis.ClientOption{InitAddress: address, DisableCache: nocsc},
+			NoLoopTracking: noLoop,
+			FallbackSETPX:  setpx,
+			ExtendInterval: time.Millisecond * 500,
+			NoCancelOnLoss: true,
+			OnLockLost: func(name string) {
+				lost <- name
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		locker := impl.(*locker)
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locker2 := newLocker(t, noLoop, setpx, nocsc)
+		defer locker2.Close()
+		_, cancel2, err := locker2.ForceWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cancel2()
+		select {
+		case name := <-lost:
+			if name != lck {
+				t.Fatalf("unexpected name %v", name)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("OnLockLost is not called")
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("unexpected context canceled %v", ctx.Err())
+		}
+		cancel()
+		if ctx.Err() == nil {
+			t.Fatal("context is not canceled by its cancel function")
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_OnExtendPartial(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		partial := make(chan int32, 10)
+		impl, err := NewLocker(LockerOption{
+			ClientOption:   rueidis.ClientOption{InitAddress: address, DisableCache: nocsc},
+			NoLoopTracking: noLoop,
+			FallbackSETPX:  setpx,
+			ExtendInterval: time.Millisecond * 500,
+			OnExtendPartial: func(name string, acked int32) {
+				partial <- acked
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		locker := impl.(*locker)
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cancel()
+		if err := locker.client.Do(context.Background(), locker.client.B().Set().Key(locker.keyname(lck, 0)).Value("other").Build()).Error(); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case acked := <-partial:
+			if acked != locker.totalcnt-1 {
+				t.Fatalf("unexpected acked %v", acked)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("OnExtendPartial is not called")
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("unexpected context canceled %v", ctx.Err())
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_OnExtendPartial_Acked(t *testing.T) {
+	var called []int32
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, KeyMajority: 3, OnExtendPartial: func(name string, acked int32) {
+		if name != "a" {
+			t.Fatalf("unexpected name %v", name)
+		}
+		called = append(called, acked)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for released := int32(0); released <= 5; released++ {
+		l.(*locker).partial("a", released)
+	}
+	if len(called) != 2 || called[0] != 4 || called[1] != 3 {
+		t.Fatalf("unexpected calls %v", called)
+	}
+	if l, _ = NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}}); l != nil {
+		l.(*locker).partial("a", 1) // no panic without the OnExtendPartial
+	}
+}
+
+// BenchmarkLocker_TrackingKeys reports how many keys are tracked by the redis server for client side caching per held lock,
+// and how many of them are left after all locks are released. The tracked keys should be bounded by the held locks.
+func BenchmarkLocker_TrackingKeys(b *testing.B) {
+	trackingKeys := func(b *testing.B, client rueidis.Client) int64 {
+		info, err := client.Do(context.Background(), client.B().Info().Section("stats").Build()).ToString()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, line := range strings.Split(info, "\r\n") {
+			if v, ok := strings.CutPrefix(line, "tracking_total_keys:"); ok {
+				n, _ := strconv.ParseInt(v, 10, 64)
+				return n
+			}
+		}
+		b.Fatal("tracking_total_keys not found")
+		return 0
+	}
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			locker := newLocker(b, true, false, false)
+			defer locker.Close()
+			cancels := make([]context.CancelFunc, n)
+			var held, left int64
+			for i := 0; i < b.N; i++ {
+				before := trackingKeys(b, locker.client)
+				for j := range cancels {
+					_, cancel, err := locker.WithContext(context.Background(), strconv.Itoa(rand.Int()))
+					if err != nil {
+						b.Fatal(err)
+					}
+					cancels[j] = cancel
+				}
+				held += trackingKeys(b, locker.client) - before
+				for _, cancel := range cancels {
+					cancel()
+				}
+				left += trackingKeys(b, locker.client) - before
+			}
+			b.ReportMetric(float64(held)/float64(b.N*n), "keys/lock")
+			b.ReportMetric(float64(left)/float64(b.N), "left-keys/op")
+		})
+	}
+}
+
+func TestLocker_Events_Dropped(t *testing.T) {
+	locker := &locker{gates: make(map[string]*gate), events: make(chan LockEvent, 1), clock: realClock{}, shared: true}
+	locker.emit(LockAcquired, "a")
+	locker.emit(LockReleased, "a") // dropped without blocking
+	locker.Close()
+	locker.emit(LockAcquired, "a") // ignored after closed
+	if e := <-locker.Events(); e.Type != LockAcquired || e.Name != "a" {
+		t.Fatalf("unexpected event %v", e)
+	}
+	if _, ok := <-locker.Events(); ok {
+		t.Fatal("events should be closed")
+	}
+	if s := LockReleased.String(); s != "released" {
+		t.Fatalf("unexpected string %v", s)
+	}
+}
+
+func TestLocker_Backoff(t *testing.T) {
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	m := l.(*locker)
+	if m.retry != 5*time.Millisecond {
+		t.Fatalf("unexpected default retry interval %v", m.retry)
+	}
+	clk := newFakeClock()
+	m.clock = clk
+	m.retry = time.Second
+
+	done := make(chan error)
+	go func() { done <- m.backoff(context.Background(), make(chan struct{})) }()
+	clk.waitTimers(t, 1)
+	clk.Advance(time.Second - time.Nanosecond)
+	select {
+	case err := <-done:
+		t.Fatalf("backoff should wait for at least the retry interval %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clk.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	ch := make(chan struct{})
+	go func() { done <- m.backoff(context.Background(), ch) }()
+	clk.waitTimers(t, 1)
+	close(ch)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- m.backoff(ctx, make(chan struct{})) }()
+	clk.waitTimers(t, 1)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	m.retry = -1
+	if err := m.backoff(ctx, make(chan struct{})); err != nil {
+		t.Fatalf("a negative retry interval should not wait %v", err)
+	}
+}
+
+type countingClient struct {
+	rueidis.Client
+	calls *int32
+}
+
+func (c countingClient) Do(ctx context.Context, cmd rueidis.Completed) rueidis.RedisResult {
+	atomic.AddInt32(c.calls, 1)
+	return c.Client.Do(ctx, cmd)
+}
+
+func TestLocker_MinRetryInterval(t *testing.T) {
+	holder := newLocker(t, false, false, true)
+	defer holder.Close()
+	var calls int32
+	locker, err := NewLocker(LockerOption{
+		ClientOption:     rueidis.ClientOption{InitAddress: address, DisableCache: true},
+		MinRetryInterval: 50 * time.Millisecond,
+		ClientBuilder: func(option rueidis.ClientOption) (rueidis.Client, error) {
+			client, err := rueidis.NewClient(option)
+			return countingClient{Client: client, calls: &calls}, err
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer locker.Close()
+
+	lck := strconv.Itoa(rand.Int())
+	_, cancel, err := holder.WithContext(context.Background(), lck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if _, _, err = locker.TryWithDeadline(context.Background(), lck, 300*time.Millisecond); err != ErrNotLocked {
+		t.Fatalf("unexpected err %v", err)
+	}
+	// each retry sends the EVALSHA, and also the EVAL on the first NOSCRIPT, of the first key, which is held by the holder.
+	if calls := atomic.LoadInt32(&calls); calls > 2*(300/50+1) {
+		t.Fatalf("the lock should not be retried more often than the MinRetryInterval: %d calls", calls)
+	}
+}
+
+func TestLocker_Pending(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		if n := locker.Pending(lck); n != 0 {
+			t.Fatalf("unexpected pending %v", n)
+		}
+		_, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := locker.Pending(lck); n != 0 {
+			t.Fatalf("unexpected pending %v", n)
+		}
+		ctx, cancelWait := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, _, err := locker.WithContext(ctx, lck); !errors.Is(err, context.Canceled) {
+					t.Error(err)
+				}
+			}()
+		}
+		for locker.Pending(lck) != 3 {
+			time.Sleep(time.Millisecond)
+		}
+		cancelWait()
+		wg.Wait()
+		if n := locker.Pending(lck); n != 0 {
+			t.Fatalf("unexpected pending %v", n)
+		}
+		cancel()
+		if n := locker.Pending(lck); n != 0 {
+			t.Fatalf("unexpected pending %v", n)
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_WithContext_Cleanup(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		_, cancel1, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locker.mu.Lock()
+		locker.gates[lck].w--
+		locker.mu.Unlock()
+		ctx2, cancel2 := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Second)
+			cancel2()
 			cancel1()
 		}()
 		if _, _, err := locker.WithContext(ctx2, lck); !errors.Is(err, context.Canceled) {
 			t.Fatal(err)
 		}
-		locker.mu.Lock()
-		keys := len(locker.gates)
-		locker.mu.Unlock()
-		if keys != 0 {
-			t.Fatalf("unexpected length %v", keys)
+		locker.mu.Lock()
+		keys := len(locker.gates)
+		locker.mu.Unlock()
+		if keys != 0 {
+			t.Fatalf("unexpected length %v", keys)
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_Close(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		locker := newLocker(t, noLoop, setpx, nocsc)
+
+		lck := strconv.Itoa(rand.Int())
+		ctx, _, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wg := sync.WaitGroup{}
+		wg.Add(10)
+		for i := 0; i < 10; i++ {
+			go func() {
+				if _, _, err := locker.WithContext(context.Background(), lck); err != ErrLockerClosed {
+					t.Error(err)
+				}
+				wg.Done()
+			}()
+		}
+		time.Sleep(time.Second)
+		locker.Close()
+		wg.Wait()
+		<-ctx.Done()
+		if err := ctx.Err(); !errors.Is(err, context.Canceled) {
+			t.Fatal(err)
+		}
+		if _, _, err := locker.WithContext(context.Background(), lck); err != ErrLockerClosed {
+			t.Error(err)
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_CloseRelease(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		client := newClient(t)
+		defer client.Close()
+
+		locker := newLocker(t, noLoop, setpx, nocsc)
+
+		lck1 := strconv.Itoa(rand.Int())
+		lck2 := strconv.Itoa(rand.Int())
+		ctx1, _, err := locker.WithContext(context.Background(), lck1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx2, _, err := locker.WithContext(context.Background(), lck2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a key taken over by others must not be deleted.
+		other := locker.keyname(lck2, 0)
+		if err := client.Do(context.Background(), client.B().Set().Key(other).Value("other").Build()).Error(); err != nil {
+			t.Fatal(err)
+		}
+		defer client.Do(context.Background(), client.B().Del().Key(other).Build())
+
+		waited := make(chan error)
+		go func() {
+			_, _, err := locker.WithContext(context.Background(), lck1)
+			waited <- err
+		}()
+		time.Sleep(time.Millisecond * 100)
+
+		if err := locker.CloseRelease(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if err := <-waited; err != ErrLockerClosed {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if ctx1.Err() == nil || ctx2.Err() == nil {
+			t.Fatal("contexts of the released locks are not canceled")
+		}
+		for _, lck := range []string{lck1, lck2} {
+			for i := int32(0); i < locker.totalcnt; i++ {
+				key := locker.keyname(lck, i)
+				v, err := client.Do(context.Background(), client.B().Get().Key(key).Build()).ToString()
+				if key == other {
+					if v != "other" {
+						t.Fatalf("the key owned by others is released %v %v", v, err)
+					}
+				} else if !rueidis.IsRedisNil(err) {
+					t.Fatalf("the key %v is not released %v %v", key, v, err)
+				}
+			}
+		}
+		if _, _, err := locker.WithContext(context.Background(), lck1); err != ErrLockerClosed {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	for _, nocsc := range []bool{false, true} {
+		t.Run("Tracking Loop", func(t *testing.T) {
+			test(t, false, false, nocsc)
+		})
+		t.Run("Tracking NoLoop", func(t *testing.T) {
+			test(t, true, false, nocsc)
+		})
+		t.Run("SET PX", func(t *testing.T) {
+			test(t, true, true, nocsc)
+		})
+	}
+}
+
+func TestLocker_CloseRelease_Timeout(t *testing.T) {
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := l.(*locker)
+	blocked := make(chan struct{})
+	defer close(blocked)
+	m.hold(make(chan struct{}), func() { <-blocked })
+	done := make(chan struct{})
+	m.hold(done, func() { t.Fatal("unlock of a released lock should not be called") })
+	m.unhold(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := l.CloseRelease(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if m.held != nil {
+		t.Fatal("held locks should be dropped")
+	}
+	m.hold(make(chan struct{}), func() {}) // no panic after closed
+	if _, _, err := l.WithContext(context.Background(), "a"); err != ErrLockerClosed {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestLocker_Reentrant(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		impl, err := NewLocker(LockerOption{
+			ClientOption:   rueidis.ClientOption{InitAddress: address, DisableCache: nocsc},
+			NoLoopTracking: noLoop,
+			FallbackSETPX:  setpx,
+			Reentrant:      true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		locker := impl.(*locker)
+		defer locker.Close()
+		locker2 := newLocker(t, noLoop, setpx, nocsc)
+		defer locker2.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
 		}
+		ctx1, cancel1, err := locker.TryWithContext(ctx, lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx2, cancel2, err := locker.WithContext(ctx, lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := locker.TryWithContext(context.Background(), lck); err != ErrNotLocked {
+			t.Fatalf("unexpected err %v", err)
+		}
+		cancel()
+		cancel1()
+		if ctx.Err() != nil || ctx1.Err() == nil || ctx2.Err() != nil {
+			t.Fatal("the lock should be held until all the acquisitions are released")
+		}
+		if _, _, err := locker2.TryWithContext(context.Background(), lck); err != ErrNotLocked {
+			t.Fatalf("unexpected err %v", err)
+		}
+		cancel2()
+		if ctx.Err() == nil || ctx2.Err() == nil {
+			t.Fatal("the lock should be released")
+		}
+		if _, _, err := locker.TryWithContext(ctx2, lck); err == nil {
+			t.Fatal("a released lock should not be re-acquired")
+		}
+		_, cancel3, err := locker2.TryWithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel3()
 	}
 	for _, nocsc := range []bool{false, true} {
 		t.Run("Tracking Loop", func(t *testing.T) {
@@ -649,35 +2789,92 @@ func TestLocker_WithContext_Cleanup(t *testing.T) {
 	}
 }
 
-func TestLocker_Close(t *testing.T) {
+func TestLocker_Reentrant_Count(t *testing.T) {
+	for _, reentrant := range []bool{false, true} {
+		l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, Reentrant: reentrant})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := l.(*locker)
+		unlocked := 0
+		ctx, cancel := m.reentrant(context.Background(), "a", func() { unlocked++ })
+		if _, _, ok := m.reentered(ctx, "b"); ok {
+			t.Fatal("other names should not be reentered")
+		}
+		if _, _, ok := m.reentered(context.Background(), "a"); ok {
+			t.Fatal("unrelated contexts should not be reentered")
+		}
+		rctx, rcancel, ok := m.reentered(ctx, "a")
+		if ok != reentrant {
+			t.Fatalf("unexpected reentered %v", ok)
+		}
+		if !reentrant {
+			cancel()
+			if unlocked != 1 {
+				t.Fatalf("unexpected unlocked %v", unlocked)
+			}
+			continue
+		}
+		cancel()
+		cancel()
+		if unlocked != 0 || rctx.Err() != nil {
+			t.Fatalf("unexpected unlocked %v", unlocked)
+		}
+		rcancel()
+		rcancel()
+		if unlocked != 1 || rctx.Err() == nil {
+			t.Fatalf("unexpected unlocked %v", unlocked)
+		}
+		if _, _, ok := m.reentered(rctx, "a"); ok {
+			t.Fatal("a released lock should not be reentered")
+		}
+	}
+}
+
+func TestLocker_WithContexts(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
 		locker := newLocker(t, noLoop, setpx, nocsc)
+		defer locker.Close()
+		locker2 := newLocker(t, noLoop, setpx, nocsc)
+		defer locker2.Close()
 
-		lck := strconv.Itoa(rand.Int())
-		ctx, _, err := locker.WithContext(context.Background(), lck)
+		lck1, lck2, lck3 := strconv.Itoa(rand.Int()), strconv.Itoa(rand.Int()), strconv.Itoa(rand.Int())
+		ctx, cancel, err := locker.WithContexts(context.Background(), lck1, lck2, lck1)
 		if err != nil {
 			t.Fatal(err)
 		}
-
-		wg := sync.WaitGroup{}
-		wg.Add(10)
-		for i := 0; i < 10; i++ {
-			go func() {
-				if _, _, err := locker.WithContext(context.Background(), lck); err != ErrLockerClosed {
-					t.Error(err)
-				}
-				wg.Done()
-			}()
+		for _, lck := range []string{lck1, lck2} {
+			if _, _, err := locker2.TryWithContext(context.Background(), lck); err != ErrNotLocked {
+				t.Fatalf("unexpected err %v", err)
+			}
 		}
-		time.Sleep(time.Second)
-		locker.Close()
-		wg.Wait()
-		<-ctx.Done()
-		if err := ctx.Err(); !errors.Is(err, context.Canceled) {
+
+		_, cancel3, err := locker2.WithContext(context.Background(), lck3)
+		if err != nil {
 			t.Fatal(err)
 		}
-		if _, _, err := locker.WithContext(context.Background(), lck); err != ErrLockerClosed {
-			t.Error(err)
+		acquired := make(chan error, 1)
+		go func() {
+			_, cancel, err := locker2.WithContexts(context.Background(), lck3, lck2)
+			if err == nil {
+				cancel()
+			}
+			acquired <- err
+		}()
+		time.Sleep(time.Millisecond * 100)
+		cancel3()
+		select {
+		case err := <-acquired:
+			t.Fatalf("some locks are still held by others %v", err)
+		case <-time.After(time.Millisecond * 100):
+		}
+
+		cancel()
+		if ctx.Err() == nil {
+			t.Fatal("the context should be canceled")
+		}
+		if err := <-acquired; err != nil {
+			t.Fatal(err)
 		}
 	}
 	for _, nocsc := range []bool{false, true} {
@@ -693,6 +2890,171 @@ func TestLocker_Close(t *testing.T) {
 	}
 }
 
+type recordBatch struct {
+	order    []string
+	attempts []int
+}
+
+func (b *recordBatch) Order(names []string) []string {
+	b.order = append(b.order[:0], SortedBatch{}.Order(names)...)
+	return names
+}
+
+func (b *recordBatch) Backoff(attempt int) time.Duration {
+	b.attempts = append(b.attempts, attempt)
+	return 0
+}
+
+//gocyclo:ignore
+func TestLocker_WithContexts_Batched(t *testing.T) {
+	strategy := &recordBatch{}
+	l, err := NewLocker(LockerOption{Client: nopClient{}, Invalidations: &Invalidations{}, BatchStrategy: strategy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := l.(*locker)
+
+	var calls, released []string
+	var contexts []context.Context
+	var losts []context.CancelFunc
+	busy := map[string]int{}
+	failure := map[string]error{}
+	fake := func(op string) func(ctx context.Context, name string) (context.Context, context.CancelFunc, error) {
+		return func(ctx context.Context, name string) (context.Context, context.CancelFunc, error) {
+			calls = append(calls, op+":"+name)
+			ctx, cancel := context.WithCancel(ctx)
+			if err := failure[name]; err != nil {
+				cancel()
+				return ctx, cancel, err
+			}
+			if busy[name] > 0 {
+				busy[name]--
+				cancel()
+				return ctx, cancel, ErrNotLocked
+			}
+			contexts = append(contexts, ctx)
+			losts = append(losts, cancel)
+			return ctx, func() {
+				cancel()
+				released = append(released, name)
+			}, nil
+		}
+	}
+	batched := func(ctx context.Context, names ...string) (context.Context, context.CancelFunc, error) {
+		calls, released, contexts, losts, strategy.attempts = nil, nil, nil, nil, nil
+		return m.batched(ctx, names, fake("wait"), fake("try"))
+	}
+
+	t.Run("Ordered", func(t *testing.T) {
+		ctx, cancel, err := batched(context.Background(), "c", "a", "b", "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(strategy.order, ",") != "a,b,c" || strings.Join(calls, ",") != "wait:a,try:b,try:c" {
+			t.Fatalf("unexpected order %v %v", strategy.order, calls)
+		}
+		if ctx.Err() != nil || len(released) != 0 {
+			t.Fatal("the locks should be held")
+		}
+		cancel()
+		cancel()
+		if ctx.Err() == nil || strings.Join(released, ",") != "c,b,a" {
+			t.Fatalf("unexpected released %v", released)
+		}
+	})
+
+	t.Run("Lost", func(t *testing.T) {
+		ctx, cancel, err := batched(context.Background(), "a", "b", "c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		losts[1]()
+		if ctx.Err() == nil || contexts[2].Err() == nil || contexts[0].Err() != nil {
+			t.Fatal("losing any of the locks should cancel the context")
+		}
+		cancel()
+		if strings.Join(released, ",") != "c,b,a" {
+			t.Fatalf("unexpected released %v", released)
+		}
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		busy["c"] = 2
+		_, cancel, err := batched(context.Background(), "a", "b", "c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(calls, ",") != "wait:a,try:b,try:c,wait:a,try:b,try:c,wait:a,try:b,try:c" {
+			t.Fatalf("unexpected calls %v", calls)
+		}
+		if strings.Join(released, ",") != "b,a,b,a" {
+			t.Fatalf("the acquired locks should be released before retries %v", released)
+		}
+		if len(strategy.attempts) != 2 || strategy.attempts[0] != 0 || strategy.attempts[1] != 1 {
+			t.Fatalf("unexpected attempts %v", strategy.attempts)
+		}
+		cancel()
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		failure["b"] = ErrMajorityUnreachable
+		defer delete(failure, "b")
+		ctx, _, err := batched(context.Background(), "a", "b", "c")
+		if err != ErrMajorityUnreachable || ctx.Err() == nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if strings.Join(released, ",") != "a" || len(strategy.attempts) != 0 {
+			t.Fatalf("unexpected released %v", released)
+		}
+	})
+
+	t.Run("Context Done", func(t *testing.T) {
+		busy["b"] = 1
+		defer delete(busy, "b")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, _, err := batched(ctx, "a", "b"); err != context.Canceled {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if strings.Join(released, ",") != "a" {
+			t.Fatalf("unexpected released %v", released)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		ctx, cancel, err := batched(context.Background())
+		if err != ErrNoNames || ctx.Err() != context.Canceled || len(calls) != 0 {
+			t.Fatalf("unexpected err %v", err)
+		}
+		cancel()
+	})
+}
+
+func TestSortedBatch(t *testing.T) {
+	if names := (SortedBatch{}).Order([]string{"b", "c", "a"}); strings.Join(names, ",") != "a,b,c" {
+		t.Fatalf("unexpected order %v", names)
+	}
+	desc := SortedBatch{Less: func(a, b string) bool { return a > b }}
+	if names := desc.Order([]string{"b", "c", "a"}); strings.Join(names, ",") != "c,b,a" {
+		t.Fatalf("unexpected order %v", names)
+	}
+	if d := (SortedBatch{}).Backoff(3); d != 0 {
+		t.Fatalf("unexpected backoff %v", d)
+	}
+	s := SortedBatch{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond * 10}
+	for _, c := range []struct {
+		attempt int
+		min     time.Duration
+	}{{0, time.Millisecond}, {1, time.Millisecond * 2}, {3, time.Millisecond * 8}, {4, time.Millisecond * 10}, {100, time.Millisecond * 10}} {
+		if d := s.Backoff(c.attempt); d < c.min || d >= c.min*2 {
+			t.Fatalf("unexpected backoff %v of the attempt %v", d, c.attempt)
+		}
+	}
+	if d := (SortedBatch{MinBackoff: time.Millisecond}).Backoff(10); d < time.Millisecond*64 || d >= time.Millisecond*128 {
+		t.Fatalf("unexpected default max backoff %v", d)
+	}
+}
+
 func TestLocker_RetryErrLockerClosed(t *testing.T) {
 	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
 		locker := newLocker(t, noLoop, setpx, nocsc)
@@ -782,3 +3144,203 @@ func TestLocker_Flush(t *testing.T) {
 		})
 	}
 }
+
+type span struct {
+	name      string
+	wait      time.Duration
+	contended bool
+	acquired  bool
+	ended     bool
+	lost      bool
+	err       error
+}
+
+func (s *span) Acquired(wait time.Duration, contended bool) {
+	s.wait, s.contended, s.acquired = wait, contended, true
+}
+
+func (s *span) End(lost bool, err error) {
+	if s.ended {
+		panic("span ended twice")
+	}
+	s.lost, s.err, s.ended = lost, err, true
+}
+
+type tracer struct {
+	mu    sync.Mutex
+	spans []*span
+}
+
+func (t *tracer) Start(ctx context.Context, name string) (context.Context, LockSpan) {
+	s := &span{name: name}
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return ctx, s
+}
+
+func TestLocker_Tracer(t *testing.T) {
+	test := func(t *testing.T, noLoop, setpx, nocsc bool) {
+		tr := &tracer{}
+		locker := newLocker(t, noLoop, setpx, nocsc)
+		locker.timeout = time.Second
+		locker.tracer = tr
+		defer locker.Close()
+
+		lck := strconv.Itoa(rand.Int())
+		_, cancel, err := locker.WithContext(context.Background(), lck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := locker.TryWithContext(context.Background(), lck); err != ErrNotLocked {
+			t.Fatal(err)
+		}
+		acquired := make(chan context.CancelFunc)
+		go func() {
+			_, cancel, err := locker.WithContext(context.Background(), lck)
+			if err != nil {
+				t.Error(err)
+			}
+			acquired <- cancel
+		}()
+		time.Sleep(time.Millisecond * 100)
+		cancel()
+		cancel()
+		cancel = <-acquired
+
+		client := newClient(t)
+		defer client.Close()
+		for i := int32(0); i < locker.totalcnt; i++ {
+			if err := client.Do(context.Background(), client.B().Del().Key(keyname(locker.prefix, lck, i)).Build()).Error(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		time.Sleep(time.Millisecond * 100)
+		cancel()
+
+		if len(tr.spans) != 3 {
+			t.Fatalf("unexpected spans %v", tr.spans)
+		}
+		if s := tr.spans[0]; s.name != lck || !s.acquired || s.contended || !s.ended || s.lost || s.err != nil {
+			t.Fatalf("unexpected span %v", *s)
+		}
+		if s := tr.spans[1]; s.acquired || !s.ended || s.err != ErrNotLocked {
+			t.Fatalf("unexpected span %v", *s)
+		}
+		if s := tr.spans[2]; !s.acquired || !s.contended || s.wait < time.Millisecond*100 || !s.ended || !s.lost || s.err != nil {
+			t.Fatalf("unexpected span %v", *s)
+		}
+	}
+	t.Run("Tracking Loop", func(t *testing.T) {
+		test(t, false, false, false)
+	})
+	t.Run("Tracking NoLoop", func(t *testing.T) {
+		test(t, true, false, false)
+	})
+	t.Run("SET PX", func(t *testing.T) {
+		test(t, true, true, false)
+	})
+}
+
+type fakeClock struct {
+	now    time.Time
+	timers []*fakeTimer
+	mu     sync.Mutex
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) timer {
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) timer {
+	t := &fakeTimer{clock: c, fn: f}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d and fires the timers that are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	for _, t := range c.timers {
+		if t.active && !t.at.After(c.now) {
+			t.active = false
+			due = append(due, t)
+		}
+	}
+	now := c.now
+	c.mu.Unlock()
+	for _, t := range due {
+		if t.fn != nil {
+			go t.fn()
+		} else {
+			select {
+			case t.ch <- now:
+			default:
+			}
+		}
+	}
+}
+
+// waitTimers waits until there are n active channel timers, which means the monitoring goroutines are waiting for the clock.
+func (c *fakeClock) waitTimers(t *testing.T, n int) {
+	for start := time.Now(); time.Since(start) < time.Second*5; time.Sleep(time.Millisecond) {
+		c.mu.Lock()
+		active := 0
+		for _, tm := range c.timers {
+			if tm.active && tm.fn == nil {
+				active++
+			}
+		}
+		c.mu.Unlock()
+		if active == n {
+			return
+		}
+	}
+	t.Fatalf("timers are not active")
+}
+
+type fakeTimer struct {
+	clock  *fakeClock
+	at     time.Time
+	ch     chan time.Time
+	fn     func()
+	active bool
+}
+
+func (t *fakeTimer) Chan() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.active
+	t.active = false
+	return active
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.active
+	if t.at.IsZero() {
+		t.clock.timers = append(t.clock.timers, t)
+	}
+	t.at = t.clock.now.Add(d)
+	t.active = true
+	return active
+}
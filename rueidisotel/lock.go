@@ -0,0 +1,59 @@
+package rueidisotel
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/redis/rueidis/rueidislock"
+)
+
+var _ rueidislock.Tracer = (*locktracer)(nil)
+
+// NewLockTracer creates a rueidislock.Tracer which can be set to rueidislock.LockerOption.Tracer.
+// It starts a "rueidislock.acquire" span for each lock and ends the span when the lock is released.
+// The following attributes are recorded:
+// - rueidislock.name: the lock name
+// - rueidislock.contended: whether the lock was held by others and had to be waited
+// - rueidislock.wait: the wait duration in seconds before the lock is acquired
+// - rueidislock.lost: whether the lock was lost before it is canceled
+func NewLockTracer(opts ...Option) (rueidislock.Tracer, error) {
+	oclient, err := newClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &locktracer{tracer: oclient.tracer, tAttrs: oclient.tAttrs}, nil
+}
+
+type locktracer struct {
+	tracer trace.Tracer
+	tAttrs trace.SpanStartEventOption
+}
+
+func (o *locktracer) Start(ctx context.Context, lock string) (context.Context, rueidislock.LockSpan) {
+	ctx, span := o.tracer.Start(ctx, "rueidislock.acquire", trace.WithAttributes(attribute.String("rueidislock.name", lock)), o.tAttrs)
+	return ctx, &lockspan{span: span}
+}
+
+type lockspan struct {
+	span trace.Span
+}
+
+func (s *lockspan) Acquired(wait time.Duration, contended bool) {
+	s.span.AddEvent("acquired")
+	s.span.SetAttributes(attribute.Bool("rueidislock.contended", contended), attribute.Float64("rueidislock.wait", wait.Seconds()))
+}
+
+func (s *lockspan) End(lost bool, err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	} else {
+		s.span.SetAttributes(attribute.Bool("rueidislock.lost", lost))
+		s.span.SetStatus(codes.Ok, "")
+	}
+	s.span.End()
+}
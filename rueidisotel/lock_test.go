@@ -0,0 +1,73 @@
+package rueidisotel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/redis/rueidis/rueidislock"
+)
+
+func TestNewLockTracer(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tracerProvider := trace.NewTracerProvider(trace.WithSyncer(exp))
+
+	tracer, err := NewLockTracer(WithTracerProvider(tracerProvider), TraceAttrs(attribute.String("any", "label")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := tracer.Start(context.Background(), "a")
+	span.Acquired(time.Second, true)
+	span.End(true, nil)
+
+	_, span = tracer.Start(context.Background(), "b")
+	span.End(false, rueidislock.ErrNotLocked)
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+	for i, expected := range []struct {
+		attrs []attribute.KeyValue
+		code  codes.Code
+	}{
+		{
+			attrs: []attribute.KeyValue{
+				attribute.String("rueidislock.name", "a"),
+				attribute.String("any", "label"),
+				attribute.Bool("rueidislock.contended", true),
+				attribute.Float64("rueidislock.wait", 1),
+				attribute.Bool("rueidislock.lost", true),
+			},
+			code: codes.Ok,
+		},
+		{
+			attrs: []attribute.KeyValue{
+				attribute.String("rueidislock.name", "b"),
+				attribute.String("any", "label"),
+			},
+			code: codes.Error,
+		},
+	} {
+		if spans[i].Name != "rueidislock.acquire" {
+			t.Fatalf("unexpected span name %v", spans[i].Name)
+		}
+		if spans[i].Status.Code != expected.code {
+			t.Fatalf("unexpected span status %v", spans[i].Status)
+		}
+		if len(spans[i].Attributes) != len(expected.attrs) {
+			t.Fatalf("unexpected attributes %v", spans[i].Attributes)
+		}
+		for j, attr := range expected.attrs {
+			if spans[i].Attributes[j] != attr {
+				t.Fatalf("unexpected attribute %v, expected %v", spans[i].Attributes[j], attr)
+			}
+		}
+	}
+}
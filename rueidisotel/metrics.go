@@ -34,6 +34,20 @@ func WithMeterProvider(provider metric.MeterProvider) Option {
 	}
 }
 
+// WithCommandMetrics enables the following metrics of the commands sent by Do, DoCache, DoMulti and DoMultiCache:
+// - rueidis_command_calls: number of commands
+// - rueidis_command_errors: number of failed commands, excluding redis nil replies
+// - rueidis_command_duration: command duration in seconds
+// They are labeled by the db.operation, which is only the first token of a command to bound the cardinality,
+// and the cache_hit, which is true if the reply of a DoCache or DoMultiCache is served from the client side cache.
+// Commands sent together by DoMulti or DoMultiCache are recorded with the duration of the whole call.
+// The buckets of the duration histogram can be set by the WithHistogramOption.
+func WithCommandMetrics() Option {
+	return func(o *otelclient) {
+		o.commands = &commandMetrics{}
+	}
+}
+
 type HistogramOption struct {
 	Buckets []float64
 }
@@ -46,6 +60,44 @@ type dialMetrics struct {
 	latency metric.Float64Histogram
 }
 
+type commandMetrics struct {
+	calls    metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func (m *commandMetrics) record(ctx context.Context, mAttrs metric.MeasurementOption, op string, hit bool, err error, elapsed time.Duration) {
+	attrs := metric.WithAttributes(attribute.String("db.operation", op), attribute.Bool("cache_hit", hit))
+	m.calls.Add(ctx, 1, mAttrs, attrs)
+	if err != nil && !rueidis.IsRedisNil(err) {
+		m.errors.Add(ctx, 1, mAttrs, attrs)
+	}
+	m.duration.Record(ctx, float64(elapsed)/float64(time.Second), mAttrs, attrs)
+}
+
+// multiOps returns the first tokens of the multi for the command metrics before they are recycled, or nil if they are disabled.
+func (o *otelclient) multiOps(multi []rueidis.Completed) []string {
+	if o.commands == nil {
+		return nil
+	}
+	ops := make([]string, len(multi))
+	for i, cmd := range multi {
+		ops[i] = first(cmd.Commands())
+	}
+	return ops
+}
+
+func (o *otelclient) multiCacheableOps(multi []rueidis.CacheableTTL) []string {
+	if o.commands == nil {
+		return nil
+	}
+	ops := make([]string, len(multi))
+	for i, cmd := range multi {
+		ops[i] = first(cmd.Cmd.Commands())
+	}
+	return ops
+}
+
 // WithHistogramOption sets the HistogramOption.
 // If not set, DefaultHistogramBuckets will be used.
 func WithHistogramOption(histogramOption HistogramOption) Option {
@@ -96,7 +148,11 @@ func NewClient(clientOption rueidis.ClientOption, opts ...Option) (rueidis.Clien
 		return nil, err
 	}
 
-	clientOption.DialFn = trackDialing(metrics, clientOption.DialFn)
+	if clientOption.DialCtxFn != nil {
+		clientOption.DialCtxFn = trackDialingCtx(metrics, clientOption.DialCtxFn)
+	} else {
+		clientOption.DialFn = trackDialing(metrics, clientOption.DialFn)
+	}
 	cli, err := rueidis.NewClient(clientOption)
 	if err != nil {
 		return nil, err
@@ -137,33 +193,65 @@ func newClient(opts ...Option) (*otelclient, error) {
 	if err != nil {
 		return nil, err
 	}
+	if cli.commands != nil {
+		cli.commands.calls, err = cli.meter.Int64Counter("rueidis_command_calls")
+		if err != nil {
+			return nil, err
+		}
+		cli.commands.errors, err = cli.meter.Int64Counter("rueidis_command_errors")
+		if err != nil {
+			return nil, err
+		}
+		cli.commands.duration, err = cli.meter.Float64Histogram(
+			"rueidis_command_duration",
+			metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(cli.histogramOption.Buckets...),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return cli, nil
 }
 
 func trackDialing(m dialMetrics, dialFn func(string, *net.Dialer, *tls.Config) (conn net.Conn, err error)) func(string, *net.Dialer, *tls.Config) (conn net.Conn, err error) {
 	return func(network string, dialer *net.Dialer, tlsConfig *tls.Config) (conn net.Conn, err error) {
-		ctx := context.Background()
-		m.attempt.Add(ctx, 1, m.mAttrs)
+		return m.track(func() (net.Conn, error) {
+			return dialFn(network, dialer, tlsConfig)
+		})
+	}
+}
 
-		start := time.Now()
+func trackDialingCtx(m dialMetrics, dialFn func(context.Context, string, *tls.Config) (conn net.Conn, err error)) func(context.Context, string, *tls.Config) (conn net.Conn, err error) {
+	return func(ctx context.Context, addr string, tlsConfig *tls.Config) (conn net.Conn, err error) {
+		return m.track(func() (net.Conn, error) {
+			return dialFn(ctx, addr, tlsConfig)
+		})
+	}
+}
 
-		conn, err = dialFn(network, dialer, tlsConfig)
-		if err != nil {
-			return nil, err
-		}
+func (m dialMetrics) track(dial func() (net.Conn, error)) (conn net.Conn, err error) {
+	ctx := context.Background()
+	m.attempt.Add(ctx, 1, m.mAttrs)
 
-		// Use floating point division for higher precision (instead of Seconds method).
-		m.latency.Record(ctx, float64(time.Since(start))/float64(time.Second), m.mAttrs)
-		m.success.Add(ctx, 1, m.mAttrs)
-		m.counts.Add(ctx, 1, m.mAttrs)
+	start := time.Now()
 
-		return &connTracker{
-			Conn:   conn,
-			counts: m.counts,
-			mAttrs: m.mAttrs,
-			once:   0,
-		}, nil
+	conn, err = dial()
+	if err != nil {
+		return nil, err
 	}
+
+	// Use floating point division for higher precision (instead of Seconds method).
+	m.latency.Record(ctx, float64(time.Since(start))/float64(time.Second), m.mAttrs)
+	m.success.Add(ctx, 1, m.mAttrs)
+	m.counts.Add(ctx, 1, m.mAttrs)
+
+	return &connTracker{
+		Conn:   conn,
+		counts: m.counts,
+		mAttrs: m.mAttrs,
+		once:   0,
+	}, nil
 }
 
 type connTracker struct {
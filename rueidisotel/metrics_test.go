@@ -7,7 +7,9 @@ import (
 	"net"
 	"strings"
 	"testing"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 
@@ -113,6 +115,110 @@ func TestNewClientMeterError(t *testing.T) {
 	}
 }
 
+func TestNewClientCommandMeterError(t *testing.T) {
+	for _, name := range []string{"rueidis_command_calls", "rueidis_command_errors", "rueidis_command_duration"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewClient(
+				rueidis.ClientOption{
+					InitAddress: []string{"127.0.0.1:6379"},
+				},
+				WithMeterProvider(&MockMeterProvider{testName: name}),
+				WithCommandMetrics(),
+			)
+			if !errors.Is(err, errMocked) || !strings.Contains(err.Error(), name) {
+				t.Errorf("mocked error: got %s, want %s", err, errMocked)
+			}
+		})
+	}
+}
+
+func TestCommandMetrics(t *testing.T) {
+	client, err := rueidis.NewClient(rueidis.ClientOption{InitAddress: []string{"127.0.0.1:6379"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := metric.NewManualReader()
+	client = WithClient(
+		client,
+		MetricAttrs(attribute.String("any", "label")),
+		WithMeterProvider(metric.NewMeterProvider(metric.WithReader(mr))),
+		WithCommandMetrics(),
+	)
+	defer client.Close()
+
+	ctx := context.Background()
+	key := "command_metrics"
+	client.Do(ctx, client.B().Set().Key(key).Value("1").Build())
+	client.Do(ctx, client.B().Hget().Key(key).Field("f").Build()) // WRONGTYPE
+	client.DoCache(ctx, client.B().Get().Key(key).Cache(), time.Minute)
+	client.DoCache(ctx, client.B().Get().Key(key).Cache(), time.Minute)
+	client.DoMulti(ctx, client.B().Get().Key("command_metrics_nil").Build(), client.B().Set().Key(key).Value("2").Build())
+	client.DoMultiCache(ctx, rueidis.CT(client.B().Get().Key("command_metrics_nil").Cache(), time.Minute))
+
+	metrics := metricdata.ResourceMetrics{}
+	if err := mr.Collect(ctx, &metrics); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name  string
+		op    string
+		hit   bool
+		value int64
+	}{
+		{name: "rueidis_command_calls", op: "SET", value: 2},
+		{name: "rueidis_command_calls", op: "HGET", value: 1},
+		{name: "rueidis_command_calls", op: "GET", hit: false, value: 3},
+		{name: "rueidis_command_calls", op: "GET", hit: true, value: 1},
+		{name: "rueidis_command_errors", op: "HGET", value: 1},
+		{name: "rueidis_command_errors", op: "GET", hit: false, value: 0},
+		{name: "rueidis_command_errors", op: "SET", value: 0},
+	} {
+		if v := int64CountMetricOf(metrics, c.name, c.op, c.hit); v != c.value {
+			t.Errorf("%s of %s (cache_hit=%v): got %d, want %d", c.name, c.op, c.hit, v, c.value)
+		}
+	}
+	if v := float64HistogramCountOf(metrics, "rueidis_command_duration", "GET", true); v != 1 {
+		t.Errorf("rueidis_command_duration of GET: got %d, want 1", v)
+	}
+}
+
+func int64CountMetricOf(metrics metricdata.ResourceMetrics, name, op string, hit bool) int64 {
+	for _, sm := range metrics.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					if hasCommandAttrs(dp.Attributes, op, hit) {
+						return dp.Value
+					}
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func float64HistogramCountOf(metrics metricdata.ResourceMetrics, name, op string, hit bool) uint64 {
+	for _, sm := range metrics.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+					if hasCommandAttrs(dp.Attributes, op, hit) {
+						return dp.Count
+					}
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func hasCommandAttrs(set attribute.Set, op string, hit bool) bool {
+	o, _ := set.Value("db.operation")
+	h, _ := set.Value("cache_hit")
+	a, _ := set.Value("any")
+	return o.AsString() == op && h.AsBool() == hit && a.AsString() == "label"
+}
+
 func TestTrackDialing(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mr := metric.NewManualReader()
@@ -229,6 +335,36 @@ func TestTrackDialing(t *testing.T) {
 			t.Error("dial latency: got 0, want 0")
 		}
 	})
+
+	t.Run("failed to dial with DialCtxFn", func(t *testing.T) {
+		mr := metric.NewManualReader()
+		meterProvider := metric.NewMeterProvider(metric.WithReader(mr))
+		_, err := NewClient(
+			rueidis.ClientOption{
+				InitAddress: []string{""},
+				DialCtxFn: func(ctx context.Context, addr string, _ *tls.Config) (conn net.Conn, err error) {
+					return nil, errors.New("dial error")
+				},
+			},
+			WithMeterProvider(meterProvider),
+		)
+		if err == nil {
+			t.Fatal(err)
+		}
+
+		metrics := metricdata.ResourceMetrics{}
+		if err := mr.Collect(context.Background(), &metrics); err != nil {
+			t.Fatal(err)
+		}
+		attempt := int64CountMetric(metrics, "rueidis_dial_attempt")
+		if attempt != 1 {
+			t.Errorf("attempt: got %d, want 1", attempt)
+		}
+		success := int64CountMetric(metrics, "rueidis_dial_success")
+		if success != 0 {
+			t.Errorf("success: got %d, want 0", success)
+		}
+	})
 }
 
 func int64CountMetric(metrics metricdata.ResourceMetrics, name string) int64 {
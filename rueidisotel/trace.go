@@ -52,9 +52,12 @@ func WithTracerProvider(provider trace.TracerProvider) Option {
 }
 
 // WithDBStatement tells the tracing hook to add raw redis commands to db.statement attribute.
+// The credentials in commands are redacted by the rueidis.RedactCommand before they are passed to the f.
 func WithDBStatement(f StatementFunc) Option {
 	return func(o *otelclient) {
-		o.dbStmtFunc = f
+		o.dbStmtFunc = func(cmdTokens []string) string {
+			return f(rueidis.RedactCommand(cmdTokens))
+		}
 	}
 }
 
@@ -69,6 +72,7 @@ type otelclient struct {
 	meter           metric.Meter
 	cscMiss         metric.Int64Counter
 	cscHits         metric.Int64Counter
+	commands        *commandMetrics
 	mAttrs          metric.MeasurementOption
 	tAttrs          trace.SpanStartEventOption
 	histogramOption HistogramOption
@@ -85,14 +89,25 @@ func (o *otelclient) Do(ctx context.Context, cmd rueidis.Completed) (resp rueidi
 		span.SetAttributes(dbstmt.String(o.dbStmtFunc(cmd.Commands())))
 	}
 
+	op, start := first(cmd.Commands()), time.Now() // the cmd may be recycled after the Do
 	resp = o.client.Do(ctx, cmd)
+	if o.commands != nil {
+		o.commands.record(ctx, o.mAttrs, op, false, resp.Error(), time.Since(start))
+	}
 	o.end(span, resp.Error())
 	return
 }
 
 func (o *otelclient) DoMulti(ctx context.Context, multi ...rueidis.Completed) (resp []rueidis.RedisResult) {
 	ctx, span := o.start(ctx, multiFirst(multi), multiSum(multi), o.tAttrs)
+	ops, start := o.multiOps(multi), time.Now()
 	resp = o.client.DoMulti(ctx, multi...)
+	if o.commands != nil {
+		elapsed := time.Since(start)
+		for i, r := range resp {
+			o.commands.record(ctx, o.mAttrs, ops[i], false, r.Error(), elapsed)
+		}
+	}
 	o.end(span, firstError(resp))
 	return
 }
@@ -121,7 +136,11 @@ func (o *otelclient) DoCache(ctx context.Context, cmd rueidis.Cacheable, ttl tim
 		span.SetAttributes(dbstmt.String(o.dbStmtFunc(cmd.Commands())))
 	}
 
+	op, start := first(cmd.Commands()), time.Now()
 	resp = o.client.DoCache(ctx, cmd, ttl)
+	if o.commands != nil {
+		o.commands.record(ctx, o.mAttrs, op, resp.IsCacheHit(), resp.Error(), time.Since(start))
+	}
 	if resp.NonRedisError() == nil {
 		if resp.IsCacheHit() {
 			o.cscHits.Add(ctx, 1, o.mAttrs)
@@ -135,7 +154,14 @@ func (o *otelclient) DoCache(ctx context.Context, cmd rueidis.Cacheable, ttl tim
 
 func (o *otelclient) DoMultiCache(ctx context.Context, multi ...rueidis.CacheableTTL) (resps []rueidis.RedisResult) {
 	ctx, span := o.start(ctx, multiCacheableFirst(multi), multiCacheableSum(multi), o.tAttrs)
+	ops, start := o.multiCacheableOps(multi), time.Now()
 	resps = o.client.DoMultiCache(ctx, multi...)
+	if o.commands != nil {
+		elapsed := time.Since(start)
+		for i, r := range resps {
+			o.commands.record(ctx, o.mAttrs, ops[i], r.IsCacheHit(), r.Error(), elapsed)
+		}
+	}
 	for _, resp := range resps {
 		if resp.NonRedisError() == nil {
 			if resp.IsCacheHit() {
@@ -194,6 +220,7 @@ func (o *otelclient) Nodes() map[string]rueidis.Client {
 			meter:           o.meter,
 			cscMiss:         o.cscMiss,
 			cscHits:         o.cscHits,
+			commands:        o.commands,
 			histogramOption: o.histogramOption,
 			dbStmtFunc:      o.dbStmtFunc,
 		}
@@ -314,6 +314,10 @@ func TestWithDBStatement(t *testing.T) {
 	if client.dbStmtFunc(cmd) != dbStmtFunc(cmd) {
 		t.Fatalf("unexpected dbStmtFunc result: got %v, expected %v", client.dbStmtFunc(cmd), dbStmtFunc(cmd))
 	}
+
+	if stmt := client.dbStmtFunc([]string{"AUTH", "user", "pass"}); stmt != "AUTH <redacted> <redacted>" {
+		t.Fatalf("unexpected dbStmtFunc result: got %v", stmt)
+	}
 }
 
 func TestWithClientSimple(t *testing.T) {
@@ -0,0 +1,193 @@
+package rueidisprob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/rueidis"
+)
+
+var (
+	ErrCapacityZero        = errors.New("capacity cannot be zero")
+	ErrRedisBloomNotLoaded = errors.New("the RedisBloom module is not loaded")
+)
+
+type redisBloomFilter struct {
+	client rueidis.Client
+
+	// name is the key of the filter in the Redis.
+	name string
+
+	capacity  int64
+	errorRate float64
+
+	// reserved is set to 1 after the filter is reserved by BF.RESERVE.
+	reserved int32
+}
+
+// NewRedisBloomFilter creates a new Bloom filter backed by the BF.* commands of the RedisBloom module.
+// The filter is reserved with the capacity and the errorRate by BF.RESERVE lazily on its first use,
+// and ErrRedisBloomNotLoaded is returned from its methods if the RedisBloom module is not loaded.
+// NOTE: If the filter is deleted by others, it will be recreated by BF.ADD or BF.MADD with the default capacity and error rate.
+func NewRedisBloomFilter(
+	client rueidis.Client,
+	name string,
+	capacity uint,
+	errorRate float64,
+) (BloomFilter, error) {
+	if len(name) == 0 {
+		return nil, ErrEmptyName
+	}
+	if capacity == 0 {
+		return nil, ErrCapacityZero
+	}
+
+	if errorRate <= 0 {
+		return nil, ErrFalsePositiveRateLessThanEqualZero
+	}
+	if errorRate > 1 {
+		return nil, ErrFalsePositiveRateGreaterThanOne
+	}
+
+	return &redisBloomFilter{
+		client:    client,
+		name:      name,
+		capacity:  int64(capacity),
+		errorRate: errorRate,
+	}, nil
+}
+
+func (c *redisBloomFilter) reserve(ctx context.Context) error {
+	if atomic.LoadInt32(&c.reserved) == 1 {
+		return nil
+	}
+	err := c.client.Do(
+		ctx,
+		c.client.B().
+			BfReserve().
+			Key(c.name).
+			ErrorRate(c.errorRate).
+			Capacity(c.capacity).
+			Build(),
+	).Error()
+	if err != nil {
+		ret, ok := rueidis.IsRedisErr(err)
+		if !ok {
+			return err
+		}
+		if msg := ret.Error(); strings.Contains(msg, "unknown command") {
+			return fmt.Errorf("%w: %s", ErrRedisBloomNotLoaded, msg)
+		} else if !strings.Contains(msg, "item exists") {
+			return err
+		}
+	}
+	atomic.StoreInt32(&c.reserved, 1)
+	return nil
+}
+
+func (c *redisBloomFilter) Add(ctx context.Context, key string) error {
+	if err := c.reserve(ctx); err != nil {
+		return err
+	}
+
+	resp := c.client.Do(
+		ctx,
+		c.client.B().
+			BfAdd().
+			Key(c.name).
+			Item(key).
+			Build(),
+	)
+	return resp.Error()
+}
+
+func (c *redisBloomFilter) AddMulti(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := c.reserve(ctx); err != nil {
+		return err
+	}
+
+	resp := c.client.Do(
+		ctx,
+		c.client.B().
+			BfMadd().
+			Key(c.name).
+			Item(keys...).
+			Build(),
+	)
+	return resp.Error()
+}
+
+func (c *redisBloomFilter) Exists(ctx context.Context, key string) (bool, error) {
+	if err := c.reserve(ctx); err != nil {
+		return false, err
+	}
+
+	resp := c.client.Do(
+		ctx,
+		c.client.B().
+			BfExists().
+			Key(c.name).
+			Item(key).
+			Build(),
+	)
+	return resp.AsBool()
+}
+
+func (c *redisBloomFilter) ExistsMulti(ctx context.Context, keys []string) ([]bool, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	if err := c.reserve(ctx); err != nil {
+		return nil, err
+	}
+
+	resp := c.client.Do(
+		ctx,
+		c.client.B().
+			BfMexists().
+			Key(c.name).
+			Item(keys...).
+			Build(),
+	)
+	return resp.AsBoolSlice()
+}
+
+func (c *redisBloomFilter) Reset(ctx context.Context) error {
+	if err := c.Delete(ctx); err != nil {
+		return err
+	}
+	return c.reserve(ctx)
+}
+
+func (c *redisBloomFilter) Delete(ctx context.Context) error {
+	resp := c.client.Do(
+		ctx,
+		c.client.B().
+			Del().
+			Key(c.name).
+			Build(),
+	)
+	atomic.StoreInt32(&c.reserved, 0)
+	return resp.Error()
+}
+
+func (c *redisBloomFilter) Count(ctx context.Context) (uint64, error) {
+	if err := c.reserve(ctx); err != nil {
+		return 0, err
+	}
+
+	resp := c.client.Do(
+		ctx,
+		c.client.B().
+			BfCard().
+			Key(c.name).
+			Build(),
+	)
+	return resp.AsUint64()
+}
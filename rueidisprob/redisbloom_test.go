@@ -0,0 +1,90 @@
+package rueidisprob
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/rueidis"
+)
+
+var redisBloomAddress = []string{"127.0.0.1:6378"}
+
+func setupRedisBloom(t *testing.T) (BloomFilter, func()) {
+	client, err := rueidis.NewClient(rueidis.ClientOption{InitAddress: redisBloomAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bf, err := NewRedisBloomFilter(client, "test_redis_bloom", 100, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bf, func() {
+		if err := bf.Delete(context.Background()); err != nil {
+			t.Error(err)
+		}
+		client.Close()
+	}
+}
+
+func TestNewRedisBloomFilterError(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		capacity  uint
+		errorRate float64
+		err       error
+	}{
+		{name: "", capacity: 100, errorRate: 0.01, err: ErrEmptyName},
+		{name: "bf", capacity: 0, errorRate: 0.01, err: ErrCapacityZero},
+		{name: "bf", capacity: 100, errorRate: 0, err: ErrFalsePositiveRateLessThanEqualZero},
+		{name: "bf", capacity: 100, errorRate: 1.1, err: ErrFalsePositiveRateGreaterThanOne},
+	} {
+		if _, err := NewRedisBloomFilter(nil, c.name, c.capacity, c.errorRate); !errors.Is(err, c.err) {
+			t.Errorf("unexpected error %v, expected %v", err, c.err)
+		}
+	}
+}
+
+func TestRedisBloomFilter(t *testing.T) {
+	bf, teardown := setupRedisBloom(t)
+	defer teardown()
+
+	ctx := context.Background()
+	if err := bf.Add(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bf.AddMulti(ctx, []string{"b", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := bf.Exists(ctx, "a"); err != nil || !exists {
+		t.Fatalf("unexpected exists %v %v", exists, err)
+	}
+	if exists, err := bf.ExistsMulti(ctx, []string{"b", "c", "d"}); err != nil || len(exists) != 3 || !exists[0] || !exists[1] || exists[2] {
+		t.Fatalf("unexpected exists %v %v", exists, err)
+	}
+	if count, err := bf.Count(ctx); err != nil || count != 3 {
+		t.Fatalf("unexpected count %v %v", count, err)
+	}
+	if err := bf.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := bf.Count(ctx); err != nil || count != 0 {
+		t.Fatalf("unexpected count %v %v", count, err)
+	}
+}
+
+func TestRedisBloomFilterNotLoaded(t *testing.T) {
+	client, err := rueidis.NewClient(rueidis.ClientOption{InitAddress: address})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	bf, err := NewRedisBloomFilter(client, "test_redis_bloom", 100, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bf.Add(context.Background(), "a"); !errors.Is(err, ErrRedisBloomNotLoaded) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
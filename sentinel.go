@@ -61,9 +61,11 @@ func (c *sentinelClient) B() Builder {
 }
 
 func (c *sentinelClient) Do(ctx context.Context, cmd Completed) (resp RedisResult) {
+	attempts := 1
 retry:
 	resp = c.mConn.Load().(conn).Do(ctx, cmd)
-	if c.retry && cmd.IsReadOnly() && c.isRetryable(resp.NonRedisError(), ctx) {
+	if c.retry && c.isRetryable(resp.NonRedisError(), ctx) && retryable(c.mOpt.RetryPolicy, ctx, cmd, attempts, resp.NonRedisError()) {
+		attempts++
 		goto retry
 	}
 	if resp.NonRedisError() == nil { // not recycle cmds if error, since cmds may be used later in pipe. consider recycle them by pipe
@@ -76,13 +78,18 @@ func (c *sentinelClient) DoMulti(ctx context.Context, multi ...Completed) []Redi
 	if len(multi) == 0 {
 		return nil
 	}
+	attempts := 1
 retry:
 	resps := c.mConn.Load().(conn).DoMulti(ctx, multi...)
-	if c.retry && allReadOnly(multi) {
+	if c.retry {
 		for _, resp := range resps.s {
-			if c.isRetryable(resp.NonRedisError(), ctx) {
-				resultsp.Put(resps)
-				goto retry
+			if err := resp.NonRedisError(); c.isRetryable(err, ctx) {
+				if retryableMulti(c.mOpt.RetryPolicy, ctx, multi, attempts, err) {
+					resultsp.Put(resps)
+					attempts++
+					goto retry
+				}
+				break
 			}
 		}
 	}
@@ -95,9 +102,11 @@ retry:
 }
 
 func (c *sentinelClient) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) (resp RedisResult) {
+	attempts := 1
 retry:
 	resp = c.mConn.Load().(conn).DoCache(ctx, cmd, ttl)
-	if c.retry && c.isRetryable(resp.NonRedisError(), ctx) {
+	if c.retry && c.isRetryable(resp.NonRedisError(), ctx) && retryableCache(c.mOpt.RetryPolicy, ctx, cmd, attempts, resp.NonRedisError()) {
+		attempts++
 		goto retry
 	}
 	if err := resp.NonRedisError(); err == nil || err == ErrDoCacheAborted {
@@ -110,13 +119,18 @@ func (c *sentinelClient) DoMultiCache(ctx context.Context, multi ...CacheableTTL
 	if len(multi) == 0 {
 		return nil
 	}
+	attempts := 1
 retry:
 	resps := c.mConn.Load().(conn).DoMultiCache(ctx, multi...)
 	if c.retry {
 		for _, resp := range resps.s {
-			if c.isRetryable(resp.NonRedisError(), ctx) {
-				resultsp.Put(resps)
-				goto retry
+			if err := resp.NonRedisError(); c.isRetryable(err, ctx) {
+				if retryableMultiCache(c.mOpt.RetryPolicy, ctx, multi, attempts, err) {
+					resultsp.Put(resps)
+					attempts++
+					goto retry
+				}
+				break
 			}
 		}
 	}
@@ -161,8 +175,7 @@ func (c *sentinelClient) DoMultiStream(ctx context.Context, multi ...Completed)
 
 func (c *sentinelClient) Dedicated(fn func(DedicatedClient) error) (err error) {
 	master := c.mConn.Load().(conn)
-	wire := master.Acquire()
-	dsc := &dedicatedSingleClient{cmd: c.cmd, conn: master, wire: wire, retry: c.retry}
+	dsc := newDedicatedSingleClient(c.cmd, master, c.retry, c.mOpt.MaxConns > 0)
 	err = fn(dsc)
 	dsc.release()
 	return err
@@ -170,14 +183,30 @@ func (c *sentinelClient) Dedicated(fn func(DedicatedClient) error) (err error) {
 
 func (c *sentinelClient) Dedicate() (DedicatedClient, func()) {
 	master := c.mConn.Load().(conn)
-	wire := master.Acquire()
-	dsc := &dedicatedSingleClient{cmd: c.cmd, conn: master, wire: wire, retry: c.retry}
+	dsc := newDedicatedSingleClient(c.cmd, master, c.retry, c.mOpt.MaxConns > 0)
 	return dsc, dsc.release
 }
 
+func (c *sentinelClient) withConn(fn func(c CoreClient) error) error {
+	master := c.mConn.Load().(conn)
+	return withPinned(c.cmd, func(ctx context.Context, slot uint16) (conn, error) { return master, nil }, fn)
+}
+
 func (c *sentinelClient) Nodes() map[string]Client {
 	conn := c.mConn.Load().(conn)
-	return map[string]Client{conn.Addr(): newSingleClientWithConn(conn, c.cmd, c.retry)}
+	return map[string]Client{conn.Addr(): newSingleClientWithConn(conn, c.cmd, c.retry, c.mOpt.RetryPolicy, c.mOpt.MaxConns > 0)}
+}
+
+func (c *sentinelClient) connStats() []ConnStat {
+	return []ConnStat{c.mConn.Load().(conn).Stats()}
+}
+
+func (c *sentinelClient) serverVersion() string {
+	return c.mConn.Load().(conn).Info()["version"].string
+}
+
+func (c *sentinelClient) flushCache(keys []string) {
+	c.mConn.Load().(conn).FlushCache(keys)
 }
 
 func (c *sentinelClient) Close() {
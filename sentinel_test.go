@@ -1446,3 +1446,38 @@ func TestSentinelClientRetry(t *testing.T) {
 		return c
 	})
 }
+
+func TestSentinelClientRetryPolicy(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	SetupClientRetryPolicy(t, func(m *mockConn, policy RetryPolicy) Client {
+		m.DoOverride = map[string]func(cmd Completed) RedisResult{
+			"SENTINEL SENTINELS masters": func(cmd Completed) RedisResult {
+				return RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{}}}
+			},
+			"SENTINEL GET-MASTER-ADDR-BY-NAME masters": func(cmd Completed) RedisResult {
+				return RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{
+					{typ: '+', string: ""}, {typ: '+', string: "5"},
+				}}}
+			},
+			"ROLE": func(cmd Completed) RedisResult {
+				return RedisResult{val: RedisMessage{typ: '*', values: []RedisMessage{{typ: '+', string: "master"}}}}
+			},
+		}
+		m.ReceiveOverride = map[string]func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error{
+			"SUBSCRIBE +sentinel +slave -sdown +sdown +switch-master +reboot": func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
+				return nil
+			},
+		}
+		c, err := newSentinelClient(&ClientOption{
+			InitAddress: []string{":0"},
+			Sentinel:    SentinelOption{MasterSet: "masters"},
+			RetryPolicy: policy,
+		}, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return c
+	})
+}
@@ -0,0 +1,87 @@
+package rueidis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+// shardResubscribeInterval is the delay before the SSubscribe subscribes the channels of a slot again after their connection is broken.
+const shardResubscribeInterval = time.Millisecond * 100
+
+// SPublish posts the message to the shard channel by the SPUBLISH of redis 7 and returns the number of clients that received it.
+// In cluster mode, the SPUBLISH is sent to the node serving the slot of the channel instead of being broadcast to the whole cluster,
+// and it is redirected by the MOVED and ASK like other commands if the slot is migrated.
+func SPublish(client Client, ctx context.Context, channel, message string) (int64, error) {
+	return client.Do(ctx, client.B().Spublish().Channel(channel).Message(message).Build()).AsInt64()
+}
+
+// SSubscribe subscribes the shard channels by the SSUBSCRIBE of redis 7 and calls the fn with their messages until the ctx is done.
+// In cluster mode, the channels are grouped by their slots, and each group is received by the Client.Receive on the node serving the slot,
+// which follows the MOVED to the new node. Unlike the Client.Receive, which returns once the subscription ends, it subscribes a group
+// again immediately after redis ends the subscription because the slot is migrated to another node, or after a short delay if its connection is broken.
+// Messages published in between are lost. The fn may be called concurrently for channels of different slots, and it should not block.
+// It returns the ctx.Err() once the ctx is done, ErrClosing once the client is closed, the *RedisError if redis rejects the SSUBSCRIBE,
+// for example, because the redis is older than 7, or nil immediately if no channels are given. All groups are stopped once one of them returns.
+func SSubscribe(client Client, ctx context.Context, fn func(msg PubSubMessage), channels ...string) error {
+	if len(channels) == 0 {
+		return nil
+	}
+	var groups [][]string
+	slots := make(map[uint16]int, len(channels))
+	for _, channel := range channels {
+		cmd := client.B().Ssubscribe().Channel(channel).Build()
+		slot := cmd.Slot()
+		cmds.PutCompleted(cmd) // the cmd is only used to find the slot of the channel with the same rule as the client.
+		if i, ok := slots[slot]; ok {
+			groups[i] = append(groups[i], channel)
+		} else {
+			slots[slot] = len(groups)
+			groups = append(groups, []string{channel})
+		}
+	}
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var ret error
+	once := sync.Once{}
+	stop := func(err error) {
+		once.Do(func() {
+			ret = err
+			cancel()
+		})
+	}
+	wg := sync.WaitGroup{}
+	wg.Add(len(groups))
+	for _, group := range groups {
+		go func(group []string) {
+			defer wg.Done()
+			for {
+				err := client.Receive(sctx, client.B().Ssubscribe().Channel(group...).Build(), fn)
+				if err == nil {
+					continue // the subscription is ended by redis because of a slot migration.
+				}
+				if sctx.Err() != nil {
+					return
+				}
+				if _, ok := err.(*RedisError); ok || err == ErrClosing {
+					stop(err)
+					return
+				}
+				timer := time.NewTimer(shardResubscribeInterval)
+				select {
+				case <-timer.C:
+				case <-sctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+		}(group)
+	}
+	wg.Wait()
+	if ret != nil {
+		return ret
+	}
+	return ctx.Err()
+}
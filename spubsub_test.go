@@ -0,0 +1,153 @@
+package rueidis
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSPublishMoved(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var mu sync.Mutex
+	var sent []string
+	var count int64
+	client, err := newClusterClient(&ClientOption{InitAddress: []string{":0"}}, func(dst string, opt *ClientOption) conn {
+		return &mockConn{DoFn: func(cmd Completed) RedisResult {
+			if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+				return slotsMultiResp
+			}
+			mu.Lock()
+			sent = append(sent, dst+" "+strings.Join(cmd.Commands(), " "))
+			mu.Unlock()
+			if atomic.AddInt64(&count, 1) == 1 {
+				return newResult(RedisMessage{typ: '-', string: "MOVED 0 :1"}, nil)
+			}
+			return newResult(RedisMessage{typ: ':', integer: 2}, nil)
+		}}
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+	if n, err := SPublish(client, context.Background(), "a", "m"); err != nil || n != 2 {
+		t.Fatalf("unexpected resp %v %v", n, err)
+	}
+	// the slot of "a" is 15495, which is served by the master 127.0.2.1:0, and the MOVED redirects it to the :1.
+	if !reflect.DeepEqual(sent, []string{"127.0.2.1:0 SPUBLISH a m", ":1 SPUBLISH a m"}) {
+		t.Fatalf("unexpected commands %v", sent)
+	}
+}
+
+func TestSSubscribe(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var subscribed, received []string
+	calls := map[string]int{}
+	client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+		return &mockConn{
+			DoFn: func(cmd Completed) RedisResult {
+				return slotsMultiResp
+			},
+			ReceiveFn: func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
+				mu.Lock()
+				subscribed = append(subscribed, dst+" "+strings.Join(subscribe.Commands(), " "))
+				calls[dst]++
+				migrated := dst == "127.0.2.1:0" && calls[dst] == 1
+				mu.Unlock()
+				if migrated {
+					return nil // the subscription is ended by redis because of a slot migration.
+				}
+				fn(PubSubMessage{Channel: subscribe.Commands()[1], Message: dst})
+				mu.Lock()
+				done := len(received) == 2
+				mu.Unlock()
+				if done {
+					cancel()
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+
+	if err := SSubscribe(client, ctx, func(msg PubSubMessage) {
+		mu.Lock()
+		received = append(received, msg.Channel+" "+msg.Message)
+		mu.Unlock()
+	}, "a", "b", "{a}c"); err != context.Canceled {
+		t.Fatalf("unexpected err %v", err)
+	}
+	sort.Strings(subscribed)
+	sort.Strings(received)
+	// the slot of "a" is 15495, which is served by the master 127.0.2.1:0, and "b" is served by the 127.0.0.1:0.
+	if !reflect.DeepEqual(subscribed, []string{"127.0.0.1:0 SSUBSCRIBE b", "127.0.2.1:0 SSUBSCRIBE a {a}c", "127.0.2.1:0 SSUBSCRIBE a {a}c"}) {
+		t.Fatalf("unexpected subscriptions %v", subscribed)
+	}
+	if !reflect.DeepEqual(received, []string{"a 127.0.2.1:0", "b 127.0.0.1:0"}) {
+		t.Fatalf("unexpected messages %v", received)
+	}
+
+	if err := SSubscribe(client, ctx, func(msg PubSubMessage) {}); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	t.Run("RedisError", func(t *testing.T) {
+		var calls int64
+		client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				DoFn: func(cmd Completed) RedisResult {
+					return slotsMultiResp
+				},
+				ReceiveFn: func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
+					if dst == "127.0.2.1:0" {
+						atomic.AddInt64(&calls, 1)
+						return &RedisError{typ: '-', string: "ERR unknown command 'SSUBSCRIBE'"}
+					}
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			}
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		defer client.Close()
+		// the error of the slot of "a" stops the subscription of "b" too, and it is not retried.
+		err = SSubscribe(client, context.Background(), func(msg PubSubMessage) {}, "a", "b")
+		if re, ok := err.(*RedisError); !ok || re.Error() != "ERR unknown command 'SSUBSCRIBE'" {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if n := atomic.LoadInt64(&calls); n != 1 {
+			t.Fatalf("unexpected calls %v", n)
+		}
+	})
+
+	t.Run("Closing", func(t *testing.T) {
+		client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				DoFn: func(cmd Completed) RedisResult {
+					return slotsMultiResp
+				},
+				ReceiveFn: func(ctx context.Context, subscribe Completed, fn func(message PubSubMessage)) error {
+					return ErrClosing
+				},
+			}
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		client.Close()
+		if err := SSubscribe(client, context.Background(), func(msg PubSubMessage) {}, "a", "b"); err != ErrClosing {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package rueidis
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// ConnStat is the statistics of the connections to a redis node.
+type ConnStat struct {
+	// Addr is the address of the redis node.
+	Addr string
+	// Dials is how many connections have been dialed to the node, including reconnections.
+	Dials uint64
+	// BytesWritten is how many bytes have been written to the node.
+	BytesWritten uint64
+	// BytesRead is how many bytes have been read from the node.
+	BytesRead uint64
+	// RTT is the round-trip time of the latest background PING, which is sent when a connection is idle.
+	// It is zero if no PING has been sent.
+	RTT time.Duration
+	// Waiting is how many callers are waiting for a connection to the node because of the ClientOption.MaxConns.
+	Waiting int64
+}
+
+// ConnStats returns the ConnStat of each redis node connected by the client.
+// It returns nil if the client is not created by the NewClient, such as a mock or a wrapped client.
+func ConnStats(client Client) []ConnStat {
+	if c, ok := client.(interface{ connStats() []ConnStat }); ok {
+		return c.connStats()
+	}
+	return nil
+}
+
+type connstats struct {
+	dials   uint64
+	written uint64
+	read    uint64
+	rtt     int64
+}
+
+func (s *connstats) stat(addr string) ConnStat {
+	if s == nil {
+		return ConnStat{Addr: addr}
+	}
+	return ConnStat{
+		Addr:         addr,
+		Dials:        atomic.LoadUint64(&s.dials),
+		BytesWritten: atomic.LoadUint64(&s.written),
+		BytesRead:    atomic.LoadUint64(&s.read),
+		RTT:          time.Duration(atomic.LoadInt64(&s.rtt)),
+	}
+}
+
+type statsConn struct {
+	net.Conn
+	s *connstats
+}
+
+func (c *statsConn) Read(b []byte) (n int, err error) {
+	n, err = c.Conn.Read(b)
+	atomic.AddUint64(&c.s.read, uint64(n))
+	return n, err
+}
+
+func (c *statsConn) Write(b []byte) (n int, err error) {
+	n, err = c.Conn.Write(b)
+	atomic.AddUint64(&c.s.written, uint64(n))
+	return n, err
+}
+
+func recordRTT(conn net.Conn, rtt time.Duration) {
+	if c, ok := conn.(*statsConn); ok {
+		atomic.StoreInt64(&c.s.rtt, int64(rtt))
+	}
+}
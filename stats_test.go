@@ -0,0 +1,61 @@
+package rueidis
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+func TestConnStats(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	n1, n2 := net.Pipe()
+	mock := &redisMock{t: t, buf: bufio.NewReader(n2), conn: n2}
+	go func() {
+		mock.Expect("HELLO", "3").
+			Reply(RedisMessage{
+				typ: '%',
+				values: []RedisMessage{
+					{typ: '+', string: "proto"},
+					{typ: ':', integer: 3},
+				},
+			})
+		mock.Expect("CLIENT", "TRACKING", "ON", "OPTIN").
+			ReplyString("OK")
+		mock.Expect("CLIENT", "SETINFO", "LIB-NAME", LibName).
+			ReplyError("UNKNOWN COMMAND")
+		mock.Expect("CLIENT", "SETINFO", "LIB-VER", LibVer).
+			ReplyError("UNKNOWN COMMAND")
+		mock.Expect("PING").ReplyString("OK")
+		mock.Close()
+	}()
+	m := makeMux("addr", &ClientOption{}, func(dst string, opt *ClientOption) (net.Conn, error) {
+		return n1, nil
+	})
+	defer m.Close()
+	if s := m.Stats(); s.Addr != "addr" || s.Dials != 0 || s.BytesWritten != 0 || s.BytesRead != 0 {
+		t.Fatalf("unexpected stat %v", s)
+	}
+	if err := m.Dial(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	s := m.Stats()
+	if s.Addr != "addr" || s.Dials != 1 || s.BytesWritten == 0 || s.BytesRead == 0 {
+		t.Fatalf("unexpected stat %v", s)
+	}
+
+	recordRTT(m.pipe(0).(*pipe).conn, time.Millisecond)
+	if s := m.Stats(); s.RTT != time.Millisecond {
+		t.Fatalf("unexpected stat %v", s)
+	}
+
+	client := newSingleClientWithConn(m, cmds.NewBuilder(cmds.NoSlot), true, nil, false)
+	if stats := ConnStats(client); len(stats) != 1 || stats[0].Addr != "addr" || stats[0].Dials != 1 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+	if stats := ConnStats(struct{ Client }{client}); stats != nil {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+}
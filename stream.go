@@ -0,0 +1,126 @@
+package rueidis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamConsumer is a helper for reading a redis stream as a consumer of a consumer group, which is created on demand.
+// It is safe for concurrent use, but a consumer name should only be used by one worker at a time.
+type StreamConsumer struct {
+	client   Client
+	stream   string
+	group    string
+	consumer string
+
+	// AutoClaimMinIdle, if positive, makes Read claim pending entries of other consumers in the group that have been
+	// idle for at least the duration by XAUTOCLAIM before reading new entries. It should be set before calling Read.
+	AutoClaimMinIdle time.Duration
+
+	mu      sync.Mutex
+	created bool
+	cursor  string
+}
+
+// NewStreamConsumer creates a StreamConsumer reading the stream as the consumer of the group.
+// The group, and the stream as well, will be created by XGROUP CREATE with MKSTREAM on the first Read if they do not exist,
+// and the group will start from new entries added after it is created.
+func NewStreamConsumer(client Client, stream, group, consumer string) *StreamConsumer {
+	return &StreamConsumer{client: client, stream: stream, group: group, consumer: consumer, cursor: "0-0"}
+}
+
+func (c *StreamConsumer) create(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.created {
+		return nil
+	}
+	err := c.client.Do(ctx, c.client.B().XgroupCreate().Key(c.stream).Group(c.group).Id("$").Mkstream().Build()).Error()
+	if ret, ok := IsRedisErr(err); ok && strings.HasPrefix(ret.Error(), "BUSYGROUP") {
+		err = nil
+	}
+	c.created = err == nil
+	return err
+}
+
+func (c *StreamConsumer) claim(ctx context.Context, count int64) ([]XRangeEntry, error) {
+	c.mu.Lock()
+	cursor := c.cursor
+	c.mu.Unlock()
+	minIdle := strconv.FormatInt(c.AutoClaimMinIdle.Milliseconds(), 10)
+	values, err := c.client.Do(ctx, c.client.B().Xautoclaim().Key(c.stream).Group(c.group).Consumer(c.consumer).
+		MinIdleTime(minIdle).Start(cursor).Count(count).Build()).ToArray()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) < 2 {
+		return nil, nil
+	}
+	if cursor, err = values[0].ToString(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.cursor = cursor
+	c.mu.Unlock()
+	entries, err := values[1].AsXRange()
+	if err != nil {
+		return nil, err
+	}
+	claimed := entries[:0]
+	for _, e := range entries {
+		if e.FieldValues != nil { // entries deleted from the stream are nil before redis 7.0
+			claimed = append(claimed, e)
+		}
+	}
+	return claimed, nil
+}
+
+// Read returns at most count entries for the consumer. If the AutoClaimMinIdle is set, idle pending entries claimed from
+// other consumers are returned first. Otherwise, new entries are read by XREADGROUP with the ">" id, waiting for at most
+// the block duration if it is positive. It returns no entries without an error if there is nothing to read in time.
+// Entries returned should be acknowledged by Ack after being processed, otherwise they will stay pending.
+func (c *StreamConsumer) Read(ctx context.Context, count int64, block time.Duration) ([]XRangeEntry, error) {
+	if err := c.create(ctx); err != nil {
+		return nil, err
+	}
+	if c.AutoClaimMinIdle > 0 {
+		if entries, err := c.claim(ctx, count); err != nil || len(entries) != 0 {
+			return entries, c.check(err)
+		}
+	}
+	var cmd Completed
+	if block > 0 {
+		cmd = c.client.B().Xreadgroup().Group(c.group, c.consumer).Count(count).Block(block.Milliseconds()).Streams().Key(c.stream).Id(">").Build()
+	} else {
+		cmd = c.client.B().Xreadgroup().Group(c.group, c.consumer).Count(count).Streams().Key(c.stream).Id(">").Build()
+	}
+	streams, err := c.client.Do(ctx, cmd).AsXRead()
+	if IsRedisNil(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, c.check(err)
+	}
+	return streams[c.stream], nil
+}
+
+// Ack acknowledges the processed entries by XACK, so that they are removed from the pending entries list of the group.
+func (c *StreamConsumer) Ack(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return c.client.Do(ctx, c.client.B().Xack().Key(c.stream).Group(c.group).Id(ids...).Build()).Error()
+}
+
+// check makes the group be created again by the next Read if it has been destroyed.
+func (c *StreamConsumer) check(err error) error {
+	if ret, ok := IsRedisErr(err); ok && strings.HasPrefix(ret.Error(), "NOGROUP") {
+		c.mu.Lock()
+		c.created = false
+		c.mu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package rueidis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func entrymsg(id string, fv ...string) RedisMessage {
+	values := make([]RedisMessage, len(fv))
+	for i, v := range fv {
+		values[i] = RedisMessage{typ: '+', string: v}
+	}
+	return RedisMessage{typ: '*', values: []RedisMessage{{typ: '+', string: id}, {typ: '*', values: values}}}
+}
+
+//gocyclo:ignore
+func TestStreamConsumer(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	m := &mockConn{}
+	client, err := newSingleClient(&ClientOption{InitAddress: []string{""}, DisableRetry: true}, m, func(dst string, opt *ClientOption) conn {
+		return m
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+
+	var received [][]string
+	replies := map[string]RedisResult{}
+	m.DoFn = func(cmd Completed) RedisResult {
+		received = append(received, append([]string(nil), cmd.Commands()...))
+		return replies[cmd.Commands()[0]]
+	}
+
+	t.Run("Read", func(t *testing.T) {
+		received = nil
+		replies["XGROUP"] = newResult(RedisMessage{typ: '-', string: "BUSYGROUP Consumer Group name already exists"}, nil)
+		replies["XREADGROUP"] = newResult(RedisMessage{typ: '%', values: []RedisMessage{
+			{typ: '+', string: "s"},
+			{typ: '*', values: []RedisMessage{entrymsg("1-0", "f", "v")}},
+		}}, nil)
+		c := NewStreamConsumer(client, "s", "g", "c")
+		entries, err := c.Read(context.Background(), 10, time.Second)
+		if err != nil || len(entries) != 1 || entries[0].ID != "1-0" || entries[0].FieldValues["f"] != "v" {
+			t.Fatalf("unexpected response %v %v", entries, err)
+		}
+		if _, err = c.Read(context.Background(), 10, 0); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if !reflect.DeepEqual(received, [][]string{
+			{"XGROUP", "CREATE", "s", "g", "$", "MKSTREAM"},
+			{"XREADGROUP", "GROUP", "g", "c", "COUNT", "10", "BLOCK", "1000", "STREAMS", "s", ">"},
+			{"XREADGROUP", "GROUP", "g", "c", "COUNT", "10", "STREAMS", "s", ">"},
+		}) {
+			t.Fatalf("unexpected commands %v", received)
+		}
+	})
+	t.Run("Read Timeout", func(t *testing.T) {
+		replies["XGROUP"] = newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+		replies["XREADGROUP"] = newResult(RedisMessage{typ: '_'}, nil)
+		c := NewStreamConsumer(client, "s", "g", "c")
+		if entries, err := c.Read(context.Background(), 10, time.Second); err != nil || len(entries) != 0 {
+			t.Fatalf("unexpected response %v %v", entries, err)
+		}
+	})
+	t.Run("Read NOGROUP", func(t *testing.T) {
+		received = nil
+		replies["XGROUP"] = newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+		replies["XREADGROUP"] = newResult(RedisMessage{typ: '-', string: "NOGROUP No such key 's' or consumer group 'g'"}, nil)
+		c := NewStreamConsumer(client, "s", "g", "c")
+		for i := 0; i < 2; i++ {
+			if _, err := c.Read(context.Background(), 10, 0); err == nil {
+				t.Fatalf("unexpected response %v", err)
+			}
+		}
+		if len(received) != 4 || received[2][0] != "XGROUP" {
+			t.Fatalf("group is not recreated %v", received)
+		}
+	})
+	t.Run("AutoClaim", func(t *testing.T) {
+		received = nil
+		replies["XGROUP"] = newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+		replies["XAUTOCLAIM"] = newResult(RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '+', string: "2-0"},
+			{typ: '*', values: []RedisMessage{entrymsg("1-0", "f", "v"), {typ: '*', values: []RedisMessage{{typ: '+', string: "1-1"}, {typ: '_'}}}}},
+			{typ: '*'},
+		}}, nil)
+		c := NewStreamConsumer(client, "s", "g", "c")
+		c.AutoClaimMinIdle = time.Minute
+		entries, err := c.Read(context.Background(), 10, 0)
+		if err != nil || len(entries) != 1 || entries[0].ID != "1-0" {
+			t.Fatalf("unexpected response %v %v", entries, err)
+		}
+		replies["XAUTOCLAIM"] = newResult(RedisMessage{typ: '*', values: []RedisMessage{
+			{typ: '+', string: "0-0"},
+			{typ: '*'},
+			{typ: '*'},
+		}}, nil)
+		replies["XREADGROUP"] = newResult(RedisMessage{typ: '_'}, nil)
+		if entries, err = c.Read(context.Background(), 10, 0); err != nil || len(entries) != 0 {
+			t.Fatalf("unexpected response %v %v", entries, err)
+		}
+		if !reflect.DeepEqual(received, [][]string{
+			{"XGROUP", "CREATE", "s", "g", "$", "MKSTREAM"},
+			{"XAUTOCLAIM", "s", "g", "c", "60000", "0-0", "COUNT", "10"},
+			{"XAUTOCLAIM", "s", "g", "c", "60000", "2-0", "COUNT", "10"},
+			{"XREADGROUP", "GROUP", "g", "c", "COUNT", "10", "STREAMS", "s", ">"},
+		}) {
+			t.Fatalf("unexpected commands %v", received)
+		}
+	})
+	t.Run("Ack", func(t *testing.T) {
+		received = nil
+		replies["XACK"] = newResult(RedisMessage{typ: ':', integer: 2}, nil)
+		c := NewStreamConsumer(client, "s", "g", "c")
+		if err := c.Ack(context.Background()); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if err := c.Ack(context.Background(), "1-0", "2-0"); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if !reflect.DeepEqual(received, [][]string{{"XACK", "s", "g", "1-0", "2-0"}}) {
+			t.Fatalf("unexpected commands %v", received)
+		}
+	})
+}
+
+func TestXaddNomkstream(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	m := &mockConn{}
+	client, err := newSingleClient(&ClientOption{InitAddress: []string{""}, DisableRetry: true}, m, func(dst string, opt *ClientOption) conn {
+		return m
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	defer client.Close()
+
+	reply := RedisMessage{typ: '_'}
+	m.DoFn = func(cmd Completed) RedisResult {
+		if !reflect.DeepEqual(cmd.Commands(), []string{"XADD", "s", "NOMKSTREAM", "MAXLEN", "~", "1000", "LIMIT", "100", "*", "f", "v"}) {
+			t.Fatalf("unexpected command %v", cmd.Commands())
+		}
+		return newResult(reply, nil)
+	}
+	xadd := func() (string, error) {
+		return client.Do(context.Background(), client.B().Xadd().Key("s").Nomkstream().
+			Maxlen().Almost().Threshold("1000").Limit(100).Id("*").FieldValue().FieldValue("f", "v").Build()).ToString()
+	}
+	if id, err := xadd(); !IsRedisNil(err) || id != "" {
+		t.Fatalf("the missing stream should be replied with nil %v %v", id, err)
+	}
+	reply = RedisMessage{typ: '$', string: "1-0"}
+	if id, err := xadd(); err != nil || id != "1-0" {
+		t.Fatalf("unexpected id %v %v", id, err)
+	}
+}
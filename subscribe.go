@@ -0,0 +1,63 @@
+package rueidis
+
+import (
+	"context"
+)
+
+// Subscribe is a helper that subscribes the channels on a dedicated connection and delivers the messages to the returned Go channel,
+// which is buffered by the buffer size, so that they can be consumed in a select loop. It returns after all the channels are subscribed.
+// The returned channel will be closed and the dedicated connection will be closed when the ctx is done or the connection is broken.
+// Note that the consumer should keep receiving from the channel, otherwise following messages will be blocked.
+func Subscribe(client Client, ctx context.Context, buffer int, channels ...string) (<-chan PubSubMessage, error) {
+	c, _ := client.Dedicate()
+
+	ch := make(chan PubSubMessage, buffer)
+	subscribed := make(chan struct{})
+	pending := make(map[string]struct{}, len(channels))
+	for _, channel := range channels {
+		pending[channel] = struct{}{}
+	}
+	wait := c.SetPubSubHooks(PubSubHooks{
+		OnMessage: func(m PubSubMessage) {
+			select {
+			case ch <- m:
+			case <-ctx.Done():
+			}
+		},
+		OnSubscription: func(s PubSubSubscription) {
+			if _, ok := pending[s.Channel]; ok && s.Kind == "subscribe" {
+				if delete(pending, s.Channel); len(pending) == 0 {
+					close(subscribed)
+				}
+			}
+		},
+	})
+	if err := c.Do(ctx, c.B().Subscribe().Channel(channels...).Build()).Error(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	select {
+	case <-subscribed:
+	case err, ok := <-wait:
+		c.Close()
+		if !ok || err == nil {
+			err = ErrClosing
+		}
+		return nil, err
+	case <-ctx.Done():
+		c.Close()
+		return nil, ctx.Err()
+	}
+
+	go func() {
+		select {
+		case <-wait:
+		case <-ctx.Done():
+		}
+		c.Close()
+		for range wait { // the hooks will not be called anymore after the wait is closed.
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
@@ -0,0 +1,127 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+
+	setup := func(t *testing.T, subscribe func(hooks PubSubHooks, cmd Completed) RedisResult) (*singleClient, chan error, *bool) {
+		var hooks PubSubHooks
+		var once sync.Once
+		closed := false
+		wait := make(chan error, 1)
+		w := &mockWire{
+			SetPubSubHooksFn: func(h PubSubHooks) <-chan error {
+				hooks = h
+				return wait
+			},
+			DoFn: func(cmd Completed) RedisResult {
+				return subscribe(hooks, cmd)
+			},
+			CloseFn: func() {
+				once.Do(func() {
+					closed = true
+					close(wait)
+				})
+			},
+		}
+		m := &mockConn{
+			AcquireFn: func() wire { return w },
+			StoreFn:   func(ww wire) {},
+		}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}, DisableRetry: true}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client, wait, &closed
+	}
+
+	confirm := func(hooks PubSubHooks, cmd Completed) RedisResult {
+		channels := append([]string(nil), cmd.Commands()[1:]...)
+		go func() {
+			for _, channel := range channels {
+				hooks.OnSubscription(PubSubSubscription{Kind: "subscribe", Channel: channel})
+			}
+			hooks.OnMessage(PubSubMessage{Channel: "a", Message: "1"})
+			hooks.OnMessage(PubSubMessage{Channel: "b", Message: "2"})
+		}()
+		return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+	}
+
+	t.Run("Deliver Until Canceled", func(t *testing.T) {
+		client, _, closed := setup(t, confirm)
+		ctx, cancel := context.WithCancel(context.Background())
+		ch, err := Subscribe(client, ctx, 1, "a", "b")
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if m := <-ch; m.Channel != "a" || m.Message != "1" {
+			t.Fatalf("unexpected message %v", m)
+		}
+		if m := <-ch; m.Channel != "b" || m.Message != "2" {
+			t.Fatalf("unexpected message %v", m)
+		}
+		cancel()
+		for range ch {
+		}
+		if !*closed {
+			t.Fatalf("dedicated connection should be closed")
+		}
+	})
+
+	t.Run("Close On Disconnect", func(t *testing.T) {
+		client, wait, _ := setup(t, confirm)
+		ch, err := Subscribe(client, context.Background(), 2, "a", "b")
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		<-ch
+		<-ch
+		wait <- errors.New("broken")
+		for range ch {
+		}
+	})
+
+	t.Run("Subscribe Error", func(t *testing.T) {
+		e := errors.New("subscribe err")
+		client, _, closed := setup(t, func(hooks PubSubHooks, cmd Completed) RedisResult {
+			return newErrResult(e)
+		})
+		if _, err := Subscribe(client, context.Background(), 0, "a"); err != e {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if !*closed {
+			t.Fatalf("dedicated connection should be closed")
+		}
+	})
+
+	t.Run("Disconnect Before Subscribed", func(t *testing.T) {
+		e := errors.New("broken")
+		client, wait, _ := setup(t, func(hooks PubSubHooks, cmd Completed) RedisResult {
+			return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+		})
+		wait <- e
+		if _, err := Subscribe(client, context.Background(), 0, "a"); err != e {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Canceled Before Subscribed", func(t *testing.T) {
+		client, _, _ := setup(t, func(hooks PubSubHooks, cmd Completed) RedisResult {
+			return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := Subscribe(client, ctx, 0, "a"); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package rueidis
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sharedResubscribeInterval is the delay before the SharedSubscriber subscribes its channels again on a new connection
+// after the previous one is broken.
+const sharedResubscribeInterval = time.Second
+
+// SharedSubscriber reference counts the subscriptions of the same channels across goroutines, so that many handlers
+// share one server subscription of a channel, and one of them unsubscribing doesn't stop the delivery to the others.
+// All the channels are subscribed on one dedicated connection, which is created on the first Subscribe.
+// If the connection is broken, the channels still having handlers are subscribed again on a new connection,
+// and the messages published in between are lost.
+type SharedSubscriber struct {
+	client   Client
+	dc       DedicatedClient
+	done     chan struct{}
+	handlers map[string][]*sharedHandler // replaced instead of modified in place, so that they can be called without the lock.
+	mu       sync.RWMutex
+	smu      sync.Mutex // serializes the SUBSCRIBE and UNSUBSCRIBE, and guards the dc and the closed.
+	closed   bool
+}
+
+type sharedHandler struct {
+	fn func(msg PubSubMessage)
+}
+
+// NewSharedSubscriber returns a SharedSubscriber using the dedicated connections of the client, which is not closed by the SharedSubscriber.Close.
+func NewSharedSubscriber(client Client) *SharedSubscriber {
+	return &SharedSubscriber{client: client, handlers: make(map[string][]*sharedHandler), done: make(chan struct{})}
+}
+
+// Subscribe adds the handler to the channel, and sends a SUBSCRIBE only if the channel has no handlers yet.
+// The returned unsubscribe removes the handler, and sends an UNSUBSCRIBE only if it is the last handler of the channel.
+// Handlers are called sequentially on the goroutine reading the connection, so they should not block,
+// and they must not call the Subscribe or the unsubscribe synchronously, which wait for the replies read by the same goroutine.
+// It returns ErrClosing if the SharedSubscriber is closed.
+func (s *SharedSubscriber) Subscribe(ctx context.Context, channel string, handler func(msg PubSubMessage)) (unsubscribe func(), err error) {
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	if s.closed {
+		return nil, ErrClosing
+	}
+	h := &sharedHandler{fn: handler}
+	if first := s.add(channel, h); s.dc == nil {
+		err = s.resubscribe(ctx)
+	} else if first {
+		err = s.dc.Do(ctx, s.dc.B().Subscribe().Channel(channel).Build()).Error()
+	}
+	if err != nil {
+		s.remove(channel, h)
+		return nil, err
+	}
+	once := sync.Once{}
+	return func() {
+		once.Do(func() { s.unsubscribe(channel, h) })
+	}, nil
+}
+
+// Close unsubscribes all the channels by closing the dedicated connection. Handlers are not called afterward.
+func (s *SharedSubscriber) Close() {
+	s.smu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+	if s.dc != nil {
+		s.dc.Close()
+		s.dc = nil
+	}
+	s.smu.Unlock()
+	s.mu.Lock()
+	s.handlers = make(map[string][]*sharedHandler)
+	s.mu.Unlock()
+}
+
+func (s *SharedSubscriber) unsubscribe(channel string, h *sharedHandler) {
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	if last := s.remove(channel, h); last && s.dc != nil {
+		// the channel will be subscribed again by the next Subscribe even if this fails.
+		_ = s.dc.Do(context.Background(), s.dc.B().Unsubscribe().Channel(channel).Build()).Error()
+	}
+}
+
+// add adds the h to the channel and returns true if it is the first handler of the channel.
+func (s *SharedSubscriber) add(channel string, h *sharedHandler) (first bool) {
+	s.mu.Lock()
+	hs := s.handlers[channel]
+	s.handlers[channel] = append(hs[:len(hs):len(hs)], h)
+	s.mu.Unlock()
+	return len(hs) == 0
+}
+
+// remove removes the h from the channel and returns true if it was the last handler of the channel.
+func (s *SharedSubscriber) remove(channel string, h *sharedHandler) (last bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hs := s.handlers[channel]
+	for i, v := range hs {
+		if v == h {
+			if len(hs) == 1 {
+				delete(s.handlers, channel)
+				return true
+			}
+			s.handlers[channel] = append(hs[:i:i], hs[i+1:]...)
+			return false
+		}
+	}
+	return false
+}
+
+func (s *SharedSubscriber) dispatch(m PubSubMessage) {
+	s.mu.RLock()
+	hs := s.handlers[m.Channel]
+	s.mu.RUnlock()
+	for _, h := range hs {
+		h.fn(m)
+	}
+}
+
+// resubscribe dedicates a new connection and subscribes all the channels having handlers on it. It must be called with the smu held.
+func (s *SharedSubscriber) resubscribe(ctx context.Context) error {
+	s.mu.RLock()
+	channels := make([]string, 0, len(s.handlers))
+	for channel := range s.handlers {
+		channels = append(channels, channel)
+	}
+	s.mu.RUnlock()
+	dc, _ := s.client.Dedicate()
+	wait := dc.SetPubSubHooks(PubSubHooks{OnMessage: s.dispatch})
+	s.dc = dc
+	go s.watch(dc, wait)
+	return dc.Do(ctx, dc.B().Subscribe().Channel(channels...).Build()).Error()
+}
+
+// watch waits until the dc is broken, and then subscribes the channels again on a new connection after the sharedResubscribeInterval.
+func (s *SharedSubscriber) watch(dc DedicatedClient, wait <-chan error) {
+	<-wait
+	s.smu.Lock()
+	broken := s.dc == dc // otherwise, it is closed by the Close.
+	if broken {
+		dc.Close()
+		s.dc = nil
+	}
+	s.smu.Unlock()
+	for range wait { // the hooks will not be called anymore after the wait is closed.
+	}
+	if !broken {
+		return
+	}
+
+	timer := time.NewTimer(sharedResubscribeInterval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-s.done:
+		return
+	}
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	s.mu.RLock()
+	n := len(s.handlers)
+	s.mu.RUnlock()
+	if !s.closed && s.dc == nil && n != 0 { // otherwise, the next Subscribe will do it.
+		_ = s.resubscribe(context.Background())
+	}
+}
@@ -0,0 +1,160 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/rueidis/internal/cmds"
+)
+
+type sharedDedicated struct {
+	DedicatedClient
+	hooks  PubSubHooks
+	wait   chan error
+	err    error
+	sent   [][]string
+	mu     sync.Mutex
+	closed bool
+}
+
+func (d *sharedDedicated) B() Builder {
+	return cmds.NewBuilder(cmds.NoSlot)
+}
+
+func (d *sharedDedicated) SetPubSubHooks(hooks PubSubHooks) <-chan error {
+	d.hooks = hooks
+	return d.wait
+}
+
+func (d *sharedDedicated) Do(ctx context.Context, cmd Completed) RedisResult {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sent = append(d.sent, append([]string(nil), cmd.Commands()...))
+	if d.err != nil {
+		return newErrResult(d.err)
+	}
+	return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+}
+
+func (d *sharedDedicated) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.closed {
+		d.closed = true
+		close(d.wait)
+	}
+}
+
+func (d *sharedDedicated) commands() [][]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sent
+}
+
+func (d *sharedDedicated) publish(channel, message string) {
+	d.hooks.OnMessage(PubSubMessage{Channel: channel, Message: message})
+}
+
+func TestSharedSubscriber(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var mu sync.Mutex
+	var dcs []*sharedDedicated
+	c := &client{
+		DedicateFn: func() (DedicatedClient, func()) {
+			mu.Lock()
+			defer mu.Unlock()
+			dc := &sharedDedicated{wait: make(chan error, 1)}
+			dcs = append(dcs, dc)
+			return dc, func() {}
+		},
+	}
+	last := func() *sharedDedicated {
+		mu.Lock()
+		defer mu.Unlock()
+		return dcs[len(dcs)-1]
+	}
+	s := NewSharedSubscriber(c)
+	ctx := context.Background()
+
+	var received1, received2 []string
+	unsubscribe1, err := s.Subscribe(ctx, "a", func(msg PubSubMessage) { received1 = append(received1, msg.Message) })
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	unsubscribe2, err := s.Subscribe(ctx, "a", func(msg PubSubMessage) { received2 = append(received2, msg.Message) })
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	unsubscribe3, err := s.Subscribe(ctx, "b", func(msg PubSubMessage) {})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	dc := last()
+	if len(dcs) != 1 || !reflect.DeepEqual(dc.commands(), [][]string{{"SUBSCRIBE", "a"}, {"SUBSCRIBE", "b"}}) {
+		t.Fatalf("unexpected commands %v", dc.commands())
+	}
+
+	dc.publish("a", "1")
+	unsubscribe1()
+	unsubscribe1()
+	dc.publish("a", "2")
+	if !reflect.DeepEqual(received1, []string{"1"}) || !reflect.DeepEqual(received2, []string{"1", "2"}) {
+		t.Fatalf("unexpected messages %v %v", received1, received2)
+	}
+	if len(dc.commands()) != 2 {
+		t.Fatalf("unexpected commands %v", dc.commands())
+	}
+	unsubscribe2()
+	if !reflect.DeepEqual(dc.commands()[2], []string{"UNSUBSCRIBE", "a"}) {
+		t.Fatalf("unexpected commands %v", dc.commands())
+	}
+
+	t.Run("Subscribe Failure", func(t *testing.T) {
+		dc.err = errors.New("subscribe")
+		if _, err := s.Subscribe(ctx, "c", func(msg PubSubMessage) {}); err != dc.err {
+			t.Fatalf("unexpected err %v", err)
+		}
+		dc.err = nil
+		unsubscribe, err := s.Subscribe(ctx, "c", func(msg PubSubMessage) {})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		unsubscribe()
+		if cmds := dc.commands(); !reflect.DeepEqual(cmds[len(cmds)-2:], [][]string{{"SUBSCRIBE", "c"}, {"UNSUBSCRIBE", "c"}}) {
+			t.Fatalf("unexpected commands %v", cmds)
+		}
+	})
+
+	t.Run("Resubscribe After Broken", func(t *testing.T) {
+		dc.wait <- errors.New("broken")
+		for {
+			mu.Lock()
+			n := len(dcs)
+			mu.Unlock()
+			if n == 2 {
+				break
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		if !reflect.DeepEqual(last().commands(), [][]string{{"SUBSCRIBE", "b"}}) {
+			t.Fatalf("unexpected commands %v", last().commands())
+		}
+		unsubscribe3()
+		if !reflect.DeepEqual(last().commands(), [][]string{{"SUBSCRIBE", "b"}, {"UNSUBSCRIBE", "b"}}) {
+			t.Fatalf("unexpected commands %v", last().commands())
+		}
+	})
+
+	s.Close()
+	if !last().closed {
+		t.Fatal("the dedicated connection should be closed")
+	}
+	if _, err := s.Subscribe(ctx, "a", func(msg PubSubMessage) {}); err != ErrClosing {
+		t.Fatalf("unexpected err %v", err)
+	}
+	s.Close()
+}
@@ -0,0 +1,22 @@
+package rueidis
+
+import (
+	"context"
+	"time"
+)
+
+type swrKey struct{}
+
+type swrStore interface {
+	FlightSWR(key, cmd string, ttl, fresh time.Duration, now time.Time) (v RedisMessage, e CacheEntry, refresh bool)
+}
+
+// DoCacheSWR is like client.DoCache with the staleTTL as the client side ttl, but with stale-while-revalidate:
+// A cached response younger than the freshTTL is returned directly. An older one is still returned immediately,
+// but the cmd is also sent in the background to refresh the cache, and the stale response keeps being served until the refresh is done.
+// A response older than the staleTTL is expired and therefore the call blocks on fetching a fresh one as usual.
+// Note that the cached responses are still invalidated by redis immediately when their keys are changed,
+// and the stale-while-revalidate only applies to the default CacheStore. Other CacheStores fall back to client.DoCache.
+func DoCacheSWR(client Client, ctx context.Context, cmd Cacheable, freshTTL, staleTTL time.Duration) RedisResult {
+	return client.DoCache(context.WithValue(ctx, swrKey{}, freshTTL), cmd, staleTTL)
+}
@@ -0,0 +1,77 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTxnAborted is returned from DoTxn if every EXEC is aborted because the watched keys are changed.
+var ErrTxnAborted = errors.New("transaction is aborted because the watched keys are changed")
+
+// TxnPipe is passed to the fn of DoTxn to read the watched keys and to queue commands for the MULTI EXEC.
+type TxnPipe struct {
+	client DedicatedClient
+	queued Commands
+}
+
+// B is the getter function to the command builder for the transaction
+func (t *TxnPipe) B() Builder {
+	return t.client.B()
+}
+
+// Do sends the cmd immediately on the connection which watches the keys. It is typically used to read the watched keys.
+func (t *TxnPipe) Do(ctx context.Context, cmd Completed) RedisResult {
+	return t.client.Do(ctx, cmd)
+}
+
+// Queue appends the cmds to the transaction. They will be sent in a MULTI EXEC after the fn of DoTxn returns.
+func (t *TxnPipe) Queue(cmds ...Completed) {
+	t.queued = append(t.queued, cmds...)
+}
+
+// DoTxn performs an optimistic transaction on a dedicated connection. It WATCHes the keys, calls the fn to read and queue commands,
+// and then sends the queued commands in a MULTI EXEC. If the EXEC is aborted because the watched keys are changed,
+// the whole fn is retried until the maxAttempts is reached, and then ErrTxnAborted is returned. A non-positive maxAttempts means no limit.
+// It returns the results of the queued commands, or the error returned by the fn without sending the EXEC.
+// In cluster mode, the keys and the queued commands should belong to the same slot.
+func DoTxn(client Client, ctx context.Context, keys []string, fn func(ctx context.Context, tx *TxnPipe) error, maxAttempts int) (resps []RedisResult, err error) {
+	for attempts := 1; ; attempts++ {
+		aborted := false
+		err = client.Dedicated(func(c DedicatedClient) error {
+			if err := c.Do(ctx, c.B().Watch().Key(keys...).Build()).Error(); err != nil {
+				return err
+			}
+			tx := &TxnPipe{client: c}
+			if err := fn(ctx, tx); err != nil || len(tx.queued) == 0 {
+				c.Do(ctx, c.B().Unwatch().Build())
+				return err
+			}
+			multi := make(Commands, 0, len(tx.queued)+2)
+			multi = append(multi, c.B().Multi().Build())
+			multi = append(multi, tx.queued...)
+			multi = append(multi, c.B().Exec().Build())
+			exec := c.DoMulti(ctx, multi...)[len(multi)-1]
+			msgs, err := exec.ToArray()
+			if IsRedisNil(err) {
+				aborted = true
+				return nil
+			} else if err != nil {
+				return err
+			}
+			resps = make([]RedisResult, len(msgs))
+			for i, msg := range msgs {
+				resps[i] = newResult(msg, nil)
+			}
+			return nil
+		})
+		if err != nil || !aborted {
+			return resps, err
+		}
+		if maxAttempts > 0 && attempts >= maxAttempts {
+			return nil, ErrTxnAborted
+		}
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDoTxn(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+
+	setup := func(t *testing.T, exec func(attempt int) RedisResult) (*singleClient, *[]string) {
+		var sent []string
+		attempt := 0
+		w := &mockWire{
+			DoFn: func(cmd Completed) RedisResult {
+				sent = append(sent, strings.Join(cmd.Commands(), " "))
+				if cmd.Commands()[0] == "GET" {
+					return newResult(RedisMessage{typ: '+', string: "1"}, nil)
+				}
+				return newResult(RedisMessage{typ: '+', string: "OK"}, nil)
+			},
+			DoMultiFn: func(multi ...Completed) *redisresults {
+				resps := make([]RedisResult, len(multi))
+				for i, cmd := range multi {
+					sent = append(sent, strings.Join(cmd.Commands(), " "))
+					resps[i] = newResult(RedisMessage{typ: '+', string: "QUEUED"}, nil)
+				}
+				attempt++
+				resps[len(resps)-1] = exec(attempt)
+				return &redisresults{s: resps}
+			},
+		}
+		m := &mockConn{
+			AcquireFn: func() wire { return w },
+			StoreFn:   func(ww wire) {},
+		}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client, &sent
+	}
+
+	incr := func(ctx context.Context, tx *TxnPipe) error {
+		v, err := tx.Do(ctx, tx.B().Get().Key("k").Build()).AsInt64()
+		if err != nil {
+			return err
+		}
+		tx.Queue(tx.B().Set().Key("k").Value(strconv.FormatInt(v+1, 10)).Build())
+		return nil
+	}
+
+	t.Run("Exec", func(t *testing.T) {
+		client, sent := setup(t, func(attempt int) RedisResult {
+			return newResult(RedisMessage{typ: '*', values: []RedisMessage{{typ: '+', string: "OK"}}}, nil)
+		})
+		resps, err := DoTxn(client, context.Background(), []string{"k"}, incr, 3)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if len(resps) != 1 || resps[0].val.string != "OK" {
+			t.Fatalf("unexpected resps %v", resps)
+		}
+		if exp := []string{"WATCH k", "GET k", "MULTI", "SET k 2", "EXEC"}; strings.Join(*sent, ",") != strings.Join(exp, ",") {
+			t.Fatalf("unexpected commands %v", *sent)
+		}
+	})
+
+	t.Run("Retry Aborted", func(t *testing.T) {
+		client, sent := setup(t, func(attempt int) RedisResult {
+			if attempt < 3 {
+				return newResult(RedisMessage{typ: '_'}, nil)
+			}
+			return newResult(RedisMessage{typ: '*', values: []RedisMessage{{typ: '+', string: "OK"}}}, nil)
+		})
+		if _, err := DoTxn(client, context.Background(), []string{"k"}, incr, 3); err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if len(*sent) != 15 {
+			t.Fatalf("unexpected commands %v", *sent)
+		}
+	})
+
+	t.Run("Max Attempts", func(t *testing.T) {
+		client, _ := setup(t, func(attempt int) RedisResult {
+			return newResult(RedisMessage{typ: '_'}, nil)
+		})
+		if _, err := DoTxn(client, context.Background(), []string{"k"}, incr, 3); err != ErrTxnAborted {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Fn Error", func(t *testing.T) {
+		client, sent := setup(t, func(attempt int) RedisResult {
+			t.Fatalf("EXEC should not be sent")
+			return RedisResult{}
+		})
+		e := errors.New("fn err")
+		if _, err := DoTxn(client, context.Background(), []string{"k"}, func(ctx context.Context, tx *TxnPipe) error {
+			return e
+		}, 3); err != e {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if exp := []string{"WATCH k", "UNWATCH"}; strings.Join(*sent, ",") != strings.Join(exp, ",") {
+			t.Fatalf("unexpected commands %v", *sent)
+		}
+	})
+
+	t.Run("Exec Error", func(t *testing.T) {
+		client, _ := setup(t, func(attempt int) RedisResult {
+			return newResult(RedisMessage{typ: '-', string: "EXECABORT"}, nil)
+		})
+		if _, err := DoTxn(client, context.Background(), []string{"k"}, incr, 3); err == nil || err.Error() != "EXECABORT" {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
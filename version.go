@@ -0,0 +1,80 @@
+package rueidis
+
+import (
+	"strconv"
+	"strings"
+)
+
+// capabilities are the minimal redis versions of commands used by helpers that have fallbacks for older servers.
+var capabilities = map[string]serverVersion{
+	"UNLINK":     {4, 0, 0},
+	"RESET":      {6, 2, 0},
+	"GETEX":      {6, 2, 0},
+	"GETDEL":     {6, 2, 0},
+	"XAUTOCLAIM": {6, 2, 0},
+	"SINTERCARD": {7, 0, 0},
+	"LMPOP":      {7, 0, 0},
+	"ZMPOP":      {7, 0, 0},
+	"FUNCTION":   {7, 0, 0},
+}
+
+type serverVersion [3]int
+
+func parseServerVersion(version string) (v serverVersion, ok bool) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) == 0 || parts[0] == "" {
+		return v, false
+	}
+	for i, p := range parts {
+		if j := strings.IndexFunc(p, func(r rune) bool { return r < '0' || r > '9' }); j >= 0 {
+			p = p[:j] // ignore suffixes like "-rc1"
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+func (v serverVersion) less(o serverVersion) bool {
+	for i := range v {
+		if v[i] != o[i] {
+			return v[i] < o[i]
+		}
+	}
+	return false
+}
+
+// lowestVersion returns the lowest valid version in the versions, or an empty string if there is none.
+func lowestVersion(versions ...string) (lowest string) {
+	var lv serverVersion
+	for _, version := range versions {
+		if v, ok := parseServerVersion(version); ok && (lowest == "" || v.less(lv)) {
+			lowest, lv = version, v
+		}
+	}
+	return lowest
+}
+
+// ServerVersion returns the redis version reported by the HELLO handshake. For cluster clients, it is the lowest version
+// of the connected nodes. It returns an empty string if the version is unknown, for example, the connections use RESP2,
+// or the client is not created by the NewClient, such as a mock or a wrapped client.
+func ServerVersion(client Client) string {
+	if c, ok := client.(interface{ serverVersion() string }); ok {
+		return c.serverVersion()
+	}
+	return ""
+}
+
+// supports reports whether the command is supported by the server version of the client according to the capabilities.
+// Commands are assumed to be supported if the version is unknown or they are not listed in the capabilities.
+func supports(client Client, command string) bool {
+	min, ok := capabilities[command]
+	if !ok {
+		return true
+	}
+	v, ok := parseServerVersion(ServerVersion(client))
+	return !ok || !v.less(min)
+}
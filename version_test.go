@@ -0,0 +1,77 @@
+package rueidis
+
+import (
+	"testing"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	for version, expected := range map[string]serverVersion{
+		"7.2.4":     {7, 2, 4},
+		"6.2":       {6, 2, 0},
+		"7":         {7, 0, 0},
+		"7.4.0-rc1": {7, 4, 0},
+	} {
+		if v, ok := parseServerVersion(version); !ok || v != expected {
+			t.Fatalf("unexpected version %v of %v", v, version)
+		}
+	}
+	for _, version := range []string{"", "x.1", "7.x"} {
+		if _, ok := parseServerVersion(version); ok {
+			t.Fatalf("unexpected ok of %v", version)
+		}
+	}
+	if v := lowestVersion("7.2.0", "", "6.2.14", "7.0.0"); v != "6.2.14" {
+		t.Fatalf("unexpected lowest version %v", v)
+	}
+	if v := lowestVersion("", "x"); v != "" {
+		t.Fatalf("unexpected lowest version %v", v)
+	}
+}
+
+func TestServerVersion(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	t.Run("single client", func(t *testing.T) {
+		m := &mockConn{
+			InfoFn: func() map[string]RedisMessage {
+				return map[string]RedisMessage{"version": {typ: '+', string: "6.0.5"}}
+			},
+		}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if v := ServerVersion(client); v != "6.0.5" {
+			t.Fatalf("unexpected version %v", v)
+		}
+		if !supports(client, "UNLINK") || supports(client, "SINTERCARD") || !supports(client, "GET") {
+			t.Fatal("unexpected capabilities")
+		}
+		if v := ServerVersion(struct{ Client }{client}); v != "" {
+			t.Fatalf("unexpected version %v", v)
+		}
+		if !supports(struct{ Client }{client}, "SINTERCARD") {
+			t.Fatal("commands should be supported if the version is unknown")
+		}
+	})
+	t.Run("cluster client", func(t *testing.T) {
+		versions := map[string]string{":0": "7.2.0", "127.0.0.1:0": "7.0.0", "127.0.1.1:1": "6.2.0"}
+		client, err := newClusterClient(&ClientOption{InitAddress: []string{":0"}}, func(dst string, opt *ClientOption) conn {
+			return &mockConn{
+				DoFn: func(cmd Completed) RedisResult {
+					return slotsResp
+				},
+				InfoFn: func() map[string]RedisMessage {
+					return map[string]RedisMessage{"version": {typ: '+', string: versions[dst]}}
+				},
+			}
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if v := ServerVersion(client); v != "6.2.0" {
+			t.Fatalf("unexpected version %v", v)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotEnoughReplicas is returned from DoWait if the write is not acknowledged by the requested number of replicas within the timeout.
+var ErrNotEnoughReplicas = errors.New("the write is not acknowledged by enough replicas")
+
+// DoWait sends the cmd followed by a WAIT numReplicas timeout in a DoMulti, so that they are sent on the same connection,
+// and returns the reply of the cmd. In cluster mode, both are sent to the master of the slot of the cmd.
+// If the cmd succeeds but fewer than numReplicas replicas acknowledge it within the timeout, the reply of the cmd is returned
+// with an error wrapping the ErrNotEnoughReplicas. Note that the write is not rolled back in that case.
+// A zero timeout blocks until the numReplicas is reached, the same as the WAIT command, unless the ctx is done.
+func DoWait(client Client, ctx context.Context, cmd Completed, numReplicas int, timeout time.Duration) (RedisResult, error) {
+	resps := client.DoMulti(ctx, cmd, client.B().Wait().Numreplicas(int64(numReplicas)).Timeout(timeout.Milliseconds()).Build())
+	if err := resps[0].Error(); err != nil {
+		return resps[0], err
+	}
+	acked, err := resps[1].AsInt64()
+	if err != nil {
+		return resps[0], err
+	}
+	if acked < int64(numReplicas) {
+		return resps[0], fmt.Errorf("%w: %d of %d replicas acknowledged", ErrNotEnoughReplicas, acked, numReplicas)
+	}
+	return resps[0], nil
+}
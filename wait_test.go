@@ -0,0 +1,118 @@
+package rueidis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDoWait(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+
+	setup := func(t *testing.T, fn func(multi ...Completed) *redisresults) *singleClient {
+		m := &mockConn{DoMultiFn: fn}
+		client, err := newSingleClient(&ClientOption{InitAddress: []string{""}, DisableRetry: true}, m, func(dst string, opt *ClientOption) conn {
+			return m
+		})
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		return client
+	}
+	reply := func(set RedisMessage, wait RedisMessage) func(multi ...Completed) *redisresults {
+		return func(multi ...Completed) *redisresults {
+			return &redisresults{s: []RedisResult{newResult(set, nil), newResult(wait, nil)}}
+		}
+	}
+
+	t.Run("Acknowledged", func(t *testing.T) {
+		var sent [][]string
+		client := setup(t, func(multi ...Completed) *redisresults {
+			for _, cmd := range multi {
+				sent = append(sent, append([]string(nil), cmd.Commands()...))
+			}
+			return reply(RedisMessage{typ: '+', string: "OK"}, RedisMessage{typ: ':', integer: 2})(multi...)
+		})
+		resp, err := DoWait(client, context.Background(), client.B().Set().Key("a").Value("b").Build(), 2, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if v, err := resp.ToString(); err != nil || v != "OK" {
+			t.Fatalf("unexpected response %v %v", v, err)
+		}
+		if !reflect.DeepEqual(sent, [][]string{{"SET", "a", "b"}, {"WAIT", "2", "1000"}}) {
+			t.Fatalf("unexpected commands %v", sent)
+		}
+	})
+
+	t.Run("Not Enough Replicas", func(t *testing.T) {
+		client := setup(t, reply(RedisMessage{typ: '+', string: "OK"}, RedisMessage{typ: ':', integer: 1}))
+		resp, err := DoWait(client, context.Background(), client.B().Set().Key("a").Value("b").Build(), 2, time.Second)
+		if !errors.Is(err, ErrNotEnoughReplicas) || !strings.Contains(err.Error(), "1 of 2") {
+			t.Fatalf("unexpected err %v", err)
+		}
+		if v, err := resp.ToString(); err != nil || v != "OK" {
+			t.Fatalf("the reply of the write should be returned %v %v", v, err)
+		}
+	})
+
+	t.Run("Command Error", func(t *testing.T) {
+		client := setup(t, reply(RedisMessage{typ: '-', string: "WRONGTYPE"}, RedisMessage{typ: ':', integer: 2}))
+		if _, err := DoWait(client, context.Background(), client.B().Set().Key("a").Value("b").Build(), 2, time.Second); err == nil {
+			t.Fatalf("unexpected err %v", err)
+		} else if ret, ok := IsRedisErr(err); !ok || ret.string != "WRONGTYPE" {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+
+	t.Run("Wait Error", func(t *testing.T) {
+		client := setup(t, reply(RedisMessage{typ: '+', string: "OK"}, RedisMessage{typ: '-', string: "ERR WAIT cannot be used with replica instances"}))
+		if _, err := DoWait(client, context.Background(), client.B().Set().Key("a").Value("b").Build(), 1, 0); err == nil || errors.Is(err, ErrNotEnoughReplicas) {
+			t.Fatalf("unexpected err %v", err)
+		}
+	})
+}
+
+func TestDoWaitCluster(t *testing.T) {
+	defer ShouldNotLeaked(SetupLeakDetection())
+	var sent [][]string
+	other := &mockConn{
+		DoFn: func(cmd Completed) RedisResult {
+			if strings.Join(cmd.Commands(), " ") == "CLUSTER SLOTS" {
+				return slotsMultiResp
+			}
+			return newErrResult(errors.New("unexpected call"))
+		},
+		DoMultiFn: func(multi ...Completed) *redisresults {
+			t.Fatalf("unexpected node of %v", multi[0].Commands())
+			return nil
+		},
+	}
+	owner := &mockConn{
+		DoMultiFn: func(multi ...Completed) *redisresults {
+			for _, cmd := range multi {
+				sent = append(sent, append([]string(nil), cmd.Commands()...))
+			}
+			return &redisresults{s: []RedisResult{newResult(RedisMessage{typ: '+', string: "OK"}, nil), newResult(RedisMessage{typ: ':', integer: 1}, nil)}}
+		},
+	}
+	client, err := newClusterClient(&ClientOption{InitAddress: []string{"127.0.0.1:0"}}, func(dst string, opt *ClientOption) conn {
+		if dst == "127.0.2.1:0" {
+			return owner
+		}
+		return other
+	})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	// the slot of "a" is 15495, which is served by 127.0.2.1:0
+	if _, err := DoWait(client, context.Background(), client.B().Set().Key("a").Value("b").Build(), 1, time.Second); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if !reflect.DeepEqual(sent, [][]string{{"SET", "a", "b"}, {"WAIT", "1", "1000"}}) {
+		t.Fatalf("unexpected commands %v", sent)
+	}
+}